@@ -0,0 +1,243 @@
+/*
+fincalc calcula VAN, TIR, ROI y payback de un flujo de efectivo sin levantar
+el servicio.
+
+Uso:
+
+	fincalc [--rate 0.1] [--format auto|json|csv] [--json] [archivo]
+
+Sin archivo (o con "-") lee de stdin. La entrada JSON usa los mismos campos
+que /api/v1/internal/calculate:
+
+	{"inversion_inicial": 1000, "flujos_ingresos": [500, 500, 500], "flujos_costos": [], "tasa_descuento": 0.1}
+
+La entrada CSV tiene un flujo neto por fila, empezando por el periodo 0
+(negativo para una inversión), como la función TIR de una hoja de cálculo; si
+la fila tiene varias columnas se usa la última y se ignora un encabezado no
+numérico. Con CSV la tasa se pasa con --rate.
+*/
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/fincore/core-go/internal/finance"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// entrada es un flujo de efectivo leído de JSON o CSV
+type entrada struct {
+	InversionInicial float64   `json:"inversion_inicial"`
+	FlujosIngresos   []float64 `json:"flujos_ingresos"`
+	FlujosCostos     []float64 `json:"flujos_costos"`
+	TasaDescuento    *float64  `json:"tasa_descuento"`
+}
+
+// resultado son las métricas impresas; usa las mismas claves que la API
+type resultado struct {
+	VAN               float64  `json:"van"`
+	TIR               *float64 `json:"tir"`
+	TIRError          string   `json:"tir_error,omitempty"`
+	ROI               float64  `json:"roi"`
+	PaybackMeses      float64  `json:"payback_meses"`
+	RecuperaInversion bool     `json:"recupera_inversion"`
+	TasaDescuento     float64  `json:"tasa_descuento"`
+}
+
+// run ejecuta la herramienta y retorna el código de salida: 0 si tuvo éxito,
+// 1 si la entrada no se pudo procesar y 2 si los argumentos son inválidos
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("fincalc", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	rate := flags.Float64("rate", 0, "tasa de descuento por periodo (p. ej. 0.1); tiene prioridad sobre tasa_descuento")
+	format := flags.String("format", "auto", "formato de la entrada: auto, json o csv")
+	jsonOutput := flags.Bool("json", false, "imprime el resultado como JSON")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() > 1 {
+		fmt.Fprintln(stderr, "fincalc: expected at most one input file")
+		return 2
+	}
+	rateSet := false
+	flags.Visit(func(f *flag.Flag) { rateSet = rateSet || f.Name == "rate" })
+
+	path := flags.Arg(0)
+	var input io.Reader = stdin
+	if path != "" && path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "fincalc: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		input = file
+	}
+
+	data, err := io.ReadAll(input)
+	if err != nil {
+		fmt.Fprintf(stderr, "fincalc: reading input: %v\n", err)
+		return 1
+	}
+
+	var in entrada
+	switch detectarFormato(*format, path, data) {
+	case "json":
+		in, err = leerJSON(data)
+	case "csv":
+		in, err = leerCSV(data)
+	default:
+		fmt.Fprintf(stderr, "fincalc: unknown format %q\n", *format)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "fincalc: %v\n", err)
+		return 1
+	}
+	if rateSet {
+		in.TasaDescuento = rate
+	}
+
+	res, err := calcular(in)
+	if err != nil {
+		fmt.Fprintf(stderr, "fincalc: %v\n", err)
+		return 1
+	}
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(res); err != nil {
+			fmt.Fprintf(stderr, "fincalc: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	imprimir(stdout, res)
+	return 0
+}
+
+// detectarFormato resuelve "auto" por la extensión del archivo o, para stdin,
+// por el primer carácter de la entrada
+func detectarFormato(format, path string, data []byte) string {
+	format = strings.ToLower(format)
+	if format != "auto" {
+		return format
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return "json"
+	case ".csv":
+		return "csv"
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		return "json"
+	}
+	return "csv"
+}
+
+func leerJSON(data []byte) (entrada, error) {
+	var in entrada
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&in); err != nil {
+		return entrada{}, fmt.Errorf("invalid JSON input: %w", err)
+	}
+	return in, nil
+}
+
+// leerCSV interpreta la primera fila numérica como el flujo del periodo 0
+func leerCSV(data []byte) (entrada, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	var flujos []float64
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return entrada{}, fmt.Errorf("invalid CSV input: %w", err)
+		}
+		valor := strings.TrimSpace(record[len(record)-1])
+		flujo, err := strconv.ParseFloat(valor, 64)
+		if err != nil {
+			// Sólo la primera fila puede ser un encabezado
+			if row == 1 {
+				continue
+			}
+			return entrada{}, fmt.Errorf("CSV row %d: invalid amount %q", row, valor)
+		}
+		flujos = append(flujos, flujo)
+	}
+	if len(flujos) == 0 {
+		return entrada{}, errors.New("CSV input has no cash flows")
+	}
+	return entrada{InversionInicial: -flujos[0], FlujosIngresos: flujos[1:]}, nil
+}
+
+// calcular obtiene las métricas con el paquete finance
+func calcular(in entrada) (resultado, error) {
+	if in.TasaDescuento == nil {
+		return resultado{}, errors.New("discount rate is required: set tasa_descuento or --rate")
+	}
+	if in.InversionInicial == 0 {
+		return resultado{}, errors.New("inversion_inicial must be non-zero")
+	}
+	if len(in.FlujosIngresos) == 0 {
+		return resultado{}, errors.New("at least one cash flow after period 0 is required")
+	}
+	if len(in.FlujosCostos) > len(in.FlujosIngresos) {
+		return resultado{}, errors.New("flujos_costos cannot be longer than flujos_ingresos")
+	}
+
+	tasa := *in.TasaDescuento
+	flujos := finance.NetFlows(in.FlujosIngresos, in.FlujosCostos)
+	payback, recupera := finance.Payback(in.InversionInicial, flujos)
+	res := resultado{
+		VAN:               finance.NPV(tasa, in.InversionInicial, flujos),
+		ROI:               finance.ROI(in.InversionInicial, flujos),
+		PaybackMeses:      payback,
+		RecuperaInversion: recupera,
+		TasaDescuento:     tasa,
+	}
+	if tir, _, err := finance.IRR(in.InversionInicial, flujos); err != nil {
+		res.TIRError = err.Error()
+	} else {
+		res.TIR = &tir
+	}
+	return res, nil
+}
+
+// imprimir escribe el resultado en texto para lectura directa
+func imprimir(w io.Writer, res resultado) {
+	fmt.Fprintf(w, "Tasa:    %.4f%%\n", res.TasaDescuento*100)
+	fmt.Fprintf(w, "VAN:     %.2f\n", res.VAN)
+	if res.TIR != nil {
+		fmt.Fprintf(w, "TIR:     %.4f%%\n", *res.TIR*100)
+	} else {
+		fmt.Fprintf(w, "TIR:     n/d (%s)\n", res.TIRError)
+	}
+	fmt.Fprintf(w, "ROI:     %.4f%%\n", res.ROI*100)
+	if res.RecuperaInversion {
+		fmt.Fprintf(w, "Payback: %.2f periodos\n", res.PaybackMeses)
+	} else {
+		fmt.Fprintln(w, "Payback: no se recupera la inversión")
+	}
+}
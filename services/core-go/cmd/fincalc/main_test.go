@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runWith ejecuta la herramienta con stdin dado y retorna código, stdout y stderr
+func runWith(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRunJSONFromStdin(t *testing.T) {
+	code, out, errOut := runWith(t, `{"inversion_inicial":1000,"flujos_ingresos":[500,500,500],"tasa_descuento":0.1}`)
+	if code != 0 {
+		t.Fatalf("exit code = %d, stderr %s", code, errOut)
+	}
+
+	for _, want := range []string{
+		"VAN:     243.43\n",
+		"TIR:     23.3752%\n",
+		"ROI:     50.0000%\n",
+		"Payback: 2.00 periodos\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRunCSVFileJSONOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flujos.csv")
+	csv := "periodo,flujo\n0,-70000\n1,12000\n2,15000\n3,18000\n4,21000\n5,26000\n"
+	if err := os.WriteFile(path, []byte(csv), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	code, out, errOut := runWith(t, "", "--json", "--rate", "0.08", path)
+	if code != 0 {
+		t.Fatalf("exit code = %d, stderr %s", code, errOut)
+	}
+
+	var res resultado
+	if err := json.Unmarshal([]byte(out), &res); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out)
+	}
+	if res.TIR == nil || math.Abs(*res.TIR-0.0866309) > 1e-6 {
+		t.Errorf("tir = %v, want ≈ 0.0866309", res.TIR)
+	}
+	if math.Abs(res.VAN-1390.96) > 0.01 {
+		t.Errorf("van = %v, want ≈ 1390.96", res.VAN)
+	}
+	if !res.RecuperaInversion || math.Abs(res.PaybackMeses-(4+4000.0/26000)) > 1e-9 {
+		t.Errorf("payback = (%v, %v), want (4.1538, true)", res.PaybackMeses, res.RecuperaInversion)
+	}
+	if res.TasaDescuento != 0.08 {
+		t.Errorf("tasa_descuento = %v, want 0.08", res.TasaDescuento)
+	}
+}
+
+func TestRunRateFlagOverridesJSON(t *testing.T) {
+	input := `{"inversion_inicial":1000,"flujos_ingresos":[1100],"tasa_descuento":0.5}`
+	code, out, _ := runWith(t, input, "--json", "--rate", "0.1")
+	if code != 0 {
+		t.Fatalf("exit code = %d", code)
+	}
+	var res resultado
+	if err := json.Unmarshal([]byte(out), &res); err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(res.VAN) > 1e-9 {
+		t.Errorf("van = %v, want 0 at the --rate of 10%%", res.VAN)
+	}
+}
+
+func TestRunWithoutIRR(t *testing.T) {
+	code, out, _ := runWith(t, "-1000\n-100\n", "--rate", "0.1")
+	if code != 0 {
+		t.Fatalf("exit code = %d", code)
+	}
+	if !strings.Contains(out, "TIR:     n/d (cash flows have no sign change, IRR is undefined)") {
+		t.Errorf("output = %s", out)
+	}
+	if !strings.Contains(out, "Payback: no se recupera la inversión") {
+		t.Errorf("output = %s", out)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		stdin string
+		args  []string
+		code  int
+	}{
+		{"sin tasa", "-1000\n500\n", nil, 1},
+		{"json inválido", `{"inversion_inicial":`, nil, 1},
+		{"campo desconocido", `{"inversion":1000}`, nil, 1},
+		{"monto inválido", "-1000\nmil\n", []string{"--rate", "0.1"}, 1},
+		{"archivo inexistente", "", []string{"--rate", "0.1", "/nonexistent/flujos.csv"}, 1},
+		{"formato desconocido", "", []string{"--format", "xml"}, 2},
+		{"flag desconocido", "", []string{"--verbose"}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, errOut := runWith(t, tt.stdin, tt.args...)
+			if code != tt.code {
+				t.Errorf("exit code = %d, want %d (stderr %s)", code, tt.code, errOut)
+			}
+			if errOut == "" {
+				t.Error("expected an error message on stderr")
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultAllowedOrigins se usan cuando CORS_ALLOWED_ORIGINS no está
+// configurada o no es válida
+var defaultAllowedOrigins = []string{
+	"http://localhost:3000",
+	"https://fincore.app",
+}
+
+// originPattern es un origen permitido; con wildcard acepta cualquier
+// subdominio de host (https://*.fincore.app)
+type originPattern struct {
+	scheme   string
+	host     string
+	wildcard bool
+}
+
+func (p originPattern) matches(origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || u.Scheme != p.scheme || u.Host == "" {
+		return false
+	}
+	if !p.wildcard {
+		return u.Host == p.host
+	}
+	subdomain, ok := strings.CutSuffix(u.Host, "."+p.host)
+	return ok && subdomain != ""
+}
+
+// parseOriginPattern valida un origen "esquema://host[:puerto]" o
+// "esquema://*.host"
+func parseOriginPattern(raw string) (originPattern, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return originPattern{}, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return originPattern{}, fmt.Errorf("origin %q must use http or https", raw)
+	}
+	if u.Host == "" || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.User != nil {
+		return originPattern{}, fmt.Errorf("origin %q must be scheme://host[:port]", raw)
+	}
+
+	pattern := originPattern{scheme: u.Scheme, host: u.Host}
+	if host, ok := strings.CutPrefix(u.Host, "*."); ok {
+		pattern.host = host
+		pattern.wildcard = true
+	}
+	if strings.Contains(pattern.host, "*") {
+		return originPattern{}, fmt.Errorf("origin %q: wildcard only allowed as leading subdomain", raw)
+	}
+	return pattern, nil
+}
+
+// parseAllowedOrigins convierte la lista separada por comas; cualquier
+// entrada inválida invalida toda la configuración
+func parseAllowedOrigins(raw string) ([]originPattern, error) {
+	var patterns []originPattern
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		pattern, err := parseOriginPattern(entry)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, pattern)
+	}
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("no origins configured")
+	}
+	return patterns, nil
+}
+
+// allowedOriginsFromEnv lee CORS_ALLOWED_ORIGINS una sola vez al construir el router
+func allowedOriginsFromEnv() []originPattern {
+	raw := os.Getenv("CORS_ALLOWED_ORIGINS")
+	if raw != "" {
+		patterns, err := parseAllowedOrigins(raw)
+		if err == nil {
+			return patterns
+		}
+		slog.Warn("Invalid CORS_ALLOWED_ORIGINS, using defaults", "error", err, "defaults", defaultAllowedOrigins)
+	}
+
+	patterns, _ := parseAllowedOrigins(strings.Join(defaultAllowedOrigins, ","))
+	return patterns
+}
+
+// CORS Middleware
+func corsMiddleware(allowedOrigins []originPattern) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		c.Header("Vary", "Origin")
+
+		for _, allowed := range allowedOrigins {
+			if origin != "" && allowed.matches(origin) {
+				c.Header("Access-Control-Allow-Origin", origin)
+				break
+			}
+		}
+
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Authorization, Content-Type, X-Service-Token, Idempotency-Key, X-Timestamp, X-Nonce")
+		c.Header("Access-Control-Max-Age", "86400")
+
+		if c.Request.Method == "OPTIONS" {
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
+}
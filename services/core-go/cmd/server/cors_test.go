@@ -0,0 +1,64 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func corsOrigin(t *testing.T, patterns []originPattern, origin string) string {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(corsMiddleware(patterns))
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Origin", origin)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Header().Get("Access-Control-Allow-Origin")
+}
+
+func TestCORSAllowedOrigins(t *testing.T) {
+	patterns, err := parseAllowedOrigins("https://fincore.app, https://*.fincore.app,http://localhost:3000")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		origin string
+		allow  bool
+	}{
+		{"https://fincore.app", true},
+		{"http://localhost:3000", true},
+		{"https://staging.fincore.app", true},
+		{"https://a.b.fincore.app", true},
+		{"https://evil.com", false},
+		{"https://evilfincore.app", false},
+		{"http://staging.fincore.app", false},
+		{"http://localhost:3001", false},
+	}
+
+	for _, tt := range tests {
+		got := corsOrigin(t, patterns, tt.origin)
+		if allowed := got == tt.origin; allowed != tt.allow {
+			t.Errorf("origin %q allowed = %v, want %v", tt.origin, allowed, tt.allow)
+		}
+	}
+}
+
+func TestCORSInvalidConfigFallsBackToDefaults(t *testing.T) {
+	for _, raw := range []string{"ftp://fincore.app", "https://fin*core.app", " , ", "https://fincore.app/path"} {
+		t.Setenv("CORS_ALLOWED_ORIGINS", raw)
+		patterns := allowedOriginsFromEnv()
+		if got := corsOrigin(t, patterns, "https://fincore.app"); got != "https://fincore.app" {
+			t.Errorf("config %q: default origin not allowed", raw)
+		}
+		if got := corsOrigin(t, patterns, "https://staging.fincore.app"); got != "" {
+			t.Errorf("config %q: defaults must not include wildcards", raw)
+		}
+	}
+}
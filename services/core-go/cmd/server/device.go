@@ -0,0 +1,23 @@
+package main
+
+import (
+	"github.com/fincore/core-go/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+// deviceMiddleware calcula el fingerprint del dispositivo a partir de los
+// headers y lo deja en device_fingerprint. No lee el body ni rechaza por sí
+// mismo: los handlers comparan el fingerprint con los dispositivos del
+// user_id de cada transacción y deciden si la operación es sensible. Sin
+// headers identificables no hay fingerprint y el request sigue sin marca.
+func deviceMiddleware(secMgr *security.SecurityManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userAgent := c.GetHeader("User-Agent")
+		acceptLanguage := c.GetHeader("Accept-Language")
+		acceptEncoding := c.GetHeader("Accept-Encoding")
+		if userAgent != "" || acceptLanguage != "" || acceptEncoding != "" {
+			c.Set("device_fingerprint", secMgr.GenerateDeviceFingerprint(userAgent, acceptLanguage, acceptEncoding))
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fincore/core-go/internal/apierror"
+	"github.com/fincore/core-go/internal/handlers"
+	"github.com/fincore/core-go/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	laptop = map[string]string{"User-Agent": "Mozilla/5.0 (X11; Linux)", "Accept-Language": "es-MX", "Accept-Encoding": "gzip"}
+	phone  = map[string]string{"User-Agent": "FinCoreApp/3.1 (iPhone)", "Accept-Language": "es-MX", "Accept-Encoding": "gzip"}
+)
+
+func TestDeviceMiddlewareFingerprint(t *testing.T) {
+	_, secMgr := newTestRouter(t)
+
+	router := gin.New()
+	router.Use(deviceMiddleware(secMgr))
+	router.POST("/", func(c *gin.Context) {
+		// El middleware no consume el body
+		var body struct {
+			UserID string `json:"user_id"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil || body.UserID == "" {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		c.Header("X-Device-Fingerprint", c.GetString("device_fingerprint"))
+		c.Status(http.StatusOK)
+	})
+
+	fingerprint := func(headers map[string]string) string {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"user_id":"user-1"}`))
+		req.Header.Set("Content-Type", "application/json")
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", w.Code)
+		}
+		return w.Header().Get("X-Device-Fingerprint")
+	}
+
+	want := secMgr.GenerateDeviceFingerprint(laptop["User-Agent"], laptop["Accept-Language"], laptop["Accept-Encoding"])
+	if got := fingerprint(laptop); got != want {
+		t.Errorf("fingerprint = %q, want %q", got, want)
+	}
+	if fingerprint(phone) == want {
+		t.Error("different devices share a fingerprint")
+	}
+	if got := fingerprint(nil); got != "" {
+		t.Errorf("fingerprint without headers = %q, want none", got)
+	}
+}
+
+func TestDeviceCheckOnTransactionRoutes(t *testing.T) {
+	router, secMgr := newTestRouter(t)
+	handlers.SetDeviceStore(security.NewMemoryDeviceStore(0, 0))
+
+	send := func(path, body string, headers map[string]string) (int, map[string]interface{}) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var resp map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+	withdraw := func(headers map[string]string) (int, map[string]interface{}) {
+		return send("/api/v1/transactions/process", `{"type":"withdrawal","user_id":"user-device","amount":"10"}`, headers)
+	}
+
+	// El primer dispositivo del usuario queda registrado
+	if code, resp := withdraw(laptop); code != http.StatusOK {
+		t.Fatalf("first device: status = %d (%v)", code, resp)
+	}
+	if code, resp := withdraw(phone); code != http.StatusForbidden || resp["code"] != apierror.DeviceRisk {
+		t.Fatalf("new device: status/code = %d/%v, want 403/%s", code, resp["code"], apierror.DeviceRisk)
+	}
+
+	// Cada elemento del lote se compara con los dispositivos de su usuario
+	code, resp := send("/api/v1/transactions/batch", `{"transactions":[
+		{"type":"withdrawal","user_id":"user-device","amount":"10"},
+		{"type":"withdrawal","user_id":"user-other","amount":"10"}]}`, phone)
+	if code != http.StatusOK {
+		t.Fatalf("batch: status = %d (%v)", code, resp)
+	}
+	items := resp["transactions"].([]interface{})
+	if tx := items[0].(map[string]interface{}); tx["status"] != "rejected" || tx["rejection_reason"] != "Unrecognized device" {
+		t.Errorf("batch item of the bound user = %v/%v, want rejected by device", tx["status"], tx["rejection_reason"])
+	}
+	if tx := items[1].(map[string]interface{}); tx["status"] != "completed" {
+		t.Errorf("batch item of a new user = %v/%v, want completed", tx["status"], tx["rejection_reason"])
+	}
+
+	// Tras registrar el teléfono se aceptan los retiros desde ambos
+	token, err := secMgr.GenerateServiceToken("python-backend", serviceName, []string{"enroll:devices"}, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enroll := map[string]string{"X-Service-Token": token}
+	body := `{"user_id":"user-device","user_agent":"` + phone["User-Agent"] + `","accept_language":"es-MX","accept_encoding":"gzip"}`
+	if code, resp := send("/api/v1/internal/devices", body, enroll); code != http.StatusOK {
+		t.Fatalf("enroll: status = %d (%v)", code, resp)
+	}
+	for name, device := range map[string]map[string]string{"laptop": laptop, "phone": phone} {
+		if code, resp := withdraw(device); code != http.StatusOK {
+			t.Errorf("%s after enrolment: status = %d (%v)", name, code, resp)
+		}
+	}
+}
+
+func TestEnrollDeviceRequiresPermission(t *testing.T) {
+	router, secMgr := newTestRouter(t)
+
+	if w := postInternal(t, router, secMgr, "/devices", []string{"calculate:metrics"}); w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want 403", w.Code)
+	}
+}
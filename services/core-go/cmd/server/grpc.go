@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/fincore/core-go/internal/handlers"
+	"github.com/fincore/core-go/internal/metrics"
+	pb "github.com/fincore/core-go/internal/pb/corev1"
+	"github.com/fincore/core-go/internal/security"
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcPermissions es el permiso requerido por cada método gRPC; los métodos
+// que no aparecen se rechazan
+var grpcPermissions = map[string]string{
+	pb.CoreService_CalculateMetrics_FullMethodName: "calculate:metrics",
+	pb.CoreService_ValidateTransfer_FullMethodName: "validate:transfers",
+}
+
+func getGRPCAddr() string {
+	port := os.Getenv("GRPC_PORT")
+	if port == "" {
+		port = "9002"
+	}
+	return ":" + port
+}
+
+// newGRPCServer crea el servidor gRPC con la misma autenticación por token de
+// servicio que las rutas internas HTTP
+func newGRPCServer(secMgr *security.SecurityManager) *grpc.Server {
+	srv := grpc.NewServer(grpc.UnaryInterceptor(serviceTokenInterceptor(secMgr)))
+	pb.RegisterCoreServiceServer(srv, coreService{})
+	return srv
+}
+
+// serviceTokenInterceptor es el equivalente gRPC de zeroTrustMiddleware y
+// requirePermission: lee el token de la metadata x-service-token o
+// authorization: Bearer, lo verifica para este servicio y exige el permiso
+// del método
+func serviceTokenInterceptor(secMgr *security.SecurityManager) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		serviceToken := firstMetadata(md, "x-service-token")
+		if serviceToken == "" {
+			serviceToken, _ = strings.CutPrefix(firstMetadata(md, "authorization"), "Bearer ")
+		}
+		if serviceToken == "" {
+			metrics.TokenVerificationFailures.WithLabelValues(metrics.TokenMissing).Inc()
+			return nil, status.Error(codes.Unauthenticated, "Service token required")
+		}
+
+		var claims *security.ServiceTokenClaims
+		var err error
+		if security.IsJWT(serviceToken) {
+			claims, err = secMgr.VerifyJWT(serviceToken, serviceName)
+		} else {
+			claims, err = secMgr.VerifyServiceTokenFor(serviceToken, serviceName)
+		}
+		if err != nil {
+			metrics.TokenVerificationFailures.WithLabelValues(metrics.TokenInvalid).Inc()
+			return nil, status.Error(codes.Unauthenticated, "Invalid service token")
+		}
+
+		permission, ok := grpcPermissions[info.FullMethod]
+		if !ok || !claims.HasPermission(permission) {
+			metrics.TokenVerificationFailures.WithLabelValues(metrics.TokenForbidden).Inc()
+			return nil, status.Error(codes.PermissionDenied, "Insufficient permissions")
+		}
+		return handler(ctx, req)
+	}
+}
+
+// firstMetadata retorna el primer valor de la clave o "" si no existe
+func firstMetadata(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// coreService implementa CoreService sobre los mismos cálculos que los
+// handlers HTTP
+type coreService struct {
+	pb.UnimplementedCoreServiceServer
+}
+
+// CalculateMetrics traduce la solicitud a handlers.CalcularMetricas
+func (coreService) CalculateMetrics(_ context.Context, req *pb.CalculateMetricsRequest) (*pb.CalculateMetricsResponse, error) {
+	if len(req.GetFlujosIngresos()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "flujos_ingresos is required")
+	}
+
+	inversionInicial := req.GetInversionInicial()
+	tasaDescuento := req.GetTasaDescuento()
+	solicitud := handlers.SolicitudMetricas{
+		InversionInicial:   &inversionInicial,
+		FlujosIngresos:     req.GetFlujosIngresos(),
+		FlujosCostos:       req.GetFlujosCostos(),
+		TasaDescuento:      &tasaDescuento,
+		TasaFinanciamiento: req.TasaFinanciamiento,
+		TasaReinversion:    req.TasaReinversion,
+		Strict:             req.Strict,
+		Currency:           req.GetCurrency(),
+		Convencion:         req.GetConvencion(),
+
+		TasaEsAnual:              req.GetTasaEsAnual(),
+		FrecuenciaCapitalizacion: req.GetFrecuenciaCapitalizacion(),
+	}
+	if req.Redondeo != nil {
+		redondeo := int(req.GetRedondeo())
+		solicitud.Redondeo = &redondeo
+	}
+	if req.PeriodosPorAnio != nil {
+		periodosPorAnio := int(req.GetPeriodosPorAnio())
+		solicitud.PeriodosPorAnio = &periodosPorAnio
+	}
+
+	resultado, err := handlers.CalcularMetricas(solicitud)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	resp := &pb.CalculateMetricsResponse{
+		Van:                    resultado.VAN,
+		Roi:                    resultado.ROI,
+		RoiAnualizado:          resultado.ROIAnualizado,
+		Tir:                    resultado.TIR,
+		TirTolerancia:          resultado.TIRTolerancia,
+		Mirr:                   resultado.MIRR,
+		PaybackMeses:           resultado.PaybackMeses,
+		RecuperaInversion:      resultado.RecuperaInversion,
+		PaybackDescontadoMeses: resultado.PaybackDescontadoMeses,
+		RecuperaDescontado:     resultado.RecuperaDescontado,
+		ProfitabilityIndex:     resultado.IndiceRentabilidad,
+		Eaa:                    resultado.EAA,
+		Convencion:             resultado.Convencion,
+		EsViable:               resultado.EsViable,
+		FlujosNetos:            resultado.FlujosNetos,
+		Currency:               resultado.Currency,
+		TirError:               resultado.TIRError,
+		MirrError:              resultado.MIRRError,
+		EaaError:               resultado.EAAError,
+		RoiAnualizadoError:     resultado.ROIAnualizadoError,
+	}
+	if r := resultado.Redondeado; r != nil {
+		resp.Redondeado = &pb.MontosRedondeados{
+			Decimales:   r.Decimales,
+			Van:         r.VAN,
+			Eaa:         r.EAA,
+			FlujosNetos: r.FlujosNetos,
+		}
+	}
+	return resp, nil
+}
+
+// ValidateTransfer traduce la solicitud a handlers.ValidateTransferRequest
+func (coreService) ValidateTransfer(ctx context.Context, req *pb.ValidateTransferRequest) (*pb.ValidateTransferResponse, error) {
+	if req.GetFromAccount() == "" || req.GetToAccount() == "" || req.GetAmount() == "" {
+		return nil, status.Error(codes.InvalidArgument, "from_account, to_account and amount are required")
+	}
+	amount, err := decimal.NewFromString(req.GetAmount())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Invalid amount")
+	}
+
+	validation, err := handlers.ValidateTransferRequest(ctx, handlers.TransferRequest{
+		FromAccount: req.GetFromAccount(),
+		ToAccount:   req.GetToAccount(),
+		Amount:      amount,
+		Currency:    req.GetCurrency(),
+		ToCurrency:  req.GetToCurrency(),
+	})
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	resp := &pb.ValidateTransferResponse{
+		IsValid:           validation.IsValid,
+		Validations:       validation.Validations,
+		ValidatedAt:       timestamppb.New(validation.ValidatedAt),
+		Currency:          validation.Currency,
+		ToCurrency:        validation.ToCurrency,
+		Degraded:          validation.Degraded,
+		RequiresApproval:  validation.RequiresApproval,
+		RequiredApprovals: int32(validation.RequiredApprovals),
+	}
+	if validation.Rate != nil {
+		resp.Rate = validation.Rate.String()
+	}
+	if validation.ConvertedAmount != nil {
+		resp.ConvertedAmount = validation.ConvertedAmount.String()
+	}
+	return resp, nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	pb "github.com/fincore/core-go/internal/pb/corev1"
+	"github.com/fincore/core-go/internal/security"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestGRPCClient levanta el servidor gRPC sobre un bufconn en memoria
+func newTestGRPCClient(t *testing.T, secMgr *security.SecurityManager) pb.CoreServiceClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	srv := newGRPCServer(secMgr)
+	go func() { _ = srv.Serve(listener) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewCoreServiceClient(conn)
+}
+
+// withServiceToken agrega a ctx un token con permissions en la metadata
+func withServiceToken(t *testing.T, secMgr *security.SecurityManager, permissions []string) context.Context {
+	t.Helper()
+	token, err := secMgr.GenerateServiceToken("python-backend", serviceName, permissions, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return metadata.AppendToOutgoingContext(context.Background(), "x-service-token", token)
+}
+
+func TestGRPCCalculateMetricsMatchesHTTP(t *testing.T) {
+	router, secMgr := newTestRouter(t)
+	client := newTestGRPCClient(t, secMgr)
+
+	token, err := secMgr.GenerateServiceToken("python-backend", serviceName, []string{"calculate:metrics"}, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := `{"inversion_inicial":10000,"flujos_ingresos":[3000,4000,5000,6000],"flujos_costos":[500,500,500,500],"tasa_descuento":0.1}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/internal/calculate", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Service-Token", token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("HTTP status = %d, body %s", w.Code, w.Body.String())
+	}
+	var httpResp struct {
+		Metrics struct {
+			VAN float64  `json:"van"`
+			TIR *float64 `json:"tir"`
+		} `json:"metrics"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &httpResp); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := client.CalculateMetrics(withServiceToken(t, secMgr, []string{"calculate:metrics"}), &pb.CalculateMetricsRequest{
+		InversionInicial: 10000,
+		FlujosIngresos:   []float64{3000, 4000, 5000, 6000},
+		FlujosCostos:     []float64{500, 500, 500, 500},
+		TasaDescuento:    0.1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetVan() != httpResp.Metrics.VAN {
+		t.Errorf("gRPC van = %v, HTTP van = %v", resp.GetVan(), httpResp.Metrics.VAN)
+	}
+	if httpResp.Metrics.TIR == nil || resp.Tir == nil || resp.GetTir() != *httpResp.Metrics.TIR {
+		t.Errorf("gRPC tir = %v, HTTP tir = %v", resp.Tir, httpResp.Metrics.TIR)
+	}
+}
+
+func TestGRPCServiceTokenAuth(t *testing.T) {
+	_, secMgr := newTestRouter(t)
+	client := newTestGRPCClient(t, secMgr)
+	req := &pb.ValidateTransferRequest{FromAccount: "a", ToAccount: "b", Amount: "10"}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want codes.Code
+	}{
+		{"sin token", context.Background(), codes.Unauthenticated},
+		{"token inválido", metadata.AppendToOutgoingContext(context.Background(), "x-service-token", "invalid"), codes.Unauthenticated},
+		{"sin permiso", withServiceToken(t, secMgr, []string{"calculate:metrics"}), codes.PermissionDenied},
+		{"permiso presente", withServiceToken(t, secMgr, []string{"validate:transfers"}), codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.ValidateTransfer(tt.ctx, req)
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("code = %v, want %v (err %v)", got, tt.want, err)
+			}
+		})
+	}
+}
+
+func TestGRPCValidateTransfer(t *testing.T) {
+	_, secMgr := newTestRouter(t)
+	client := newTestGRPCClient(t, secMgr)
+	permissions := []string{"validate:transfers"}
+
+	resp, err := client.ValidateTransfer(withServiceToken(t, secMgr, permissions), &pb.ValidateTransferRequest{FromAccount: "a", ToAccount: "a", Amount: "-5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetIsValid() || len(resp.GetValidations()) != 2 {
+		t.Errorf("is_valid = %v, validations = %v", resp.GetIsValid(), resp.GetValidations())
+	}
+
+	// Cada token es de un solo uso
+	_, err = client.ValidateTransfer(withServiceToken(t, secMgr, permissions), &pb.ValidateTransferRequest{FromAccount: "a", ToAccount: "b", Amount: "10", Currency: "XX"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("invalid currency: err = %v, want InvalidArgument", err)
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/fincore/core-go/internal/buildinfo"
+	"github.com/fincore/core-go/internal/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+// readinessTimeout limita cuánto espera cada comprobación de dependencias
+const readinessTimeout = 2 * time.Second
+
+// shuttingDown se activa al comenzar el apagado: readiness responde 503 para
+// que los balanceadores dejen de enviar tráfico nuevo mientras terminan los
+// requests en curso
+var shuttingDown atomic.Bool
+
+// defaultShutdownDrainSeconds es lo que se espera por defecto, con readiness
+// ya en 503, antes de cerrar los listeners
+const defaultShutdownDrainSeconds = 5
+
+// drainConnections marca el servicio como en apagado y espera drain antes de
+// retornar, para que los balanceadores vean el 503 de readiness y dejen de
+// enviar tráfico mientras los listeners siguen abiertos. Una segunda señal en
+// quit corta la espera.
+func drainConnections(drain time.Duration, quit <-chan os.Signal) {
+	shuttingDown.Store(true)
+	if drain <= 0 {
+		return
+	}
+	slog.Info("Draining connections before shutdown", "drain", drain.String())
+	timer := time.NewTimer(drain)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-quit:
+		slog.Warn("Second signal received, skipping the remaining drain")
+	}
+}
+
+// dependencyCheck comprueba que una dependencia externa esté disponible
+type dependencyCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// readinessChecks retorna las dependencias que deben estar disponibles para
+// atender tráfico: el store del ledger y Vault si VAULT_ADDR está configurada
+func readinessChecks(vaultAddr string) []dependencyCheck {
+	checks := []dependencyCheck{
+		{name: "ledger", check: handlers.PingLedger},
+	}
+	if vaultAddr != "" {
+		checks = append(checks, dependencyCheck{name: "vault", check: vaultHealthCheck(vaultAddr)})
+	}
+	return checks
+}
+
+// vaultHealthCheck consulta /v1/sys/health; un Vault activo o en standby
+// cuenta como disponible, uno sellado o sin inicializar no
+func vaultHealthCheck(vaultAddr string) func(ctx context.Context) error {
+	url := strings.TrimRight(vaultAddr, "/") + "/v1/sys/health?standbyok=true"
+	client := &http.Client{Timeout: readinessTimeout}
+
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("vault health returned %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
+
+// livenessHandler indica que el proceso está vivo, sin consultar
+// dependencias, junto con la versión desplegada y su tiempo de actividad
+func livenessHandler(c *gin.Context) {
+	info := buildinfo.Get()
+	c.JSON(http.StatusOK, gin.H{
+		"status":         "healthy",
+		"service":        serviceName,
+		"version":        info.Version,
+		"commit":         info.Commit,
+		"build_date":     info.BuildDate,
+		"started_at":     info.StartedAt,
+		"uptime_seconds": info.UptimeSeconds,
+	})
+}
+
+// readinessHandler responde 503 si alguna dependencia no está disponible o
+// si el servicio se está apagando
+func readinessHandler(checks []dependencyCheck) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if shuttingDown.Load() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"status":  "shutting_down",
+				"service": serviceName,
+			})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+		defer cancel()
+
+		status := http.StatusOK
+		results := gin.H{}
+		for _, dep := range checks {
+			if err := dep.check(ctx); err != nil {
+				status = http.StatusServiceUnavailable
+				results[dep.name] = "unavailable: " + err.Error()
+				continue
+			}
+			results[dep.name] = "ok"
+		}
+
+		state := "ready"
+		if status != http.StatusOK {
+			state = "not_ready"
+		}
+		response := gin.H{
+			"status":  state,
+			"service": serviceName,
+			"checks":  results,
+		}
+		// El estado del breaker se informa sin afectar la disponibilidad: el
+		// Ping del ledger ya refleja si la base de datos responde
+		if circuit, ok := handlers.LedgerCircuitState(); ok {
+			response["circuit_breakers"] = gin.H{"ledger": circuit}
+		}
+		c.JSON(status, response)
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fincore/core-go/internal/buildinfo"
+	"github.com/fincore/core-go/internal/handlers"
+	"github.com/fincore/core-go/internal/ledger"
+	"github.com/gin-gonic/gin"
+)
+
+// useTestLedger instala un ledger en memoria para la prueba
+func useTestLedger(t *testing.T) *ledger.MockLedgerStore {
+	t.Helper()
+	store := ledger.NewMockLedgerStore()
+	chain, err := ledger.NewLedgerChain(context.Background(), store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handlers.SetLedgerChain(chain)
+	return store
+}
+
+func getHealth(t *testing.T, router *gin.Engine, path string) (int, map[string]interface{}) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	return w.Code, body
+}
+
+func TestLivenessAndAlias(t *testing.T) {
+	router, _ := newTestRouter(t)
+	for _, path := range []string{"/health", "/health/live"} {
+		if code, body := getHealth(t, router, path); code != http.StatusOK || body["status"] != "healthy" {
+			t.Errorf("%s = %d %v, want 200 healthy", path, code, body)
+		}
+	}
+}
+
+func TestLivenessReportsBuildInfo(t *testing.T) {
+	// Simula los valores que inyecta -ldflags al compilar
+	original := buildinfo.Version
+	buildinfo.Version = "1.4.2-test"
+	t.Cleanup(func() { buildinfo.Version = original })
+
+	router, _ := newTestRouter(t)
+	code, body := getHealth(t, router, "/health")
+	if code != http.StatusOK || body["version"] != "1.4.2-test" {
+		t.Errorf("health = %d %v, want the injected version", code, body)
+	}
+	if body["commit"] != buildinfo.Commit || body["build_date"] != buildinfo.BuildDate {
+		t.Errorf("health = %v, want commit and build date", body)
+	}
+	if uptime, ok := body["uptime_seconds"].(float64); !ok || uptime < 0 {
+		t.Errorf("uptime_seconds = %v, want a non-negative number", body["uptime_seconds"])
+	}
+	if _, err := time.Parse(time.RFC3339, body["started_at"].(string)); err != nil {
+		t.Errorf("started_at = %v: %v", body["started_at"], err)
+	}
+}
+
+func TestReadinessHealthyDependencies(t *testing.T) {
+	useTestLedger(t)
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer vault.Close()
+	t.Setenv("VAULT_ADDR", vault.URL)
+
+	router, _ := newTestRouter(t)
+	code, body := getHealth(t, router, "/health/ready")
+	if code != http.StatusOK || body["status"] != "ready" {
+		t.Fatalf("ready = %d %v, want 200 ready", code, body)
+	}
+	checks := body["checks"].(map[string]interface{})
+	if checks["ledger"] != "ok" || checks["vault"] != "ok" {
+		t.Errorf("checks = %v, want ledger and vault ok", checks)
+	}
+}
+
+func TestReadinessLedgerDown(t *testing.T) {
+	store := useTestLedger(t)
+	store.Err = errors.New("connection refused")
+	t.Setenv("VAULT_ADDR", "")
+
+	router, _ := newTestRouter(t)
+	code, body := getHealth(t, router, "/health/ready")
+	if code != http.StatusServiceUnavailable || body["status"] != "not_ready" {
+		t.Errorf("ready = %d %v, want 503 not_ready", code, body)
+	}
+
+	// Liveness no depende del ledger
+	if code, _ := getHealth(t, router, "/health/live"); code != http.StatusOK {
+		t.Errorf("live = %d, want 200", code)
+	}
+}
+
+func TestReadinessVaultSealed(t *testing.T) {
+	useTestLedger(t)
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer vault.Close()
+	t.Setenv("VAULT_ADDR", vault.URL)
+
+	router, _ := newTestRouter(t)
+	code, body := getHealth(t, router, "/health/ready")
+	if code != http.StatusServiceUnavailable {
+		t.Errorf("ready = %d %v, want 503", code, body)
+	}
+}
+
+func TestReadinessDuringShutdown(t *testing.T) {
+	useTestLedger(t)
+	t.Setenv("VAULT_ADDR", "")
+	router, _ := newTestRouter(t)
+
+	shuttingDown.Store(true)
+	t.Cleanup(func() { shuttingDown.Store(false) })
+
+	// Las dependencias siguen sanas, pero el balanceador debe dejar de enrutar
+	code, body := getHealth(t, router, "/health/ready")
+	if code != http.StatusServiceUnavailable || body["status"] != "shutting_down" {
+		t.Errorf("ready = %d %v, want 503 shutting_down", code, body)
+	}
+	if code, _ := getHealth(t, router, "/health/live"); code != http.StatusOK {
+		t.Errorf("live = %d, want 200 while draining", code)
+	}
+
+	shuttingDown.Store(false)
+	if code, body := getHealth(t, router, "/health/ready"); code != http.StatusOK {
+		t.Errorf("ready = %d %v, want 200 once the flag is cleared", code, body)
+	}
+}
+
+func TestDrainConnectionsKeepsServingUntilTheDelay(t *testing.T) {
+	useTestLedger(t)
+	t.Setenv("VAULT_ADDR", "")
+	router, _ := newTestRouter(t)
+	t.Cleanup(func() { shuttingDown.Store(false) })
+
+	done := make(chan struct{})
+	go func() {
+		drainConnections(200*time.Millisecond, make(chan os.Signal))
+		close(done)
+	}()
+
+	// Durante la espera el router sigue respondiendo, con readiness en 503
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("drain returned before the delay")
+	default:
+	}
+	if code, _ := getHealth(t, router, "/health/ready"); code != http.StatusServiceUnavailable {
+		t.Errorf("ready while draining = %d, want 503", code)
+	}
+	if code, _ := getHealth(t, router, "/health/live"); code != http.StatusOK {
+		t.Errorf("live while draining = %d, want 200", code)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("drain did not return after the delay")
+	}
+}
+
+func TestDrainConnectionsStopsOnSecondSignal(t *testing.T) {
+	t.Cleanup(func() { shuttingDown.Store(false) })
+
+	quit := make(chan os.Signal, 1)
+	quit <- os.Interrupt
+	start := time.Now()
+	drainConnections(time.Minute, quit)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("drain took %v after a second signal, want it to stop early", elapsed)
+	}
+	if !shuttingDown.Load() {
+		t.Error("drain must mark the service as shutting down")
+	}
+}
+
+func TestReadinessReportsLedgerCircuit(t *testing.T) {
+	store := ledger.NewMockLedgerStore()
+	breaker := ledger.NewBreakerStore(store, 1, time.Minute)
+	chain, err := ledger.NewLedgerChain(context.Background(), breaker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handlers.SetLedgerChain(chain)
+	t.Setenv("VAULT_ADDR", "")
+
+	router, _ := newTestRouter(t)
+	_, body := getHealth(t, router, "/health/ready")
+	if circuits, _ := body["circuit_breakers"].(map[string]interface{}); circuits["ledger"] != "closed" {
+		t.Fatalf("circuit_breakers = %v, want ledger closed", body["circuit_breakers"])
+	}
+
+	// Un fallo abre el circuito y las escrituras responden 503 sin llegar al store
+	store.Err = errors.New("connection refused")
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/ledger/entry", strings.NewReader(`{"entry_type":"deposit","amount":"10"}`))
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("first write = %d, want 500", w.Code)
+	}
+
+	store.Err = nil
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/ledger/entry", strings.NewReader(`{"entry_type":"deposit","amount":"10"}`))
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("write with open circuit = %d, want 503", w.Code)
+	}
+
+	_, body = getHealth(t, router, "/health/ready")
+	if circuits, _ := body["circuit_breakers"].(map[string]interface{}); circuits["ledger"] != "open" {
+		t.Errorf("circuit_breakers = %v, want ledger open", body["circuit_breakers"])
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fincore/core-go/internal/handlers"
+	"github.com/shopspring/decimal"
+)
+
+// configureTransactionLimits aplica los límites antifraude del entorno:
+//   - TRANSACTION_MAX_AMOUNTS: montos máximos por tipo ("withdrawal=50000,payout=100000")
+//   - VELOCITY_LIMIT: volumen máximo por usuario y moneda dentro de la ventana
+//   - VELOCITY_WINDOW: duración de la ventana (por defecto 24h)
+func configureTransactionLimits() {
+	if value := os.Getenv("TRANSACTION_MAX_AMOUNTS"); value != "" {
+		handlers.SetMaxAmounts(parseMaxAmounts(value))
+	}
+
+	if value := os.Getenv("VELOCITY_WINDOW"); value != "" {
+		window, err := time.ParseDuration(value)
+		if err != nil || window <= 0 {
+			slog.Warn("Invalid VELOCITY_WINDOW, using default", "value", value, "default", handlers.DefaultVelocityWindow)
+		} else {
+			handlers.SetVelocityStore(handlers.NewMemoryVelocityStore(window))
+		}
+	}
+
+	if value := os.Getenv("VELOCITY_LIMIT"); value != "" {
+		limit, err := decimal.NewFromString(value)
+		if err != nil || limit.IsNegative() {
+			slog.Warn("Invalid VELOCITY_LIMIT, velocity checks disabled", "value", value)
+			return
+		}
+		handlers.SetVelocityLimit(limit)
+	}
+}
+
+// configureApprovalPolicy aplica la aprobación múltiple de montos altos:
+//   - APPROVAL_THRESHOLD: monto sobre el cual una transacción queda pendiente de aprobación
+//   - REQUIRED_APPROVALS: aprobadores distintos que la completan (por defecto 2)
+func configureApprovalPolicy() {
+	value := os.Getenv("APPROVAL_THRESHOLD")
+	if value == "" {
+		return
+	}
+	threshold, err := decimal.NewFromString(value)
+	if err != nil || threshold.IsNegative() {
+		slog.Warn("Invalid APPROVAL_THRESHOLD, approvals disabled", "value", value)
+		return
+	}
+	handlers.SetApprovalPolicy(threshold, getEnvInt("REQUIRED_APPROVALS", handlers.DefaultRequiredApprovals))
+}
+
+// parseMaxAmounts interpreta una lista "tipo=monto" separada por comas,
+// ignorando las entradas inválidas
+func parseMaxAmounts(value string) map[string]decimal.Decimal {
+	limits := make(map[string]decimal.Decimal)
+	for _, item := range strings.Split(value, ",") {
+		txType, rawAmount, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok {
+			slog.Warn("Invalid TRANSACTION_MAX_AMOUNTS entry", "entry", item)
+			continue
+		}
+		amount, err := decimal.NewFromString(strings.TrimSpace(rawAmount))
+		if err != nil || !amount.IsPositive() {
+			slog.Warn("Invalid TRANSACTION_MAX_AMOUNTS entry", "entry", item)
+			continue
+		}
+		limits[strings.TrimSpace(txType)] = amount
+	}
+	return limits
+}
@@ -13,26 +13,117 @@ package main
 
 import (
 	"context"
-	"log"
+	"crypto/ecdsa"
+	"errors"
+	"log/slog"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/fincore/core-go/internal/apierror"
+	"github.com/fincore/core-go/internal/buildinfo"
 	"github.com/fincore/core-go/internal/handlers"
+	"github.com/fincore/core-go/internal/logging"
+	"github.com/fincore/core-go/internal/metrics"
 	"github.com/fincore/core-go/internal/security"
+	"github.com/fincore/core-go/internal/shutdown"
+	"github.com/fincore/core-go/internal/tracing"
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
 )
 
+// serviceName identifica a este servicio en health checks y como Target
+// esperado de los tokens de servicio
+const serviceName = "fincore-core-go"
+
+// defaultShutdownTimeoutSeconds es el plazo por defecto del apagado ordenado
+const defaultShutdownTimeoutSeconds = 30
+
 func main() {
+	// Logs estructurados en JSON
+	slog.SetDefault(logging.New(os.Stdout))
+
 	// Configurar modo producción
 	if os.Getenv("GIN_MODE") == "" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	// Trazas OpenTelemetry, exportadas por OTLP si OTEL_EXPORTER_OTLP_ENDPOINT está configurada
+	shutdownTracing, err := tracing.Setup(context.Background(), serviceName)
+	if err != nil {
+		fatal("Tracing initialization failed", err)
+	}
+
 	// Inicializar seguridad
-	securityManager := security.NewSecurityManager()
+	securityManager, err := security.NewSecurityManager()
+	if err != nil {
+		fatal("Security initialization failed", err)
+	}
+	securityManager.SetTokenLeeway(time.Duration(getEnvInt("SERVICE_TOKEN_LEEWAY_SECONDS", int(security.DefaultTokenLeeway/time.Second))) * time.Second)
+	securityManager.SetSigningKeyRotationWindow(time.Duration(getEnvInt("SIGNING_KEY_ROTATION_WINDOW_SECONDS", int(security.DefaultSigningKeyRotationWindow/time.Second))) * time.Second)
+	// Algoritmo HMAC de los tokens emitidos (HS256 por defecto, HS512 por cumplimiento)
+	if alg := os.Getenv("SERVICE_TOKEN_ALGORITHM"); alg != "" {
+		if err := securityManager.SetServiceTokenAlgorithm(alg); err != nil {
+			fatal("Invalid SERVICE_TOKEN_ALGORITHM", err)
+		}
+	}
+	if err := configureECDSAKeys(securityManager); err != nil {
+		fatal("ECDSA key configuration failed", err)
+	}
+	handlers.SetSecurityManager(securityManager)
+
+	// Pool de workers y límite de tamaño para lotes
+	handlers.SetBatchWorkers(getEnvInt("BATCH_WORKERS", handlers.DefaultBatchWorkers))
+	handlers.SetMaxBatchSize(getEnvInt("MAX_BATCH_SIZE", handlers.DefaultMaxBatchSize))
+	// Plazo por línea de /batch/stream, que no queda sujeto a ReadTimeout/WriteTimeout
+	handlers.SetStreamLineTimeout(time.Duration(getEnvInt("BATCH_STREAM_LINE_TIMEOUT_MS", int(handlers.DefaultStreamLineTimeout/time.Millisecond))) * time.Millisecond)
+
+	// Espera máxima de los proveedores externos de ValidateTransfer
+	defaultProviderTimeoutMS := int(handlers.DefaultProviderTimeout / time.Millisecond)
+	handlers.SetExchangeRateTimeout(time.Duration(getEnvInt("EXCHANGE_RATE_TIMEOUT_MS", defaultProviderTimeoutMS)) * time.Millisecond)
+	handlers.SetBalanceTimeout(time.Duration(getEnvInt("BALANCE_TIMEOUT_MS", defaultProviderTimeoutMS)) * time.Millisecond)
+
+	// Tipos de transacción aceptados (lista separada por comas)
+	if types := os.Getenv("TRANSACTION_TYPES"); types != "" {
+		handlers.SetTransactionTypes(strings.Split(types, ","))
+	}
+
+	// Moneda asumida cuando un request no indica currency
+	configureDefaultCurrency()
+
+	// Con STRICT_AMOUNT_PRECISION=false el exceso de decimales se redondea
+	handlers.SetStrictAmountPrecision(os.Getenv("STRICT_AMOUNT_PRECISION") != "false")
+
+	// Montos máximos por tipo y límite de velocidad por usuario
+	configureTransactionLimits()
+
+	// Transacciones sobre APPROVAL_THRESHOLD requieren varios aprobadores
+	configureApprovalPolicy()
+
+	// Dispositivos conocidos por usuario para bloquear retiros desde uno nuevo
+	handlers.SetDeviceStore(security.NewMemoryDeviceStore(
+		getEnvInt("DEVICE_STORE_MAX_USERS", security.DefaultMaxDeviceUsers),
+		getEnvInt("MAX_DEVICES_PER_USER", security.DefaultMaxDevicesPerUser),
+	))
+
+	// Inicializar ledger y store de transacciones
+	stores, closeStorage, err := setupStorage(context.Background(), securityManager)
+	if err != nil {
+		fatal("Storage initialization failed", err)
+	}
+	defer closeStorage()
+	handlers.SetLedgerChain(stores.ledger)
+	handlers.SetTransactionStore(stores.transactions)
+
+	// Webhooks firmados al completar transacciones (WEBHOOK_URLS separadas por comas)
+	webhooks := newWebhookDispatcher(securityManager)
+	handlers.SetWebhookDispatcher(webhooks)
 
 	// Crear router
 	router := setupRouter(securityManager)
@@ -48,9 +139,22 @@ func main() {
 
 	// Iniciar servidor en goroutine
 	go func() {
-		log.Printf("Starting FinCore Go Service on %s", srv.Addr)
+		slog.Info("Starting FinCore Go Service", "addr", srv.Addr, "version", buildinfo.Version, "commit", buildinfo.Commit)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server error: %s", err)
+			fatal("Server error", err)
+		}
+	}()
+
+	// Servidor gRPC con CalculateMetrics y ValidateTransfer
+	grpcServer := newGRPCServer(securityManager)
+	grpcListener, err := net.Listen("tcp", getGRPCAddr())
+	if err != nil {
+		fatal("gRPC listen failed", err)
+	}
+	go func() {
+		slog.Info("Starting FinCore gRPC Service", "addr", grpcListener.Addr().String())
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			fatal("gRPC server error", err)
 		}
 	}()
 
@@ -58,16 +162,102 @@ func main() {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
-	log.Println("Shutting down server...")
+	slog.Info("Shutting down server")
+
+	// Readiness responde 503 durante SHUTDOWN_DRAIN_SECONDS antes de cerrar los
+	// listeners; el periodo de gracia del orquestador debe cubrir la espera y
+	// SHUTDOWN_TIMEOUT_SECONDS
+	drainConnections(time.Duration(getEnvInt("SHUTDOWN_DRAIN_SECONDS", defaultShutdownDrainSeconds))*time.Second, quit)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// Un único plazo para todo el apagado, configurable con SHUTDOWN_TIMEOUT_SECONDS
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(getEnvInt("SHUTDOWN_TIMEOUT_SECONDS", defaultShutdownTimeoutSeconds))*time.Second)
 	defer cancel()
 
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatal("Server forced to shutdown:", err)
+	// Primero dejar de aceptar trabajo y terminar los requests y lotes en curso
+	servers := shutdown.NewManager(slog.Default().With("phase", "servers"))
+	servers.Register("batch workers", func(ctx context.Context) error {
+		drained, err := handlers.DrainBatches(ctx)
+		slog.Info("Drained batch jobs", "count", drained)
+		return err
+	})
+	servers.Register("http server", srv.Shutdown)
+	servers.Register("grpc server", func(ctx context.Context) error {
+		return stopGRPC(ctx, grpcServer)
+	})
+
+	// Después, el trabajo en segundo plano que generaron esos requests
+	background := shutdown.NewManager(slog.Default().With("phase", "background"))
+	if webhooks != nil {
+		// Entregar los webhooks encolados por las últimas transacciones
+		background.Register("webhook dispatcher", webhooks.Close)
+	}
+	background.Register("tracing", shutdownTracing)
+
+	clean := true
+	for _, manager := range []*shutdown.Manager{servers, background} {
+		clean = manager.Shutdown(ctx).Clean() && clean
+	}
+	if !clean {
+		slog.Error("Server exited before all components stopped")
+		os.Exit(1)
+	}
+
+	slog.Info("Server exited cleanly")
+}
+
+// stopGRPC espera a que terminen las llamadas gRPC en curso; si ctx vence
+// antes las cancela y retorna ctx.Err()
+func stopGRPC(ctx context.Context, srv *grpc.Server) error {
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		srv.Stop()
+		return ctx.Err()
+	}
+}
+
+// fatal registra el error y termina el proceso
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// configureECDSAKeys carga las claves PEM de los tokens ES256. Sólo el
+// emisor necesita SERVICE_TOKEN_EC_PRIVATE_KEY_FILE; para verificar basta
+// SERVICE_TOKEN_EC_PUBLIC_KEY_FILE. Sin ninguna, sólo se aceptan tokens HMAC.
+func configureECDSAKeys(secMgr *security.SecurityManager) error {
+	var private *ecdsa.PrivateKey
+	var public *ecdsa.PublicKey
+
+	if path := os.Getenv("SERVICE_TOKEN_EC_PRIVATE_KEY_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if private, err = security.ParseECDSAPrivateKeyPEM(data); err != nil {
+			return err
+		}
+	}
+	if path := os.Getenv("SERVICE_TOKEN_EC_PUBLIC_KEY_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if public, err = security.ParseECDSAPublicKeyPEM(data); err != nil {
+			return err
+		}
 	}
 
-	log.Println("Server exited cleanly")
+	if private == nil && public == nil {
+		return nil
+	}
+	return secMgr.SetECDSAKeys(private, public)
 }
 
 func getServerAddr() string {
@@ -78,55 +268,150 @@ func getServerAddr() string {
 	return ":" + port
 }
 
+// configureDefaultCurrency aplica DEFAULT_CURRENCY (ISO-4217); si no está
+// definida o no es válida se mantiene handlers.DefaultCurrency
+func configureDefaultCurrency() {
+	currency := os.Getenv("DEFAULT_CURRENCY")
+	if currency == "" {
+		return
+	}
+	if err := handlers.SetDefaultCurrency(currency); err != nil {
+		slog.Warn("Invalid DEFAULT_CURRENCY, using default", "value", currency, "default", handlers.DefaultCurrency)
+	}
+}
+
+// newWebhookDispatcher crea el dispatcher de webhooks para las URLs de
+// WEBHOOK_URLS; las URLs que no son http(s) se ignoran. Retorna nil si no hay
+// ninguna, lo que desactiva los webhooks.
+func newWebhookDispatcher(secMgr *security.SecurityManager) *handlers.WebhookDispatcher {
+	var endpoints []string
+	for _, endpoint := range strings.Split(os.Getenv("WEBHOOK_URLS"), ",") {
+		endpoint = strings.TrimSpace(endpoint)
+		if endpoint == "" {
+			continue
+		}
+		parsed, err := url.Parse(endpoint)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			slog.Warn("Invalid webhook URL, ignoring", "value", endpoint)
+			continue
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	if len(endpoints) == 0 {
+		return nil
+	}
+	return handlers.NewWebhookDispatcher(secMgr, endpoints, getEnvInt("WEBHOOK_MAX_ATTEMPTS", handlers.DefaultWebhookMaxAttempts), handlers.DefaultWebhookBackoff)
+}
+
+// getEnvInt lee una variable de entorno entera, usando el valor por defecto
+// cuando no está definida o no es válida
+func getEnvInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		slog.Warn("Invalid integer environment variable, using default", "key", key, "value", value, "default", fallback)
+		return fallback
+	}
+	return n
+}
+
 func setupRouter(secMgr *security.SecurityManager) *gin.Engine {
 	router := gin.New()
 
 	// Middleware de seguridad
-	router.Use(gin.Recovery())
+	router.Use(recoveryMiddleware())
 	router.Use(securityMiddleware(secMgr))
-	router.Use(corsMiddleware())
-
-	// Health check
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status":  "healthy",
-			"service": "fincore-core-go",
-			"version": "1.0.0",
-		})
-	})
+	router.Use(tracing.Middleware())
+	router.Use(logging.Middleware(slog.Default()))
+	router.Use(corsMiddleware(allowedOriginsFromEnv()))
+
+	// Rutas y métodos no soportados responden con el mismo envelope JSON
+	router.HandleMethodNotAllowed = true
+	router.NoRoute(jsonError(http.StatusNotFound, apierror.RouteNotFound, "Route not found"))
+	router.NoMethod(jsonError(http.StatusMethodNotAllowed, apierror.MethodNotAllowed, "Method not allowed"))
+
+	// Métricas Prometheus, fuera de los grupos autenticados
+	router.GET("/metrics", gin.WrapH(metrics.Handler(metrics.NewRegistry())))
+
+	// Health checks: /health se mantiene como alias de liveness
+	router.GET("/health", livenessHandler)
+	router.GET("/health/live", livenessHandler)
+	router.GET("/health/ready", readinessHandler(readinessChecks(os.Getenv("VAULT_ADDR"))))
 
 	// API v1
 	v1 := router.Group("/api/v1")
+	publicRateLimit := newPublicRateLimiter()
+	// Firma HMAC de requests públicos, activada con ENABLE_REQUEST_SIGNING
+	requestSigning := newRequestSigningMiddleware()
+	// Rechazo de requests repetidos, activado por grupo con REPLAY_PROTECTED_GROUPS;
+	// va después de la firma para que requests no firmados no ocupen el cache
+	replay := newReplayProtection()
 	{
 		// Transacciones financieras (requiere mTLS)
 		transactions := v1.Group("/transactions")
-		transactions.Use(mTLSMiddleware())
+		transactions.Use(mTLSMiddleware(), requestSigning, replay.forGroup("transactions"), publicRateLimit, deviceMiddleware(secMgr))
 		{
+			transactions.GET("", handlers.ListTransactions)
 			transactions.POST("/process", handlers.ProcessTransaction)
 			transactions.GET("/verify/:id", handlers.VerifyTransaction)
+			transactions.POST("/verify", handlers.VerifyTransactions)
 			transactions.POST("/batch", handlers.BatchProcess)
+			transactions.POST("/batch/stream", handlers.BatchProcessStream)
+			transactions.POST("/reverse/:id", handlers.ReverseTransaction)
+			// Cada aprobador se identifica con su propio token de servicio
+			transactions.POST("/approve/:id", zeroTrustMiddleware(secMgr), requirePermission("approve:transactions"), handlers.ApproveTransaction)
 		}
 
 		// Ledger inmutable
 		ledger := v1.Group("/ledger")
+		ledger.Use(requestSigning, replay.forGroup("ledger"), publicRateLimit)
 		{
 			ledger.POST("/entry", handlers.CreateLedgerEntry)
 			ledger.GET("/verify", handlers.VerifyLedgerIntegrity)
 			ledger.GET("/entry/:sequence", handlers.GetLedgerEntry)
+			ledger.GET("/proof/:sequence", handlers.GetLedgerProof)
+			ledger.GET("/export", handlers.ExportLedger)
 		}
 
 		// Servicios internos (Zero Trust)
 		internal := v1.Group("/internal")
-		internal.Use(zeroTrustMiddleware(secMgr))
+		internal.Use(zeroTrustMiddleware(secMgr), replay.forGroup("internal"), newInternalRateLimiter())
 		{
-			internal.POST("/calculate", handlers.CalculateMetrics)
-			internal.POST("/validate-transfer", handlers.ValidateTransfer)
+			internal.POST("/calculate", requirePermission("calculate:metrics"), handlers.CalculateMetrics)
+			internal.POST("/calculate/batch", requirePermission("calculate:metrics"), handlers.CalculateMetricsBatch)
+			internal.POST("/calculate-precise", requirePermission("calculate:metrics"), handlers.CalculateMetricsPrecise)
+			internal.POST("/calculate-decimal", requirePermission("calculate:metrics"), handlers.CalculateMetricsDecimal)
+			internal.POST("/xirr", requirePermission("calculate:metrics"), handlers.CalculateXIRR)
+			internal.POST("/validate-transfer", requirePermission("validate:transfers"), handlers.ValidateTransfer)
+			internal.POST("/amortization", requirePermission("calculate:amortization"), handlers.GenerateAmortization)
+			internal.POST("/future-value", requirePermission("calculate:future-value"), handlers.CalculateFutureValue)
+			internal.POST("/break-even", requirePermission("calculate:break-even"), handlers.CalculateBreakEven)
+			internal.POST("/wacc", requirePermission("calculate:wacc"), handlers.CalculateWACC)
+			internal.POST("/token", requirePermission("admin:tokens"), handlers.MintServiceToken)
+			internal.POST("/devices", requirePermission("enroll:devices"), handlers.EnrollDevice)
+			internal.POST("/keys/rotate", requirePermission("admin:keys"), handlers.RotateSigningKey)
+			internal.GET("/reconcile", requirePermission("audit:reconcile"), handlers.ReconcileTransactions)
 		}
 	}
 
 	return router
 }
 
+// recoveryMiddleware es gin.Recovery salvo para http.ErrAbortHandler, que se
+// relanza para que net/http corte la conexión: un handler que ya envió el
+// status no debe terminar con una respuesta aparentemente completa
+func recoveryMiddleware() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, err any) {
+		if err == http.ErrAbortHandler {
+			panic(err)
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+	})
+}
+
 // Middleware de seguridad general
 func securityMiddleware(secMgr *security.SecurityManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -146,32 +431,10 @@ func securityMiddleware(secMgr *security.SecurityManager) gin.HandlerFunc {
 	}
 }
 
-// CORS Middleware
-func corsMiddleware() gin.HandlerFunc {
+// jsonError responde con el envelope de error del servicio
+func jsonError(status int, code, message string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		origin := c.GetHeader("Origin")
-		allowedOrigins := []string{
-			"http://localhost:3000",
-			"https://fincore.app",
-		}
-
-		for _, allowed := range allowedOrigins {
-			if origin == allowed {
-				c.Header("Access-Control-Allow-Origin", origin)
-				break
-			}
-		}
-
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Authorization, Content-Type, X-Service-Token")
-		c.Header("Access-Control-Max-Age", "86400")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(http.StatusNoContent)
-			return
-		}
-
-		c.Next()
+		apierror.Respond(c, status, code, message)
 	}
 }
 
@@ -181,9 +444,7 @@ func mTLSMiddleware() gin.HandlerFunc {
 		// En producción, verificar certificado del cliente
 		if os.Getenv("ENABLE_MTLS") == "true" {
 			if c.Request.TLS == nil || len(c.Request.TLS.PeerCertificates) == 0 {
-				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-					"error": "mTLS certificate required",
-				})
+				apierror.Respond(c, http.StatusUnauthorized, apierror.MTLSRequired, "mTLS certificate required")
 				return
 			}
 		}
@@ -191,23 +452,32 @@ func mTLSMiddleware() gin.HandlerFunc {
 	}
 }
 
-// Zero Trust Middleware para comunicación entre servicios
+// Zero Trust Middleware para comunicación entre servicios. Acepta el token
+// propio en X-Service-Token o un JWT HS256 en X-Service-Token o como
+// Authorization: Bearer.
 func zeroTrustMiddleware(secMgr *security.SecurityManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		serviceToken := c.GetHeader("X-Service-Token")
 		if serviceToken == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"error": "Service token required",
-			})
+			serviceToken, _ = strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		}
+		if serviceToken == "" {
+			metrics.TokenVerificationFailures.WithLabelValues(metrics.TokenMissing).Inc()
+			apierror.Respond(c, http.StatusUnauthorized, apierror.TokenRequired, "Service token required")
 			return
 		}
 
-		// Verificar token temporal
-		claims, err := secMgr.VerifyServiceToken(serviceToken)
+		// Verificar token temporal emitido para este servicio
+		var claims *security.ServiceTokenClaims
+		var err error
+		if security.IsJWT(serviceToken) {
+			claims, err = secMgr.VerifyJWT(serviceToken, serviceName)
+		} else {
+			claims, err = secMgr.VerifyServiceTokenFor(serviceToken, serviceName)
+		}
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid service token",
-			})
+			metrics.TokenVerificationFailures.WithLabelValues(metrics.TokenInvalid).Inc()
+			apierror.Respond(c, http.StatusUnauthorized, tokenErrorCode(err), "Invalid service token")
 			return
 		}
 
@@ -215,3 +485,35 @@ func zeroTrustMiddleware(secMgr *security.SecurityManager) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// tokenErrorCode distingue los tokens vencidos, revocados o reutilizados
+// del resto de los tokens inválidos
+func tokenErrorCode(err error) string {
+	switch {
+	case errors.Is(err, security.ErrTokenExpired):
+		return apierror.TokenExpired
+	case errors.Is(err, security.ErrTokenRevoked):
+		return apierror.TokenRevoked
+	case errors.Is(err, security.ErrTokenReplay):
+		return apierror.TokenReplayed
+	default:
+		return apierror.TokenInvalid
+	}
+}
+
+// requirePermission exige que el token verificado por zeroTrustMiddleware
+// incluya el permiso dado; debe encadenarse después de ese middleware
+func requirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Get("service_claims")
+		claims, ok := value.(*security.ServiceTokenClaims)
+		if !ok || !claims.HasPermission(permission) {
+			metrics.TokenVerificationFailures.WithLabelValues(metrics.TokenForbidden).Inc()
+			apierror.RespondWith(c, http.StatusForbidden, apierror.InsufficientPermissions, "Insufficient permissions", gin.H{
+				"required_permission": permission,
+			})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,423 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/fincore/core-go/internal/apierror"
+	"github.com/fincore/core-go/internal/handlers"
+	"github.com/fincore/core-go/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+func newTestRouter(t *testing.T) (*gin.Engine, *security.SecurityManager) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	t.Setenv("SECRET_KEY", "test-secret-key-0123456789-abcdefghij")
+	t.Setenv("ENCRYPTION_KEY", "test-encryption-key-0123456789-abcdef")
+	t.Setenv("INTEGRITY_KEY", "test-integrity-key-0123456789-abcdefgh")
+
+	secMgr, err := security.NewSecurityManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	handlers.SetSecurityManager(secMgr)
+	return setupRouter(secMgr), secMgr
+}
+
+// postInternal llama a un endpoint interno con un token que otorga permissions
+func postInternal(t *testing.T, router *gin.Engine, secMgr *security.SecurityManager, path string, permissions []string) *httptest.ResponseRecorder {
+	t.Helper()
+	token, err := secMgr.GenerateServiceToken("python-backend", serviceName, permissions, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := `{"from_account":"a","to_account":"b","amount":"10"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/internal"+path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Service-Token", token)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRequirePermission(t *testing.T) {
+	router, secMgr := newTestRouter(t)
+
+	tests := []struct {
+		name        string
+		permissions []string
+		want        int
+	}{
+		{"permiso presente", []string{"validate:transfers"}, http.StatusOK},
+		{"permiso ausente", []string{"calculate:metrics"}, http.StatusForbidden},
+		{"sin permisos", nil, http.StatusForbidden},
+		{"comodín global", []string{security.PermissionWildcard}, http.StatusOK},
+		{"comodín de acción", []string{"validate:*"}, http.StatusOK},
+		{"comodín de otra acción", []string{"calculate:*"}, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := postInternal(t, router, secMgr, "/validate-transfer", tt.permissions)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d (body %s)", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestMintTokenRequiresAdminPermission(t *testing.T) {
+	router, secMgr := newTestRouter(t)
+
+	if w := postInternal(t, router, secMgr, "/token", []string{"calculate:*", "validate:*"}); w.Code != http.StatusForbidden {
+		t.Errorf("without admin:tokens: status = %d, want 403", w.Code)
+	}
+	// El body de postInternal no es una solicitud de token válida: 400 indica
+	// que la ruta superó el control de permisos
+	if w := postInternal(t, router, secMgr, "/token", []string{"admin:tokens"}); w.Code != http.StatusBadRequest {
+		t.Errorf("with admin:tokens: status = %d, want 400", w.Code)
+	}
+}
+
+func TestRotateSigningKeyRequiresAdminPermission(t *testing.T) {
+	router, secMgr := newTestRouter(t)
+
+	if w := postInternal(t, router, secMgr, "/keys/rotate", []string{"admin:tokens"}); w.Code != http.StatusForbidden {
+		t.Errorf("without admin:keys: status = %d, want 403", w.Code)
+	}
+	// El body de postInternal no trae key: 422 indica que la ruta superó el
+	// control de permisos sin rotar la clave
+	if w := postInternal(t, router, secMgr, "/keys/rotate", []string{"admin:keys"}); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("with admin:keys: status = %d, want 422", w.Code)
+	}
+}
+
+// scrapeMetric retorna el valor de una serie en la salida de /metrics
+func scrapeMetric(t *testing.T, router *gin.Engine, series string) float64 {
+	t.Helper()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("/metrics status = %d", w.Code)
+	}
+
+	for _, line := range strings.Split(w.Body.String(), "\n") {
+		if value, ok := strings.CutPrefix(line, series+" "); ok {
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return n
+		}
+	}
+	return 0
+}
+
+func TestMetricsCountProcessedTransactions(t *testing.T) {
+	router, _ := newTestRouter(t)
+	series := `fincore_core_transactions_processed_total{status="completed"}`
+	before := scrapeMetric(t, router, series)
+
+	body := `{"type":"investment","user_id":"user-1","amount":"100.00"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/transactions/process", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("process status = %d: %s", w.Code, w.Body.String())
+	}
+
+	if after := scrapeMetric(t, router, series); after != before+1 {
+		t.Errorf("%s = %v, want %v", series, after, before+1)
+	}
+}
+
+func TestDefaultCurrencyFromEnv(t *testing.T) {
+	router, _ := newTestRouter(t)
+	t.Cleanup(func() { _ = handlers.SetDefaultCurrency(handlers.DefaultCurrency) })
+
+	processCurrency := func() string {
+		t.Helper()
+		body := `{"type":"investment","user_id":"user-1","amount":"100.00"}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/transactions/process", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("process status = %d: %s", w.Code, w.Body.String())
+		}
+		var resp struct {
+			Transaction struct {
+				Currency string `json:"currency"`
+			} `json:"transaction"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp.Transaction.Currency
+	}
+
+	if got := processCurrency(); got != "MXN" {
+		t.Errorf("currency without DEFAULT_CURRENCY = %q, want MXN", got)
+	}
+
+	t.Setenv("DEFAULT_CURRENCY", "eur")
+	configureDefaultCurrency()
+	if got := processCurrency(); got != "EUR" {
+		t.Errorf("currency with DEFAULT_CURRENCY=eur = %q, want EUR", got)
+	}
+
+	// Un código inválido se ignora y se mantiene la moneda anterior
+	t.Setenv("DEFAULT_CURRENCY", "EURO")
+	configureDefaultCurrency()
+	if got := processCurrency(); got != "EUR" {
+		t.Errorf("currency after invalid DEFAULT_CURRENCY = %q, want EUR", got)
+	}
+}
+
+func TestPublicRateLimitByClient(t *testing.T) {
+	t.Setenv("RATE_LIMIT_RPS", "1")
+	t.Setenv("RATE_LIMIT_BURST", "2")
+	router, _ := newTestRouter(t)
+
+	process := func(remoteAddr, userID string) int {
+		body := `{"type":"investment","user_id":"` + userID + `","amount":"10"}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/transactions/process", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Cambiar user_id en cada request no evade el límite del cliente
+	for i, userID := range []string{"user-1", "user-2"} {
+		if code := process("198.51.100.1:1234", userID); code != http.StatusOK {
+			t.Fatalf("request %d status = %d, want 200", i+1, code)
+		}
+	}
+	if code := process("198.51.100.1:1234", "user-3"); code != http.StatusTooManyRequests {
+		t.Errorf("3rd request with a new user_id status = %d, want 429", code)
+	}
+	if code := process("198.51.100.2:1234", "user-1"); code != http.StatusOK {
+		t.Errorf("other client status = %d, want 200", code)
+	}
+}
+
+func TestClientRateLimitKey(t *testing.T) {
+	newContext := func() *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"user_id":"user-1"}`))
+		c.Request.RemoteAddr = "198.51.100.1:1234"
+		return c
+	}
+
+	if key := clientRateLimitKey(newContext()); key != "ip:198.51.100.1" {
+		t.Errorf("anonymous key = %q, want ip:198.51.100.1", key)
+	}
+
+	c := newContext()
+	c.Request.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: "python-backend"}}}}
+	if key := clientRateLimitKey(c); key != "mtls:CN=python-backend" {
+		t.Errorf("mTLS key = %q, want mtls:CN=python-backend", key)
+	}
+
+	// La firma identifica al cliente aunque también haya certificado
+	c.Set("client_id", "mobile-app")
+	if key := clientRateLimitKey(c); key != "client:mobile-app" {
+		t.Errorf("signed key = %q, want client:mobile-app", key)
+	}
+}
+
+func TestJSONNotFoundAndMethodNotAllowed(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	tests := []struct {
+		method string
+		path   string
+		want   int
+		code   string
+	}{
+		{http.MethodGet, "/api/v1/unknown", http.StatusNotFound, apierror.RouteNotFound},
+		{http.MethodDelete, "/api/v1/transactions/process", http.StatusMethodNotAllowed, apierror.MethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+		if w.Code != tt.want {
+			t.Errorf("%s %s status = %d, want %d", tt.method, tt.path, w.Code, tt.want)
+		}
+		var body map[string]string
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s %s body is not JSON: %s", tt.method, tt.path, w.Body.String())
+		}
+		if body["error"] == "" || body["code"] != tt.code || body["request_id"] != w.Header().Get("X-Request-ID") {
+			t.Errorf("%s %s body = %v, want error, code %s and request_id %q", tt.method, tt.path, body, tt.code, w.Header().Get("X-Request-ID"))
+		}
+	}
+}
+
+func TestRecoveryMiddleware(t *testing.T) {
+	router := gin.New()
+	router.Use(recoveryMiddleware())
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+	router.GET("/abort", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+		c.Writer.Flush()
+		panic(http.ErrAbortHandler)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("panic status = %d, want 500", w.Code)
+	}
+
+	// ErrAbortHandler llega a net/http, que corta la respuesta
+	defer func() {
+		if err := recover(); err != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler", err)
+		}
+	}()
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/abort", nil))
+	t.Error("ErrAbortHandler was swallowed")
+}
+
+func TestZeroTrustErrorCodes(t *testing.T) {
+	router, secMgr := newTestRouter(t)
+
+	tests := []struct {
+		name   string
+		token  string
+		status int
+		code   string
+	}{
+		{"sin token", "", http.StatusUnauthorized, apierror.TokenRequired},
+		{"token malformado", "not-a-token", http.StatusUnauthorized, apierror.TokenInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/internal/validate-transfer", strings.NewReader("{}"))
+			if tt.token != "" {
+				req.Header.Set("X-Service-Token", tt.token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			var body map[string]interface{}
+			_ = json.Unmarshal(w.Body.Bytes(), &body)
+			if w.Code != tt.status || body["code"] != tt.code {
+				t.Errorf("status/code = %d/%v, want %d/%s", w.Code, body["code"], tt.status, tt.code)
+			}
+		})
+	}
+
+	w := postInternal(t, router, secMgr, "/validate-transfer", []string{"calculate:metrics"})
+	var body map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	if w.Code != http.StatusForbidden || body["code"] != apierror.InsufficientPermissions {
+		t.Errorf("status/code = %d/%v, want 403/%s", w.Code, body["code"], apierror.InsufficientPermissions)
+	}
+}
+
+func TestTokenErrorCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{fmt.Errorf("validate: %w", security.ErrTokenExpired), apierror.TokenExpired},
+		{security.ErrTokenRevoked, apierror.TokenRevoked},
+		{security.ErrTokenReplay, apierror.TokenReplayed},
+		{errors.New("invalid token signature"), apierror.TokenInvalid},
+	}
+	for _, tt := range tests {
+		if got := tokenErrorCode(tt.err); got != tt.want {
+			t.Errorf("tokenErrorCode(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestZeroTrustAcceptsJWT(t *testing.T) {
+	router, secMgr := newTestRouter(t)
+
+	token, err := secMgr.GenerateJWT("node-gateway", serviceName, []string{"validate:transfers"}, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := `{"from_account":"a","to_account":"b","amount":"10"}`
+	for _, header := range []string{"X-Service-Token", "Authorization"} {
+		value := token
+		if header == "Authorization" {
+			value = "Bearer " + token
+		}
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/internal/validate-transfer", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(header, value)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Errorf("JWT in %s: status = %d, want 200", header, w.Code)
+		}
+	}
+
+	// Un JWT emitido para otro servicio se rechaza
+	other, _ := secMgr.GenerateJWT("node-gateway", "other-service", []string{"validate:transfers"}, 60)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/internal/validate-transfer", strings.NewReader(body))
+	req.Header.Set("X-Service-Token", other)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("JWT for another audience: status = %d, want 401", w.Code)
+	}
+}
+
+func TestApproveRouteRequiresApproverToken(t *testing.T) {
+	router, secMgr := newTestRouter(t)
+	path := "/api/v1/transactions/approve/00000000-0000-4000-8000-000000000000"
+
+	tests := []struct {
+		name        string
+		permissions []string
+		want        int
+	}{
+		{"sin token", nil, http.StatusUnauthorized},
+		{"sin permiso de aprobación", []string{"validate:transfers"}, http.StatusForbidden},
+		// Supera la autorización y llega al handler, que no encuentra la transacción
+		{"aprobador", []string{"approve:transactions"}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, path, nil)
+			if tt.permissions != nil {
+				token, err := secMgr.GenerateServiceToken("treasury", serviceName, tt.permissions, 60)
+				if err != nil {
+					t.Fatal(err)
+				}
+				req.Header.Set("X-Service-Token", token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d (body %s)", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"github.com/fincore/core-go/internal/ratelimit"
+	"github.com/fincore/core-go/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+// Límites por defecto (solicitudes por segundo y ráfaga)
+const (
+	defaultRateLimitRPS           = 50
+	defaultRateLimitBurst         = 100
+	defaultInternalRateLimitRPS   = 200
+	defaultInternalRateLimitBurst = 400
+)
+
+// newPublicRateLimiter limita los endpoints públicos por cliente
+func newPublicRateLimiter() gin.HandlerFunc {
+	store := ratelimit.NewStore(
+		float64(getEnvInt("RATE_LIMIT_RPS", defaultRateLimitRPS)),
+		getEnvInt("RATE_LIMIT_BURST", defaultRateLimitBurst),
+		getEnvInt("RATE_LIMIT_MAX_KEYS", ratelimit.DefaultMaxKeys),
+	)
+	return ratelimit.Middleware(store, clientRateLimitKey)
+}
+
+// newInternalRateLimiter limita los endpoints internos por servicio de origen
+func newInternalRateLimiter() gin.HandlerFunc {
+	store := ratelimit.NewStore(
+		float64(getEnvInt("INTERNAL_RATE_LIMIT_RPS", defaultInternalRateLimitRPS)),
+		getEnvInt("INTERNAL_RATE_LIMIT_BURST", defaultInternalRateLimitBurst),
+		getEnvInt("RATE_LIMIT_MAX_KEYS", ratelimit.DefaultMaxKeys),
+	)
+	return ratelimit.Middleware(store, serviceRateLimitKey)
+}
+
+// clientRateLimitKey usa la identidad autenticada del cliente: el client_id
+// de la firma HMAC o el subject del certificado mTLS, y sin ellas la IP. Un
+// user_id del body no sirve como clave: no está autenticado y uno aleatorio
+// por request evadiría el límite y desplazaría del store a los legítimos.
+func clientRateLimitKey(c *gin.Context) string {
+	if clientID := c.GetString("client_id"); clientID != "" {
+		return "client:" + clientID
+	}
+	if tls := c.Request.TLS; tls != nil && len(tls.PeerCertificates) > 0 {
+		return "mtls:" + tls.PeerCertificates[0].Subject.String()
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// serviceRateLimitKey usa el Source del token verificado por zeroTrustMiddleware
+func serviceRateLimitKey(c *gin.Context) string {
+	if value, ok := c.Get("service_claims"); ok {
+		if claims, ok := value.(*security.ServiceTokenClaims); ok {
+			return "service:" + claims.Source
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fincore/core-go/internal/apierror"
+	"github.com/fincore/core-go/internal/logging"
+	"github.com/fincore/core-go/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+// Headers de la protección contra replay
+const (
+	replayTimestampHeader = "X-Timestamp"
+	replayNonceHeader     = "X-Nonce"
+)
+
+// defaultReplayWindowSeconds es la diferencia máxima entre X-Timestamp y el
+// reloj del servidor, en cualquier dirección
+const defaultReplayWindowSeconds = 300
+
+// maxNonceLength acota la memoria que ocupa cada nonce recordado
+const maxNonceLength = 128
+
+// replayProtection rechaza requests repetidos en los grupos de rutas que la
+// activan. Todos los grupos comparten el cache de nonces.
+type replayProtection struct {
+	groups map[string]bool
+	cache  *security.NonceCache
+	window time.Duration
+	now    func() time.Time
+}
+
+// newReplayProtection activa la protección en los grupos de
+// REPLAY_PROTECTED_GROUPS (separados por comas, por ejemplo
+// "transactions,ledger"), con una ventana de REPLAY_WINDOW_SECONDS y hasta
+// REPLAY_NONCE_CACHE_SIZE nonces recordados
+func newReplayProtection() *replayProtection {
+	groups := make(map[string]bool)
+	for _, group := range strings.Split(os.Getenv("REPLAY_PROTECTED_GROUPS"), ",") {
+		if group = strings.TrimSpace(group); group != "" {
+			groups[group] = true
+		}
+	}
+	return &replayProtection{
+		groups: groups,
+		cache:  security.NewNonceCache(getEnvInt("REPLAY_NONCE_CACHE_SIZE", security.MaxTrackedNonces)),
+		window: time.Duration(getEnvInt("REPLAY_WINDOW_SECONDS", defaultReplayWindowSeconds)) * time.Second,
+		now:    time.Now,
+	}
+}
+
+// forGroup retorna el middleware del grupo, que no hace nada si el grupo no
+// activó la protección
+func (p *replayProtection) forGroup(group string) gin.HandlerFunc {
+	if !p.groups[group] {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return p.middleware()
+}
+
+// middleware exige X-Timestamp (segundos Unix) dentro de la ventana y un
+// X-Nonce no usado en ella. El nonce se recuerda hasta que su timestamp sale
+// de la ventana, momento desde el cual el request ya se rechaza por antiguo.
+func (p *replayProtection) middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timestamp := c.GetHeader(replayTimestampHeader)
+		nonce := c.GetHeader(replayNonceHeader)
+		if timestamp == "" || nonce == "" {
+			apierror.Respond(c, http.StatusUnauthorized, apierror.ReplayHeadersRequired, "X-Timestamp and X-Nonce headers required")
+			return
+		}
+		if len(nonce) > maxNonceLength {
+			apierror.RespondWith(c, http.StatusBadRequest, apierror.InvalidNonce, "Nonce too long", gin.H{
+				"max_length": maxNonceLength,
+			})
+			return
+		}
+
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, apierror.InvalidTimestamp, "Invalid request timestamp")
+			return
+		}
+		sentAt, now := time.Unix(unix, 0), p.now()
+		if age := now.Sub(sentAt); age > p.window || age < -p.window {
+			apierror.Respond(c, http.StatusUnauthorized, apierror.RequestExpired, "Request timestamp outside the allowed window")
+			return
+		}
+
+		// La ventana incluye su límite, así que el nonce se recuerda hasta
+		// justo después de sentAt+window. Con el cache lleno de nonces
+		// vigentes se rechaza: olvidar uno permitiría repetir su request.
+		added, err := p.cache.Add(nonce, sentAt.Add(p.window+time.Nanosecond), now)
+		if err != nil {
+			logging.FromContext(c).Error("replay protection unavailable", "error", err)
+			apierror.Respond(c, http.StatusServiceUnavailable, apierror.ReplayCacheFull, "Replay protection temporarily unavailable")
+			return
+		}
+		if !added {
+			apierror.Respond(c, http.StatusUnauthorized, apierror.RequestReplayed, "Request already received")
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fincore/core-go/internal/apierror"
+	"github.com/fincore/core-go/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+// replayRouter monta la protección en el grupo /protected y deja /open sin ella
+func replayRouter(t *testing.T, now time.Time) *gin.Engine {
+	t.Helper()
+	return replayRouterWithCapacity(t, now, 100)
+}
+
+// replayRouterWithCapacity es replayRouter con capacity nonces recordados
+func replayRouterWithCapacity(t *testing.T, now time.Time, capacity int) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	replay := &replayProtection{
+		groups: map[string]bool{"protected": true},
+		cache:  security.NewNonceCache(capacity),
+		window: 5 * time.Minute,
+		now:    func() time.Time { return now },
+	}
+	router := gin.New()
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.Group("/protected", replay.forGroup("protected")).POST("", ok)
+	router.Group("/open", replay.forGroup("open")).POST("", ok)
+	return router
+}
+
+// doReplayable envía un POST con los headers de replay que no estén vacíos
+func doReplayable(router *gin.Engine, path string, sentAt time.Time, nonce string) (*httptest.ResponseRecorder, string) {
+	req := httptest.NewRequest(http.MethodPost, path, nil)
+	if !sentAt.IsZero() {
+		req.Header.Set(replayTimestampHeader, strconv.FormatInt(sentAt.Unix(), 10))
+	}
+	if nonce != "" {
+		req.Header.Set(replayNonceHeader, nonce)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp struct {
+		Code string `json:"code"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return w, resp.Code
+}
+
+func TestReplayProtectionAcceptsFreshRequests(t *testing.T) {
+	now := time.Now()
+	router := replayRouter(t, now)
+
+	for _, sentAt := range []time.Time{now, now.Add(-4 * time.Minute), now.Add(4 * time.Minute)} {
+		if w, code := doReplayable(router, "/protected", sentAt, "nonce-"+sentAt.String()); w.Code != http.StatusOK {
+			t.Errorf("sent at %v: status/code = %d/%s, want 200", sentAt, w.Code, code)
+		}
+	}
+}
+
+func TestReplayProtectionRejectsStaleRequests(t *testing.T) {
+	now := time.Now()
+	router := replayRouter(t, now)
+
+	for _, sentAt := range []time.Time{now.Add(-6 * time.Minute), now.Add(6 * time.Minute)} {
+		if w, code := doReplayable(router, "/protected", sentAt, "nonce-"+sentAt.String()); w.Code != http.StatusUnauthorized || code != apierror.RequestExpired {
+			t.Errorf("sent at %v: status/code = %d/%s, want 401/%s", sentAt, w.Code, code, apierror.RequestExpired)
+		}
+	}
+}
+
+func TestReplayProtectionRejectsReplayedRequests(t *testing.T) {
+	now := time.Now()
+	router := replayRouter(t, now)
+
+	if w, _ := doReplayable(router, "/protected", now, "nonce-1"); w.Code != http.StatusOK {
+		t.Fatalf("first request: status = %d, want 200", w.Code)
+	}
+	// El mismo nonce con otro timestamp dentro de la ventana sigue siendo un replay
+	for _, sentAt := range []time.Time{now, now.Add(-time.Minute)} {
+		if w, code := doReplayable(router, "/protected", sentAt, "nonce-1"); w.Code != http.StatusUnauthorized || code != apierror.RequestReplayed {
+			t.Errorf("replay sent at %v: status/code = %d/%s, want 401/%s", sentAt, w.Code, code, apierror.RequestReplayed)
+		}
+	}
+	if w, _ := doReplayable(router, "/protected", now, "nonce-2"); w.Code != http.StatusOK {
+		t.Errorf("new nonce: status = %d, want 200", w.Code)
+	}
+}
+
+func TestReplayProtectionFailsClosedWhenCacheIsFull(t *testing.T) {
+	now := time.Now()
+	router := replayRouterWithCapacity(t, now, 2)
+
+	for _, nonce := range []string{"nonce-1", "nonce-2"} {
+		if w, _ := doReplayable(router, "/protected", now, nonce); w.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, want 200", nonce, w.Code)
+		}
+	}
+	if w, code := doReplayable(router, "/protected", now, "nonce-3"); w.Code != http.StatusServiceUnavailable || code != apierror.ReplayCacheFull {
+		t.Errorf("full cache: status/code = %d/%s, want 503/%s", w.Code, code, apierror.ReplayCacheFull)
+	}
+	// Un flood de nonces nuevos no hace olvidar los vigentes
+	if w, code := doReplayable(router, "/protected", now, "nonce-1"); w.Code != http.StatusUnauthorized || code != apierror.RequestReplayed {
+		t.Errorf("replay after flood: status/code = %d/%s, want 401/%s", w.Code, code, apierror.RequestReplayed)
+	}
+}
+
+func TestReplayProtectionInvalidHeaders(t *testing.T) {
+	now := time.Now()
+	router := replayRouter(t, now)
+
+	tests := []struct {
+		name   string
+		sentAt time.Time
+		nonce  string
+		status int
+		code   string
+	}{
+		{"sin timestamp", time.Time{}, "nonce-1", http.StatusUnauthorized, apierror.ReplayHeadersRequired},
+		{"sin nonce", now, "", http.StatusUnauthorized, apierror.ReplayHeadersRequired},
+		{"nonce demasiado largo", now, strings.Repeat("n", maxNonceLength+1), http.StatusBadRequest, apierror.InvalidNonce},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w, code := doReplayable(router, "/protected", tt.sentAt, tt.nonce); w.Code != tt.status || code != tt.code {
+				t.Errorf("status/code = %d/%s, want %d/%s", w.Code, code, tt.status, tt.code)
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/protected", nil)
+	req.Header.Set(replayTimestampHeader, "ayer")
+	req.Header.Set(replayNonceHeader, "nonce-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), apierror.InvalidTimestamp) {
+		t.Errorf("invalid timestamp: status = %d, body = %s, want 400 %s", w.Code, w.Body.String(), apierror.InvalidTimestamp)
+	}
+}
+
+func TestReplayProtectionIsOptInPerGroup(t *testing.T) {
+	router := replayRouter(t, time.Now())
+
+	// Sin headers y repetido, el grupo que no la activó no se ve afectado
+	for i := 0; i < 2; i++ {
+		if w, _ := doReplayable(router, "/open", time.Time{}, ""); w.Code != http.StatusOK {
+			t.Errorf("open group: status = %d, want 200", w.Code)
+		}
+	}
+
+	t.Setenv("REPLAY_PROTECTED_GROUPS", " ledger , transactions")
+	replay := newReplayProtection()
+	if !replay.groups["ledger"] || !replay.groups["transactions"] || replay.groups["internal"] || replay.window != defaultReplayWindowSeconds*time.Second {
+		t.Errorf("replay protection = %+v, want ledger and transactions with the default window", replay)
+	}
+}
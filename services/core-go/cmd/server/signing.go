@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fincore/core-go/internal/apierror"
+	"github.com/fincore/core-go/internal/logging"
+	"github.com/fincore/core-go/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+// Headers de la firma de requests
+const (
+	clientIDHeader           = "X-Client-ID"
+	signatureHeader          = "X-Signature"
+	signatureTimestampHeader = "X-Signature-Timestamp"
+)
+
+// defaultSignatureMaxAgeSeconds es la diferencia máxima entre el timestamp
+// firmado y el reloj del servidor, en cualquier dirección
+const defaultSignatureMaxAgeSeconds = 300
+
+// maxSignedBodyBytes limita el body que se lee en memoria para verificarlo
+const maxSignedBodyBytes = 32 << 20
+
+// newRequestSigningMiddleware exige firma en los endpoints públicos cuando
+// ENABLE_REQUEST_SIGNING=true. Los secretos se leen de REQUEST_SIGNING_SECRETS
+// como lista "cliente=secreto" separada por comas; sin secretos válidos todos
+// los requests se rechazan.
+func newRequestSigningMiddleware() gin.HandlerFunc {
+	if os.Getenv("ENABLE_REQUEST_SIGNING") != "true" {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	secrets := parseClientSecrets(os.Getenv("REQUEST_SIGNING_SECRETS"))
+	if len(secrets) == 0 {
+		slog.Warn("Request signing enabled without REQUEST_SIGNING_SECRETS, all signed endpoints will reject requests")
+	}
+	maxAge := time.Duration(getEnvInt("REQUEST_SIGNATURE_MAX_AGE_SECONDS", defaultSignatureMaxAgeSeconds)) * time.Second
+	return requestSigningMiddleware(secrets, maxAge, time.Now)
+}
+
+// parseClientSecrets interpreta una lista "cliente=secreto" separada por
+// comas, ignorando las entradas inválidas
+func parseClientSecrets(value string) security.StaticClientSecrets {
+	secrets := make(security.StaticClientSecrets)
+	if value == "" {
+		return secrets
+	}
+	for _, item := range strings.Split(value, ",") {
+		clientID, secret, ok := strings.Cut(strings.TrimSpace(item), "=")
+		clientID, secret = strings.TrimSpace(clientID), strings.TrimSpace(secret)
+		if !ok || clientID == "" || secret == "" {
+			slog.Warn("Invalid REQUEST_SIGNING_SECRETS entry", "client_id", clientID)
+			continue
+		}
+		secrets[clientID] = []byte(secret)
+	}
+	return secrets
+}
+
+// requestSigningMiddleware verifica X-Signature, el HMAC-SHA256 de
+// "timestamp.body" con el secreto del cliente de X-Client-ID. Un timestamp
+// fuera de maxAge se rechaza para que una firma capturada no pueda
+// reutilizarse indefinidamente. El body se restaura para el handler.
+func requestSigningMiddleware(store security.ClientSecretStore, maxAge time.Duration, now func() time.Time) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clientID := c.GetHeader(clientIDHeader)
+		signature := c.GetHeader(signatureHeader)
+		timestamp := c.GetHeader(signatureTimestampHeader)
+		if clientID == "" || signature == "" || timestamp == "" {
+			apierror.Respond(c, http.StatusUnauthorized, apierror.SignatureRequired, "Request signature required")
+			return
+		}
+
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			apierror.Respond(c, http.StatusUnauthorized, apierror.SignatureInvalid, "Invalid signature timestamp")
+			return
+		}
+		if age := now().Sub(time.Unix(unix, 0)); age > maxAge || age < -maxAge {
+			apierror.Respond(c, http.StatusUnauthorized, apierror.SignatureExpired, "Request signature expired")
+			return
+		}
+
+		secret, err := store.ClientSecret(c.Request.Context(), clientID)
+		if errors.Is(err, security.ErrUnknownClient) {
+			apierror.Respond(c, http.StatusUnauthorized, apierror.SignatureInvalid, "Invalid request signature")
+			return
+		}
+		if err != nil {
+			logging.FromContext(c).Error("failed to look up signing secret", "client_id", clientID, "error", err)
+			apierror.Respond(c, http.StatusInternalServerError, apierror.Internal, "Failed to verify request signature")
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			body, err = io.ReadAll(io.LimitReader(c.Request.Body, maxSignedBodyBytes+1))
+			if err != nil {
+				apierror.Respond(c, http.StatusBadRequest, apierror.InvalidRequest, "Failed to read request body")
+				return
+			}
+			if len(body) > maxSignedBodyBytes {
+				apierror.Respond(c, http.StatusRequestEntityTooLarge, apierror.BodyTooLarge, "Request body too large")
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		if !security.VerifyRequestSignature(secret, timestamp, body, signature) {
+			apierror.Respond(c, http.StatusUnauthorized, apierror.SignatureInvalid, "Invalid request signature")
+			return
+		}
+
+		c.Set("client_id", clientID)
+		c.Next()
+	}
+}
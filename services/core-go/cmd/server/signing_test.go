@@ -0,0 +1,129 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fincore/core-go/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+// signedRouter monta requestSigningMiddleware con un cliente conocido y un
+// handler que devuelve el body recibido
+func signedRouter(t *testing.T, now time.Time) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	secrets := security.StaticClientSecrets{"partner-app": []byte("partner-signing-secret")}
+	router := gin.New()
+	router.Use(requestSigningMiddleware(secrets, 5*time.Minute, func() time.Time { return now }))
+	router.POST("/", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, string(body))
+	})
+	return router
+}
+
+// doSigned envía body firmando signedBody con el secreto del cliente
+func doSigned(router *gin.Engine, body, signedBody string, signedAt time.Time) *httptest.ResponseRecorder {
+	timestamp := strconv.FormatInt(signedAt.Unix(), 10)
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(clientIDHeader, "partner-app")
+	req.Header.Set(signatureTimestampHeader, timestamp)
+	req.Header.Set(signatureHeader, security.SignRequest([]byte("partner-signing-secret"), timestamp, []byte(signedBody)))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRequestSigningAcceptsValidSignature(t *testing.T) {
+	now := time.Now()
+	router := signedRouter(t, now)
+
+	body := `{"user_id":"user-1","amount":"10"}`
+	w := doSigned(router, body, body, now.Add(-30*time.Second))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	if w.Body.String() != body {
+		t.Errorf("handler body = %q, want %q", w.Body.String(), body)
+	}
+}
+
+func TestRequestSigningRejectsTamperedBody(t *testing.T) {
+	now := time.Now()
+	router := signedRouter(t, now)
+
+	w := doSigned(router, `{"user_id":"user-1","amount":"10000"}`, `{"user_id":"user-1","amount":"10"}`, now)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "Invalid request signature") {
+		t.Errorf("body = %s", w.Body.String())
+	}
+}
+
+func TestRequestSigningRejectsExpiredTimestamp(t *testing.T) {
+	now := time.Now()
+	router := signedRouter(t, now)
+
+	tests := []struct {
+		name     string
+		signedAt time.Time
+	}{
+		{"timestamp antiguo", now.Add(-10 * time.Minute)},
+		{"timestamp futuro", now.Add(10 * time.Minute)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doSigned(router, `{}`, `{}`, tt.signedAt)
+			if w.Code != http.StatusUnauthorized {
+				t.Fatalf("status = %d, want 401", w.Code)
+			}
+			if !strings.Contains(w.Body.String(), "Request signature expired") {
+				t.Errorf("body = %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRequestSigningRejectsMissingHeadersAndUnknownClient(t *testing.T) {
+	now := time.Now()
+	router := signedRouter(t, now)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`)))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("unsigned status = %d, want 401", w.Code)
+	}
+
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+	req.Header.Set(clientIDHeader, "unknown-app")
+	req.Header.Set(signatureTimestampHeader, timestamp)
+	req.Header.Set(signatureHeader, security.SignRequest([]byte("partner-signing-secret"), timestamp, []byte(`{}`)))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("unknown client status = %d, want 401", w.Code)
+	}
+}
+
+func TestParseClientSecrets(t *testing.T) {
+	secrets := parseClientSecrets("app-a=secret-a, app-b = secret-b ,invalid,=no-client,app-c=")
+	if len(secrets) != 2 {
+		t.Fatalf("secrets = %v, want app-a and app-b", secrets)
+	}
+	if string(secrets["app-a"]) != "secret-a" {
+		t.Errorf("app-a = %q", secrets["app-a"])
+	}
+	if string(secrets["app-b"]) != "secret-b" {
+		t.Errorf("app-b = %q", secrets["app-b"])
+	}
+}
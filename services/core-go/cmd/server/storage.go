@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fincore/core-go/internal/handlers"
+	"github.com/fincore/core-go/internal/ledger"
+	"github.com/fincore/core-go/internal/security"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// storage agrupa los stores persistentes del servicio
+type storage struct {
+	ledger       *ledger.LedgerChain
+	transactions handlers.TransactionStore
+}
+
+// setupStorage construye la cadena del ledger y el store de transacciones
+// sobre PostgreSQL cuando DATABASE_URL está configurada, o en memoria en
+// desarrollo, con hasta LEDGER_MEMORY_CAPACITY entradas. Las entradas del ledger se firman con secMgr, que también cifra
+// los campos de ENCRYPTED_TRANSACTION_FIELDS (separados por comas). Retorna
+// una función para liberar las conexiones al cerrar el servicio.
+func setupStorage(ctx context.Context, secMgr *security.SecurityManager) (*storage, func(), error) {
+	var ledgerStore ledger.LedgerStore
+	var txStore handlers.TransactionStore
+	closeFn := func() {}
+
+	if databaseURL := os.Getenv("DATABASE_URL"); databaseURL != "" {
+		pool, err := pgxpool.New(ctx, databaseURL)
+		if err != nil {
+			return nil, nil, err
+		}
+		pgLedger, err := ledger.NewPostgresLedgerStore(ctx, pool)
+		if err != nil {
+			pool.Close()
+			return nil, nil, err
+		}
+		pgTransactions, err := handlers.NewPostgresTransactionStore(ctx, pool)
+		if err != nil {
+			pool.Close()
+			return nil, nil, err
+		}
+		ledgerStore = pgLedger
+		txStore = pgTransactions
+		closeFn = pool.Close
+	} else {
+		slog.Warn("DATABASE_URL not set, using in-memory ledger and transaction stores (data is not persisted)")
+		ledgerStore = ledger.NewInMemoryLedgerStore(getEnvInt("LEDGER_MEMORY_CAPACITY", ledger.DefaultInMemoryCapacity))
+		txStore = handlers.NewMockTransactionStore()
+	}
+
+	// El breaker evita que una base de datos lenta acumule requests bloqueados
+	ledgerStore = ledger.NewBreakerStore(ledgerStore,
+		getEnvInt("LEDGER_BREAKER_FAILURES", ledger.DefaultBreakerFailures),
+		time.Duration(getEnvInt("LEDGER_BREAKER_TIMEOUT_SECONDS", int(ledger.DefaultBreakerTimeout/time.Second)))*time.Second,
+	)
+
+	// Campos sensibles cifrados en reposo; sólo quedan en claro en memoria
+	if fields := os.Getenv("ENCRYPTED_TRANSACTION_FIELDS"); fields != "" {
+		encrypted, err := handlers.NewEncryptedTransactionStore(txStore, secMgr, strings.Split(fields, ","))
+		if err != nil {
+			closeFn()
+			return nil, nil, err
+		}
+		txStore = encrypted
+	}
+
+	chain, err := ledger.NewSignedLedgerChain(ctx, ledgerStore, secMgr)
+	if err != nil {
+		closeFn()
+		return nil, nil, err
+	}
+	return &storage{ledger: chain, transactions: txStore}, closeFn, nil
+}
@@ -0,0 +1,124 @@
+/*
+Errores de la API HTTP
+
+Implementa:
+- Códigos de error estables y legibles por máquina
+- Helper que escribe el envelope de error de forma uniforme
+
+Los clientes deben decidir por code; error es un mensaje para personas y
+puede cambiar. Un código publicado no se renombra ni se reutiliza.
+*/
+package apierror
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// Códigos generales
+const (
+	// InvalidRequest indica un body o query string que no se pudo decodificar
+	InvalidRequest = "INVALID_REQUEST"
+	// ValidationFailed indica campos que incumplen las reglas de binding; la
+	// respuesta detalla cada campo en fields
+	ValidationFailed = "VALIDATION_FAILED"
+	// InvalidParameter indica parámetros bien formados pero inválidos para el
+	// cálculo o la operación pedida
+	InvalidParameter = "INVALID_PARAMETER"
+	InvalidTimestamp = "INVALID_TIMESTAMP"
+	RouteNotFound    = "ROUTE_NOT_FOUND"
+	MethodNotAllowed = "METHOD_NOT_ALLOWED"
+	BodyTooLarge     = "BODY_TOO_LARGE"
+	BatchTooLarge    = "BATCH_TOO_LARGE"
+	RateLimited      = "RATE_LIMITED"
+
+	// Internal indica un fallo del servicio o de sus dependencias
+	Internal            = "INTERNAL_ERROR"
+	LedgerUnavailable   = "LEDGER_UNAVAILABLE"
+	LedgerFull          = "LEDGER_FULL"
+	ShuttingDown        = "SERVICE_SHUTTING_DOWN"
+	ProcessingTimeout   = "PROCESSING_TIMEOUT"
+	ClientClosedRequest = "CLIENT_CLOSED_REQUEST"
+)
+
+// Códigos de transacciones
+const (
+	AmountNotPositive       = "AMOUNT_NOT_POSITIVE"
+	AmountPrecisionExceeded = "AMOUNT_PRECISION_EXCEEDED"
+	AmountLimitExceeded     = "AMOUNT_LIMIT_EXCEEDED"
+	VelocityLimitExceeded   = "VELOCITY_LIMIT_EXCEEDED"
+	InvalidCurrency         = "INVALID_CURRENCY"
+	InvalidTransactionType  = "INVALID_TRANSACTION_TYPE"
+	InvalidTransactionID    = "INVALID_TRANSACTION_ID"
+	TransactionNotFound     = "TRANSACTION_NOT_FOUND"
+	DeviceRisk              = "DEVICE_RISK"
+	DeviceStoreFull         = "DEVICE_STORE_FULL"
+	IdempotencyKeyTooLong   = "IDEMPOTENCY_KEY_TOO_LONG"
+	IdempotencyKeyInUse     = "IDEMPOTENCY_KEY_IN_USE"
+	IdempotencyKeyReused    = "IDEMPOTENCY_KEY_REUSED"
+	InvalidCursor           = "INVALID_CURSOR"
+	InvalidLimit            = "INVALID_LIMIT"
+	// VersionConflict indica que la transacción cambió desde que se leyó
+	VersionConflict          = "VERSION_CONFLICT"
+	InvalidIfMatch           = "INVALID_IF_MATCH"
+	AlreadyReversed          = "ALREADY_REVERSED"
+	TransactionNotReversible = "TRANSACTION_NOT_REVERSIBLE"
+	TransactionNotPending    = "TRANSACTION_NOT_PENDING"
+	DuplicateApproval        = "DUPLICATE_APPROVAL"
+)
+
+// Códigos del ledger
+const (
+	LedgerEntryNotFound = "LEDGER_ENTRY_NOT_FOUND"
+	InvalidSequence     = "INVALID_SEQUENCE"
+	InvalidRange        = "INVALID_RANGE"
+	UnsupportedFormat   = "UNSUPPORTED_FORMAT"
+	ClientRefTooLong    = "CLIENT_REF_TOO_LONG"
+)
+
+// Códigos de autenticación y autorización
+const (
+	MTLSRequired            = "MTLS_REQUIRED"
+	TokenRequired           = "TOKEN_REQUIRED"
+	TokenInvalid            = "TOKEN_INVALID"
+	TokenExpired            = "TOKEN_EXPIRED"
+	TokenRevoked            = "TOKEN_REVOKED"
+	TokenReplayed           = "TOKEN_REPLAYED"
+	InsufficientPermissions = "INSUFFICIENT_PERMISSIONS"
+	TokenTTLOutOfRange      = "TOKEN_TTL_OUT_OF_RANGE"
+	SignatureRequired       = "SIGNATURE_REQUIRED"
+	SignatureInvalid        = "SIGNATURE_INVALID"
+	SignatureExpired        = "SIGNATURE_EXPIRED"
+	ReplayHeadersRequired   = "REPLAY_HEADERS_REQUIRED"
+	InvalidNonce            = "INVALID_NONCE"
+	RequestExpired          = "REQUEST_EXPIRED"
+	RequestReplayed         = "REQUEST_REPLAYED"
+	ReplayCacheFull         = "REPLAY_CACHE_FULL"
+)
+
+// Body retorna el envelope de error {"error", "code"} con los campos de extra
+// y el request_id del request, si lo hay
+func Body(c *gin.Context, code, message string, extra gin.H) gin.H {
+	body := gin.H{
+		"error": message,
+		"code":  code,
+	}
+	if requestID := c.GetString("request_id"); requestID != "" {
+		body["request_id"] = requestID
+	}
+	for key, value := range extra {
+		body[key] = value
+	}
+	return body
+}
+
+// Respond escribe la respuesta de error y detiene la cadena de handlers, de
+// modo que sirve igual en handlers y en middleware
+func Respond(c *gin.Context, status int, code, message string) {
+	c.AbortWithStatusJSON(status, Body(c, code, message, nil))
+}
+
+// RespondWith agrega a la respuesta de error campos con detalles del fallo,
+// como el límite superado o la versión vigente
+func RespondWith(c *gin.Context, status int, code, message string, extra gin.H) {
+	c.AbortWithStatusJSON(status, Body(c, code, message, extra))
+}
@@ -0,0 +1,51 @@
+/*
+Información de build del servicio
+
+Implementa:
+- Versión, commit y fecha de build inyectados con -ldflags al compilar:
+
+	go build -ldflags "-X github.com/fincore/core-go/internal/buildinfo.Version=1.2.0 \
+	  -X github.com/fincore/core-go/internal/buildinfo.Commit=$(git rev-parse HEAD) \
+	  -X github.com/fincore/core-go/internal/buildinfo.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+
+- Tiempo de actividad desde el inicio del proceso
+*/
+package buildinfo
+
+import "time"
+
+// Valores inyectados al compilar; un binario compilado sin -ldflags conserva
+// los valores por defecto
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildDate = "unknown"
+)
+
+// startTime se fija al inicializar el paquete, al arrancar el proceso
+var startTime = time.Now()
+
+// Info describe el binario en ejecución
+type Info struct {
+	Version       string    `json:"version"`
+	Commit        string    `json:"commit"`
+	BuildDate     string    `json:"build_date"`
+	StartedAt     time.Time `json:"started_at"`
+	UptimeSeconds int64     `json:"uptime_seconds"`
+}
+
+// Get retorna la información de build y el tiempo de actividad actual
+func Get() Info {
+	return Info{
+		Version:       Version,
+		Commit:        Commit,
+		BuildDate:     BuildDate,
+		StartedAt:     startTime.UTC(),
+		UptimeSeconds: int64(Uptime() / time.Second),
+	}
+}
+
+// Uptime retorna el tiempo transcurrido desde el inicio del proceso
+func Uptime() time.Duration {
+	return time.Since(startTime)
+}
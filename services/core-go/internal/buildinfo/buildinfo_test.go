@@ -0,0 +1,23 @@
+package buildinfo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetReportsUptimeSinceStart(t *testing.T) {
+	original := startTime
+	startTime = time.Now().Add(-90 * time.Second)
+	t.Cleanup(func() { startTime = original })
+
+	info := Get()
+	if info.UptimeSeconds < 90 || info.UptimeSeconds > 91 {
+		t.Errorf("uptime = %ds, want 90s", info.UptimeSeconds)
+	}
+	if !info.StartedAt.Equal(startTime) || info.StartedAt.Location() != time.UTC {
+		t.Errorf("started_at = %v, want %v in UTC", info.StartedAt, startTime)
+	}
+	if info.Version != Version || info.Commit != Commit || info.BuildDate != BuildDate {
+		t.Errorf("info = %+v, want the package variables", info)
+	}
+}
@@ -0,0 +1,197 @@
+package canonical
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Marshal serializa v como JSON canónico al estilo de RFC 8785 (JCS), para
+// que los hashes de integridad, del ledger y de auditoría sean reproducibles
+// desde servicios escritos en otros lenguajes:
+//   - Las claves de los objetos se ordenan por unidades UTF-16 en todos los
+//     niveles, como en RFC 8785
+//   - Sin espacios en blanco
+//   - Los números se normalizan: 1, 1.0 y json.Number("1") producen "1";
+//     se usa la notación más corta y exponente sólo fuera de [1e-6, 1e21),
+//     con el formato de ECMAScript (1e+21, 1e-7)
+//   - Los strings se emiten en UTF-8 sin escapar; sólo se escapan '"', '\' y
+//     los caracteres de control (U+0000 a U+001F)
+//
+// Los enteros de Go se emiten exactos aunque superen 2^53. Los tipos que no
+// son JSON básicos (structs, decimal.Decimal, time.Time) pasan primero por
+// json.Marshal y se canonicalizan tras decodificarlos. NaN e Inf retornan
+// error.
+func Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeCanonical(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeCanonical(buf *bytes.Buffer, v interface{}) error {
+	switch value := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		if value {
+			buf.WriteString("true")
+		} else {
+			buf.WriteString("false")
+		}
+	case string:
+		writeCanonicalString(buf, value)
+	case json.Number:
+		f, err := value.Float64()
+		if err != nil {
+			return fmt.Errorf("invalid number %q: %w", value, err)
+		}
+		return writeCanonicalFloat(buf, f)
+	case float64:
+		return writeCanonicalFloat(buf, value)
+	case float32:
+		return writeCanonicalFloat(buf, float64(value))
+	case int:
+		buf.WriteString(strconv.FormatInt(int64(value), 10))
+	case int8:
+		buf.WriteString(strconv.FormatInt(int64(value), 10))
+	case int16:
+		buf.WriteString(strconv.FormatInt(int64(value), 10))
+	case int32:
+		buf.WriteString(strconv.FormatInt(int64(value), 10))
+	case int64:
+		buf.WriteString(strconv.FormatInt(value, 10))
+	case uint:
+		buf.WriteString(strconv.FormatUint(uint64(value), 10))
+	case uint8:
+		buf.WriteString(strconv.FormatUint(uint64(value), 10))
+	case uint16:
+		buf.WriteString(strconv.FormatUint(uint64(value), 10))
+	case uint32:
+		buf.WriteString(strconv.FormatUint(uint64(value), 10))
+	case uint64:
+		buf.WriteString(strconv.FormatUint(value, 10))
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return lessUTF16(keys[i], keys[j]) })
+
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalString(buf, k)
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, value[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, item := range value {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	default:
+		// Convertir a la representación JSON genérica y canonicalizarla
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %T: %w", value, err)
+		}
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber()
+		var generic interface{}
+		if err := decoder.Decode(&generic); err != nil {
+			return fmt.Errorf("failed to decode %T: %w", value, err)
+		}
+		return writeCanonical(buf, generic)
+	}
+	return nil
+}
+
+func writeCanonicalFloat(buf *bytes.Buffer, f float64) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return errors.New("NaN and Inf cannot be canonicalized")
+	}
+	if f == 0 {
+		// -0 y 0 se representan igual
+		buf.WriteByte('0')
+		return nil
+	}
+
+	abs := math.Abs(f)
+	if abs >= 1e-6 && abs < 1e21 {
+		buf.WriteString(strconv.FormatFloat(f, 'f', -1, 64))
+		return nil
+	}
+	// Go rellena el exponente a dos dígitos (1e-07); ECMAScript no
+	mantissa, exponent, _ := strings.Cut(strconv.FormatFloat(f, 'e', -1, 64), "e")
+	sign, digits := exponent[:1], strings.TrimLeft(exponent[1:], "0")
+	buf.WriteString(mantissa + "e" + sign + digits)
+	return nil
+}
+
+// lessUTF16 compara las claves por unidades de código UTF-16; sólo difiere
+// del orden por bytes con caracteres fuera del plano básico
+func lessUTF16(a, b string) bool {
+	ua, ub := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+	return len(ua) < len(ub)
+}
+
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+	const hex = "0123456789abcdef"
+
+	buf.WriteByte('"')
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == '"':
+			buf.WriteString(`\"`)
+		case r == '\\':
+			buf.WriteString(`\\`)
+		case r == '\b':
+			buf.WriteString(`\b`)
+		case r == '\f':
+			buf.WriteString(`\f`)
+		case r == '\n':
+			buf.WriteString(`\n`)
+		case r == '\r':
+			buf.WriteString(`\r`)
+		case r == '\t':
+			buf.WriteString(`\t`)
+		case r < 0x20:
+			buf.WriteString(`\u00`)
+			buf.WriteByte(hex[r>>4])
+			buf.WriteByte(hex[r&0xF])
+		case r == utf8.RuneError && size == 1:
+			// Bytes UTF-8 inválidos se reemplazan por U+FFFD
+			buf.WriteString("�")
+		default:
+			buf.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	buf.WriteByte('"')
+}
@@ -0,0 +1,81 @@
+package canonical
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		name  string
+		input interface{}
+		want  string
+	}{
+		{"claves ordenadas", map[string]interface{}{"b": 1, "a": 2, "aa": 3}, `{"a":2,"aa":3,"b":1}`},
+		{"anidado", map[string]interface{}{"x": map[string]interface{}{"d": true, "c": nil}, "a": []interface{}{map[string]interface{}{"z": 1, "y": 2}}}, `{"a":[{"y":2,"z":1}],"x":{"c":null,"d":true}}`},
+		{"orden UTF-16", map[string]interface{}{"\U0001F600": 1, "ﬁ": 2}, `{"😀":1,"ﬁ":2}`},
+		{"flotante entero", 3.0, `3`},
+		{"flotante", 0.1, `0.1`},
+		{"json.Number", json.Number("1.50"), `1.5`},
+		{"exponente grande", 1e21, `1e+21`},
+		{"exponente pequeño", 1.5e-7, `1.5e-7`},
+		{"límite inferior", 1e-6, `0.000001`},
+		{"cero negativo", math.Copysign(0, -1), `0`},
+		{"entero grande", int64(9007199254740993), `9007199254740993`},
+		{"unicode sin escapar", "ñandú <&>", `"ñandú <&>"`},
+		{"control", "a\nb\u0001\"\\", `"a\nb\u0001\"\\"`},
+		{"utf8 inválido", "a\xffb", `"a�b"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Marshal(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Marshal = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarshalEquivalentInputs(t *testing.T) {
+	// Mismo contenido con distinto orden y tipos numéricos tras un round-trip JSON
+	a := map[string]interface{}{"amount": 100, "meta": map[string]interface{}{"z": 1.0}}
+	b := map[string]interface{}{"meta": map[string]interface{}{"z": json.Number("1")}, "amount": 100.0}
+
+	ja, _ := Marshal(a)
+	jb, _ := Marshal(b)
+	if string(ja) != string(jb) {
+		t.Errorf("Marshal differs for equal inputs: %s vs %s", ja, jb)
+	}
+}
+
+func TestMarshalNonBasicTypes(t *testing.T) {
+	input := struct {
+		Zeta   string          `json:"zeta"`
+		Amount decimal.Decimal `json:"amount"`
+		At     time.Time       `json:"at"`
+	}{"z", decimal.RequireFromString("10.50"), time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+	got, err := Marshal(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"amount":"10.5","at":"2024-01-02T03:04:05Z","zeta":"z"}`; string(got) != want {
+		t.Errorf("Marshal = %s, want %s", got, want)
+	}
+}
+
+func TestMarshalRejectsNaN(t *testing.T) {
+	for _, v := range []float64{math.NaN(), math.Inf(1)} {
+		if _, err := Marshal(v); err == nil {
+			t.Errorf("Marshal(%v) must fail", v)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package finance
+
+import (
+	"errors"
+	"math"
+)
+
+var (
+	// ErrNoSignChange indica que los flujos no cambian de signo y la TIR no existe
+	ErrNoSignChange = errors.New("cash flows have no sign change, IRR is undefined")
+	// ErrIRRNotBracketed indica que no se encontró un intervalo con cambio de signo del VAN
+	ErrIRRNotBracketed = errors.New("could not bracket IRR within the search range")
+	// ErrTotalLoss indica un ROI de -100% o menor, que no admite anualización
+	ErrTotalLoss = errors.New("ROI is -100% or lower, annualized ROI is undefined")
+)
+
+const (
+	// IRRMinRate es el límite inferior de búsqueda (una tasa de -100% no es válida)
+	IRRMinRate = -0.99
+	// IRRMaxRate es el límite superior al expandir el intervalo de búsqueda
+	IRRMaxRate = 1e6
+
+	// maxIterations limita la bisección para no iterar indefinidamente
+	maxIterations = 1000
+	// targetTolerance es la precisión buscada sobre la tasa
+	targetTolerance = 1e-10
+)
+
+// NetFlows resta a cada ingreso el costo del mismo periodo; los costos que
+// faltan cuentan como cero y los sobrantes se ignoran
+func NetFlows(income, costs []float64) []float64 {
+	net := make([]float64, len(income))
+	for i := range income {
+		cost := 0.0
+		if i < len(costs) {
+			cost = costs[i]
+		}
+		net[i] = income[i] - cost
+	}
+	return net
+}
+
+// NPV descuenta los flujos netos a la tasa dada y resta la inversión inicial.
+// El flujo i (desde 0) corresponde al periodo i+1.
+func NPV(rate, initial float64, flows []float64) float64 {
+	return NPVOffset(rate, initial, flows, 0)
+}
+
+// NPVOffset descuenta el flujo del periodo i (desde 1) con exponente
+// i - offset. Usa math.Pow para que exponentes no enteros (p. ej.
+// convenciones de medio periodo) se descuenten correctamente en lugar de
+// truncarse.
+func NPVOffset(rate, initial float64, flows []float64, offset float64) float64 {
+	npv := -initial
+	for _, flow := range DiscountFlows(rate, flows, offset) {
+		npv += flow
+	}
+	return npv
+}
+
+// DiscountFlows retorna el valor presente de cada flujo con la misma
+// convención que NPVOffset
+func DiscountFlows(rate float64, flows []float64, offset float64) []float64 {
+	discounted := make([]float64, len(flows))
+	for i, flow := range flows {
+		discounted[i] = flow / math.Pow(1+rate, float64(i+1)-offset)
+	}
+	return discounted
+}
+
+// EAA convierte el VAN en la anualidad equivalente de n periodos:
+// VAN · r / (1 - (1+r)^-n). Con tasa cero el factor de anualidad es n.
+// Retorna un error cuando no hay periodos.
+func EAA(npv, rate float64, periods int) (float64, error) {
+	if periods == 0 {
+		return 0, errors.New("EAA requires at least one period of cash flows")
+	}
+	if rate == 0 {
+		return npv / float64(periods), nil
+	}
+	return npv * rate / (1 - math.Pow(1+rate, -float64(periods))), nil
+}
+
+// Payback retorna el periodo (interpolado) en que los flujos acumulados
+// recuperan la inversión inicial y si efectivamente la recuperan.
+// Cuando nunca se recupera retorna (-1, false).
+func Payback(initial float64, flows []float64) (float64, bool) {
+	accumulated := -initial
+	if accumulated >= 0 {
+		// Sin inversión que recuperar: el payback es inmediato
+		return 0, true
+	}
+
+	for i, flow := range flows {
+		previous := accumulated
+		accumulated += flow
+		if accumulated >= 0 {
+			// Solo se interpola cuando hay un cruce real; un flujo nulo no
+			// puede producir el cruce, pero se protege la división igualmente
+			if flow == 0 {
+				return float64(i + 1), true
+			}
+			return float64(i) + (-previous / flow), true
+		}
+	}
+	return -1, false
+}
+
+// ROI retorna la ganancia neta (suma de flujos menos inversión) sobre la
+// magnitud de la inversión, para que un subsidio inicial (inversión
+// negativa) no invierta el signo
+func ROI(initial float64, flows []float64) float64 {
+	total := 0.0
+	for _, flow := range flows {
+		total += flow
+	}
+	return (total - initial) / math.Abs(initial)
+}
+
+// AnnualizedROI convierte el ROI total de periods periodos en una tasa anual
+// compuesta: (1+roi)^(1/años) - 1, con años = periods / periodsPerYear.
+// Retorna ErrTotalLoss cuando roi <= -1, donde la raíz no está definida.
+func AnnualizedROI(roi float64, periods, periodsPerYear int) (float64, error) {
+	if periods <= 0 || periodsPerYear <= 0 {
+		return 0, errors.New("annualized ROI requires at least one period of cash flows")
+	}
+	if roi <= -1 {
+		return 0, ErrTotalLoss
+	}
+	years := float64(periods) / float64(periodsPerYear)
+	return math.Pow(1+roi, 1/years) - 1, nil
+}
+
+// PeriodRate convierte una tasa nominal anual capitalizable compoundingPerYear
+// veces al año en la tasa efectiva de un periodo de 1/periodsPerYear años:
+// (1 + tasa/m)^(m/p) - 1. Con m = p es simplemente tasa/m.
+func PeriodRate(nominalAnnual float64, compoundingPerYear, periodsPerYear int) (float64, error) {
+	if compoundingPerYear <= 0 || periodsPerYear <= 0 {
+		return 0, errors.New("compounding and period frequencies must be positive")
+	}
+	base := 1 + nominalAnnual/float64(compoundingPerYear)
+	if base <= 0 {
+		return 0, errors.New("nominal rate must be greater than -100% per compounding period")
+	}
+	return math.Pow(base, float64(compoundingPerYear)/float64(periodsPerYear)) - 1, nil
+}
+
+// DeflateFlows expresa flujos nominales en moneda del periodo 0: el flujo del
+// periodo t (desde 1) se divide por (1+inflación)^(t/periodsPerYear).
+// Retorna error si la inflación anual no es mayor que -100%.
+func DeflateFlows(flows []float64, annualInflation float64, periodsPerYear int) ([]float64, error) {
+	return DeflateFlowsOffset(flows, annualInflation, periodsPerYear, 0)
+}
+
+// DeflateFlowsOffset es DeflateFlows con el flujo del periodo t ubicado en
+// t - offset, igual que en NPVOffset
+func DeflateFlowsOffset(flows []float64, annualInflation float64, periodsPerYear int, offset float64) ([]float64, error) {
+	if periodsPerYear <= 0 {
+		return nil, errors.New("periods per year must be positive")
+	}
+	if annualInflation <= -1 {
+		return nil, errors.New("annual inflation must be greater than -100%")
+	}
+	deflated := make([]float64, len(flows))
+	for i, flow := range flows {
+		deflated[i] = flow / math.Pow(1+annualInflation, (float64(i+1)-offset)/float64(periodsPerYear))
+	}
+	return deflated, nil
+}
+
+// RealRate convierte una tasa nominal por periodo en la tasa real con la
+// relación de Fisher: (1+nominal)/(1+inflación del periodo) - 1, donde la
+// inflación del periodo es (1+inflación anual)^(1/periodsPerYear) - 1.
+func RealRate(nominal, annualInflation float64, periodsPerYear int) (float64, error) {
+	if periodsPerYear <= 0 {
+		return 0, errors.New("periods per year must be positive")
+	}
+	if annualInflation <= -1 {
+		return 0, errors.New("annual inflation must be greater than -100%")
+	}
+	return (1+nominal)/math.Pow(1+annualInflation, 1/float64(periodsPerYear)) - 1, nil
+}
+
+// ProfitabilityIndex retorna 1 + VAN / |inversión|. Con inversión positiva
+// equivale a valor presente de los flujos futuros / inversión; el valor
+// absoluto hace que el índice supere 1 exactamente cuando el VAN es positivo.
+func ProfitabilityIndex(npv, initial float64) float64 {
+	return 1 + npv/math.Abs(initial)
+}
+
+// IRR obtiene la tasa interna de retorno por bisección sobre el VAN.
+// Retorna la tasa, la tolerancia alcanzada (semiancho del intervalo final)
+// y un error cuando los flujos no cambian de signo o no convergen.
+func IRR(initial float64, flows []float64) (float64, float64, error) {
+	return IRROffset(initial, flows, 0)
+}
+
+// IRROffset es IRR sobre el VAN de NPVOffset, de modo que la tasa anula el
+// VAN calculado con la misma convención de descuento
+func IRROffset(initial float64, flows []float64, offset float64) (float64, float64, error) {
+	if !HasSignChange(-initial, flows) {
+		return 0, 0, ErrNoSignChange
+	}
+
+	npv := func(rate float64) float64 { return NPVOffset(rate, initial, flows, offset) }
+	low, high := IRRMinRate, 1.0
+	npvLow, npvHigh := npv(low), npv(high)
+
+	// Expandir el intervalo hasta encontrar un cambio de signo en el VAN
+	for npvLow*npvHigh > 0 {
+		if high >= IRRMaxRate {
+			return 0, 0, ErrIRRNotBracketed
+		}
+		high *= 2
+		npvHigh = npv(high)
+	}
+
+	rate, tolerance := Bisect(npv, low, high, npvLow)
+	return rate, tolerance, nil
+}
+
+// Bisect busca la raíz de f dentro de [low, high], intervalo en el que f
+// debe cambiar de signo (fLow = f(low)). Retorna la raíz y el semiancho del
+// intervalo final.
+func Bisect(f func(float64) float64, low, high, fLow float64) (float64, float64) {
+	mid := (low + high) / 2
+	for i := 0; i < maxIterations; i++ {
+		mid = (low + high) / 2
+		fMid := f(mid)
+
+		if fMid == 0 || (high-low)/2 < targetTolerance {
+			break
+		}
+
+		if fLow*fMid < 0 {
+			high = mid
+		} else {
+			low, fLow = mid, fMid
+		}
+	}
+	return mid, (high - low) / 2
+}
+
+// MIRR obtiene la TIR modificada: los flujos negativos se traen a valor
+// presente a la tasa de financiamiento y los positivos se llevan a valor futuro
+// a la tasa de reinversión; la MIRR es la tasa que iguala ambos en n periodos.
+func MIRR(initial float64, flows []float64, financeRate, reinvestRate float64) (float64, error) {
+	n := len(flows)
+	if n == 0 {
+		return 0, errors.New("MIRR requires at least one period of cash flows")
+	}
+
+	// La inversión inicial es el flujo del periodo 0
+	series := append([]float64{-initial}, flows...)
+
+	presentNegatives := 0.0
+	futurePositives := 0.0
+	for t, flow := range series {
+		if flow < 0 {
+			presentNegatives += -flow / math.Pow(1+financeRate, float64(t))
+		} else if flow > 0 {
+			futurePositives += flow * math.Pow(1+reinvestRate, float64(n-t))
+		}
+	}
+
+	if presentNegatives == 0 {
+		return 0, errors.New("MIRR requires at least one negative cash flow")
+	}
+	if futurePositives == 0 {
+		return 0, errors.New("MIRR requires at least one positive cash flow")
+	}
+
+	return math.Pow(futurePositives/presentNegatives, 1/float64(n)) - 1, nil
+}
+
+// HasSignChange indica si la serie (flujo inicial + flujos) cambia de signo
+func HasSignChange(initial float64, flows []float64) bool {
+	positive, negative := initial > 0, initial < 0
+	for _, flow := range flows {
+		if flow > 0 {
+			positive = true
+		} else if flow < 0 {
+			negative = true
+		}
+	}
+	return positive && negative
+}
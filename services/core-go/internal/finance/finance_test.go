@@ -0,0 +1,365 @@
+package finance
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// Los valores de referencia de NPV, IRR y MIRR son los ejemplos de la
+// documentación de las funciones VNA, TIR y TIRM de Excel.
+
+func TestNPV(t *testing.T) {
+	tests := []struct {
+		name    string
+		rate    float64
+		initial float64
+		flows   []float64
+		want    float64
+	}{
+		// -1000 + 500/1.1 + 500/1.1^2 + 500/1.1^3
+		{"anualidad", 0.1, 1000, []float64{500, 500, 500}, 243.42599549211},
+		{"excel", 0.08, 40000, []float64{8000, 9200, 10000, 12000, 14500}, 1922.06155493},
+		{"excel con pérdida final", 0.08, 40000, []float64{8000, 9200, 10000, 12000, 14500, -9000}, -3749.46508702},
+		{"tasa cero", 0, 1000, []float64{300, 300, 300}, -100},
+		{"sin flujos", 0.1, 1000, nil, -1000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NPV(tt.rate, tt.initial, tt.flows); math.Abs(got-tt.want) > 1e-8 {
+				t.Errorf("NPV = %.11f, want %.11f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNPVMatchesMathPow(t *testing.T) {
+	flows := []float64{500, 500, 500}
+	want := -1000.0
+	for i, f := range flows {
+		want += f / math.Pow(1.1, float64(i+1))
+	}
+	if got := NPV(0.1, 1000, flows); got != want {
+		t.Errorf("NPV = %v, want %v (math.Pow)", got, want)
+	}
+}
+
+func TestNPVOffsetMidPeriod(t *testing.T) {
+	flows := []float64{400, 400, 400}
+	end := NPV(0.1, 1000, flows)
+	mid := NPVOffset(0.1, 1000, flows, 0.5)
+
+	// Descontar medio periodo antes multiplica el valor presente por (1+r)^0.5
+	if want := (end+1000)*math.Sqrt(1.1) - 1000; math.Abs(mid-want) > 1e-9 {
+		t.Errorf("mid-period NPV = %v, want %v", mid, want)
+	}
+}
+
+func TestPowTruncationRegression(t *testing.T) {
+	// powTruncado reproduce el helper anterior, que truncaba el exponente
+	powTruncado := func(base, exp float64) float64 {
+		result := 1.0
+		for i := 0; i < int(exp); i++ {
+			result *= base
+		}
+		return result
+	}
+
+	// Para periodos enteros ambos coinciden...
+	if got, want := powTruncado(1.1, 3), math.Pow(1.1, 3); math.Abs(got-want) > 1e-12 {
+		t.Errorf("integer exponent: truncated = %v, math.Pow = %v", got, want)
+	}
+	// ...pero con medio periodo el helper anterior perdía la fracción
+	if powTruncado(1.1, 2.5) == math.Pow(1.1, 2.5) {
+		t.Error("expected the truncating helper to diverge on fractional exponents")
+	}
+}
+
+func TestDiscountFlows(t *testing.T) {
+	got := DiscountFlows(0.1, []float64{110, 121}, 0)
+	if math.Abs(got[0]-100) > 1e-9 || math.Abs(got[1]-100) > 1e-9 {
+		t.Errorf("DiscountFlows = %v, want [100 100]", got)
+	}
+}
+
+func TestNetFlows(t *testing.T) {
+	got := NetFlows([]float64{500, 500, 500}, []float64{100, 200})
+	want := []float64{400, 300, 500}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("NetFlows = %v, want %v", got, want)
+		}
+	}
+	if got := NetFlows([]float64{100}, []float64{10, 20}); len(got) != 1 || got[0] != 90 {
+		t.Errorf("extra costs: NetFlows = %v, want [90]", got)
+	}
+}
+
+func TestEAA(t *testing.T) {
+	// VAN 243.43 a 3 años al 10%: factor de anualidad 2.486852
+	eaa, err := EAA(243.42599549211, 0.1, 3)
+	if err != nil || math.Abs(eaa-97.88519637462) > 1e-9 {
+		t.Errorf("EAA = %v, %v; want 97.88519637462", eaa, err)
+	}
+
+	if eaa, err := EAA(600, 0, 3); err != nil || eaa != 200 {
+		t.Errorf("EAA(600, 0, 3) = %v, %v; want 200", eaa, err)
+	}
+	if _, err := EAA(600, 0.1, 0); err == nil {
+		t.Error("expected error without periods")
+	}
+}
+
+func TestPayback(t *testing.T) {
+	tests := []struct {
+		name     string
+		initial  float64
+		flows    []float64
+		want     float64
+		recovers bool
+	}{
+		{"flujos iguales", 1000, []float64{500, 500, 500}, 2, true},
+		{"interpolado", 1000, []float64{400, 400, 400}, 2.5, true},
+		{"flujos crecientes", 10000, []float64{2000, 3000, 4000, 5000}, 3.2, true},
+		{"sin inversion y flujo nulo", 0, []float64{0, 100}, 0, true},
+		{"subsidio inicial", -500, []float64{-100}, 0, true},
+		{"flujo nulo antes del cruce", 1000, []float64{500, 0, 500}, 3, true},
+		{"cruce exacto", 1000, []float64{1000, 0}, 1, true},
+		{"nunca recupera", 1000, []float64{100, 0, 100}, -1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, recovers := Payback(tt.initial, tt.flows)
+			if math.IsNaN(got) || math.IsInf(got, 0) {
+				t.Fatalf("payback = %v, want a finite value", got)
+			}
+			if math.Abs(got-tt.want) > 1e-12 || recovers != tt.recovers {
+				t.Errorf("payback = (%v, %v), want (%v, %v)", got, recovers, tt.want, tt.recovers)
+			}
+		})
+	}
+}
+
+func TestROI(t *testing.T) {
+	tests := []struct {
+		name    string
+		initial float64
+		flows   []float64
+		want    float64
+	}{
+		{"ganancia", 1000, []float64{500, 500, 500}, 0.5},
+		{"pérdida", 1000, []float64{200, 300}, -0.5},
+		// Un subsidio de 200 más 300 de flujos son 500 de ganancia sobre 200
+		{"subsidio inicial", -200, []float64{100, 200}, 2.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ROI(tt.initial, tt.flows); math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("ROI = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnnualizedROI(t *testing.T) {
+	// 36 flujos mensuales con ROI total de 72.8%: 1.728 = 1.2^3, es decir 20% anual
+	roi, err := AnnualizedROI(0.728, 36, 12)
+	if err != nil || math.Abs(roi-0.2) > 1e-12 {
+		t.Errorf("AnnualizedROI(0.728, 36, 12) = %v, %v; want 0.2", roi, err)
+	}
+
+	// Medio año: el ROI anual compone el semestral
+	if roi, err := AnnualizedROI(0.1, 6, 12); err != nil || math.Abs(roi-0.21) > 1e-12 {
+		t.Errorf("AnnualizedROI(0.1, 6, 12) = %v, %v; want 0.21", roi, err)
+	}
+
+	for _, total := range []float64{-1, -1.5} {
+		if _, err := AnnualizedROI(total, 24, 12); !errors.Is(err, ErrTotalLoss) {
+			t.Errorf("AnnualizedROI(%v) error = %v, want ErrTotalLoss", total, err)
+		}
+	}
+	if _, err := AnnualizedROI(0.5, 0, 12); err == nil {
+		t.Error("expected error without periods")
+	}
+}
+
+func TestDeflateFlows(t *testing.T) {
+	// 10% anual sobre flujos semestrales: el segundo semestre es un año completo
+	got, err := DeflateFlows([]float64{110, 110}, 0.1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []float64{110 / math.Sqrt(1.1), 100}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-9 {
+			t.Errorf("flow %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	if _, err := DeflateFlows([]float64{100}, -1, 12); err == nil {
+		t.Error("expected error for inflation of -100%")
+	}
+	if _, err := DeflateFlows([]float64{100}, 0.1, 0); err == nil {
+		t.Error("expected error without periods per year")
+	}
+}
+
+func TestRealRate(t *testing.T) {
+	// 10% nominal con 10% de inflación anual es 0% real
+	if got, err := RealRate(0.1, 0.1, 1); err != nil || math.Abs(got) > 1e-12 {
+		t.Errorf("RealRate(0.1, 0.1, 1) = %v, %v; want 0", got, err)
+	}
+	// Mensual: la inflación del periodo es la raíz doceava de la anual
+	want := 1.01/math.Pow(1.12, 1.0/12) - 1
+	if got, err := RealRate(0.01, 0.12, 12); err != nil || math.Abs(got-want) > 1e-12 {
+		t.Errorf("RealRate(0.01, 0.12, 12) = %v, %v; want %v", got, err, want)
+	}
+	if _, err := RealRate(0.1, -1, 12); err == nil {
+		t.Error("expected error for inflation of -100%")
+	}
+	if _, err := RealRate(0.1, 0.1, 0); err == nil {
+		t.Error("expected error without periods per year")
+	}
+}
+
+func TestPeriodRate(t *testing.T) {
+	tests := []struct {
+		name    string
+		nominal float64
+		m, p    int
+		want    float64
+	}{
+		{"mensual sobre flujos mensuales", 0.12, 12, 12, 0.01},
+		{"anual sobre flujos mensuales", 0.12, 1, 12, math.Pow(1.12, 1.0/12) - 1},
+		{"mensual sobre flujos anuales", 0.12, 12, 1, math.Pow(1.01, 12) - 1},
+		{"trimestral sobre flujos semestrales", 0.08, 4, 2, 1.02*1.02 - 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := PeriodRate(tt.nominal, tt.m, tt.p); err != nil || math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("PeriodRate(%v, %d, %d) = %v, %v; want %v", tt.nominal, tt.m, tt.p, got, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := PeriodRate(-12, 12, 12); err == nil {
+		t.Error("expected error for a rate of -100% per compounding period")
+	}
+	if _, err := PeriodRate(0.1, 0, 12); err == nil {
+		t.Error("expected error without compounding frequency")
+	}
+}
+
+func TestProfitabilityIndex(t *testing.T) {
+	if got := ProfitabilityIndex(250, 1000); got != 1.25 {
+		t.Errorf("PI = %v, want 1.25", got)
+	}
+	// Con subsidio el índice sigue superando 1 sólo si el VAN es positivo
+	if got := ProfitabilityIndex(-100, -500); got != 0.8 {
+		t.Errorf("PI = %v, want 0.8", got)
+	}
+}
+
+func TestIRR(t *testing.T) {
+	tests := []struct {
+		name    string
+		initial float64
+		flows   []float64
+		want    float64
+	}{
+		{"anualidad", 1000, []float64{500, 500, 500}, 0.2337520},
+		{"excel 4 años", 70000, []float64{12000, 15000, 18000, 21000}, -0.0212448},
+		{"excel 5 años", 70000, []float64{12000, 15000, 18000, 21000, 26000}, 0.0866309},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, tolerance, err := IRR(tt.initial, tt.flows)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(rate-tt.want) > 1e-6 {
+				t.Errorf("IRR = %v, want ≈ %v", rate, tt.want)
+			}
+			if tolerance > 1e-9 {
+				t.Errorf("tolerance = %v, want < 1e-9", tolerance)
+			}
+			// El VAN a la TIR debe ser prácticamente cero
+			if npv := NPV(rate, tt.initial, tt.flows); math.Abs(npv) > 1e-4 {
+				t.Errorf("NPV at IRR = %v, want ≈ 0", npv)
+			}
+		})
+	}
+}
+
+func TestIRROffset(t *testing.T) {
+	// A mitad de periodo 1100 se descuenta con (1+r)^0.5: 1100/1.1 = 1000
+	rate, _, err := IRROffset(1000, []float64{1100}, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(rate-0.21) > 1e-9 {
+		t.Errorf("IRR with offset 0.5 = %v, want 0.21", rate)
+	}
+	if npv := NPVOffset(rate, 1000, []float64{1100}, 0.5); math.Abs(npv) > 1e-6 {
+		t.Errorf("NPVOffset at IRR = %v, want ≈ 0", npv)
+	}
+}
+
+func TestIRRWithoutSignChange(t *testing.T) {
+	if _, _, err := IRR(1000, []float64{-100, -100}); !errors.Is(err, ErrNoSignChange) {
+		t.Errorf("err = %v, want ErrNoSignChange", err)
+	}
+	if _, _, err := IRR(0, []float64{100}); !errors.Is(err, ErrNoSignChange) {
+		t.Errorf("zero investment: err = %v, want ErrNoSignChange", err)
+	}
+}
+
+func TestBisect(t *testing.T) {
+	f := func(x float64) float64 { return x*x - 2 }
+	root, tolerance := Bisect(f, 0, 2, f(0))
+	if math.Abs(root-math.Sqrt2) > 1e-9 || tolerance > 1e-10 {
+		t.Errorf("Bisect = %v ± %v, want √2", root, tolerance)
+	}
+}
+
+func TestMIRR(t *testing.T) {
+	// TIRM de Excel: financiamiento 10%, reinversión 12%
+	got, err := MIRR(120000, []float64{39000, 30000, 21000, 37000, 46000}, 0.1, 0.12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(got-0.1260941) > 1e-6 {
+		t.Errorf("MIRR = %v, want ≈ 0.1260941", got)
+	}
+
+	// Con ambas tasas iguales y un único flujo final, MIRR = TIR
+	got, err = MIRR(1000, []float64{0, 0, 1331}, 0.1, 0.1)
+	if err != nil || math.Abs(got-0.1) > 1e-12 {
+		t.Errorf("MIRR = %v, %v; want 0.1", got, err)
+	}
+}
+
+func TestMIRRSameSignFlows(t *testing.T) {
+	if _, err := MIRR(-1000, []float64{100, 100}, 0.1, 0.1); err == nil {
+		t.Error("expected error for all-positive flows")
+	}
+	if _, err := MIRR(1000, []float64{-100, -100}, 0.1, 0.1); err == nil {
+		t.Error("expected error for all-negative flows")
+	}
+	if _, err := MIRR(1000, nil, 0.1, 0.1); err == nil {
+		t.Error("expected error without periods")
+	}
+}
+
+func TestHasSignChange(t *testing.T) {
+	if !HasSignChange(-1000, []float64{500}) {
+		t.Error("expected sign change")
+	}
+	if HasSignChange(-1000, []float64{-500, 0}) {
+		t.Error("unexpected sign change")
+	}
+	if HasSignChange(0, []float64{0, 0}) {
+		t.Error("zero series has no sign change")
+	}
+}
@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/fincore/core-go/internal/apierror"
+	"github.com/fincore/core-go/internal/logging"
+	"github.com/fincore/core-go/internal/metrics"
+	"github.com/fincore/core-go/internal/security"
+	"github.com/fincore/core-go/internal/tracing"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// TransactionStatusPendingApproval es el estado de una transacción que
+	// superó el umbral de aprobación y espera a sus aprobadores
+	TransactionStatusPendingApproval = "pending_approval"
+	// DefaultRequiredApprovals es cuántos aprobadores distintos se exigen por defecto
+	DefaultRequiredApprovals = 2
+)
+
+// ErrDuplicateApproval indica que el aprobador ya aprobó la transacción
+var ErrDuplicateApproval = errors.New("approver already approved this transaction")
+
+// approvalThreshold es el monto a partir del cual (excluido) una transacción
+// requiere aprobación; cero desactiva el control
+var approvalThreshold decimal.Decimal
+
+// requiredApprovals es cuántos aprobadores distintos completan una transacción
+var requiredApprovals = DefaultRequiredApprovals
+
+// SetApprovalPolicy configura el umbral de aprobación y los aprobadores
+// exigidos; un umbral cero lo desactiva y approvals no positivo restaura
+// DefaultRequiredApprovals
+func SetApprovalPolicy(threshold decimal.Decimal, approvals int) {
+	if approvals <= 0 {
+		approvals = DefaultRequiredApprovals
+	}
+	approvalThreshold = threshold
+	requiredApprovals = approvals
+}
+
+// requiresApproval indica si el monto supera el umbral de aprobación
+func requiresApproval(amount decimal.Decimal) bool {
+	return approvalThreshold.IsPositive() && amount.GreaterThan(approvalThreshold)
+}
+
+// ApprovalState son las aprobaciones registradas de una transacción pendiente
+type ApprovalState struct {
+	// Approvers son los servicios que aprobaron, en orden de aprobación
+	Approvers []string
+	// CreateLedgerEntry es el create_ledger_entry del request original, que
+	// se aplica al completar la transacción
+	CreateLedgerEntry bool
+}
+
+// ApprovalStore registra quién aprobó cada transacción pendiente. Approve
+// debe ser atómico para que dos aprobaciones concurrentes no cuenten como
+// una ni el mismo aprobador cuente dos veces.
+type ApprovalStore interface {
+	// Open registra una transacción pendiente de aprobación
+	Open(txID string, createLedgerEntry bool)
+	// Approve agrega el aprobador y retorna el estado resultante; si ya
+	// había aprobado retorna ErrDuplicateApproval
+	Approve(txID, approver string) (ApprovalState, error)
+	// Retract quita una aprobación cuando no se pudo completar la transacción
+	Retract(txID, approver string)
+	// Close olvida una transacción que ya no está pendiente
+	Close(txID string)
+}
+
+// MemoryApprovalStore implementa ApprovalStore en memoria. Una transacción
+// pendiente sin registro (por ejemplo tras un reinicio) empieza sin
+// aprobaciones y sin entrada del ledger.
+type MemoryApprovalStore struct {
+	mu      sync.Mutex
+	pending map[string]*ApprovalState
+}
+
+// NewMemoryApprovalStore crea un store de aprobaciones vacío
+func NewMemoryApprovalStore() *MemoryApprovalStore {
+	return &MemoryApprovalStore{pending: make(map[string]*ApprovalState)}
+}
+
+// Open crea el registro de la transacción
+func (s *MemoryApprovalStore) Open(txID string, createLedgerEntry bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending[txID] = &ApprovalState{CreateLedgerEntry: createLedgerEntry}
+}
+
+// Approve agrega el aprobador si todavía no aprobó
+func (s *MemoryApprovalStore) Approve(txID, approver string) (ApprovalState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.pending[txID]
+	if !ok {
+		state = &ApprovalState{}
+		s.pending[txID] = state
+	}
+	for _, existing := range state.Approvers {
+		if existing == approver {
+			return ApprovalState{}, ErrDuplicateApproval
+		}
+	}
+	state.Approvers = append(state.Approvers, approver)
+	return ApprovalState{
+		Approvers:         append([]string(nil), state.Approvers...),
+		CreateLedgerEntry: state.CreateLedgerEntry,
+	}, nil
+}
+
+// Retract quita al aprobador del registro
+func (s *MemoryApprovalStore) Retract(txID, approver string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.pending[txID]
+	if !ok {
+		return
+	}
+	for i, existing := range state.Approvers {
+		if existing == approver {
+			state.Approvers = append(state.Approvers[:i], state.Approvers[i+1:]...)
+			return
+		}
+	}
+}
+
+// Close elimina el registro de la transacción
+func (s *MemoryApprovalStore) Close(txID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pending, txID)
+}
+
+// approvalStore es el store usado por ProcessTransaction y ApproveTransaction
+var approvalStore ApprovalStore = NewMemoryApprovalStore()
+
+// SetApprovalStore reemplaza el store de aprobaciones
+func SetApprovalStore(store ApprovalStore) {
+	approvalStore = store
+}
+
+// respondPendingApproval responde 202 con una transacción que espera aprobación
+func respondPendingApproval(c *gin.Context, transaction Transaction, approvers []string) {
+	if approvers == nil {
+		approvers = []string{}
+	}
+	sort.Strings(approvers)
+	c.JSON(http.StatusAccepted, gin.H{
+		"success":            true,
+		"transaction":        transaction,
+		"approved_by":        approvers,
+		"required_approvals": requiredApprovals,
+		"message":            "Transaction pending approval",
+	})
+}
+
+// ApproveTransaction registra la aprobación del servicio que presenta el
+// token y, al reunir requiredApprovals aprobadores distintos, completa la
+// transacción: cambia su estado, crea la entrada del ledger si el request
+// original la pidió y notifica a los webhooks. Debe encadenarse después del
+// middleware Zero Trust, que deja los claims del token en service_claims.
+func ApproveTransaction(c *gin.Context) {
+	transactionID := c.Param("id")
+	if _, err := uuid.Parse(transactionID); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidTransactionID, "Invalid transaction ID")
+		return
+	}
+
+	value, _ := c.Get("service_claims")
+	claims, ok := value.(*security.ServiceTokenClaims)
+	if !ok || claims.Source == "" {
+		apierror.Respond(c, http.StatusUnauthorized, apierror.TokenRequired, "Service token required")
+		return
+	}
+	approver := claims.Source
+
+	ctx := c.Request.Context()
+	getCtx, getSpan := tracing.Start(ctx, "transaction_store.get")
+	transaction, err := transactionStore.Get(getCtx, transactionID)
+	tracing.EndSpan(getSpan, err)
+	if errors.Is(err, ErrTransactionNotFound) {
+		apierror.Respond(c, http.StatusNotFound, apierror.TransactionNotFound, "Transaction not found")
+		return
+	}
+	if err != nil {
+		if respondClientClosed(c, err) {
+			return
+		}
+		logging.FromContext(c).Error("failed to load transaction", "transaction_id", transactionID, "error", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.Internal, "Failed to load transaction")
+		return
+	}
+	if transaction.Status != TransactionStatusPendingApproval {
+		respondNotPending(c, transaction)
+		return
+	}
+
+	state, err := approvalStore.Approve(transaction.ID, approver)
+	if errors.Is(err, ErrDuplicateApproval) {
+		apierror.RespondWith(c, http.StatusConflict, apierror.DuplicateApproval, "Approver already approved this transaction", gin.H{
+			"approver": approver,
+		})
+		return
+	}
+	if err != nil {
+		logging.FromContext(c).Error("failed to record approval", "transaction_id", transaction.ID, "error", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.Internal, "Failed to record approval")
+		return
+	}
+	logging.FromContext(c).Info("transaction approval recorded",
+		"transaction_id", transaction.ID, "approver", approver, "approvals", len(state.Approvers))
+
+	switch {
+	case len(state.Approvers) < requiredApprovals:
+		respondPendingApproval(c, transaction, state.Approvers)
+		return
+	case len(state.Approvers) > requiredApprovals:
+		// Otro aprobador completó la transacción entretanto
+		approvalStore.Retract(transaction.ID, approver)
+		respondNotPending(c, transaction)
+		return
+	}
+
+	// Esta aprobación completa el quórum
+	updateCtx, updateSpan := tracing.Start(ctx, "transaction_store.update_status")
+	version, err := transactionStore.UpdateStatus(updateCtx, transaction.ID, transaction.Version, "completed")
+	tracing.EndSpan(updateSpan, err)
+	if err != nil {
+		approvalStore.Retract(transaction.ID, approver)
+		if errors.Is(err, ErrTransactionVersionConflict) {
+			respondVersionConflict(c, gin.H{"expected_version": transaction.Version})
+			return
+		}
+		if respondClientClosed(c, err) {
+			return
+		}
+		logging.FromContext(c).Error("failed to complete approved transaction", "transaction_id", transaction.ID, "error", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.Internal, "Failed to complete transaction")
+		return
+	}
+	transaction.Status = "completed"
+	transaction.Version = version
+
+	response := gin.H{
+		"success":     true,
+		"transaction": transaction,
+		"approved_by": state.Approvers,
+		"message":     "Transaction approved and processed",
+	}
+
+	// Si el ledger falla la transacción vuelve a quedar pendiente y el último
+	// aprobador puede reintentar
+	if state.CreateLedgerEntry {
+		ledgerCtx, ledgerSpan := tracing.Start(ctx, "ledger.append")
+		entry, created, err := ledgerChain.AppendOnce(ledgerCtx, ledgerEntryForTransaction(transaction))
+		tracing.EndSpan(ledgerSpan, err)
+		if err != nil {
+			restoreCtx, restoreSpan := tracing.Start(context.WithoutCancel(ctx), "transaction_store.update_status")
+			_, restoreErr := transactionStore.UpdateStatus(restoreCtx, transaction.ID, transaction.Version, TransactionStatusPendingApproval)
+			tracing.EndSpan(restoreSpan, restoreErr)
+			if restoreErr != nil {
+				logging.FromContext(c).Error("failed to restore pending approval", "transaction_id", transaction.ID, "error", restoreErr)
+			}
+			approvalStore.Retract(transaction.ID, approver)
+			if respondLedgerUnavailable(c, err) {
+				return
+			}
+			if respondClientClosed(c, err) {
+				return
+			}
+			logging.FromContext(c).Error("failed to create ledger entry for transaction", "transaction_id", transaction.ID, "error", err)
+			apierror.Respond(c, http.StatusInternalServerError, apierror.Internal, "Failed to create ledger entry")
+			return
+		}
+		if created {
+			metrics.LedgerEntriesCreated.Inc()
+		}
+		response["ledger_sequence"] = entry.SequenceNumber
+	}
+	approvalStore.Close(transaction.ID)
+
+	if webhookDispatcher != nil {
+		webhookDispatcher.Enqueue(WebhookEventTransactionCompleted, transaction)
+	}
+	metrics.TransactionsProcessed.WithLabelValues(transaction.Status).Inc()
+
+	attachReceipt(c, response, transaction)
+	c.JSON(http.StatusOK, response)
+}
+
+// respondNotPending responde 409 cuando la transacción ya no espera aprobación
+func respondNotPending(c *gin.Context, transaction Transaction) {
+	apierror.RespondWith(c, http.StatusConflict, apierror.TransactionNotPending, "Transaction is not pending approval", gin.H{
+		"status": transaction.Status,
+	})
+}
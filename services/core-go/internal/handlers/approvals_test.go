@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fincore/core-go/internal/apierror"
+	"github.com/fincore/core-go/internal/security"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+func useApprovalPolicy(t *testing.T, threshold string, approvals int) {
+	t.Helper()
+	SetApprovalPolicy(decimal.RequireFromString(threshold), approvals)
+	SetApprovalStore(NewMemoryApprovalStore())
+	t.Cleanup(func() { SetApprovalPolicy(decimal.Zero, DefaultRequiredApprovals) })
+}
+
+// approve llama a ApproveTransaction con los claims que dejaría el middleware
+// Zero Trust para un token de source
+func approve(t *testing.T, id, source string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	t.Helper()
+	router := gin.New()
+	router.POST("/approve/:id", func(c *gin.Context) {
+		c.Set("service_claims", &security.ServiceTokenClaims{Source: source, Permissions: []string{"approve:transactions"}})
+	}, ApproveTransaction)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/approve/"+id, nil))
+	var resp map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response %q: %v", w.Body.String(), err)
+	}
+	return w, resp
+}
+
+func submitLargeTransaction(t *testing.T) string {
+	t.Helper()
+	w, resp := doJSON(t, ProcessTransaction, map[string]interface{}{
+		"type": "withdrawal", "user_id": "u1", "amount": "50000", "create_ledger_entry": true,
+	})
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202 (%v)", w.Code, resp)
+	}
+	tx := resp["transaction"].(map[string]interface{})
+	if tx["status"] != TransactionStatusPendingApproval || resp["required_approvals"] != float64(2) {
+		t.Fatalf("response = %v, want a transaction pending 2 approvals", resp)
+	}
+	if _, ok := resp["ledger_sequence"]; ok {
+		t.Error("pending transaction must not create a ledger entry yet")
+	}
+	return tx["id"].(string)
+}
+
+func TestProcessTransactionApprovalThreshold(t *testing.T) {
+	useApprovalPolicy(t, "10000", 2)
+	useMockTransactionStore(t)
+	ledgerStore := useMockLedger(t)
+
+	submitLargeTransaction(t)
+	if entries, _ := ledgerStore.List(context.Background()); len(entries) != 0 {
+		t.Errorf("ledger entries = %d, want 0 while pending", len(entries))
+	}
+
+	// Igual al umbral no requiere aprobación
+	w, resp := doJSON(t, ProcessTransaction, map[string]interface{}{"type": "withdrawal", "user_id": "u1", "amount": "10000"})
+	if w.Code != http.StatusOK || resp["transaction"].(map[string]interface{})["status"] != "completed" {
+		t.Errorf("status = %d, response = %v, want a completed transaction", w.Code, resp)
+	}
+
+	validation, err := ValidateTransferRequest(context.Background(), TransferRequest{FromAccount: "a", ToAccount: "b", Amount: decimal.NewFromInt(20000)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !validation.IsValid || !validation.RequiresApproval || validation.RequiredApprovals != 2 {
+		t.Errorf("validation = %+v, want valid and requiring 2 approvals", validation)
+	}
+}
+
+func TestApproveTransactionRequiresQuorum(t *testing.T) {
+	useApprovalPolicy(t, "10000", 2)
+	store := useMockTransactionStore(t)
+	ledgerStore := useMockLedger(t)
+	id := submitLargeTransaction(t)
+
+	w, resp := approve(t, id, "treasury")
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("first approval: status = %d, want 202 (%v)", w.Code, resp)
+	}
+	if approvers := resp["approved_by"].([]interface{}); len(approvers) != 1 || approvers[0] != "treasury" {
+		t.Errorf("approved_by = %v, want [treasury]", approvers)
+	}
+	if tx, _ := store.Get(context.Background(), id); tx.Status != TransactionStatusPendingApproval {
+		t.Errorf("status after one approval = %s, want pending_approval", tx.Status)
+	}
+
+	w, resp = approve(t, id, "compliance")
+	if w.Code != http.StatusOK {
+		t.Fatalf("second approval: status = %d, want 200 (%v)", w.Code, resp)
+	}
+	if resp["transaction"].(map[string]interface{})["status"] != "completed" || resp["ledger_sequence"] == nil || resp["receipt"] == nil {
+		t.Errorf("response = %v, want a completed transaction with ledger entry and receipt", resp)
+	}
+	if tx, _ := store.Get(context.Background(), id); tx.Status != "completed" || !verifyTransactionIntegrity(tx) {
+		t.Errorf("stored transaction = %+v, want completed and intact", tx)
+	}
+	if entries, _ := ledgerStore.List(context.Background()); len(entries) != 1 || entries[0].ClientRef != transactionLedgerRef(id) {
+		t.Errorf("ledger entries = %+v, want the transaction entry", entries)
+	}
+
+	// Ya completada, otra aprobación no tiene efecto
+	w, resp = approve(t, id, "risk")
+	if w.Code != http.StatusConflict || resp["code"] != apierror.TransactionNotPending {
+		t.Errorf("late approval: status/code = %d/%v, want 409/%s", w.Code, resp["code"], apierror.TransactionNotPending)
+	}
+}
+
+func TestApproveTransactionRejectsDuplicateApprover(t *testing.T) {
+	useApprovalPolicy(t, "10000", 2)
+	store := useMockTransactionStore(t)
+	useMockLedger(t)
+	id := submitLargeTransaction(t)
+
+	if w, _ := approve(t, id, "treasury"); w.Code != http.StatusAccepted {
+		t.Fatalf("first approval: status = %d, want 202", w.Code)
+	}
+	w, resp := approve(t, id, "treasury")
+	if w.Code != http.StatusConflict || resp["code"] != apierror.DuplicateApproval {
+		t.Errorf("duplicate approval: status/code = %d/%v, want 409/%s", w.Code, resp["code"], apierror.DuplicateApproval)
+	}
+	if tx, _ := store.Get(context.Background(), id); tx.Status != TransactionStatusPendingApproval {
+		t.Errorf("status = %s, want pending_approval: a repeated approver must not count twice", tx.Status)
+	}
+}
+
+func TestApproveTransactionErrors(t *testing.T) {
+	useApprovalPolicy(t, "10000", 2)
+	useMockTransactionStore(t)
+	useMockLedger(t)
+
+	w, resp := doJSON(t, ProcessTransaction, map[string]interface{}{"type": "deposit", "user_id": "u1", "amount": "10"})
+	completedID := resp["transaction"].(map[string]interface{})["id"].(string)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+
+	tests := []struct {
+		name   string
+		id     string
+		status int
+		code   string
+	}{
+		{"id inválido", "not-a-uuid", http.StatusBadRequest, apierror.InvalidTransactionID},
+		{"inexistente", "00000000-0000-4000-8000-000000000000", http.StatusNotFound, apierror.TransactionNotFound},
+		{"no pendiente", completedID, http.StatusConflict, apierror.TransactionNotPending},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, resp := approve(t, tt.id, "treasury")
+			if w.Code != tt.status || resp["code"] != tt.code {
+				t.Errorf("status/code = %d/%v, want %d/%s", w.Code, resp["code"], tt.status, tt.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/shopspring/decimal"
+)
+
+// BalanceProvider consulta el saldo disponible de una cuenta en una moneda
+type BalanceProvider interface {
+	Balance(ctx context.Context, account, currency string) (decimal.Decimal, error)
+}
+
+// balanceProvider es opcional: sin proveedor ValidateTransfer sólo hace las
+// validaciones que no requieren consultar saldos
+var balanceProvider BalanceProvider
+
+// SetBalanceProvider configura el proveedor de saldos; nil lo desactiva
+func SetBalanceProvider(provider BalanceProvider) {
+	balanceProvider = provider
+}
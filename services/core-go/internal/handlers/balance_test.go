@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// mockBalanceProvider retorna saldos fijos por "cuenta/moneda"
+type mockBalanceProvider map[string]string
+
+func (m mockBalanceProvider) Balance(_ context.Context, account, currency string) (decimal.Decimal, error) {
+	balance, ok := m[account+"/"+currency]
+	if !ok {
+		return decimal.Zero, errors.New("account not found")
+	}
+	return decimal.RequireFromString(balance), nil
+}
+
+func useBalances(t *testing.T, balances mockBalanceProvider) {
+	t.Helper()
+	SetBalanceProvider(balances)
+	t.Cleanup(func() { SetBalanceProvider(nil) })
+}
+
+func TestValidateTransferBalance(t *testing.T) {
+	useBalances(t, mockBalanceProvider{"acc-1/MXN": "500.00"})
+
+	tests := []struct {
+		name       string
+		from       string
+		amount     string
+		wantValid  bool
+		validation string
+	}{
+		{"saldo suficiente", "acc-1", "100", true, ""},
+		{"saldo exacto", "acc-1", "500.00", true, ""},
+		{"saldo insuficiente", "acc-1", "500.01", false, "Insufficient funds"},
+		{"cuenta desconocida", "acc-9", "1", false, "Source account balance unavailable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, resp := doJSON(t, ValidateTransfer, map[string]interface{}{
+				"from_account": tt.from,
+				"to_account":   "acc-2",
+				"amount":       tt.amount,
+			})
+			if resp["is_valid"] != tt.wantValid {
+				t.Fatalf("is_valid = %v, want %v (%v)", resp["is_valid"], tt.wantValid, resp["validations"])
+			}
+			validations := resp["validations"].([]interface{})
+			if tt.validation != "" && (len(validations) != 1 || validations[0] != tt.validation) {
+				t.Errorf("validations = %v, want [%s]", validations, tt.validation)
+			}
+		})
+	}
+}
+
+func TestValidateTransferWithoutBalanceProvider(t *testing.T) {
+	_, resp := doJSON(t, ValidateTransfer, map[string]interface{}{
+		"from_account": "acc-1",
+		"to_account":   "acc-2",
+		"amount":       "1000000",
+	})
+	if resp["is_valid"] != true {
+		t.Errorf("is_valid = %v, want true without a balance provider", resp["is_valid"])
+	}
+}
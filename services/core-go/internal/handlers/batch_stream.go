@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/fincore/core-go/internal/apierror"
+	"github.com/fincore/core-go/internal/logging"
+	"github.com/fincore/core-go/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// maxStreamLineBytes es el tamaño máximo de cada línea del lote NDJSON
+const maxStreamLineBytes = 64 << 10
+
+// DefaultStreamLineTimeout es la espera máxima por defecto para leer o
+// escribir cada línea de un lote NDJSON
+const DefaultStreamLineTimeout = 10 * time.Second
+
+// streamLineTimeout reemplaza por línea los ReadTimeout y WriteTimeout del
+// servidor, que acotan el request completo y cortarían un lote largo
+var streamLineTimeout = DefaultStreamLineTimeout
+
+// SetStreamLineTimeout configura la espera máxima por línea del lote NDJSON;
+// valores no positivos restauran el valor por defecto
+func SetStreamLineTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultStreamLineTimeout
+	}
+	streamLineTimeout = timeout
+}
+
+// BatchStreamResult es el resultado de una línea de un lote NDJSON. Line es
+// el número de línea (desde 1) en el body; los resultados se emiten en el
+// orden en que terminan, no en el de entrada.
+type BatchStreamResult struct {
+	Line        int          `json:"line"`
+	Transaction *Transaction `json:"transaction,omitempty"`
+	Error       string       `json:"error,omitempty"`
+}
+
+// BatchStreamSummary es la última línea de la respuesta de un lote NDJSON
+type BatchStreamSummary struct {
+	TotalProcessed   int    `json:"total_processed"`
+	TotalRejected    int    `json:"total_rejected"`
+	TotalErrors      int    `json:"total_errors"`
+	ProcessingTimeMs int64  `json:"processing_time_ms"`
+	Error            string `json:"error,omitempty"`
+}
+
+// streamLine es una línea del body pendiente de procesar; tooLong indica que
+// superó maxStreamLineBytes y se descartó sin leerla completa
+type streamLine struct {
+	number  int
+	data    []byte
+	tooLong bool
+}
+
+// BatchProcessStream procesa un lote NDJSON (una transacción JSON por línea)
+// a medida que llega y responde en NDJSON con un BatchStreamResult por línea
+// y un BatchStreamSummary final. Ni el lote ni los resultados se acumulan en
+// memoria, por lo que no aplica el límite de MAX_BATCH_SIZE. Una línea que no
+// es JSON válido o que supera maxStreamLineBytes produce un resultado con
+// error y el lote continúa.
+func BatchProcessStream(c *gin.Context) {
+	startTime := time.Now()
+
+	if !batchJobs.begin() {
+		apierror.Respond(c, http.StatusServiceUnavailable, apierror.ShuttingDown, "Service is shutting down")
+		return
+	}
+	defer batchJobs.end()
+
+	// Sin full duplex, net/http cierra el body de HTTP/1.1 en el primer Flush
+	// y el resto del lote se perdería
+	controller := http.NewResponseController(c.Writer)
+	if err := controller.EnableFullDuplex(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		apierror.Respond(c, http.StatusInternalServerError, apierror.Internal, "Streaming not available")
+		return
+	}
+
+	ctx := c.Request.Context()
+	fingerprint := c.GetString("device_fingerprint")
+	lines := make(chan streamLine)
+	results := make(chan BatchStreamResult, batchWorkers)
+
+	// El lector entrega las líneas al pool sin esperar al resto del body
+	var readErr error
+	go func() {
+		defer close(lines)
+		reader := bufio.NewReader(c.Request.Body)
+		for number := 1; ; number++ {
+			// Cada línea tiene su propio plazo en lugar del ReadTimeout del servidor
+			if err := controller.SetReadDeadline(time.Now().Add(streamLineTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+				readErr = err
+				return
+			}
+			line, err := readStreamLine(reader)
+			if err != nil {
+				if err != io.EOF {
+					readErr = err
+				}
+				return
+			}
+			line.number = number
+			if !line.tooLong && len(line.data) == 0 {
+				continue
+			}
+			select {
+			case lines <- line:
+			case <-ctx.Done():
+				readErr = ctx.Err()
+				return
+			}
+		}
+	}()
+
+	go func() {
+		runWorkers(ctx, batchWorkers, lines, func(line streamLine) {
+			results <- processStreamLine(ctx, line, fingerprint)
+		})
+		close(results)
+	}()
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+	encoder := json.NewEncoder(c.Writer)
+
+	var summary BatchStreamSummary
+	for result := range results {
+		switch {
+		case result.Error != "":
+			summary.TotalErrors++
+		case result.Transaction.Status == "rejected":
+			summary.TotalRejected++
+		default:
+			summary.TotalProcessed++
+		}
+		// Si el cliente se desconectó se sigue drenando para liberar los workers
+		_ = controller.SetWriteDeadline(time.Now().Add(streamLineTimeout))
+		if err := encoder.Encode(result); err == nil {
+			c.Writer.Flush()
+		}
+	}
+
+	// readErr se asignó antes de cerrar lines, y results se cierra después
+	if readErr != nil {
+		logging.FromContext(c).Warn("batch stream interrupted", "error", readErr)
+		summary.Error = fmt.Sprintf("failed to read batch stream: %v", readErr)
+	}
+
+	elapsed := time.Since(startTime)
+	summary.ProcessingTimeMs = elapsed.Milliseconds()
+	_ = controller.SetWriteDeadline(time.Now().Add(streamLineTimeout))
+	if err := encoder.Encode(gin.H{"summary": summary}); err == nil {
+		c.Writer.Flush()
+	}
+
+	metrics.BatchSize.Observe(float64(summary.TotalProcessed + summary.TotalRejected + summary.TotalErrors))
+	metrics.ProcessingDuration.WithLabelValues("batch_stream").Observe(elapsed.Seconds())
+	metrics.TransactionsProcessed.WithLabelValues("completed").Add(float64(summary.TotalProcessed))
+	metrics.TransactionsProcessed.WithLabelValues("rejected").Add(float64(summary.TotalRejected))
+}
+
+// readStreamLine lee la siguiente línea del body sin el salto de línea. Una
+// línea de más de maxStreamLineBytes se descarta hasta su final sin
+// acumularla y se retorna con tooLong. Retorna io.EOF cuando no quedan líneas.
+func readStreamLine(reader *bufio.Reader) (streamLine, error) {
+	var line streamLine
+	var data []byte
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		// Se admite el salto de línea más allá del límite
+		if !line.tooLong && len(data)+len(chunk) <= maxStreamLineBytes+1 {
+			data = append(data, chunk...)
+		} else {
+			line.tooLong = true
+			data = nil
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err != nil && (err != io.EOF || (len(data) == 0 && !line.tooLong)) {
+			return streamLine{}, err
+		}
+
+		// Con io.EOF es la última línea, sin salto de línea final
+		data = bytes.TrimSuffix(data, []byte("\n"))
+		if len(data) > maxStreamLineBytes {
+			line.tooLong = true
+		}
+		if !line.tooLong {
+			line.data = bytes.TrimSpace(data)
+		}
+		return line, nil
+	}
+}
+
+// processStreamLine decodifica una línea y la procesa como un elemento de lote
+func processStreamLine(ctx context.Context, line streamLine, fingerprint string) BatchStreamResult {
+	if line.tooLong {
+		return BatchStreamResult{Line: line.number, Error: fmt.Sprintf("line exceeds %d bytes", maxStreamLineBytes)}
+	}
+	var txData batchTransaction
+	if err := json.Unmarshal(line.data, &txData); err != nil {
+		return BatchStreamResult{Line: line.number, Error: fmt.Sprintf("invalid JSON: %v", err)}
+	}
+	transaction := processBatchTransaction(ctx, txData, fingerprint)
+	return BatchStreamResult{Line: line.number, Transaction: &transaction}
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestBatchProcessStreamMixedLines(t *testing.T) {
+	SetBatchWorkers(4)
+	defer SetBatchWorkers(0)
+
+	body := strings.Join([]string{
+		`{"type":"deposit","user_id":"user-1","amount":"100","currency":"MXN"}`,
+		`{"type":"deposit","user_id":`,
+		``,
+		`{"type":"unknown","user_id":"user-2","amount":"50","currency":"MXN"}`,
+		`not json`,
+		`{"type":"withdrawal","user_id":"user-3","amount":"25.5","currency":"USD"}`,
+	}, "\n")
+
+	router := gin.New()
+	router.POST("/", BatchProcessStream)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("Content-Type = %q, want application/x-ndjson", ct)
+	}
+
+	results := make(map[int]BatchStreamResult)
+	var summary *BatchStreamSummary
+	scanner := bufio.NewScanner(w.Body)
+	for scanner.Scan() {
+		var line struct {
+			BatchStreamResult
+			Summary *BatchStreamSummary `json:"summary"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("response line %q is not JSON: %v", scanner.Text(), err)
+		}
+		if line.Summary != nil {
+			summary = line.Summary
+			continue
+		}
+		if summary != nil {
+			t.Fatal("result emitted after the summary")
+		}
+		results[line.Line] = line.BatchStreamResult
+	}
+
+	if len(results) != 5 {
+		t.Fatalf("results = %d, want 5 (blank line skipped)", len(results))
+	}
+	for _, n := range []int{1, 6} {
+		if tx := results[n].Transaction; tx == nil || tx.Status != "completed" {
+			t.Errorf("line %d = %+v, want completed transaction", n, results[n])
+		}
+	}
+	if tx := results[4].Transaction; tx == nil || tx.Status != "rejected" {
+		t.Errorf("line 4 = %+v, want rejected transaction", results[4])
+	}
+	for _, n := range []int{2, 5} {
+		if results[n].Error == "" || results[n].Transaction != nil {
+			t.Errorf("line %d = %+v, want JSON error", n, results[n])
+		}
+	}
+
+	if summary == nil {
+		t.Fatal("summary line missing")
+	}
+	if summary.TotalProcessed != 2 || summary.TotalRejected != 1 || summary.TotalErrors != 2 {
+		t.Errorf("summary = %+v, want 2 processed, 1 rejected, 2 errors", *summary)
+	}
+}
+
+func TestBatchProcessStreamLineTooLong(t *testing.T) {
+	deposit := `{"type":"deposit","user_id":"user-1","amount":"1","currency":"MXN"}`
+	body := deposit + "\n" +
+		`{"user_id":"` + strings.Repeat("x", maxStreamLineBytes) + `"}` + "\n" +
+		deposit
+
+	router := gin.New()
+	router.POST("/", BatchProcessStream)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+
+	results, summary := decodeStream(t, w.Body)
+	if results[2].Error == "" || results[2].Transaction != nil {
+		t.Errorf("line 2 = %+v, want a line too long error", results[2])
+	}
+	if tx := results[3].Transaction; tx == nil || tx.Status != "completed" {
+		t.Errorf("line 3 = %+v, want the batch to continue after the long line", results[3])
+	}
+	if summary.Error != "" || summary.TotalProcessed != 2 || summary.TotalErrors != 1 {
+		t.Errorf("summary = %+v, want 2 processed and 1 error", summary)
+	}
+}
+
+func TestBatchProcessStreamOverHTTPServer(t *testing.T) {
+	SetBatchWorkers(4)
+	defer SetBatchWorkers(0)
+
+	router := gin.New()
+	router.POST("/", BatchProcessStream)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	// Un body chunked mucho más largo que lo leído antes del primer Flush
+	const total = 20000
+	bodyReader, bodyWriter := io.Pipe()
+	go func() {
+		for i := 0; i < total; i++ {
+			line := `{"type":"deposit","user_id":"user-1","amount":"1","currency":"MXN"}` + "\n"
+			if _, err := io.WriteString(bodyWriter, line); err != nil {
+				return
+			}
+		}
+		bodyWriter.Close()
+	}()
+
+	resp, err := http.Post(server.URL, "application/x-ndjson", bodyReader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	results, summary := decodeStream(t, resp.Body)
+	if summary.Error != "" || summary.TotalProcessed != total || len(results) != total {
+		t.Errorf("summary = %+v with %d results, want %d processed", summary, len(results), total)
+	}
+}
+
+// decodeStream separa los resultados por línea y el resumen de una respuesta NDJSON
+func decodeStream(t *testing.T, body io.Reader) (map[int]BatchStreamResult, BatchStreamSummary) {
+	t.Helper()
+	results := make(map[int]BatchStreamResult)
+	var summary *BatchStreamSummary
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		var line struct {
+			BatchStreamResult
+			Summary *BatchStreamSummary `json:"summary"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("response line %q is not JSON: %v", scanner.Text(), err)
+		}
+		if line.Summary != nil {
+			summary = line.Summary
+			continue
+		}
+		results[line.Line] = line.BatchStreamResult
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if summary == nil {
+		t.Fatal("summary line missing")
+	}
+	return results, *summary
+}
+
+func TestBatchProcessStreamPastServerTimeouts(t *testing.T) {
+	SetStreamLineTimeout(500 * time.Millisecond)
+	defer SetStreamLineTimeout(0)
+
+	router := gin.New()
+	router.POST("/", BatchProcessStream)
+	server := httptest.NewUnstartedServer(router)
+	server.Config.ReadTimeout = 200 * time.Millisecond
+	server.Config.WriteTimeout = 200 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	// El lote dura más que los timeouts del servidor pero cada línea llega a tiempo
+	const total = 8
+	bodyReader, bodyWriter := io.Pipe()
+	go func() {
+		for i := 0; i < total; i++ {
+			time.Sleep(100 * time.Millisecond)
+			line := `{"type":"deposit","user_id":"user-1","amount":"1","currency":"MXN"}` + "\n"
+			if _, err := io.WriteString(bodyWriter, line); err != nil {
+				return
+			}
+		}
+		bodyWriter.Close()
+	}()
+
+	resp, err := http.Post(server.URL, "application/x-ndjson", bodyReader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	results, summary := decodeStream(t, resp.Body)
+	if summary.Error != "" || summary.TotalProcessed != total || len(results) != total {
+		t.Errorf("summary = %+v with %d results, want %d processed", summary, len(results), total)
+	}
+}
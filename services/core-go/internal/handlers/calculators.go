@@ -0,0 +1,372 @@
+/*
+Calculadoras financieras para servicios internos
+
+Maneja:
+- Tablas de amortización (sistema francés)
+- Valor futuro con interés compuesto y anualidades
+- Punto de equilibrio en unidades e ingresos
+- Costo promedio ponderado de capital (WACC)
+*/
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/fincore/core-go/internal/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+// decimalesMoneda es la escala con la que se redondean los montos monetarios
+const decimalesMoneda = 2
+
+const (
+	// MaxPlazoMeses acota el plazo de GenerateAmortization (50 años); la
+	// tabla tiene una fila por periodo
+	MaxPlazoMeses = 600
+	// MaxPeriodosValorFuturo acota los periodos de CalculateFutureValue,
+	// que elevan (1+r) a esa potencia con aritmética decimal
+	MaxPeriodosValorFuturo = 1200
+)
+
+// mesesPorFrecuencia traduce la frecuencia de pago a meses por periodo
+var mesesPorFrecuencia = map[string]int{
+	"mensual":    1,
+	"bimestral":  2,
+	"trimestral": 3,
+	"semestral":  6,
+	"anual":      12,
+}
+
+// AmortizationRow representa un periodo de la tabla de amortización
+type AmortizationRow struct {
+	Periodo int             `json:"periodo"`
+	Pago    decimal.Decimal `json:"pago"`
+	Interes decimal.Decimal `json:"interes"`
+	Capital decimal.Decimal `json:"capital"`
+	Saldo   decimal.Decimal `json:"saldo"`
+}
+
+// GenerateAmortization genera la tabla de amortización de un préstamo con el sistema francés
+func GenerateAmortization(c *gin.Context) {
+	var req struct {
+		Principal      decimal.Decimal `json:"principal" binding:"required"`
+		TasaAnual      decimal.Decimal `json:"tasa_anual"`
+		PlazoMeses     int             `json:"plazo_meses" binding:"required"`
+		FrecuenciaPago string          `json:"frecuencia_pago"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request")
+		return
+	}
+
+	if req.Principal.LessThanOrEqual(decimal.Zero) {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidParameter, "principal must be positive")
+		return
+	}
+	if req.TasaAnual.IsNegative() {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidParameter, "tasa_anual cannot be negative")
+		return
+	}
+	if req.PlazoMeses <= 0 {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidParameter, "plazo_meses must be positive")
+		return
+	}
+	if req.PlazoMeses > MaxPlazoMeses {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidParameter, fmt.Sprintf("plazo_meses cannot exceed %d", MaxPlazoMeses))
+		return
+	}
+
+	// Frecuencia mensual por defecto
+	frecuencia := strings.ToLower(req.FrecuenciaPago)
+	if frecuencia == "" {
+		frecuencia = "mensual"
+	}
+	mesesPeriodo, ok := mesesPorFrecuencia[frecuencia]
+	if !ok {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidParameter, "Unsupported frecuencia_pago")
+		return
+	}
+	if req.PlazoMeses%mesesPeriodo != 0 {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidParameter, "plazo_meses must be a multiple of the payment frequency")
+		return
+	}
+
+	periodos := req.PlazoMeses / mesesPeriodo
+	tasaPeriodo := req.TasaAnual.Mul(decimal.NewFromInt(int64(mesesPeriodo))).Div(decimal.NewFromInt(12))
+	tabla := calcularAmortizacion(req.Principal, tasaPeriodo, periodos)
+
+	totalPagado := decimal.Zero
+	totalIntereses := decimal.Zero
+	for _, fila := range tabla {
+		totalPagado = totalPagado.Add(fila.Pago)
+		totalIntereses = totalIntereses.Add(fila.Interes)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":         true,
+		"pago_periodico":  tabla[0].Pago,
+		"periodos":        periodos,
+		"tasa_periodo":    tasaPeriodo,
+		"total_pagado":    totalPagado,
+		"total_intereses": totalIntereses,
+		"tabla":           tabla,
+	})
+}
+
+// calcularAmortizacion construye la tabla con cuota fija: P·r / (1 - (1+r)^-n).
+// Cada monto se redondea a centavos y el último periodo absorbe la diferencia
+// de redondeo para que el saldo final sea exactamente cero.
+func calcularAmortizacion(principal, tasaPeriodo decimal.Decimal, periodos int) []AmortizationRow {
+	n := decimal.NewFromInt(int64(periodos))
+
+	var pago decimal.Decimal
+	if tasaPeriodo.IsZero() {
+		pago = principal.Div(n)
+	} else {
+		factor := decimal.NewFromInt(1).Add(tasaPeriodo).Pow(n)
+		pago = principal.Mul(tasaPeriodo).Mul(factor).Div(factor.Sub(decimal.NewFromInt(1)))
+	}
+	pago = pago.Round(decimalesMoneda)
+
+	tabla := make([]AmortizationRow, 0, periodos)
+	saldo := principal
+	for periodo := 1; periodo <= periodos; periodo++ {
+		interes := saldo.Mul(tasaPeriodo).Round(decimalesMoneda)
+		capital := pago.Sub(interes)
+		pagoPeriodo := pago
+
+		// Ajuste de redondeo en el último periodo
+		if periodo == periodos {
+			capital = saldo
+			pagoPeriodo = capital.Add(interes)
+		}
+
+		saldo = saldo.Sub(capital)
+		tabla = append(tabla, AmortizationRow{
+			Periodo: periodo,
+			Pago:    pagoPeriodo,
+			Interes: interes,
+			Capital: capital,
+			Saldo:   saldo,
+		})
+	}
+
+	return tabla
+}
+
+// Tipos de anualidad soportados por CalculateFutureValue
+const (
+	anualidadOrdinaria  = "ordinaria"
+	anualidadAnticipada = "anticipada"
+)
+
+// CalculateFutureValue calcula el valor futuro de un monto inicial más
+// aportaciones periódicas opcionales (anualidad ordinaria o anticipada)
+func CalculateFutureValue(c *gin.Context) {
+	var req struct {
+		ValorPresente       decimal.Decimal `json:"valor_presente"`
+		TasaPeriodo         decimal.Decimal `json:"tasa_periodo"`
+		Periodos            int             `json:"periodos"`
+		AportacionPeriodica decimal.Decimal `json:"aportacion_periodica"`
+		Tipo                string          `json:"tipo"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request")
+		return
+	}
+
+	if req.Periodos < 0 {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidParameter, "periodos cannot be negative")
+		return
+	}
+	if req.Periodos > MaxPeriodosValorFuturo {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidParameter, fmt.Sprintf("periodos cannot exceed %d", MaxPeriodosValorFuturo))
+		return
+	}
+	// Se permiten tasas negativas (deflación) pero no de -100% o menos
+	if req.TasaPeriodo.LessThanOrEqual(decimal.NewFromInt(-1)) {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidParameter, "tasa_periodo must be greater than -1")
+		return
+	}
+
+	tipo := strings.ToLower(req.Tipo)
+	if tipo == "" {
+		tipo = anualidadOrdinaria
+	}
+	if tipo != anualidadOrdinaria && tipo != anualidadAnticipada {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidParameter, "tipo must be 'ordinaria' or 'anticipada'")
+		return
+	}
+
+	valorFuturo := calcularValorFuturo(req.ValorPresente, req.TasaPeriodo, req.Periodos, req.AportacionPeriodica, tipo == anualidadAnticipada)
+	totalAportaciones := req.AportacionPeriodica.Mul(decimal.NewFromInt(int64(req.Periodos)))
+	interesGanado := valorFuturo.Sub(req.ValorPresente).Sub(totalAportaciones)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":            true,
+		"valor_futuro":       valorFuturo.Round(decimalesMoneda),
+		"total_aportaciones": totalAportaciones.Round(decimalesMoneda),
+		"interes_ganado":     interesGanado.Round(decimalesMoneda),
+		"tipo":               tipo,
+	})
+}
+
+// calcularValorFuturo aplica VF = VP·(1+r)^n + A·((1+r)^n - 1)/r, multiplicando
+// la anualidad por (1+r) cuando las aportaciones son al inicio del periodo
+func calcularValorFuturo(valorPresente, tasa decimal.Decimal, periodos int, aportacion decimal.Decimal, anticipada bool) decimal.Decimal {
+	if periodos == 0 {
+		return valorPresente
+	}
+
+	n := decimal.NewFromInt(int64(periodos))
+	uno := decimal.NewFromInt(1)
+	factor := uno.Add(tasa).Pow(n)
+
+	var valorAnualidad decimal.Decimal
+	if tasa.IsZero() {
+		valorAnualidad = aportacion.Mul(n)
+	} else {
+		valorAnualidad = aportacion.Mul(factor.Sub(uno)).Div(tasa)
+		if anticipada {
+			valorAnualidad = valorAnualidad.Mul(uno.Add(tasa))
+		}
+	}
+
+	return valorPresente.Mul(factor).Add(valorAnualidad)
+}
+
+// CalculateBreakEven calcula las unidades e ingresos con los que los ingresos
+// cubren los costos fijos y, opcionalmente, las necesarias para una utilidad objetivo
+func CalculateBreakEven(c *gin.Context) {
+	var req struct {
+		CostosFijos           decimal.Decimal  `json:"costos_fijos"`
+		PrecioUnitario        decimal.Decimal  `json:"precio_unitario" binding:"required"`
+		CostoVariableUnitario decimal.Decimal  `json:"costo_variable_unitario"`
+		UtilidadObjetivo      *decimal.Decimal `json:"utilidad_objetivo"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request")
+		return
+	}
+
+	if req.CostosFijos.IsNegative() {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidParameter, "costos_fijos cannot be negative")
+		return
+	}
+	if req.CostoVariableUnitario.IsNegative() {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidParameter, "costo_variable_unitario cannot be negative")
+		return
+	}
+	// Sin margen de contribución positivo ningún volumen cubre los costos fijos
+	if req.PrecioUnitario.LessThanOrEqual(req.CostoVariableUnitario) {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidParameter, "precio_unitario must be greater than costo_variable_unitario, no break-even exists")
+		return
+	}
+	if req.UtilidadObjetivo != nil && req.UtilidadObjetivo.IsNegative() {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidParameter, "utilidad_objetivo cannot be negative")
+		return
+	}
+
+	margen := req.PrecioUnitario.Sub(req.CostoVariableUnitario)
+	unidades, ingreso := calcularPuntoEquilibrio(req.CostosFijos, req.PrecioUnitario, margen)
+
+	response := gin.H{
+		"success":             true,
+		"margen_contribucion": margen,
+		"unidades_equilibrio": unidades,
+		"ingreso_equilibrio":  ingreso,
+		"ratio_margen":        margen.DivRound(req.PrecioUnitario, 6),
+	}
+	if req.UtilidadObjetivo != nil {
+		unidadesObjetivo, ingresoObjetivo := calcularPuntoEquilibrio(req.CostosFijos.Add(*req.UtilidadObjetivo), req.PrecioUnitario, margen)
+		response["unidades_objetivo"] = unidadesObjetivo
+		response["ingreso_objetivo"] = ingresoObjetivo
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// calcularPuntoEquilibrio retorna las unidades enteras necesarias para cubrir
+// los costos (costos / margen, redondeado hacia arriba porque no se venden
+// fracciones de unidad) y el ingreso exacto de equilibrio redondeado a centavos
+func calcularPuntoEquilibrio(costos, precio, margen decimal.Decimal) (decimal.Decimal, decimal.Decimal) {
+	unidadesExactas := costos.DivRound(margen, 16)
+	return unidadesExactas.Ceil(), unidadesExactas.Mul(precio).Round(decimalesMoneda)
+}
+
+// decimalesTasa es la escala con la que se redondean las tasas calculadas
+const decimalesTasa = 10
+
+// ParametrosWACC son los insumos del costo promedio ponderado de capital
+type ParametrosWACC struct {
+	ValorCapital  decimal.Decimal `json:"valor_capital"`
+	ValorDeuda    decimal.Decimal `json:"valor_deuda"`
+	CostoCapital  decimal.Decimal `json:"costo_capital"`
+	CostoDeuda    decimal.Decimal `json:"costo_deuda"`
+	TasaImpuestos decimal.Decimal `json:"tasa_impuestos"`
+}
+
+// ResultadoWACC desglosa el WACC y sus componentes
+type ResultadoWACC struct {
+	WACC                       decimal.Decimal `json:"wacc"`
+	PesoCapital                decimal.Decimal `json:"peso_capital"`
+	PesoDeuda                  decimal.Decimal `json:"peso_deuda"`
+	CostoDeudaDespuesImpuestos decimal.Decimal `json:"costo_deuda_despues_impuestos"`
+}
+
+// calcularWACC aplica WACC = E/V·Re + D/V·Rd·(1 - T), con V = E + D
+func calcularWACC(p ParametrosWACC) (ResultadoWACC, error) {
+	if p.ValorCapital.IsNegative() || p.ValorDeuda.IsNegative() {
+		return ResultadoWACC{}, errors.New("valor_capital and valor_deuda cannot be negative")
+	}
+	total := p.ValorCapital.Add(p.ValorDeuda)
+	if total.IsZero() {
+		return ResultadoWACC{}, errors.New("total capital (valor_capital + valor_deuda) must be greater than zero")
+	}
+	if p.TasaImpuestos.IsNegative() || p.TasaImpuestos.GreaterThan(decimal.NewFromInt(1)) {
+		return ResultadoWACC{}, errors.New("tasa_impuestos must be between 0 and 1")
+	}
+
+	uno := decimal.NewFromInt(1)
+	pesoCapital := p.ValorCapital.DivRound(total, decimalesTasa+digitosGuarda)
+	pesoDeuda := uno.Sub(pesoCapital)
+	costoDeudaNeto := p.CostoDeuda.Mul(uno.Sub(p.TasaImpuestos))
+	wacc := pesoCapital.Mul(p.CostoCapital).Add(pesoDeuda.Mul(costoDeudaNeto))
+
+	return ResultadoWACC{
+		WACC:                       wacc.Round(decimalesTasa),
+		PesoCapital:                pesoCapital.Round(decimalesTasa),
+		PesoDeuda:                  pesoDeuda.Round(decimalesTasa),
+		CostoDeudaDespuesImpuestos: costoDeudaNeto.Round(decimalesTasa),
+	}, nil
+}
+
+// CalculateWACC calcula el costo promedio ponderado de capital después de impuestos
+func CalculateWACC(c *gin.Context) {
+	var req ParametrosWACC
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request")
+		return
+	}
+
+	resultado, err := calcularWACC(req)
+	if err != nil {
+		respondInvalidParameter(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":                       true,
+		"wacc":                          resultado.WACC,
+		"peso_capital":                  resultado.PesoCapital,
+		"peso_deuda":                    resultado.PesoDeuda,
+		"costo_deuda_despues_impuestos": resultado.CostoDeudaDespuesImpuestos,
+	})
+}
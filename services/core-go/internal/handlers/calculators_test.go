@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/fincore/core-go/internal/apierror"
+	"github.com/fincore/core-go/internal/finance"
+	"github.com/shopspring/decimal"
+)
+
+func TestCalcularAmortizacionPrincipalSum(t *testing.T) {
+	principal := decimal.RequireFromString("100000")
+	tasa := decimal.RequireFromString("0.12").Div(decimal.NewFromInt(12))
+
+	tabla := calcularAmortizacion(principal, tasa, 36)
+	if len(tabla) != 36 {
+		t.Fatalf("len(tabla) = %d, want 36", len(tabla))
+	}
+
+	sumaCapital := decimal.Zero
+	for _, fila := range tabla {
+		sumaCapital = sumaCapital.Add(fila.Capital)
+	}
+	if !sumaCapital.Equal(principal) {
+		t.Errorf("sum of principal portions = %s, want %s", sumaCapital, principal)
+	}
+
+	if saldo := tabla[len(tabla)-1].Saldo; !saldo.IsZero() {
+		t.Errorf("final balance = %s, want 0", saldo)
+	}
+
+	// Cuota de referencia: 100000 al 1% mensual en 36 meses = 3321.43
+	if pago := tabla[0].Pago; !pago.Equal(decimal.RequireFromString("3321.43")) {
+		t.Errorf("payment = %s, want 3321.43", pago)
+	}
+}
+
+func TestCalcularAmortizacionZeroRate(t *testing.T) {
+	tabla := calcularAmortizacion(decimal.NewFromInt(1000), decimal.Zero, 3)
+
+	// 1000 / 3 = 333.33; el último periodo absorbe el centavo restante
+	if !tabla[0].Pago.Equal(decimal.RequireFromString("333.33")) {
+		t.Errorf("payment = %s, want 333.33", tabla[0].Pago)
+	}
+	if !tabla[2].Capital.Equal(decimal.RequireFromString("333.34")) {
+		t.Errorf("last principal = %s, want 333.34", tabla[2].Capital)
+	}
+	if !tabla[2].Saldo.IsZero() {
+		t.Errorf("final balance = %s, want 0", tabla[2].Saldo)
+	}
+}
+
+func TestGenerateAmortizationValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		body map[string]interface{}
+		want int
+	}{
+		{"trimestral", map[string]interface{}{"principal": "12000", "tasa_anual": "0.1", "plazo_meses": 12, "frecuencia_pago": "trimestral"}, http.StatusOK},
+		{"frecuencia desconocida", map[string]interface{}{"principal": "12000", "tasa_anual": "0.1", "plazo_meses": 12, "frecuencia_pago": "semanal"}, http.StatusBadRequest},
+		{"plazo no divisible", map[string]interface{}{"principal": "12000", "tasa_anual": "0.1", "plazo_meses": 10, "frecuencia_pago": "trimestral"}, http.StatusBadRequest},
+		{"principal negativo", map[string]interface{}{"principal": "-1", "tasa_anual": "0.1", "plazo_meses": 12}, http.StatusBadRequest},
+		{"plazo máximo", map[string]interface{}{"principal": "12000", "tasa_anual": "0.1", "plazo_meses": MaxPlazoMeses, "frecuencia_pago": "semestral"}, http.StatusOK},
+		{"plazo excesivo", map[string]interface{}{"principal": "12000", "tasa_anual": "0.1", "plazo_meses": 2000000000}, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, resp := doJSON(t, GenerateAmortization, tt.body)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d (%v)", w.Code, tt.want, resp)
+			}
+			if tt.want == http.StatusOK {
+				want := tt.body["plazo_meses"].(int) / mesesPorFrecuencia[tt.body["frecuencia_pago"].(string)]
+				if tabla := resp["tabla"].([]interface{}); len(tabla) != want {
+					t.Errorf("len(tabla) = %d, want %d", len(tabla), want)
+				}
+			}
+		})
+	}
+}
+
+func TestCalculateFutureValue(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        map[string]interface{}
+		valorFuturo string
+		interes     string
+	}{
+		// 1000·1.05^10 = 1628.89
+		{"solo valor presente", map[string]interface{}{"valor_presente": "1000", "tasa_periodo": "0.05", "periodos": 10}, "1628.89", "628.89"},
+		// 100·(1.05^10 - 1)/0.05 = 1257.79
+		{"anualidad ordinaria", map[string]interface{}{"tasa_periodo": "0.05", "periodos": 10, "aportacion_periodica": "100"}, "1257.79", "257.79"},
+		// 1257.79·1.05 = 1320.68
+		{"anualidad anticipada", map[string]interface{}{"tasa_periodo": "0.05", "periodos": 10, "aportacion_periodica": "100", "tipo": "anticipada"}, "1320.68", "320.68"},
+		// 1000·0.98^2 = 960.40
+		{"tasa negativa", map[string]interface{}{"valor_presente": "1000", "tasa_periodo": "-0.02", "periodos": 2}, "960.4", "-39.6"},
+		{"cero periodos", map[string]interface{}{"valor_presente": "1000", "tasa_periodo": "0.05", "periodos": 0, "aportacion_periodica": "100"}, "1000", "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, resp := doJSON(t, CalculateFutureValue, tt.body)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200 (%v)", w.Code, resp)
+			}
+			if resp["valor_futuro"] != tt.valorFuturo {
+				t.Errorf("valor_futuro = %v, want %s", resp["valor_futuro"], tt.valorFuturo)
+			}
+			if resp["interes_ganado"] != tt.interes {
+				t.Errorf("interes_ganado = %v, want %s", resp["interes_ganado"], tt.interes)
+			}
+		})
+	}
+}
+
+func TestCalculateFutureValueTooManyPeriods(t *testing.T) {
+	w, resp := doJSON(t, CalculateFutureValue, map[string]interface{}{
+		"valor_presente": "1000",
+		"tasa_periodo":   "0.05",
+		"periodos":       MaxPeriodosValorFuturo + 1,
+	})
+	if w.Code != http.StatusBadRequest || resp["code"] != apierror.InvalidParameter {
+		t.Errorf("status/code = %d/%v, want 400/%s", w.Code, resp["code"], apierror.InvalidParameter)
+	}
+}
+
+func TestCalculateFutureValueInvalidTipo(t *testing.T) {
+	w, _ := doJSON(t, CalculateFutureValue, map[string]interface{}{
+		"valor_presente": "1000",
+		"tasa_periodo":   "0.05",
+		"periodos":       1,
+		"tipo":           "diferida",
+	})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+}
+
+func TestCalculateBreakEven(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     map[string]interface{}
+		unidades string
+		ingreso  string
+	}{
+		// 10000 / (50 - 30) = 500 unidades = 25000
+		{"exacto", map[string]interface{}{"costos_fijos": "10000", "precio_unitario": "50", "costo_variable_unitario": "30"}, "500", "25000"},
+		// 1000 / 3 = 333.33 unidades: se requieren 334, ingreso 333.33·7
+		{"fraccionario", map[string]interface{}{"costos_fijos": "1000", "precio_unitario": "7", "costo_variable_unitario": "4"}, "334", "2333.33"},
+		{"sin costos fijos", map[string]interface{}{"costos_fijos": "0", "precio_unitario": "7", "costo_variable_unitario": "4"}, "0", "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, resp := doJSON(t, CalculateBreakEven, tt.body)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200 (%v)", w.Code, resp)
+			}
+			if resp["unidades_equilibrio"] != tt.unidades {
+				t.Errorf("unidades_equilibrio = %v, want %s", resp["unidades_equilibrio"], tt.unidades)
+			}
+			if resp["ingreso_equilibrio"] != tt.ingreso {
+				t.Errorf("ingreso_equilibrio = %v, want %s", resp["ingreso_equilibrio"], tt.ingreso)
+			}
+			if _, ok := resp["unidades_objetivo"]; ok {
+				t.Error("unidades_objetivo must be omitted without utilidad_objetivo")
+			}
+		})
+	}
+}
+
+func TestCalculateBreakEvenNoSolution(t *testing.T) {
+	for _, variable := range []string{"50", "60"} {
+		w, resp := doJSON(t, CalculateBreakEven, map[string]interface{}{
+			"costos_fijos":            "10000",
+			"precio_unitario":         "50",
+			"costo_variable_unitario": variable,
+		})
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("variable %s: status = %d, want 400", variable, w.Code)
+		}
+		if resp["error"] != "precio_unitario must be greater than costo_variable_unitario, no break-even exists" {
+			t.Errorf("variable %s: error = %v", variable, resp["error"])
+		}
+	}
+}
+
+func TestCalculateBreakEvenTargetProfit(t *testing.T) {
+	// (10000 + 5000) / 20 = 750 unidades = 37500
+	w, resp := doJSON(t, CalculateBreakEven, map[string]interface{}{
+		"costos_fijos":            "10000",
+		"precio_unitario":         "50",
+		"costo_variable_unitario": "30",
+		"utilidad_objetivo":       "5000",
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if resp["unidades_objetivo"] != "750" || resp["ingreso_objetivo"] != "37500" {
+		t.Errorf("objetivo = %v unidades / %v, want 750 / 37500", resp["unidades_objetivo"], resp["ingreso_objetivo"])
+	}
+	if resp["unidades_equilibrio"] != "500" {
+		t.Errorf("unidades_equilibrio = %v, want 500", resp["unidades_equilibrio"])
+	}
+}
+
+func TestCalculateWACC(t *testing.T) {
+	// Ejemplo de libro: E = 600, D = 400, Re = 12%, Rd = 6%, T = 30%
+	// WACC = 0.6·0.12 + 0.4·0.06·(1 - 0.3) = 0.0888
+	w, resp := doJSON(t, CalculateWACC, map[string]interface{}{
+		"valor_capital":  "600000",
+		"valor_deuda":    "400000",
+		"costo_capital":  "0.12",
+		"costo_deuda":    "0.06",
+		"tasa_impuestos": "0.30",
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%v)", w.Code, resp)
+	}
+	if resp["wacc"] != "0.0888" {
+		t.Errorf("wacc = %v, want 0.0888", resp["wacc"])
+	}
+	if resp["peso_capital"] != "0.6" || resp["peso_deuda"] != "0.4" {
+		t.Errorf("pesos = %v/%v, want 0.6/0.4", resp["peso_capital"], resp["peso_deuda"])
+	}
+	if resp["costo_deuda_despues_impuestos"] != "0.042" {
+		t.Errorf("costo_deuda_despues_impuestos = %v, want 0.042", resp["costo_deuda_despues_impuestos"])
+	}
+}
+
+func TestCalculateWACCValidation(t *testing.T) {
+	cases := []map[string]interface{}{
+		{"valor_capital": "0", "valor_deuda": "0", "costo_capital": "0.1"},
+		{"valor_capital": "-100", "valor_deuda": "200", "costo_capital": "0.1"},
+		{"valor_capital": "100", "valor_deuda": "100", "costo_capital": "0.1", "tasa_impuestos": "1.5"},
+	}
+	for _, body := range cases {
+		if w, _ := doJSON(t, CalculateWACC, body); w.Code != http.StatusBadRequest {
+			t.Errorf("%v: status = %d, want 400", body, w.Code)
+		}
+	}
+}
+
+func TestCalculateMetricsDerivesRateFromWACC(t *testing.T) {
+	flujos := []float64{400, 400, 400}
+	w, resp := doJSON(t, CalculateMetrics, map[string]interface{}{
+		"inversion_inicial": 1000,
+		"flujos_ingresos":   flujos,
+		"wacc": map[string]interface{}{
+			"valor_capital":  "600000",
+			"valor_deuda":    "400000",
+			"costo_capital":  "0.12",
+			"costo_deuda":    "0.06",
+			"tasa_impuestos": "0.30",
+		},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+
+	metrics := metricsOf(t, resp)
+	if metrics["tasa_descuento"] != 0.0888 {
+		t.Errorf("tasa_descuento = %v, want 0.0888", metrics["tasa_descuento"])
+	}
+	if want := finance.NPV(0.0888, 1000, flujos); metrics["van"] != want {
+		t.Errorf("van = %v, want %v", metrics["van"], want)
+	}
+
+	// Sin tasa ni WACC no hay con qué descontar
+	if w, _ := doJSON(t, CalculateMetrics, map[string]interface{}{"inversion_inicial": 1000, "flujos_ingresos": flujos}); w.Code != http.StatusBadRequest {
+		t.Errorf("missing rate: status = %d, want 400", w.Code)
+	}
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrInvalidCurrency indica un código de moneda que no es ISO-4217 o, en las
+// transacciones, que tampoco es una criptomoneda admitida
+var ErrInvalidCurrency = errors.New("Invalid currency code")
+
+// codigosISO4217 contiene los códigos de moneda ISO-4217 vigentes
+var codigosISO4217 = map[string]struct{}{}
+
+func init() {
+	codigos := `AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND BOB
+		BRL BSD BTN BWP BYN BZD CAD CDF CHF CLP CNY COP CRC CUP CVE CZK DJF DKK DOP DZD
+		EGP ERN ETB EUR FJD FKP GBP GEL GHS GIP GMD GNF GTQ GYD HKD HNL HTG HUF IDR ILS
+		INR IQD IRR ISK JMD JOD JPY KES KGS KHR KMF KPW KRW KWD KYD KZT LAK LBP LKR LRD
+		LSL LYD MAD MDL MGA MKD MMK MNT MOP MRU MUR MVR MWK MXN MYR MZN NAD NGN NIO NOK
+		NPR NZD OMR PAB PEN PGK PHP PKR PLN PYG QAR RON RSD RUB RWF SAR SBD SCR SDG SEK
+		SGD SHP SLE SOS SRD SSP STN SVC SYP SZL THB TJS TMT TND TOP TRY TTD TWD TZS UAH
+		UGX USD UYU UZS VES VND VUV WST XAF XCD XOF XPF YER ZAR ZMW ZWL`
+
+	for _, codigo := range strings.Fields(codigos) {
+		codigosISO4217[codigo] = struct{}{}
+	}
+}
+
+// esCodigoISO4217 indica si el código es una moneda ISO-4217 válida (en mayúsculas)
+func esCodigoISO4217(codigo string) bool {
+	_, ok := codigosISO4217[codigo]
+	return ok
+}
+
+// criptomonedas son los códigos no ISO-4217 que se aceptan en transacciones
+var criptomonedas = map[string]struct{}{
+	"BTC": {}, "ETH": {}, "USDC": {}, "USDT": {},
+}
+
+// esMonedaDeTransaccion indica si una transacción puede operar en la moneda
+// (en mayúsculas): ISO-4217 o una de las criptomonedas admitidas. Es la
+// misma regla para transacciones individuales y lotes.
+func esMonedaDeTransaccion(codigo string) bool {
+	if _, ok := criptomonedas[codigo]; ok {
+		return true
+	}
+	return esCodigoISO4217(codigo)
+}
+
+// DefaultCurrency es la moneda que se asume cuando un request no indica
+// ninguna y no se configuró otra con SetDefaultCurrency
+const DefaultCurrency = "MXN"
+
+// defaultCurrency es la moneda asumida por ProcessTransaction, BatchProcess
+// y ValidateTransfer cuando el request no trae currency
+var defaultCurrency = DefaultCurrency
+
+// SetDefaultCurrency configura la moneda por defecto; retorna un error si no
+// es un código ISO-4217 y mantiene la anterior
+func SetDefaultCurrency(currency string) error {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if !esCodigoISO4217(currency) {
+		return fmt.Errorf("invalid default currency %q", currency)
+	}
+	defaultCurrency = currency
+	return nil
+}
+
+// monedaPorDefecto retorna currency o, si está vacía, la moneda por defecto
+func monedaPorDefecto(currency string) string {
+	if currency == "" {
+		return defaultCurrency
+	}
+	return currency
+}
+
+// normalizarMoneda pasa currency a mayúsculas sin espacios o, si está vacía,
+// retorna la moneda por defecto
+func normalizarMoneda(currency string) string {
+	return monedaPorDefecto(strings.ToUpper(strings.TrimSpace(currency)))
+}
+
+// decimalesPorMoneda define la escala máxima de los montos por moneda; las
+// monedas no listadas usan decimalesMoneda
+var decimalesPorMoneda = map[string]int32{
+	// Monedas ISO-4217 sin subdivisión
+	"CLP": 0, "ISK": 0, "JPY": 0, "KRW": 0, "PYG": 0, "UGX": 0, "VND": 0,
+	"XAF": 0, "XOF": 0, "XPF": 0,
+	// Monedas ISO-4217 con tres decimales
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	// Criptomonedas (ver criptomonedas)
+	"BTC": 8, "ETH": 8, "USDC": 6, "USDT": 6,
+}
+
+// strictAmountPrecision indica si los montos con más decimales de los que
+// admite la moneda se rechazan (true) o se redondean (false)
+var strictAmountPrecision = true
+
+// SetStrictAmountPrecision configura si el exceso de decimales se rechaza o se redondea
+func SetStrictAmountPrecision(strict bool) {
+	strictAmountPrecision = strict
+}
+
+// decimalesDeMoneda retorna la escala máxima permitida para la moneda
+func decimalesDeMoneda(currency string) int32 {
+	if places, ok := decimalesPorMoneda[strings.ToUpper(currency)]; ok {
+		return places
+	}
+	return decimalesMoneda
+}
+
+// aplicarPrecisionMoneda ajusta el monto a la escala de la moneda. Los ceros
+// a la derecha no cuentan como exceso; en modo estricto cualquier otro
+// decimal adicional produce un error y en modo no estricto se redondea.
+func aplicarPrecisionMoneda(amount decimal.Decimal, currency string) (decimal.Decimal, error) {
+	places := decimalesDeMoneda(currency)
+	if -amount.Exponent() <= places {
+		return amount, nil
+	}
+
+	rounded := amount.Round(places)
+	if strictAmountPrecision && !rounded.Equal(amount) {
+		return amount, fmt.Errorf("amount %s exceeds %d decimal places allowed for %s", amount, places, strings.ToUpper(currency))
+	}
+	return rounded, nil
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestAplicarPrecisionMonedaValid(t *testing.T) {
+	cases := []struct {
+		amount   string
+		currency string
+	}{
+		{"100.25", "MXN"},
+		{"100.50", "usd"},
+		{"100.000", "MXN"},
+		{"1500", "JPY"},
+		{"0.12345678", "BTC"},
+		{"1.125", "KWD"},
+	}
+	for _, tc := range cases {
+		amount := decimal.RequireFromString(tc.amount)
+		got, err := aplicarPrecisionMoneda(amount, tc.currency)
+		if err != nil {
+			t.Errorf("%s %s: unexpected error %v", tc.amount, tc.currency, err)
+			continue
+		}
+		if !got.Equal(amount) {
+			t.Errorf("%s %s: amount changed to %s", tc.amount, tc.currency, got)
+		}
+	}
+}
+
+func TestAplicarPrecisionMonedaStrictRejects(t *testing.T) {
+	cases := map[string]string{
+		"0.000001":    "MXN",
+		"10.125":      "USD",
+		"1500.5":      "JPY",
+		"0.123456789": "BTC",
+	}
+	for amount, currency := range cases {
+		if _, err := aplicarPrecisionMoneda(decimal.RequireFromString(amount), currency); err == nil {
+			t.Errorf("%s %s: expected precision error", amount, currency)
+		}
+	}
+}
+
+func TestAplicarPrecisionMonedaRoundsWhenNotStrict(t *testing.T) {
+	SetStrictAmountPrecision(false)
+	defer SetStrictAmountPrecision(true)
+
+	cases := []struct {
+		amount, currency, want string
+	}{
+		{"10.125", "MXN", "10.13"},
+		{"10.124", "USD", "10.12"},
+		{"1500.5", "JPY", "1501"},
+		{"0.123456789", "BTC", "0.12345679"},
+	}
+	for _, tc := range cases {
+		got, err := aplicarPrecisionMoneda(decimal.RequireFromString(tc.amount), tc.currency)
+		if err != nil {
+			t.Fatalf("%s %s: unexpected error %v", tc.amount, tc.currency, err)
+		}
+		if !got.Equal(decimal.RequireFromString(tc.want)) {
+			t.Errorf("%s %s = %s, want %s", tc.amount, tc.currency, got, tc.want)
+		}
+	}
+}
+
+func TestProcessTransactionRejectsExcessPrecision(t *testing.T) {
+	w, resp := doJSON(t, ProcessTransaction, map[string]interface{}{
+		"type":    "deposit",
+		"user_id": "user-1",
+		"amount":  "0.000001",
+	})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", w.Code)
+	}
+	if resp["max_decimals"] != float64(2) {
+		t.Errorf("max_decimals = %v, want 2", resp["max_decimals"])
+	}
+}
+
+func TestBatchProcessRoundsPrecisionWhenNotStrict(t *testing.T) {
+	SetStrictAmountPrecision(false)
+	defer SetStrictAmountPrecision(true)
+
+	w, resp := doJSON(t, BatchProcess, map[string]interface{}{
+		"transactions": []map[string]interface{}{
+			{"type": "deposit", "user_id": "user-1", "amount": "10.129"},
+		},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	tx := resp["transactions"].([]interface{})[0].(map[string]interface{})
+	if tx["status"] != "completed" || tx["amount"] != "10.13" {
+		t.Errorf("transaction = %v/%v, want completed/10.13", tx["status"], tx["amount"])
+	}
+}
+
+func TestBatchProcessAcceptsSupportedCryptocurrencies(t *testing.T) {
+	w, resp := doJSON(t, BatchProcess, map[string]interface{}{
+		"transactions": []map[string]interface{}{
+			{"type": "deposit", "user_id": "user-1", "amount": "0.12345678", "currency": "btc"},
+			{"type": "deposit", "user_id": "user-1", "amount": "1.5", "currency": "USDT"},
+			{"type": "deposit", "user_id": "user-1", "amount": "0.123456789", "currency": "BTC"},
+			{"type": "deposit", "user_id": "user-1", "amount": "1", "currency": "DOGE"},
+		},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	results := resp["transactions"].([]interface{})
+	want := []string{"completed", "completed", "rejected", "rejected"}
+	for i, status := range want {
+		if got := results[i].(map[string]interface{})["status"]; got != status {
+			t.Errorf("transaction %d status = %v, want %s (%v)", i, got, status, results[i])
+		}
+	}
+	if reason := results[3].(map[string]interface{})["rejection_reason"]; reason != ErrInvalidCurrency.Error() {
+		t.Errorf("DOGE rejection = %v, want %q", reason, ErrInvalidCurrency)
+	}
+}
+
+func TestSetDefaultCurrency(t *testing.T) {
+	t.Cleanup(func() { _ = SetDefaultCurrency(DefaultCurrency) })
+
+	if err := SetDefaultCurrency(" usd "); err != nil {
+		t.Fatalf("SetDefaultCurrency: %v", err)
+	}
+	if err := SetDefaultCurrency("ZZZ"); err == nil {
+		t.Error("expected error for an invalid currency")
+	}
+
+	// ProcessTransaction y BatchProcess comparten la moneda por defecto
+	w, resp := doJSON(t, ProcessTransaction, map[string]interface{}{
+		"type":    "deposit",
+		"user_id": "user-1",
+		"amount":  "10",
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("process status = %d, want 200", w.Code)
+	}
+	if got := resp["transaction"].(map[string]interface{})["currency"]; got != "USD" {
+		t.Errorf("process currency = %v, want USD", got)
+	}
+
+	_, resp = doJSON(t, BatchProcess, map[string]interface{}{
+		"transactions": []map[string]interface{}{
+			{"type": "deposit", "user_id": "user-1", "amount": "10"},
+			{"type": "deposit", "user_id": "user-2", "amount": "10", "currency": "mxn"},
+		},
+	})
+	results := resp["transactions"].([]interface{})
+	if got := results[0].(map[string]interface{})["currency"]; got != "USD" {
+		t.Errorf("batch default currency = %v, want USD", got)
+	}
+	if got := results[1].(map[string]interface{})["currency"]; got != "MXN" {
+		t.Errorf("batch explicit currency = %v, want MXN", got)
+	}
+}
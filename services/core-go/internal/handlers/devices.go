@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/fincore/core-go/internal/apierror"
+	"github.com/fincore/core-go/internal/logging"
+	"github.com/fincore/core-go/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+// deviceStore guarda los dispositivos conocidos de cada usuario
+var deviceStore security.DeviceStore = security.NewMemoryDeviceStore(0, 0)
+
+// SetDeviceStore reemplaza el store de dispositivos conocidos
+func SetDeviceStore(store security.DeviceStore) {
+	deviceStore = store
+}
+
+// deviceRisk indica si el dispositivo del request no es conocido para el
+// usuario. El fingerprint lo calcula el middleware de dispositivo a partir de
+// los headers; sin él no hay señal y no se marca. Se evalúa por usuario, de
+// modo que cada elemento de un lote se compara con los dispositivos de su
+// propio user_id. Si el store falla el dispositivo no se puede confirmar.
+func deviceRisk(ctx context.Context, fingerprint, userID string) bool {
+	if fingerprint == "" || userID == "" {
+		return false
+	}
+	known, err := deviceStore.Bind(ctx, userID, fingerprint)
+	if err != nil {
+		logging.FromStdContext(ctx).Warn("device store lookup failed", "user_id", userID, "error", err)
+		return true
+	}
+	return !known
+}
+
+// EnrollDevice registra un dispositivo como conocido para el usuario, por
+// ejemplo después de que el backend lo verificó con un segundo factor. Recibe
+// los mismos headers con los que el middleware calcula el fingerprint del
+// dispositivo. Debe montarse detrás de zeroTrustMiddleware y de un permiso
+// propio: quien lo invoca decide en qué dispositivos se confía.
+func EnrollDevice(c *gin.Context) {
+	var req struct {
+		UserID         string `json:"user_id" binding:"required"`
+		UserAgent      string `json:"user_agent"`
+		AcceptLanguage string `json:"accept_language"`
+		AcceptEncoding string `json:"accept_encoding"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+	if strings.TrimSpace(req.UserAgent) == "" && strings.TrimSpace(req.AcceptLanguage) == "" && strings.TrimSpace(req.AcceptEncoding) == "" {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidParameter, "user_agent, accept_language or accept_encoding is required")
+		return
+	}
+
+	fingerprint := securityManager.GenerateDeviceFingerprint(req.UserAgent, req.AcceptLanguage, req.AcceptEncoding)
+	if err := deviceStore.Remember(c.Request.Context(), req.UserID, fingerprint); err != nil {
+		if errors.Is(err, security.ErrDeviceStoreFull) {
+			apierror.Respond(c, http.StatusServiceUnavailable, apierror.DeviceStoreFull, "Device store is full")
+			return
+		}
+		logging.FromContext(c).Error("failed to enroll device", "user_id", req.UserID, "error", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.Internal, "Failed to enroll device")
+		return
+	}
+
+	value, _ := c.Get("service_claims")
+	enrolledBy := ""
+	if claims, ok := value.(*security.ServiceTokenClaims); ok {
+		enrolledBy = claims.Source
+	}
+	logging.FromContext(c).Info("device enrolled", "user_id", req.UserID, "enrolled_by", enrolledBy)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":            true,
+		"user_id":            req.UserID,
+		"device_fingerprint": fingerprint,
+	})
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/fincore/core-go/internal/apierror"
+	"github.com/fincore/core-go/internal/security"
+)
+
+// failingDeviceStore simula un store de dispositivos caído
+type failingDeviceStore struct{}
+
+func (failingDeviceStore) Bind(context.Context, string, string) (bool, error) {
+	return false, errors.New("connection refused")
+}
+
+func (failingDeviceStore) Remember(context.Context, string, string) error {
+	return errors.New("connection refused")
+}
+
+func TestDeviceRisk(t *testing.T) {
+	SetDeviceStore(security.NewMemoryDeviceStore(0, 0))
+	ctx := context.Background()
+
+	if deviceRisk(ctx, "laptop", "user-1") {
+		t.Error("first device of a user flagged")
+	}
+	if !deviceRisk(ctx, "phone", "user-1") {
+		t.Error("second device of a user not flagged")
+	}
+	if deviceRisk(ctx, "", "user-1") || deviceRisk(ctx, "phone", "") {
+		t.Error("request without fingerprint or user flagged")
+	}
+
+	// Sin poder confirmar el dispositivo se trata como desconocido
+	SetDeviceStore(failingDeviceStore{})
+	defer SetDeviceStore(security.NewMemoryDeviceStore(0, 0))
+	if !deviceRisk(ctx, "laptop", "user-1") {
+		t.Error("device not flagged when the store fails")
+	}
+}
+
+func TestEnrollDevice(t *testing.T) {
+	SetDeviceStore(security.NewMemoryDeviceStore(1, 0))
+	defer SetDeviceStore(security.NewMemoryDeviceStore(0, 0))
+
+	w, resp := doJSON(t, EnrollDevice, map[string]interface{}{"user_id": "user-1", "user_agent": "FinCoreApp/3.1"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%v)", w.Code, resp)
+	}
+	fingerprint := securityManager.GenerateDeviceFingerprint("FinCoreApp/3.1", "", "")
+	if resp["device_fingerprint"] != fingerprint {
+		t.Errorf("device_fingerprint = %v, want %s", resp["device_fingerprint"], fingerprint)
+	}
+	if deviceRisk(context.Background(), fingerprint, "user-1") {
+		t.Error("enrolled device flagged")
+	}
+
+	tests := []struct {
+		name   string
+		body   map[string]interface{}
+		status int
+		code   string
+	}{
+		{"sin usuario", map[string]interface{}{"user_agent": "FinCoreApp/3.1"}, http.StatusUnprocessableEntity, apierror.ValidationFailed},
+		{"sin headers", map[string]interface{}{"user_id": "user-1"}, http.StatusBadRequest, apierror.InvalidParameter},
+		{"store lleno", map[string]interface{}{"user_id": "user-2", "user_agent": "FinCoreApp/3.1"}, http.StatusServiceUnavailable, apierror.DeviceStoreFull},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w, resp := doJSON(t, EnrollDevice, tt.body); w.Code != tt.status || resp["code"] != tt.code {
+				t.Errorf("status/code = %d/%v, want %d/%s", w.Code, resp["code"], tt.status, tt.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrRateUnavailable indica que no hay tipo de cambio para el par de monedas
+var ErrRateUnavailable = errors.New("exchange rate unavailable")
+
+// ExchangeRateProvider obtiene el tipo de cambio para convertir montos de
+// una moneda a otra: monto_destino = monto_origen * tasa
+type ExchangeRateProvider interface {
+	Rate(ctx context.Context, from, to string) (decimal.Decimal, error)
+}
+
+// StaticRateProvider sirve tipos de cambio fijos, útil para pruebas y
+// entornos sin proveedor externo. Un par registrado también se usa invertido.
+type StaticRateProvider struct {
+	rates map[string]decimal.Decimal
+}
+
+// NewStaticRateProvider crea un proveedor con tasas indexadas por "ORIGEN/DESTINO"
+func NewStaticRateProvider(rates map[string]decimal.Decimal) *StaticRateProvider {
+	copied := make(map[string]decimal.Decimal, len(rates))
+	for pair, rate := range rates {
+		copied[pair] = rate
+	}
+	return &StaticRateProvider{rates: copied}
+}
+
+// Rate retorna la tasa directa, la inversa de la tasa registrada o 1 para la misma moneda
+func (p *StaticRateProvider) Rate(_ context.Context, from, to string) (decimal.Decimal, error) {
+	if from == to {
+		return decimal.NewFromInt(1), nil
+	}
+	if rate, ok := p.rates[from+"/"+to]; ok {
+		return rate, nil
+	}
+	if rate, ok := p.rates[to+"/"+from]; ok && !rate.IsZero() {
+		return decimal.NewFromInt(1).DivRound(rate, 10), nil
+	}
+	return decimal.Zero, fmt.Errorf("%w: %s/%s", ErrRateUnavailable, from, to)
+}
+
+// exchangeRateProvider convierte montos entre monedas en ValidateTransfer.
+// Sin proveedor configurado sólo se aceptan transferencias en la misma moneda.
+var exchangeRateProvider ExchangeRateProvider = NewStaticRateProvider(nil)
+
+// SetExchangeRateProvider configura el proveedor de tipos de cambio
+func SetExchangeRateProvider(provider ExchangeRateProvider) {
+	exchangeRateProvider = provider
+}
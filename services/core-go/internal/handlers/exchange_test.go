@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func useStaticRates(t *testing.T, rates map[string]string) {
+	t.Helper()
+	parsed := map[string]decimal.Decimal{}
+	for pair, rate := range rates {
+		parsed[pair] = decimal.RequireFromString(rate)
+	}
+	previous := exchangeRateProvider
+	SetExchangeRateProvider(NewStaticRateProvider(parsed))
+	t.Cleanup(func() { SetExchangeRateProvider(previous) })
+}
+
+func TestValidateTransferConversion(t *testing.T) {
+	useStaticRates(t, map[string]string{"USD/MXN": "17.1234", "EUR/MXN": "20"})
+
+	tests := []struct {
+		name      string
+		currency  string
+		to        string
+		amount    string
+		rate      string
+		converted string
+	}{
+		{"par directo", "USD", "MXN", "100.00", "17.1234", "1712.34"},
+		{"par inverso", "MXN", "EUR", "1000", "0.05", "50"},
+		{"misma moneda", "MXN", "mxn", "250.50", "1", "250.5"},
+		{"redondeo a centavos", "USD", "MXN", "0.333", "17.1234", "5.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, resp := doJSON(t, ValidateTransfer, map[string]interface{}{
+				"from_account": "acc-1",
+				"to_account":   "acc-2",
+				"amount":       tt.amount,
+				"currency":     tt.currency,
+				"to_currency":  tt.to,
+			})
+			if w.Code != http.StatusOK || resp["is_valid"] != true {
+				t.Fatalf("status = %d, resp = %v", w.Code, resp)
+			}
+
+			rate := decimal.RequireFromString(resp["rate"].(string))
+			converted := decimal.RequireFromString(resp["converted_amount"].(string))
+			if !rate.Equal(decimal.RequireFromString(tt.rate)) {
+				t.Errorf("rate = %s, want %s", rate, tt.rate)
+			}
+			if !converted.Equal(decimal.RequireFromString(tt.converted)) {
+				t.Errorf("converted_amount = %s, want %s", converted, tt.converted)
+			}
+		})
+	}
+}
+
+func TestValidateTransferMissingRate(t *testing.T) {
+	useStaticRates(t, map[string]string{"USD/MXN": "17"})
+
+	_, resp := doJSON(t, ValidateTransfer, map[string]interface{}{
+		"from_account": "acc-1",
+		"to_account":   "acc-2",
+		"amount":       "10",
+		"currency":     "USD",
+		"to_currency":  "JPY",
+	})
+	if resp["is_valid"] != false {
+		t.Fatalf("is_valid = %v, want false", resp["is_valid"])
+	}
+	validations := resp["validations"].([]interface{})
+	if len(validations) != 1 || validations[0] != "No exchange rate available for USD/JPY" {
+		t.Errorf("validations = %v", validations)
+	}
+	if _, ok := resp["converted_amount"]; ok {
+		t.Error("converted_amount must be omitted without a rate")
+	}
+}
+
+func TestValidateTransferWithoutConversion(t *testing.T) {
+	_, resp := doJSON(t, ValidateTransfer, map[string]interface{}{
+		"from_account": "acc-1",
+		"to_account":   "acc-2",
+		"amount":       "10",
+	})
+	if resp["is_valid"] != true {
+		t.Errorf("is_valid = %v, want true", resp["is_valid"])
+	}
+	if _, ok := resp["rate"]; ok {
+		t.Error("rate must be omitted when to_currency is not given")
+	}
+}
@@ -9,9 +9,23 @@ Maneja:
 package handlers
 
 import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/fincore/core-go/internal/apierror"
+	"github.com/fincore/core-go/internal/finance"
+	"github.com/fincore/core-go/internal/ledger"
+	"github.com/fincore/core-go/internal/logging"
+	"github.com/fincore/core-go/internal/metrics"
+	"github.com/fincore/core-go/internal/security"
+	"github.com/fincore/core-go/internal/tracing"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
@@ -19,36 +33,80 @@ import (
 
 // Transaction representa una transacción financiera
 type Transaction struct {
-	ID              string          `json:"id"`
-	Type            string          `json:"type"`
-	UserID          string          `json:"user_id"`
-	ProjectID       string          `json:"project_id,omitempty"`
-	InvestmentID    string          `json:"investment_id,omitempty"`
-	Amount          decimal.Decimal `json:"amount"`
-	Currency        string          `json:"currency"`
-	Status          string          `json:"status"`
-	IntegrityHash   string          `json:"integrity_hash"`
-	ProcessedAt     time.Time       `json:"processed_at"`
-	ProcessingTime  int64           `json:"processing_time_ms"`
+	ID             string          `json:"id"`
+	Type           string          `json:"type"`
+	UserID         string          `json:"user_id"`
+	ProjectID      string          `json:"project_id,omitempty"`
+	InvestmentID   string          `json:"investment_id,omitempty"`
+	Amount         decimal.Decimal `json:"amount"`
+	Currency       string          `json:"currency"`
+	Status         string          `json:"status"`
+	IntegrityHash  string          `json:"integrity_hash"`
+	ProcessedAt    time.Time       `json:"processed_at"`
+	ProcessingTime int64           `json:"processing_time_ms"`
+
+	// RejectionReason explica por qué una transacción de un lote fue rechazada
+	RejectionReason string `json:"rejection_reason,omitempty"`
+
+	// ReversesID es el ID de la transacción que esta reversa compensa
+	ReversesID string `json:"reverses_id,omitempty"`
+
+	// CreateLedgerEntry indica si la transacción debe tener entrada en el
+	// ledger; la conciliación sólo reclama la entrada de estas
+	CreateLedgerEntry bool `json:"create_ledger_entry"`
+
+	// Version aumenta con cada actualización; las actualizaciones la
+	// comparan para no pisar un cambio concurrente
+	Version int64 `json:"version"`
+
+	// UserIDIndex es el índice ciego de UserID cuando el store lo guarda
+	// cifrado; sólo lo usa el store para filtrar por usuario
+	UserIDIndex string `json:"-"`
+}
+
+// BatchItemError describe el error de validación de un elemento de un lote
+type BatchItemError struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
 }
 
 // LedgerEntry representa una entrada en el ledger inmutable
-type LedgerEntry struct {
-	SequenceNumber int64           `json:"sequence_number"`
-	PreviousHash   string          `json:"previous_hash"`
-	EntryHash      string          `json:"entry_hash"`
-	EntryType      string          `json:"entry_type"`
-	Amount         decimal.Decimal `json:"amount"`
-	Currency       string          `json:"currency"`
-	Description    string          `json:"description"`
-	CreatedAt      time.Time       `json:"created_at"`
-	IsVerified     bool            `json:"is_verified"`
+type LedgerEntry = ledger.LedgerEntry
+
+// securityManager calcula los hashes de integridad de las transacciones
+var securityManager *security.SecurityManager
+
+// SetSecurityManager configura el SecurityManager usado por los handlers
+func SetSecurityManager(sm *security.SecurityManager) {
+	securityManager = sm
+}
+
+// ledgerChain encadena y persiste las entradas del ledger. Por defecto usa un
+// store en memoria; main lo reemplaza por el store configurado.
+var ledgerChain = mustLedgerChain(ledger.NewInMemoryLedgerStore(ledger.DefaultInMemoryCapacity))
+
+func mustLedgerChain(store ledger.LedgerStore) *ledger.LedgerChain {
+	chain, err := ledger.NewLedgerChain(context.Background(), store)
+	if err != nil {
+		panic(err)
+	}
+	return chain
+}
+
+// SetLedgerChain reemplaza la cadena del ledger usada por los handlers
+func SetLedgerChain(chain *ledger.LedgerChain) {
+	ledgerChain = chain
 }
 
 // ProcessTransaction procesa una transacción de forma concurrente
 func ProcessTransaction(c *gin.Context) {
 	startTime := time.Now()
 
+	// El span de validación se cierra al empezar el procesamiento; el defer
+	// cubre los retornos anticipados (End es idempotente)
+	_, validateSpan := tracing.Start(c.Request.Context(), "transaction.validate")
+	defer validateSpan.End()
+
 	var req struct {
 		Type         string          `json:"type" binding:"required"`
 		UserID       string          `json:"user_id" binding:"required"`
@@ -56,30 +114,113 @@ func ProcessTransaction(c *gin.Context) {
 		InvestmentID string          `json:"investment_id"`
 		Amount       decimal.Decimal `json:"amount" binding:"required"`
 		Currency     string          `json:"currency"`
+		// CreateLedgerEntry registra además la transacción en el ledger
+		CreateLedgerEntry bool `json:"create_ledger_entry"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request",
-			"details": err.Error(),
+		respondBindingError(c, err)
+		return
+	}
+
+	// Validar tipo contra la lista permitida (sin distinguir mayúsculas)
+	txType, ok := normalizeTransactionType(req.Type)
+	if !ok {
+		apierror.RespondWith(c, http.StatusBadRequest, apierror.InvalidTransactionType, "Invalid transaction type", gin.H{
+			"allowed_types": allowedTransactionTypeList(),
 		})
 		return
 	}
+	req.Type = txType
 
 	// Validar monto positivo
 	if req.Amount.LessThanOrEqual(decimal.Zero) {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Amount must be positive",
+		apierror.Respond(c, http.StatusBadRequest, apierror.AmountNotPositive, "Amount must be positive")
+		return
+	}
+
+	// La moneda forma parte de la clave de velocidad: un código inventado
+	// permitiría repartir el volumen en claves nuevas
+	req.Currency = normalizarMoneda(req.Currency)
+	if !esMonedaDeTransaccion(req.Currency) {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidCurrency, ErrInvalidCurrency.Error())
+		return
+	}
+
+	// Ajustar el monto a los decimales que admite la moneda
+	amount, err := aplicarPrecisionMoneda(req.Amount, req.Currency)
+	if err != nil {
+		apierror.RespondWith(c, http.StatusBadRequest, apierror.AmountPrecisionExceeded, "Amount exceeds currency precision", gin.H{
+			"details":      err.Error(),
+			"max_decimals": decimalesDeMoneda(req.Currency),
 		})
 		return
 	}
+	req.Amount = amount
+
+	// Monto máximo por tipo y dispositivo, igual que en los lotes
+	if violation := checkTransactionLimits(req.Type, req.Amount, deviceRisk(c.Request.Context(), c.GetString("device_fingerprint"), req.UserID)); violation != nil {
+		respondPolicyViolation(c, violation)
+		return
+	}
+
+	validateSpan.End()
+	ctx, processSpan := tracing.Start(c.Request.Context(), "transaction.process")
+	defer processSpan.End()
+
+	// Un reintento con la misma clave y el mismo request retorna la
+	// transacción original; la clave es propia de cada cliente
+	idempotencyKey := c.GetHeader(IdempotencyKeyHeader)
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		apierror.Respond(c, http.StatusBadRequest, apierror.IdempotencyKeyTooLong, "Idempotency-Key is too long")
+		return
+	}
+	if idempotencyKey != "" {
+		idempotencyKey = idempotencyStoreKey(c.GetString("client_id"), req.UserID, idempotencyKey)
+		requestHash, err := idempotencyRequestHash(map[string]interface{}{
+			"type":                req.Type,
+			"user_id":             req.UserID,
+			"project_id":          req.ProjectID,
+			"investment_id":       req.InvestmentID,
+			"amount":              req.Amount.String(),
+			"currency":            req.Currency,
+			"create_ledger_entry": req.CreateLedgerEntry,
+		})
+		if err != nil {
+			logging.FromContext(c).Error("failed to hash idempotent request", "error", err)
+			apierror.Respond(c, http.StatusInternalServerError, apierror.Internal, "Failed to process transaction")
+			return
+		}
+		if respondIdempotentRequest(c, idempotencyKey, requestHash) {
+			return
+		}
+		if !idempotencyStore.Reserve(idempotencyKey, requestHash) {
+			if respondIdempotentRequest(c, idempotencyKey, requestHash) {
+				return
+			}
+			apierror.Respond(c, http.StatusConflict, apierror.IdempotencyKeyInUse, "A request with this Idempotency-Key is already in progress")
+			return
+		}
+	}
 
-	// Establecer currency por defecto
-	if req.Currency == "" {
-		req.Currency = "MXN"
+	// Volumen acumulado del usuario dentro de la ventana
+	violation, err := reserveVelocity(ctx, req.UserID, req.Currency, req.Amount)
+	if err != nil || violation != nil {
+		if idempotencyKey != "" {
+			idempotencyStore.Release(idempotencyKey)
+		}
+		if err != nil {
+			if respondClientClosed(c, err) {
+				return
+			}
+			logging.FromContext(c).Error("failed to check velocity limit", "user_id", req.UserID, "error", err)
+			apierror.Respond(c, http.StatusInternalServerError, apierror.Internal, "Failed to check velocity limit")
+			return
+		}
+		respondPolicyViolation(c, violation)
+		return
 	}
 
-	// Procesar transacción (simulado - en producción conectaría a BD)
 	transaction := Transaction{
 		ID:           uuid.New().String(),
 		Type:         req.Type,
@@ -89,18 +230,233 @@ func ProcessTransaction(c *gin.Context) {
 		Amount:       req.Amount,
 		Currency:     req.Currency,
 		Status:       "completed",
-		ProcessedAt:  time.Now(),
+		Version:      initialTransactionVersion,
+		// CreateLedgerEntry se guarda para que la conciliación sepa si falta
+		// una entrada o si nunca se pidió
+		CreateLedgerEntry: req.CreateLedgerEntry,
+		// PostgreSQL guarda microsegundos; truncar para que el hash siga
+		// verificando tras leer la transacción del store
+		ProcessedAt: time.Now().UTC().Truncate(time.Microsecond),
 	}
 
+	// Un monto sobre el umbral queda pendiente hasta reunir los aprobadores
+	if requiresApproval(transaction.Amount) {
+		transaction.Status = TransactionStatusPendingApproval
+	}
+
+	// Sellar la transacción para detectar manipulaciones posteriores
+	transaction.IntegrityHash = calculateTransactionHash(transaction)
+
 	// Calcular tiempo de procesamiento
 	processingTime := time.Since(startTime).Milliseconds()
 	transaction.ProcessingTime = processingTime
 
-	c.JSON(http.StatusOK, gin.H{
+	insertCtx, insertSpan := tracing.Start(ctx, "transaction_store.insert")
+	err = transactionStore.Insert(insertCtx, transaction)
+	tracing.EndSpan(insertSpan, err)
+	if err != nil {
+		if idempotencyKey != "" {
+			idempotencyStore.Release(idempotencyKey)
+		}
+		releaseVelocity(ctx, transaction)
+		if respondClientClosed(c, err) {
+			return
+		}
+		logging.FromContext(c).Error("failed to persist transaction", "transaction_id", transaction.ID, "error", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.Internal, "Failed to persist transaction")
+		return
+	}
+
+	// La entrada del ledger y el webhook esperan a la última aprobación
+	if transaction.Status == TransactionStatusPendingApproval {
+		approvalStore.Open(transaction.ID, req.CreateLedgerEntry)
+		if idempotencyKey != "" {
+			idempotencyStore.Complete(idempotencyKey, transaction)
+		}
+		metrics.TransactionsProcessed.WithLabelValues(transaction.Status).Inc()
+		respondPendingApproval(c, transaction, nil)
+		return
+	}
+
+	response := gin.H{
 		"success":     true,
 		"transaction": transaction,
 		"message":     "Transaction processed successfully",
-	})
+	}
+
+	// El ledger es inmutable, así que si su escritura falla se compensa
+	// eliminando la transacción recién guardada. La compensación no se
+	// cancela aunque el cliente se haya desconectado.
+	if req.CreateLedgerEntry {
+		ledgerCtx, ledgerSpan := tracing.Start(ctx, "ledger.append")
+		entry, created, err := ledgerChain.AppendOnce(ledgerCtx, ledgerEntryForTransaction(transaction))
+		tracing.EndSpan(ledgerSpan, err)
+		if err != nil {
+			deleteCtx, deleteSpan := tracing.Start(context.WithoutCancel(ctx), "transaction_store.delete")
+			deleteErr := transactionStore.Delete(deleteCtx, transaction.ID)
+			tracing.EndSpan(deleteSpan, deleteErr)
+			if deleteErr != nil {
+				logging.FromContext(c).Error("failed to compensate transaction", "transaction_id", transaction.ID, "error", deleteErr)
+			}
+			if idempotencyKey != "" {
+				idempotencyStore.Release(idempotencyKey)
+			}
+			if deleteErr == nil {
+				releaseVelocity(ctx, transaction)
+			}
+			if respondLedgerUnavailable(c, err) {
+				return
+			}
+			if respondClientClosed(c, err) {
+				return
+			}
+			logging.FromContext(c).Error("failed to create ledger entry for transaction", "transaction_id", transaction.ID, "error", err)
+			apierror.Respond(c, http.StatusInternalServerError, apierror.Internal, "Failed to create ledger entry")
+			return
+		}
+		if created {
+			metrics.LedgerEntriesCreated.Inc()
+		}
+		response["ledger_sequence"] = entry.SequenceNumber
+	}
+
+	if idempotencyKey != "" {
+		idempotencyStore.Complete(idempotencyKey, transaction)
+	}
+
+	// Avisar a los sistemas suscritos sin esperar la entrega
+	if webhookDispatcher != nil {
+		webhookDispatcher.Enqueue(WebhookEventTransactionCompleted, transaction)
+	}
+
+	metrics.TransactionsProcessed.WithLabelValues(transaction.Status).Inc()
+	metrics.ProcessingDuration.WithLabelValues("single").Observe(time.Since(startTime).Seconds())
+
+	attachReceipt(c, response, transaction)
+	c.JSON(http.StatusOK, response)
+}
+
+// releaseVelocity devuelve al volumen del usuario el monto de una transacción
+// que no se procesó. Como la compensación, no se cancela si el cliente se
+// desconectó.
+func releaseVelocity(ctx context.Context, tx Transaction) {
+	if !velocityLimit.IsPositive() {
+		return
+	}
+	releaseCtx, span := tracing.Start(context.WithoutCancel(ctx), "velocity_store.release")
+	err := velocityStore.Release(releaseCtx, velocityKey(tx.UserID, tx.Currency), tx.Amount)
+	tracing.EndSpan(span, err)
+	if err != nil {
+		logging.FromStdContext(ctx).Error("failed to release velocity reservation", "transaction_id", tx.ID, "error", err)
+	}
+}
+
+// transactionLedgerRef es el client_ref de la entrada del ledger de una
+// transacción; impide registrarla dos veces y permite encontrarla después
+func transactionLedgerRef(transactionID string) string {
+	return "transaction:" + transactionID
+}
+
+// ledgerEntryForTransaction deriva la entrada del ledger de una transacción
+func ledgerEntryForTransaction(tx Transaction) LedgerEntry {
+	return LedgerEntry{
+		EntryType:   tx.Type,
+		Amount:      tx.Amount,
+		Currency:    tx.Currency,
+		Description: fmt.Sprintf("%s transaction %s for user %s", tx.Type, tx.ID, tx.UserID),
+		CreatedAt:   tx.ProcessedAt,
+		IsVerified:  true,
+		ClientRef:   transactionLedgerRef(tx.ID),
+	}
+}
+
+// transactionIntegrityData retorna los campos canónicos que cubre el hash de integridad
+func transactionIntegrityData(tx Transaction) map[string]interface{} {
+	data := map[string]interface{}{
+		"id":           tx.ID,
+		"type":         tx.Type,
+		"user_id":      tx.UserID,
+		"amount":       tx.Amount.String(),
+		"currency":     tx.Currency,
+		"processed_at": tx.ProcessedAt.UTC().Format(time.RFC3339Nano),
+	}
+	// Sólo las reversas llevan reverses_id; así el hash del resto no cambia
+	if tx.ReversesID != "" {
+		data["reverses_id"] = tx.ReversesID
+	}
+	return data
+}
+
+// calculateTransactionHash calcula el HMAC de integridad de la transacción
+func calculateTransactionHash(tx Transaction) string {
+	return securityManager.CalculateIntegrityHMAC(transactionIntegrityData(tx))
+}
+
+// verifyTransactionIntegrity recalcula el HMAC y lo compara con el almacenado
+func verifyTransactionIntegrity(tx Transaction) bool {
+	return securityManager.VerifyIntegrityHMAC(transactionIntegrityData(tx), tx.IntegrityHash)
+}
+
+// attachReceipt agrega a la respuesta el comprobante firmado de la
+// transacción, con el que el cliente puede demostrar a un tercero lo que se
+// procesó. La transacción ya está guardada, así que si no puede firmarse se
+// omite el comprobante en lugar de fallar la respuesta.
+func attachReceipt(c *gin.Context, response gin.H, tx Transaction) {
+	receipt, err := securityManager.GenerateReceipt(tx)
+	if err != nil {
+		logging.FromContext(c).Error("failed to sign transaction receipt", "transaction_id", tx.ID, "error", err)
+		return
+	}
+	response["receipt"] = receipt
+}
+
+// respondIdempotentRequest responde a un reintento de una clave ya completada:
+// con la transacción original si el request coincide y con 422 si la clave
+// se reutilizó con otro request. Retorna false si la clave no está completada.
+func respondIdempotentRequest(c *gin.Context, key, requestHash string) bool {
+	original, originalHash, ok := idempotencyStore.Lookup(key)
+	if !ok {
+		return false
+	}
+	if originalHash != requestHash {
+		apierror.Respond(c, http.StatusUnprocessableEntity, apierror.IdempotencyKeyReused, "Idempotency-Key was already used with a different request")
+		return true
+	}
+	respondIdempotentReplay(c, original)
+	return true
+}
+
+// respondIdempotentReplay responde un reintento con la transacción original
+func respondIdempotentReplay(c *gin.Context, transaction Transaction) {
+	// Una transacción pendiente pudo aprobarse después del request original
+	if transaction.Status == TransactionStatusPendingApproval {
+		if current, err := transactionStore.Get(c.Request.Context(), transaction.ID); err == nil {
+			transaction = current
+		} else {
+			logging.FromContext(c).Warn("failed to refresh pending transaction for replay", "transaction_id", transaction.ID, "error", err)
+		}
+	}
+	if transaction.Status == TransactionStatusPendingApproval {
+		c.Header("Idempotent-Replayed", "true")
+		respondPendingApproval(c, transaction, nil)
+		return
+	}
+
+	response := gin.H{
+		"success":     true,
+		"transaction": transaction,
+		"message":     "Transaction processed successfully",
+	}
+	// Si la solicitud original creó la entrada del ledger se vuelve a informar
+	if entry, err := ledgerChain.GetByClientRef(c.Request.Context(), transactionLedgerRef(transaction.ID)); err == nil {
+		response["ledger_sequence"] = entry.SequenceNumber
+	} else if !errors.Is(err, ledger.ErrEntryNotFound) {
+		logging.FromContext(c).Warn("failed to look up ledger entry for replay", "transaction_id", transaction.ID, "error", err)
+	}
+
+	attachReceipt(c, response, transaction)
+	c.Header("Idempotent-Replayed", "true")
+	c.JSON(http.StatusOK, response)
 }
 
 // VerifyTransaction verifica el estado de una transacción
@@ -109,104 +465,418 @@ func VerifyTransaction(c *gin.Context) {
 
 	// Validar UUID
 	if _, err := uuid.Parse(transactionID); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid transaction ID",
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidTransactionID, "Invalid transaction ID")
+		return
+	}
+
+	ctx, span := tracing.Start(c.Request.Context(), "transaction_store.get")
+	transaction, err := transactionStore.Get(ctx, transactionID)
+	tracing.EndSpan(span, err)
+	if errors.Is(err, ErrTransactionNotFound) {
+		apierror.Respond(c, http.StatusNotFound, apierror.TransactionNotFound, "Transaction not found")
+		return
+	}
+	if err != nil {
+		if respondClientClosed(c, err) {
+			return
+		}
+		logging.FromContext(c).Error("failed to read transaction", "transaction_id", transactionID, "error", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.Internal, "Failed to read transaction")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"transaction_id":  transaction.ID,
+		"status":          transaction.Status,
+		"integrity_valid": verifyTransactionIntegrity(transaction),
+		"verified_at":     time.Now(),
+	})
+}
+
+// TransactionVerification es el resultado de una transacción en VerifyTransactions
+type TransactionVerification struct {
+	Status         string `json:"status"`
+	IntegrityValid bool   `json:"integrity_valid"`
+}
+
+// VerifyTransactions verifica varias transacciones en una sola consulta al
+// store. Los IDs que no son UUID se informan en invalid_ids y los que no
+// existen en not_found; el resto aparece en transactions indexado por ID.
+func VerifyTransactions(c *gin.Context) {
+	var req struct {
+		IDs []string `json:"ids" binding:"required,min=1"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+	if len(req.IDs) > maxBatchSize {
+		apierror.RespondWith(c, http.StatusRequestEntityTooLarge, apierror.BatchTooLarge, "Too many transaction IDs", gin.H{
+			"max_batch_size": maxBatchSize,
 		})
 		return
 	}
 
-	// En producción, buscaría en la BD
+	// Los IDs se normalizan a la forma canónica del UUID y se deduplican
+	invalidIDs := []string{}
+	ids := make([]string, 0, len(req.IDs))
+	seen := make(map[string]struct{}, len(req.IDs))
+	for _, raw := range req.IDs {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			invalidIDs = append(invalidIDs, raw)
+			continue
+		}
+		id := parsed.String()
+		if _, dup := seen[id]; dup {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+
+	found := map[string]Transaction{}
+	if len(ids) > 0 {
+		ctx, span := tracing.Start(c.Request.Context(), "transaction_store.get_many")
+		var err error
+		found, err = transactionStore.GetMany(ctx, ids)
+		tracing.EndSpan(span, err)
+		if err != nil {
+			if respondClientClosed(c, err) {
+				return
+			}
+			logging.FromContext(c).Error("failed to read transactions", "count", len(ids), "error", err)
+			apierror.Respond(c, http.StatusInternalServerError, apierror.Internal, "Failed to read transactions")
+			return
+		}
+	}
+
+	results := make(map[string]TransactionVerification, len(found))
+	notFound := []string{}
+	for _, id := range ids {
+		transaction, ok := found[id]
+		if !ok {
+			notFound = append(notFound, id)
+			continue
+		}
+		results[id] = TransactionVerification{
+			Status:         transaction.Status,
+			IntegrityValid: verifyTransactionIntegrity(transaction),
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"transaction_id": transactionID,
-		"status":        "verified",
-		"verified_at":   time.Now(),
+		"transactions": results,
+		"not_found":    notFound,
+		"invalid_ids":  invalidIDs,
+		"verified_at":  time.Now(),
 	})
 }
 
-// BatchProcess procesa múltiples transacciones concurrentemente
+const (
+	// DefaultListLimit es el tamaño de página cuando no se indica limit
+	DefaultListLimit = 20
+	// MaxListLimit es el tamaño de página máximo aceptado
+	MaxListLimit = 100
+)
+
+// ListTransactions lista las transacciones de un usuario, de la más reciente a
+// la más antigua, con paginación por cursor
+func ListTransactions(c *gin.Context) {
+	userID := c.Query("user_id")
+	if userID == "" {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidParameter, "user_id is required")
+		return
+	}
+
+	limit := DefaultListLimit
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > MaxListLimit {
+			apierror.RespondWith(c, http.StatusBadRequest, apierror.InvalidLimit, "Invalid limit", gin.H{
+				"max_limit": MaxListLimit,
+			})
+			return
+		}
+		limit = n
+	}
+
+	filter := TransactionFilter{
+		UserID: userID,
+		Status: c.Query("status"),
+		// Pedir un elemento extra para saber si hay otra página
+		Limit: limit + 1,
+	}
+	if raw := c.Query("cursor"); raw != "" {
+		cursor, err := decodeTransactionCursor(raw)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, apierror.InvalidCursor, "Invalid cursor")
+			return
+		}
+		filter.After = &cursor
+	}
+
+	ctx, span := tracing.Start(c.Request.Context(), "transaction_store.list")
+	page, err := transactionStore.List(ctx, filter)
+	tracing.EndSpan(span, err)
+	if err != nil {
+		if respondClientClosed(c, err) {
+			return
+		}
+		logging.FromContext(c).Error("failed to list transactions", "user_id", userID, "error", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.Internal, "Failed to list transactions")
+		return
+	}
+
+	var nextCursor string
+	if len(page) > limit {
+		page = page[:limit]
+		last := page[len(page)-1]
+		nextCursor = encodeTransactionCursor(TransactionCursor{ProcessedAt: last.ProcessedAt, ID: last.ID})
+	}
+	if page == nil {
+		page = []Transaction{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"transactions": page,
+		"next_cursor":  nextCursor,
+	})
+}
+
+// encodeTransactionCursor serializa el cursor como "processed_at|id" en base64 URL-safe
+func encodeTransactionCursor(cursor TransactionCursor) string {
+	raw := cursor.ProcessedAt.UTC().Format(time.RFC3339Nano) + "|" + cursor.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeTransactionCursor interpreta un cursor emitido por encodeTransactionCursor
+func decodeTransactionCursor(value string) (TransactionCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return TransactionCursor{}, err
+	}
+	processedAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return TransactionCursor{}, errors.New("malformed cursor")
+	}
+	at, err := time.Parse(time.RFC3339Nano, processedAt)
+	if err != nil {
+		return TransactionCursor{}, err
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return TransactionCursor{}, err
+	}
+	return TransactionCursor{ProcessedAt: at, ID: id}, nil
+}
+
+// batchTransaction es una transacción dentro de un lote
+type batchTransaction struct {
+	Type         string          `json:"type"`
+	UserID       string          `json:"user_id"`
+	ProjectID    string          `json:"project_id"`
+	InvestmentID string          `json:"investment_id"`
+	Amount       decimal.Decimal `json:"amount"`
+	Currency     string          `json:"currency"`
+}
+
+// BatchProcess procesa múltiples transacciones con un pool de workers acotado
 func BatchProcess(c *gin.Context) {
 	startTime := time.Now()
 
+	// Durante el apagado no se aceptan lotes nuevos; los que ya empezaron
+	// se completan antes de cerrar el servidor
+	if !batchJobs.begin() {
+		apierror.Respond(c, http.StatusServiceUnavailable, apierror.ShuttingDown, "Service is shutting down")
+		return
+	}
+	defer batchJobs.end()
+
 	var req struct {
-		Transactions []struct {
-			Type         string          `json:"type"`
-			UserID       string          `json:"user_id"`
-			ProjectID    string          `json:"project_id"`
-			InvestmentID string          `json:"investment_id"`
-			Amount       decimal.Decimal `json:"amount"`
-			Currency     string          `json:"currency"`
-		} `json:"transactions" binding:"required"`
+		Transactions []batchTransaction `json:"transactions" binding:"required"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request",
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request")
+		return
+	}
+
+	if len(req.Transactions) > maxBatchSize {
+		apierror.RespondWith(c, http.StatusRequestEntityTooLarge, apierror.BatchTooLarge, "Batch too large", gin.H{
+			"max_batch_size": maxBatchSize,
 		})
 		return
 	}
 
-	// Procesar en paralelo usando goroutines
-	results := make([]Transaction, len(req.Transactions))
-	done := make(chan int, len(req.Transactions))
-
-	for i, tx := range req.Transactions {
-		go func(index int, txData struct {
-			Type         string
-			UserID       string
-			ProjectID    string
-			InvestmentID string
-			Amount       decimal.Decimal
-			Currency     string
-		}) {
-			currency := txData.Currency
-			if currency == "" {
-				currency = "MXN"
-			}
+	// El lote no tiene user_id propio: el dispositivo se compara por elemento
+	fingerprint := c.GetString("device_fingerprint")
 
-			results[index] = Transaction{
-				ID:           uuid.New().String(),
-				Type:         txData.Type,
-				UserID:       txData.UserID,
-				ProjectID:    txData.ProjectID,
-				InvestmentID: txData.InvestmentID,
-				Amount:       txData.Amount,
-				Currency:     currency,
-				Status:       "completed",
-				ProcessedAt:  time.Now(),
-			}
-			done <- index
-		}(i, struct {
-			Type         string
-			UserID       string
-			ProjectID    string
-			InvestmentID string
-			Amount       decimal.Decimal
-			Currency     string
-		}{
-			Type:         tx.Type,
-			UserID:       tx.UserID,
-			ProjectID:    tx.ProjectID,
-			InvestmentID: tx.InvestmentID,
-			Amount:       tx.Amount,
-			Currency:     tx.Currency,
-		})
+	// Cada worker escribe en results[index] para preservar el orden de entrada.
+	// Si el cliente se desconecta el pool deja de tomar elementos.
+	results := make([]Transaction, len(req.Transactions))
+	err := runWorkerPool(c.Request.Context(), len(req.Transactions), func(index int) {
+		results[index] = processBatchTransaction(c.Request.Context(), req.Transactions[index], fingerprint)
+	})
+	if err != nil {
+		if respondClientClosed(c, err) {
+			return
+		}
+		apierror.Respond(c, http.StatusGatewayTimeout, apierror.ProcessingTimeout, "Batch processing timed out")
+		return
 	}
 
-	// Esperar a que terminen todas
-	for i := 0; i < len(req.Transactions); i++ {
-		<-done
+	// Reportar los elementos rechazados sin afectar a los válidos
+	itemErrors := []BatchItemError{}
+	for i, tx := range results {
+		if tx.Status == "rejected" {
+			itemErrors = append(itemErrors, BatchItemError{Index: i, Error: tx.RejectionReason})
+		}
 	}
 
-	processingTime := time.Since(startTime).Milliseconds()
+	elapsed := time.Since(startTime)
+	processingTime := elapsed.Milliseconds()
+
+	metrics.BatchSize.Observe(float64(len(results)))
+	metrics.ProcessingDuration.WithLabelValues("batch").Observe(elapsed.Seconds())
+	metrics.TransactionsProcessed.WithLabelValues("completed").Add(float64(len(results) - len(itemErrors)))
+	metrics.TransactionsProcessed.WithLabelValues("rejected").Add(float64(len(itemErrors)))
 
 	c.JSON(http.StatusOK, gin.H{
-		"success":            true,
+		"success":            len(itemErrors) == 0,
 		"transactions":       results,
-		"total_processed":    len(results),
+		"errors":             itemErrors,
+		"total_processed":    len(results) - len(itemErrors),
+		"total_rejected":     len(itemErrors),
 		"processing_time_ms": processingTime,
 	})
 }
 
+// processBatchTransaction valida y construye la transacción de un elemento
+// del lote; los elementos inválidos o que incumplen las políticas de
+// ProcessTransaction se marcan como "rejected" con su motivo. fingerprint es
+// el dispositivo del request, que se compara con los del user_id del elemento.
+func processBatchTransaction(ctx context.Context, txData batchTransaction, fingerprint string) Transaction {
+	currency := normalizarMoneda(txData.Currency)
+
+	txType, _ := normalizeTransactionType(txData.Type)
+	transaction := Transaction{
+		ID:           uuid.New().String(),
+		Type:         txType,
+		UserID:       txData.UserID,
+		ProjectID:    txData.ProjectID,
+		InvestmentID: txData.InvestmentID,
+		Amount:       txData.Amount,
+		Currency:     currency,
+		Status:       "completed",
+		Version:      initialTransactionVersion,
+		ProcessedAt:  time.Now(),
+	}
+
+	if err := validateTransactionFields(txData.Type, txData.UserID, txData.Amount, currency); err != nil {
+		transaction.Status = "rejected"
+		transaction.RejectionReason = err.Error()
+		return transaction
+	}
+
+	amount, err := aplicarPrecisionMoneda(txData.Amount, currency)
+	if err != nil {
+		transaction.Status = "rejected"
+		transaction.RejectionReason = err.Error()
+		return transaction
+	}
+	transaction.Amount = amount
+
+	if violation := checkTransactionLimits(txType, amount, deviceRisk(ctx, fingerprint, transaction.UserID)); violation != nil {
+		transaction.Status = "rejected"
+		transaction.RejectionReason = violation.message
+		return transaction
+	}
+	// Un lote no abre aprobaciones: el monto debe enviarse individualmente
+	if requiresApproval(amount) {
+		transaction.Status = "rejected"
+		transaction.RejectionReason = "Amount requires approval; submit it as an individual transaction"
+		return transaction
+	}
+	violation, err := reserveVelocity(ctx, transaction.UserID, currency, amount)
+	if err != nil {
+		transaction.Status = "rejected"
+		transaction.RejectionReason = "Failed to check velocity limit"
+		return transaction
+	}
+	if violation != nil {
+		transaction.Status = "rejected"
+		transaction.RejectionReason = violation.message
+		return transaction
+	}
+
+	transaction.IntegrityHash = calculateTransactionHash(transaction)
+	return transaction
+}
+
+// validateTransactionFields aplica las validaciones comunes a toda transacción
+func validateTransactionFields(txType, userID string, amount decimal.Decimal, currency string) error {
+	if strings.TrimSpace(txType) == "" {
+		return errors.New("type is required")
+	}
+	if _, ok := normalizeTransactionType(txType); !ok {
+		return fmt.Errorf("invalid transaction type %q (allowed: %s)", txType, strings.Join(allowedTransactionTypeList(), ", "))
+	}
+	if strings.TrimSpace(userID) == "" {
+		return errors.New("user_id is required")
+	}
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return errors.New("Amount must be positive")
+	}
+	if !esMonedaDeTransaccion(currency) {
+		return ErrInvalidCurrency
+	}
+	return nil
+}
+
+// PingLedger comprueba que el store del ledger esté disponible
+func PingLedger(ctx context.Context) error {
+	return ledgerChain.Ping(ctx)
+}
+
+// LedgerCircuitState retorna el estado del circuit breaker del ledger; ok es
+// false si el store no tiene breaker
+func LedgerCircuitState() (string, bool) {
+	return ledgerChain.CircuitState()
+}
+
+// respondLedgerUnavailable responde 503 si err indica que el circuit breaker
+// del ledger está abierto, o 507 si el ledger en memoria está lleno; retorna
+// false si err es otro error
+func respondLedgerUnavailable(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, ledger.ErrCircuitOpen):
+		apierror.Respond(c, http.StatusServiceUnavailable, apierror.LedgerUnavailable, "Ledger temporarily unavailable")
+	case errors.Is(err, ledger.ErrLedgerFull):
+		apierror.Respond(c, http.StatusInsufficientStorage, apierror.LedgerFull, "Ledger capacity exhausted")
+	default:
+		return false
+	}
+	return true
+}
+
+// StatusClientClosedRequest es el código no estándar (el de nginx) con que
+// se responde y registra un request abandonado por el cliente
+const StatusClientClosedRequest = 499
+
+// respondClientClosed responde 499 si err se debe a que el cliente cerró el
+// request; retorna false si err es otro error
+func respondClientClosed(c *gin.Context, err error) bool {
+	if !errors.Is(err, context.Canceled) {
+		return false
+	}
+	logging.FromContext(c).Info("client closed request", "error", err)
+	apierror.Respond(c, StatusClientClosedRequest, apierror.ClientClosedRequest, "Client closed request")
+	return true
+}
+
+// maxClientRefLength coincide con la columna client_ref del ledger
+const maxClientRefLength = 255
+
 // CreateLedgerEntry crea una entrada en el ledger inmutable
 func CreateLedgerEntry(c *gin.Context) {
 	var req struct {
@@ -216,25 +886,49 @@ func CreateLedgerEntry(c *gin.Context) {
 		Description string          `json:"description"`
 		UserID      string          `json:"user_id"`
 		ProjectID   string          `json:"project_id"`
+		ClientRef   string          `json:"client_ref"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request",
-		})
+		respondBindingError(c, err)
+		return
+	}
+	if len(req.ClientRef) > maxClientRefLength {
+		apierror.Respond(c, http.StatusBadRequest, apierror.ClientRefTooLong, "client_ref is too long")
 		return
 	}
 
-	// En producción, esto iría a PostgreSQL con triggers de inmutabilidad
-	entry := LedgerEntry{
-		SequenceNumber: time.Now().UnixNano(),
-		EntryType:      req.EntryType,
-		Amount:         req.Amount,
-		Currency:       req.Currency,
-		Description:    req.Description,
-		CreatedAt:      time.Now(),
-		IsVerified:     true,
+	// Un client_ref repetido retorna la entrada original en lugar de duplicarla
+	ctx, span := tracing.Start(c.Request.Context(), "ledger.append")
+	entry, created, err := ledgerChain.AppendOnce(ctx, LedgerEntry{
+		EntryType:   req.EntryType,
+		Amount:      req.Amount,
+		Currency:    req.Currency,
+		Description: req.Description,
+		CreatedAt:   time.Now(),
+		IsVerified:  true,
+		ClientRef:   req.ClientRef,
+	})
+	tracing.EndSpan(span, err)
+	if respondLedgerUnavailable(c, err) {
+		return
 	}
+	if err != nil {
+		if respondClientClosed(c, err) {
+			return
+		}
+		logging.FromContext(c).Error("failed to persist ledger entry", "error", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.Internal, "Failed to persist ledger entry")
+		return
+	}
+	if !created {
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"entry":   entry,
+		})
+		return
+	}
+	metrics.LedgerEntriesCreated.Inc()
 
 	c.JSON(http.StatusCreated, gin.H{
 		"success": true,
@@ -244,10 +938,36 @@ func CreateLedgerEntry(c *gin.Context) {
 
 // VerifyLedgerIntegrity verifica la integridad de la cadena del ledger
 func VerifyLedgerIntegrity(c *gin.Context) {
-	// En producción, verificaría toda la cadena de hashes
+	ctx, span := tracing.Start(c.Request.Context(), "ledger.entries")
+	entries, err := ledgerChain.Entries(ctx)
+	tracing.EndSpan(span, err)
+	if respondLedgerUnavailable(c, err) {
+		return
+	}
+	if err != nil {
+		if respondClientClosed(c, err) {
+			return
+		}
+		logging.FromContext(c).Error("failed to read ledger", "error", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.Internal, "Failed to read ledger")
+		return
+	}
+
+	result := ledgerChain.Verify(entries)
+	if !result.Valid {
+		c.JSON(http.StatusOK, gin.H{
+			"is_valid":               false,
+			"entries_verified":       result.EntriesVerified,
+			"first_invalid_sequence": result.FirstInvalidSequence,
+			"verified_at":            time.Now(),
+			"message":                result.Reason,
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"is_valid":         true,
-		"entries_verified": 0,
+		"entries_verified": result.EntriesVerified,
 		"verified_at":      time.Now(),
 		"message":          "Ledger integrity verified",
 	})
@@ -255,125 +975,217 @@ func VerifyLedgerIntegrity(c *gin.Context) {
 
 // GetLedgerEntry obtiene una entrada específica del ledger
 func GetLedgerEntry(c *gin.Context) {
-	sequence := c.Param("sequence")
+	sequence, err := strconv.ParseInt(c.Param("sequence"), 10, 64)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidSequence, "Invalid sequence number")
+		return
+	}
+
+	ctx, span := tracing.Start(c.Request.Context(), "ledger.get")
+	entry, err := ledgerChain.Get(ctx, sequence)
+	tracing.EndSpan(span, err)
+	if err != nil {
+		if respondLedgerUnavailable(c, err) {
+			return
+		}
+		if errors.Is(err, ledger.ErrEntryNotFound) {
+			apierror.Respond(c, http.StatusNotFound, apierror.LedgerEntryNotFound, "Ledger entry not found")
+			return
+		}
+		if respondClientClosed(c, err) {
+			return
+		}
+		logging.FromContext(c).Error("failed to read ledger entry", "sequence", sequence, "error", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.Internal, "Failed to read ledger")
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"sequence_number": sequence,
-		"status":         "found",
+		"success": true,
+		"entry":   entry,
 	})
 }
 
-// CalculateMetrics calcula métricas financieras
-func CalculateMetrics(c *gin.Context) {
-	startTime := time.Now()
-
-	var req struct {
-		InversionInicial float64   `json:"inversion_inicial" binding:"required"`
-		FlujosIngresos   []float64 `json:"flujos_ingresos" binding:"required"`
-		FlujosCostos     []float64 `json:"flujos_costos"`
-		TasaDescuento    float64   `json:"tasa_descuento" binding:"required"`
+// GetLedgerProof genera la prueba de inclusión Merkle de una entrada del ledger
+// para que un auditor pueda verificarla sin descargar todo el ledger
+func GetLedgerProof(c *gin.Context) {
+	sequence, err := strconv.ParseInt(c.Param("sequence"), 10, 64)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidSequence, "Invalid sequence number")
+		return
 	}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request",
-		})
+	ctx, span := tracing.Start(c.Request.Context(), "ledger.entries")
+	entries, err := ledgerChain.Entries(ctx)
+	tracing.EndSpan(span, err)
+	if respondLedgerUnavailable(c, err) {
+		return
+	}
+	if err != nil {
+		if respondClientClosed(c, err) {
+			return
+		}
+		logging.FromContext(c).Error("failed to read ledger", "error", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.Internal, "Failed to read ledger")
 		return
 	}
 
-	// Calcular flujos netos
-	flujosNetos := make([]float64, len(req.FlujosIngresos))
-	for i := range req.FlujosIngresos {
-		costo := 0.0
-		if i < len(req.FlujosCostos) {
-			costo = req.FlujosCostos[i]
+	index := -1
+	leaves := make([]string, len(entries))
+	for i, entry := range entries {
+		leaves[i] = ledger.MerkleLeafHash(entry)
+		if entry.SequenceNumber == sequence {
+			index = i
 		}
-		flujosNetos[i] = req.FlujosIngresos[i] - costo
 	}
 
-	// Calcular VAN
-	van := -req.InversionInicial
-	for i, flujo := range flujosNetos {
-		van += flujo / pow(1+req.TasaDescuento, float64(i+1))
+	if index < 0 {
+		apierror.Respond(c, http.StatusNotFound, apierror.LedgerEntryNotFound, "Ledger entry not found")
+		return
 	}
 
-	// Calcular Payback
-	acumulado := -req.InversionInicial
-	payback := -1.0
-	for i, flujo := range flujosNetos {
-		acumulado += flujo
-		if acumulado >= 0 && payback < 0 {
-			// Interpolación
-			flujoAnterior := acumulado - flujo
-			payback = float64(i) + (-flujoAnterior / flujo)
-			break
-		}
+	proof, err := ledger.MerkleProof(leaves, index)
+	if err != nil {
+		logging.FromContext(c).Error("failed to generate merkle proof", "error", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.Internal, "Failed to generate proof")
+		return
 	}
 
-	// Calcular ROI
-	totalFlujos := 0.0
-	for _, f := range flujosNetos {
-		totalFlujos += f
+	c.JSON(http.StatusOK, gin.H{
+		"sequence_number": sequence,
+		"leaf_hash":       leaves[index],
+		"proof":           proof,
+		"merkle_root":     ledger.MerkleRoot(leaves),
+		"tree_size":       len(leaves),
+		"generated_at":    time.Now(),
+	})
+}
+
+// CalculateMetrics calcula métricas financieras
+func CalculateMetrics(c *gin.Context) {
+	startTime := time.Now()
+
+	var req SolicitudMetricas
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
 	}
-	roi := (totalFlujos - req.InversionInicial) / req.InversionInicial
 
-	processingTime := time.Since(startTime).Microseconds()
+	metrics, err := CalcularMetricas(req)
+	if err != nil {
+		respondInvalidParameter(c, err)
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"metrics": gin.H{
-			"van":              van,
-			"roi":              roi,
-			"payback_meses":    payback,
-			"es_viable":        van > 0,
-			"flujos_netos":     flujosNetos,
-		},
-		"processing_time_us": processingTime,
+		"success":            true,
+		"metrics":            metrics,
+		"processing_time_us": time.Since(startTime).Microseconds(),
 	})
 }
 
 // ValidateTransfer valida una transferencia antes de ejecutarla
 func ValidateTransfer(c *gin.Context) {
-	var req struct {
-		FromAccount string          `json:"from_account" binding:"required"`
-		ToAccount   string          `json:"to_account" binding:"required"`
-		Amount      decimal.Decimal `json:"amount" binding:"required"`
-		Currency    string          `json:"currency"`
+	var req TransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
 	}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request",
-		})
+	validation, err := ValidateTransferRequest(c.Request.Context(), req)
+	if err != nil {
+		respondInvalidParameter(c, err)
 		return
 	}
+	c.JSON(http.StatusOK, validation)
+}
 
-	// Validaciones
-	validations := []string{}
-	isValid := true
+// Convenciones de descuento aceptadas por CalculateMetrics
+const (
+	convencionFinPeriodo   = "fin_periodo"
+	convencionMitadPeriodo = "mitad_periodo"
+)
 
-	if req.Amount.LessThanOrEqual(decimal.Zero) {
-		validations = append(validations, "Amount must be positive")
-		isValid = false
+// redondearBancario redondea al número de decimales con redondeo bancario
+// (mitad al par) para no sesgar sumas de montos redondeados
+func redondearBancario(valor float64, decimales int32) float64 {
+	return decimal.NewFromFloat(valor).RoundBank(decimales).InexactFloat64()
+}
+
+// desplazamientoConvencion retorna cuánto se adelanta el exponente de
+// descuento: 0 al fin de periodo, 0.5 a mitad de periodo
+func desplazamientoConvencion(convencion string) float64 {
+	if convencion == convencionMitadPeriodo {
+		return 0.5
 	}
+	return 0
+}
 
-	if req.FromAccount == req.ToAccount {
-		validations = append(validations, "Source and destination accounts must be different")
-		isValid = false
+// vanEnTasa fija la inversión, los flujos y la convención para buscar raíces del VAN
+func vanEnTasa(inversionInicial float64, flujos []float64, desplazamiento float64) func(float64) float64 {
+	return func(tasa float64) float64 {
+		return finance.NPVOffset(tasa, inversionInicial, flujos, desplazamiento)
 	}
+}
 
-	c.JSON(http.StatusOK, gin.H{
-		"is_valid":    isValid,
-		"validations": validations,
-		"validated_at": time.Now(),
-	})
+// maxPuntosSensibilidad acota el número de tasas evaluadas en un barrido
+const maxPuntosSensibilidad = 1000
+
+// RangoSensibilidad define el barrido de tasas del análisis de sensibilidad
+type RangoSensibilidad struct {
+	TasaMin float64 `json:"tasa_min"`
+	TasaMax float64 `json:"tasa_max"`
+	Paso    float64 `json:"paso"`
+}
+
+// PuntoSensibilidad es el VAN a una tasa de descuento
+type PuntoSensibilidad struct {
+	Tasa float64 `json:"tasa"`
+	VAN  float64 `json:"van"`
+}
+
+// validar comprueba que el barrido sea finito y esté acotado
+func (r RangoSensibilidad) validar() error {
+	if r.Paso <= 0 {
+		return errors.New("sensibilidad.paso must be positive")
+	}
+	if r.TasaMin <= -1 {
+		return errors.New("sensibilidad.tasa_min must be greater than -1")
+	}
+	if r.TasaMax < r.TasaMin {
+		return errors.New("sensibilidad.tasa_max must not be lower than tasa_min")
+	}
+	if (r.TasaMax-r.TasaMin)/r.Paso+1 > maxPuntosSensibilidad {
+		return fmt.Errorf("sensibilidad cannot evaluate more than %d rates", maxPuntosSensibilidad)
+	}
+	return nil
 }
 
-// Función auxiliar para potencia
-func pow(base, exp float64) float64 {
-	result := 1.0
-	for i := 0; i < int(exp); i++ {
-		result *= base
+// calcularSensibilidad evalúa el VAN en cada tasa del barrido y retorna la
+// tasa de equilibrio (VAN = 0) refinada por bisección en el primer tramo
+// donde el VAN cambia de signo, o nil si no cruza cero dentro del rango.
+// El VAN usa la misma convención de descuento que el resto de las métricas.
+func calcularSensibilidad(inversionInicial float64, flujos []float64, rango RangoSensibilidad, desplazamiento float64) ([]PuntoSensibilidad, *float64) {
+	van := vanEnTasa(inversionInicial, flujos, desplazamiento)
+
+	// Calcular cada tasa desde el índice evita acumular error al sumar el paso
+	n := int(math.Floor((rango.TasaMax-rango.TasaMin)/rango.Paso+1e-9)) + 1
+	puntos := make([]PuntoSensibilidad, n)
+	for i := range puntos {
+		tasa := rango.TasaMin + float64(i)*rango.Paso
+		puntos[i] = PuntoSensibilidad{Tasa: tasa, VAN: van(tasa)}
+	}
+
+	for i, punto := range puntos {
+		if punto.VAN == 0 {
+			tasa := punto.Tasa
+			return puntos, &tasa
+		}
+		if i > 0 && puntos[i-1].VAN*punto.VAN < 0 {
+			anterior := puntos[i-1]
+			tasa, _ := finance.Bisect(van, anterior.Tasa, punto.Tasa, anterior.VAN)
+			return puntos, &tasa
+		}
 	}
-	return result
+	return puntos, nil
 }
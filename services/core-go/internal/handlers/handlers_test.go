@@ -0,0 +1,1002 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fincore/core-go/internal/apierror"
+	"github.com/fincore/core-go/internal/finance"
+	"github.com/fincore/core-go/internal/ledger"
+	"github.com/fincore/core-go/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+
+	os.Setenv("SECRET_KEY", "test-secret-key-for-handlers-0123456789")
+	os.Setenv("ENCRYPTION_KEY", "test-encryption-key-for-handlers-0123456")
+	os.Setenv("INTEGRITY_KEY", "test-integrity-key-for-handlers-01234567")
+	SetSecurityManager(security.MustNewSecurityManager())
+}
+
+// doJSON ejecuta un handler con un body JSON y retorna la respuesta decodificada
+func doJSON(t *testing.T, handler gin.HandlerFunc, body interface{}) (*httptest.ResponseRecorder, map[string]interface{}) {
+	t.Helper()
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("marshal body: %v", err)
+	}
+
+	router := gin.New()
+	router.POST("/", handler)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	var resp map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response %q: %v", w.Body.String(), err)
+	}
+	return w, resp
+}
+
+// useMockLedger configura los handlers con un ledger en memoria vacío
+func useMockLedger(t *testing.T) *ledger.MockLedgerStore {
+	t.Helper()
+	store := ledger.NewMockLedgerStore()
+	chain, err := ledger.NewLedgerChain(context.Background(), store)
+	if err != nil {
+		t.Fatalf("NewLedgerChain: %v", err)
+	}
+	SetLedgerChain(chain)
+	return store
+}
+
+// doGet ejecuta un GET contra el handler registrado en la ruta dada
+func doGet(t *testing.T, pattern string, handler gin.HandlerFunc, target string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	t.Helper()
+
+	router := gin.New()
+	router.GET(pattern, handler)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+
+	var resp map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response %q: %v", w.Body.String(), err)
+	}
+	return w, resp
+}
+
+func metricsOf(t *testing.T, resp map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	metrics, ok := resp["metrics"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("response has no metrics: %v", resp)
+	}
+	return metrics
+}
+
+func TestCalculateMetricsTIR(t *testing.T) {
+	// -1000 seguido de 3 flujos de 500: TIR de referencia ≈ 23.3752%
+	w, resp := doJSON(t, CalculateMetrics, map[string]interface{}{
+		"inversion_inicial": 1000,
+		"flujos_ingresos":   []float64{500, 500, 500},
+		"tasa_descuento":    0.1,
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+
+	metrics := metricsOf(t, resp)
+	tir, ok := metrics["tir"].(float64)
+	if !ok {
+		t.Fatalf("tir = %v, want a number", metrics["tir"])
+	}
+	if math.Abs(tir-0.233752) > 1e-5 {
+		t.Errorf("tir = %v, want ≈ 0.233752", tir)
+	}
+	if _, ok := metrics["tir_error"]; ok {
+		t.Errorf("unexpected tir_error: %v", metrics["tir_error"])
+	}
+
+	// El VAN a la TIR debe ser prácticamente cero
+	if van := finance.NPV(tir, 1000, []float64{500, 500, 500}); math.Abs(van) > 1e-6 {
+		t.Errorf("VAN at TIR = %v, want ≈ 0", van)
+	}
+}
+
+func TestCalculateMetricsTIRWithoutSignChange(t *testing.T) {
+	_, resp := doJSON(t, CalculateMetrics, map[string]interface{}{
+		"inversion_inicial": 1000,
+		"flujos_ingresos":   []float64{100, 100},
+		"flujos_costos":     []float64{200, 200},
+		"tasa_descuento":    0.1,
+	})
+
+	metrics := metricsOf(t, resp)
+	if metrics["tir"] != nil {
+		t.Errorf("tir = %v, want null", metrics["tir"])
+	}
+	if metrics["tir_error"] == nil {
+		t.Error("expected tir_error when flows have no sign change")
+	}
+}
+
+func TestCalculateMetricsSensibilidad(t *testing.T) {
+	for _, convencion := range []string{convencionFinPeriodo, convencionMitadPeriodo} {
+		t.Run(convencion, func(t *testing.T) {
+			w, resp := doJSON(t, CalculateMetrics, map[string]interface{}{
+				"inversion_inicial": 1000,
+				"flujos_ingresos":   []float64{500, 500, 500},
+				"tasa_descuento":    0.1,
+				"convencion":        convencion,
+				"sensibilidad":      map[string]interface{}{"tasa_min": 0, "tasa_max": 0.5, "paso": 0.05},
+			})
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", w.Code)
+			}
+
+			metrics := metricsOf(t, resp)
+			sensibilidad := metrics["sensibilidad"].(map[string]interface{})
+			puntos := sensibilidad["puntos"].([]interface{})
+			if len(puntos) != 11 {
+				t.Fatalf("len(puntos) = %d, want 11", len(puntos))
+			}
+			ultimo := puntos[10].(map[string]interface{})
+			if math.Abs(ultimo["tasa"].(float64)-0.5) > 1e-12 {
+				t.Errorf("last tasa = %v, want 0.5", ultimo["tasa"])
+			}
+
+			equilibrio, ok := sensibilidad["tasa_equilibrio"].(float64)
+			if !ok {
+				t.Fatalf("tasa_equilibrio = %v, want a number", sensibilidad["tasa_equilibrio"])
+			}
+			tir := metrics["tir"].(float64)
+			if math.Abs(equilibrio-tir) > 1e-8 {
+				t.Errorf("tasa_equilibrio = %v, want TIR %v", equilibrio, tir)
+			}
+			// La TIR anula el VAN con la convención pedida
+			if van := finance.NPVOffset(tir, 1000, []float64{500, 500, 500}, desplazamientoConvencion(convencion)); math.Abs(van) > 1e-6 {
+				t.Errorf("VAN at TIR = %v, want 0", van)
+			}
+		})
+	}
+}
+
+func TestCalculateMetricsSensibilidadWithoutCrossing(t *testing.T) {
+	_, resp := doJSON(t, CalculateMetrics, map[string]interface{}{
+		"inversion_inicial": 1000,
+		"flujos_ingresos":   []float64{500, 500, 500},
+		"tasa_descuento":    0.1,
+		"sensibilidad":      map[string]interface{}{"tasa_min": 0, "tasa_max": 0.1, "paso": 0.01},
+	})
+
+	sensibilidad := metricsOf(t, resp)["sensibilidad"].(map[string]interface{})
+	if sensibilidad["tasa_equilibrio"] != nil {
+		t.Errorf("tasa_equilibrio = %v, want null when VAN does not cross zero", sensibilidad["tasa_equilibrio"])
+	}
+}
+
+func TestCalculateMetricsSensibilidadInvalidRange(t *testing.T) {
+	rangos := []map[string]interface{}{
+		{"tasa_min": 0, "tasa_max": 0.5, "paso": 0},
+		{"tasa_min": 0, "tasa_max": 0.5, "paso": -0.1},
+		{"tasa_min": 0.5, "tasa_max": 0, "paso": 0.1},
+		{"tasa_min": -1, "tasa_max": 0, "paso": 0.1},
+		{"tasa_min": 0, "tasa_max": 1, "paso": 1e-6},
+	}
+	for _, rango := range rangos {
+		w, _ := doJSON(t, CalculateMetrics, map[string]interface{}{
+			"inversion_inicial": 1000,
+			"flujos_ingresos":   []float64{500, 500, 500},
+			"tasa_descuento":    0.1,
+			"sensibilidad":      rango,
+		})
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%v: status = %d, want 400", rango, w.Code)
+		}
+	}
+}
+
+func TestCalculateMetricsEAARanksProjects(t *testing.T) {
+	eaaOf := func(inversion float64, flujos []float64) (van, eaa float64) {
+		t.Helper()
+		_, resp := doJSON(t, CalculateMetrics, map[string]interface{}{
+			"inversion_inicial": inversion,
+			"flujos_ingresos":   flujos,
+			"tasa_descuento":    0.1,
+		})
+		metrics := metricsOf(t, resp)
+		return metrics["van"].(float64), metrics["eaa"].(float64)
+	}
+
+	// El proyecto largo tiene mayor VAN pero genera menos valor por periodo
+	vanCorto, eaaCorto := eaaOf(1000, []float64{700, 700})
+	vanLargo, eaaLargo := eaaOf(1000, []float64{300, 300, 300, 300, 300, 300})
+	if vanLargo <= vanCorto {
+		t.Fatalf("setup: long VAN %v must exceed short VAN %v", vanLargo, vanCorto)
+	}
+	if eaaCorto <= eaaLargo {
+		t.Errorf("EAA short = %v, long = %v; short project must rank higher", eaaCorto, eaaLargo)
+	}
+
+	// EAA = VAN · r / (1 - (1+r)^-n)
+	if want := vanCorto * 0.1 / (1 - math.Pow(1.1, -2)); math.Abs(eaaCorto-want) > 1e-9 {
+		t.Errorf("eaa = %v, want %v", eaaCorto, want)
+	}
+}
+
+func TestCalculateMetricsConvencionMitadPeriodo(t *testing.T) {
+	vanCon := func(convencion string) (float64, map[string]interface{}) {
+		t.Helper()
+		body := map[string]interface{}{
+			"inversion_inicial": 1000,
+			"flujos_ingresos":   []float64{400, 400, 400},
+			"tasa_descuento":    0.1,
+		}
+		if convencion != "" {
+			body["convencion"] = convencion
+		}
+		w, resp := doJSON(t, CalculateMetrics, body)
+		if w.Code != http.StatusOK {
+			t.Fatalf("convencion %q: status = %d, want 200", convencion, w.Code)
+		}
+		metrics := metricsOf(t, resp)
+		return metrics["van"].(float64), metrics
+	}
+
+	vanDefecto, metrics := vanCon("")
+	vanFin, _ := vanCon("fin_periodo")
+	vanMitad, _ := vanCon("mitad_periodo")
+	if metrics["convencion"] != "fin_periodo" {
+		t.Errorf("default convencion = %v, want fin_periodo", metrics["convencion"])
+	}
+	if vanDefecto != vanFin {
+		t.Errorf("default VAN = %v, want end-of-period VAN %v", vanDefecto, vanFin)
+	}
+
+	// Descontar medio periodo antes multiplica el valor presente de los flujos por (1+r)^0.5
+	if want := (vanFin+1000)*math.Sqrt(1.1) - 1000; math.Abs(vanMitad-want) > 1e-9 {
+		t.Errorf("mid-period VAN = %v, want %v", vanMitad, want)
+	}
+	if vanMitad <= vanFin {
+		t.Errorf("mid-period VAN %v must exceed end-of-period VAN %v", vanMitad, vanFin)
+	}
+
+	if w, _ := doJSON(t, CalculateMetrics, map[string]interface{}{
+		"inversion_inicial": 1000,
+		"flujos_ingresos":   []float64{400},
+		"tasa_descuento":    0.1,
+		"convencion":        "inicio_periodo",
+	}); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown convencion: status = %d, want 400", w.Code)
+	}
+}
+
+func TestCalculateMetricsDiscountedPayback(t *testing.T) {
+	// Recupera en términos simples (1050 >= 1000) pero no descontado al 10%
+	_, resp := doJSON(t, CalculateMetrics, map[string]interface{}{
+		"inversion_inicial": 1000,
+		"flujos_ingresos":   []float64{350, 350, 350},
+		"tasa_descuento":    0.1,
+	})
+
+	metrics := metricsOf(t, resp)
+	if payback := metrics["payback_meses"].(float64); payback < 0 {
+		t.Errorf("payback_meses = %v, want a recovery on a simple basis", payback)
+	}
+	if got := metrics["payback_descontado_meses"].(float64); got != -1 {
+		t.Errorf("payback_descontado_meses = %v, want -1", got)
+	}
+	if metrics["recupera_descontado"] != false {
+		t.Errorf("recupera_descontado = %v, want false", metrics["recupera_descontado"])
+	}
+}
+
+func TestCalculateMetricsDiscountedPaybackRecovers(t *testing.T) {
+	_, resp := doJSON(t, CalculateMetrics, map[string]interface{}{
+		"inversion_inicial": 1000,
+		"flujos_ingresos":   []float64{500, 500, 500},
+		"tasa_descuento":    0.1,
+	})
+
+	metrics := metricsOf(t, resp)
+	simple := metrics["payback_meses"].(float64)
+	descontado := metrics["payback_descontado_meses"].(float64)
+	if math.Abs(simple-2) > 1e-9 {
+		t.Errorf("payback_meses = %v, want 2", simple)
+	}
+	// 454.55 + 413.22 = 867.77; faltan 132.23 de 375.66 en el tercer periodo
+	if math.Abs(descontado-2.352) > 1e-3 {
+		t.Errorf("payback_descontado_meses = %v, want ≈ 2.352", descontado)
+	}
+	if metrics["recupera_descontado"] != true {
+		t.Errorf("recupera_descontado = %v, want true", metrics["recupera_descontado"])
+	}
+}
+
+func TestCalculateMetricsRecuperaInversion(t *testing.T) {
+	_, resp := doJSON(t, CalculateMetrics, map[string]interface{}{
+		"inversion_inicial": 1000,
+		"flujos_ingresos":   []float64{100, 100},
+		"tasa_descuento":    0.1,
+	})
+
+	metrics := metricsOf(t, resp)
+	if metrics["recupera_inversion"] != false {
+		t.Errorf("recupera_inversion = %v, want false", metrics["recupera_inversion"])
+	}
+	if metrics["payback_meses"].(float64) != -1 {
+		t.Errorf("payback_meses = %v, want -1", metrics["payback_meses"])
+	}
+}
+
+func TestCalculateMetricsMIRR(t *testing.T) {
+	// -1000, 500, 500, 500 con financiamiento 10% y reinversión 12%:
+	// VF = 500*1.12^2 + 500*1.12 + 500 = 1687.2 → MIRR = (1687.2/1000)^(1/3) - 1
+	_, resp := doJSON(t, CalculateMetrics, map[string]interface{}{
+		"inversion_inicial":   1000,
+		"flujos_ingresos":     []float64{500, 500, 500},
+		"tasa_descuento":      0.1,
+		"tasa_financiamiento": 0.1,
+		"tasa_reinversion":    0.12,
+	})
+
+	metrics := metricsOf(t, resp)
+	mirr, ok := metrics["mirr"].(float64)
+	if !ok {
+		t.Fatalf("mirr = %v, want a number", metrics["mirr"])
+	}
+	want := math.Pow(1687.2/1000, 1.0/3) - 1
+	if math.Abs(mirr-want) > 1e-9 {
+		t.Errorf("mirr = %v, want %v", mirr, want)
+	}
+}
+
+func TestCalculateMetricsMIRROmittedWithoutRates(t *testing.T) {
+	_, resp := doJSON(t, CalculateMetrics, map[string]interface{}{
+		"inversion_inicial": 1000,
+		"flujos_ingresos":   []float64{500, 500, 500},
+		"tasa_descuento":    0.1,
+	})
+
+	if mirr := metricsOf(t, resp)["mirr"]; mirr != nil {
+		t.Errorf("mirr = %v, want null", mirr)
+	}
+}
+
+func TestCalculateMetricsProfitabilityIndex(t *testing.T) {
+	tests := []struct {
+		name   string
+		flujos []float64
+		viable bool
+	}{
+		{"viable", []float64{500, 500, 500}, true},
+		{"no viable", []float64{300, 300, 300}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, resp := doJSON(t, CalculateMetrics, map[string]interface{}{
+				"inversion_inicial": 1000,
+				"flujos_ingresos":   tt.flujos,
+				"tasa_descuento":    0.1,
+			})
+
+			metrics := metricsOf(t, resp)
+			pi := metrics["profitability_index"].(float64)
+			van := metrics["van"].(float64)
+
+			// PI > 1 y VAN > 0 deben coincidir siempre
+			if (pi > 1) != (van > 0) {
+				t.Errorf("inconsistent signals: profitability_index = %v, van = %v", pi, van)
+			}
+			if metrics["es_viable"] != tt.viable {
+				t.Errorf("es_viable = %v, want %v", metrics["es_viable"], tt.viable)
+			}
+		})
+	}
+}
+
+func TestCalculateMetricsSubsidioInicial(t *testing.T) {
+	// inversion_inicial negativa: el proyecto recibe 200 en el periodo 0
+	w, resp := doJSON(t, CalculateMetrics, map[string]interface{}{
+		"inversion_inicial": -200,
+		"flujos_ingresos":   []float64{100, 100},
+		"tasa_descuento":    0.1,
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+
+	metrics := metricsOf(t, resp)
+	wantVAN := 200 + 100/1.1 + 100/1.21
+	if van := metrics["van"].(float64); math.Abs(van-wantVAN) > 1e-9 {
+		t.Errorf("van = %v, want %v", van, wantVAN)
+	}
+	// (200 + 200) / |−200|: el subsidio no invierte el signo
+	if roi := metrics["roi"].(float64); math.Abs(roi-2) > 1e-9 {
+		t.Errorf("roi = %v, want 2", roi)
+	}
+	if pi := metrics["profitability_index"].(float64); math.Abs(pi-(1+wantVAN/200)) > 1e-9 {
+		t.Errorf("profitability_index = %v, want %v", pi, 1+wantVAN/200)
+	}
+	if metrics["es_viable"] != true {
+		t.Errorf("es_viable = %v, want true", metrics["es_viable"])
+	}
+	if metrics["payback_meses"] != 0.0 || metrics["recupera_inversion"] != true {
+		t.Errorf("payback = %v/%v, want immediate", metrics["payback_meses"], metrics["recupera_inversion"])
+	}
+}
+
+func TestCalculateMetricsZeroInvestment(t *testing.T) {
+	w, resp := doJSON(t, CalculateMetrics, map[string]interface{}{
+		"inversion_inicial": 0,
+		"flujos_ingresos":   []float64{500},
+		"tasa_descuento":    0.1,
+	})
+	// Un cero explícito no es un campo ausente: el PI no está definido
+	if w.Code != http.StatusBadRequest || resp["code"] != apierror.InvalidParameter {
+		t.Errorf("status/code = %d/%v, want 400/%s", w.Code, resp["code"], apierror.InvalidParameter)
+	}
+	if resp["error"] != "inversion_inicial must be non-zero" {
+		t.Errorf("error = %v, want inversion_inicial must be non-zero", resp["error"])
+	}
+}
+
+func TestCalculateMetricsCostLengthMismatch(t *testing.T) {
+	body := map[string]interface{}{
+		"inversion_inicial": 1000,
+		"flujos_ingresos":   []float64{500, 500},
+		"flujos_costos":     []float64{100, 100, 100},
+		"tasa_descuento":    0.1,
+	}
+
+	// Por defecto se rechaza
+	if w, _ := doJSON(t, CalculateMetrics, body); w.Code != http.StatusBadRequest {
+		t.Errorf("default: status = %d, want 400", w.Code)
+	}
+
+	body["strict"] = true
+	if w, _ := doJSON(t, CalculateMetrics, body); w.Code != http.StatusBadRequest {
+		t.Errorf("strict=true: status = %d, want 400", w.Code)
+	}
+
+	// Con strict=false el costo sobrante se ignora
+	body["strict"] = false
+	w, resp := doJSON(t, CalculateMetrics, body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("strict=false: status = %d, want 200", w.Code)
+	}
+	if netos := metricsOf(t, resp)["flujos_netos"].([]interface{}); len(netos) != 2 {
+		t.Errorf("flujos_netos has %d items, want 2", len(netos))
+	}
+}
+
+func TestCalculateMetricsCurrency(t *testing.T) {
+	body := map[string]interface{}{
+		"inversion_inicial": 1000,
+		"flujos_ingresos":   []float64{500, 500, 500},
+		"tasa_descuento":    0.1,
+		"currency":          "usd",
+	}
+
+	w, resp := doJSON(t, CalculateMetrics, body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if got := metricsOf(t, resp)["currency"]; got != "USD" {
+		t.Errorf("currency = %v, want USD", got)
+	}
+
+	for _, invalida := range []string{"XYZ", "US", "dollars"} {
+		body["currency"] = invalida
+		if w, _ := doJSON(t, CalculateMetrics, body); w.Code != http.StatusBadRequest {
+			t.Errorf("currency %q: status = %d, want 400", invalida, w.Code)
+		}
+	}
+}
+
+func TestCalculateMetricsRedondeo(t *testing.T) {
+	// VAN de -1000 y flujos netos 499.885, 500, 500 al 10%: 243.3214500375...
+	body := map[string]interface{}{
+		"inversion_inicial": 1000,
+		"flujos_ingresos":   []float64{500, 500, 500},
+		"flujos_costos":     []float64{0.115, 0, 0},
+		"tasa_descuento":    0.1,
+	}
+
+	_, resp := doJSON(t, CalculateMetrics, body)
+	if _, ok := metricsOf(t, resp)["redondeado"]; ok {
+		t.Error("redondeado present without redondeo")
+	}
+
+	body["redondeo"] = 2
+	w, resp := doJSON(t, CalculateMetrics, body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	metrics := metricsOf(t, resp)
+	redondeado, ok := metrics["redondeado"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("redondeado = %v, want an object", metrics["redondeado"])
+	}
+	if redondeado["van"] != 243.32 {
+		t.Errorf("van redondeado = %v, want 243.32 (van = %v)", redondeado["van"], metrics["van"])
+	}
+	if van := metrics["van"].(float64); van == 243.32 {
+		t.Error("raw van was rounded too")
+	}
+	// 499.885 está a mitad de camino: el redondeo bancario elige el par (.88, no .89)
+	if netos := redondeado["flujos_netos"].([]interface{}); netos[0] != 499.88 {
+		t.Errorf("flujos_netos[0] = %v, want 499.88", netos[0])
+	}
+
+	body["redondeo"] = 9
+	if w, _ := doJSON(t, CalculateMetrics, body); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("redondeo 9: status = %d, want 422", w.Code)
+	}
+}
+
+func TestCalculateMetricsROIAnualizado(t *testing.T) {
+	// 36 flujos mensuales de 48 sobre 1000: ROI total 72.8% en 3 años, que
+	// compuesto equivale a 20% anual (1.2^3 = 1.728)
+	mensuales := make([]float64, 36)
+	for i := range mensuales {
+		mensuales[i] = 48
+	}
+	tests := []struct {
+		name string
+		body map[string]interface{}
+	}{
+		{"mensual por defecto", map[string]interface{}{
+			"inversion_inicial": 1000, "flujos_ingresos": mensuales, "tasa_descuento": 0.01,
+		}},
+		{"anual", map[string]interface{}{
+			"inversion_inicial": 1000, "flujos_ingresos": []float64{576, 576, 576},
+			"tasa_descuento": 0.1, "periodos_por_anio": 1,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, resp := doJSON(t, CalculateMetrics, tt.body)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", w.Code)
+			}
+			metrics := metricsOf(t, resp)
+			if roi := metrics["roi"].(float64); math.Abs(roi-0.728) > 1e-9 {
+				t.Errorf("roi = %v, want 0.728", roi)
+			}
+			roiAnualizado, ok := metrics["roi_anualizado"].(float64)
+			if !ok || math.Abs(roiAnualizado-0.2) > 1e-9 {
+				t.Errorf("roi_anualizado = %v, want 0.2", metrics["roi_anualizado"])
+			}
+		})
+	}
+}
+
+func TestCalculateMetricsInflacionAnual(t *testing.T) {
+	body := map[string]interface{}{
+		"inversion_inicial": 1000,
+		"flujos_ingresos":   []float64{550, 605},
+		"tasa_descuento":    0.05,
+		"periodos_por_anio": 1,
+	}
+	w, resp := doJSON(t, CalculateMetrics, body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	nominal := metricsOf(t, resp)
+	if nominal["van_nominal"] != nil || nominal["van_real"] != nil {
+		t.Errorf("van_nominal/van_real = %v/%v, want omitted without inflation", nominal["van_nominal"], nominal["van_real"])
+	}
+
+	// Los flujos crecen exactamente al 10% anual: los reales son 500 y 500 y,
+	// descontados a la tasa real 1.05/1.1 - 1, dan el mismo VAN que el nominal
+	body["inflacion_anual"] = 0.1
+	w, resp = doJSON(t, CalculateMetrics, body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	metrics := metricsOf(t, resp)
+	vanNominal := 550/1.05 + 605/(1.05*1.05) - 1000
+	tasaReal := 1.05/1.1 - 1
+	vanReal := 500/(1+tasaReal) + 500/((1+tasaReal)*(1+tasaReal)) - 1000
+	if math.Abs(vanReal-vanNominal) > 1e-9 {
+		t.Fatalf("reference van_real %v differs from van_nominal %v", vanReal, vanNominal)
+	}
+	if van := metrics["van"].(float64); math.Abs(van-nominal["van"].(float64)) > 1e-9 {
+		t.Errorf("van = %v, want the nominal %v", van, nominal["van"])
+	}
+	if got, ok := metrics["van_nominal"].(float64); !ok || math.Abs(got-vanNominal) > 1e-9 {
+		t.Errorf("van_nominal = %v, want %v", metrics["van_nominal"], vanNominal)
+	}
+	if got, ok := metrics["van_real"].(float64); !ok || math.Abs(got-vanReal) > 1e-9 {
+		t.Errorf("van_real = %v, want %v", metrics["van_real"], vanReal)
+	}
+
+	// También con flujos mensuales a mitad de periodo
+	body["periodos_por_anio"] = 12
+	body["convencion"] = "mitad_periodo"
+	body["flujos_ingresos"] = []float64{500 * math.Pow(1.1, 0.5/12), 500 * math.Pow(1.1, 1.5/12)}
+	w, resp = doJSON(t, CalculateMetrics, body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	metrics = metricsOf(t, resp)
+	if vanReal, vanNominal := metrics["van_real"].(float64), metrics["van_nominal"].(float64); math.Abs(vanReal-vanNominal) > 1e-9 {
+		t.Errorf("mitad_periodo: van_real = %v, want the nominal %v", vanReal, vanNominal)
+	}
+
+	body["inflacion_anual"] = -1
+	if w, _ := doJSON(t, CalculateMetrics, body); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("inflacion_anual -1: status = %d, want 422", w.Code)
+	}
+}
+
+func TestCalculateMetricsROIAnualizadoTotalLoss(t *testing.T) {
+	w, resp := doJSON(t, CalculateMetrics, map[string]interface{}{
+		"inversion_inicial": 1000,
+		"flujos_ingresos":   []float64{0, 0, 0},
+		"tasa_descuento":    0.1,
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	metrics := metricsOf(t, resp)
+	if metrics["roi"] != -1.0 || metrics["roi_anualizado"] != nil {
+		t.Errorf("roi/roi_anualizado = %v/%v, want -1/null", metrics["roi"], metrics["roi_anualizado"])
+	}
+	if metrics["roi_anualizado_error"] == nil {
+		t.Error("expected roi_anualizado_error for a total loss")
+	}
+
+	if w, _ := doJSON(t, CalculateMetrics, map[string]interface{}{
+		"inversion_inicial": 1000, "flujos_ingresos": []float64{500}, "tasa_descuento": 0.1, "periodos_por_anio": 0,
+	}); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("periodos_por_anio=0: status = %d, want 422", w.Code)
+	}
+}
+
+func TestCalculateMetricsTasaAnual(t *testing.T) {
+	inversion, flujos := 1000.0, []float64{100, 100, 100, 100, 100, 100, 100, 100, 100, 100, 100, 100}
+	tests := []struct {
+		name       string
+		extra      map[string]interface{}
+		tasaPeriod float64
+	}{
+		// 12% nominal capitalizable mensualmente equivale a 1% mensual
+		{"mensual", map[string]interface{}{"tasa_es_anual": true}, 0.01},
+		{"efectiva anual", map[string]interface{}{"tasa_es_anual": true, "frecuencia_capitalizacion": "anual"}, math.Pow(1.12, 1.0/12) - 1},
+		{"flujos trimestrales", map[string]interface{}{"tasa_es_anual": true, "periodos_por_anio": 4}, 0.03},
+		// Sin tasa_es_anual la tasa se sigue usando por periodo
+		{"por periodo", nil, 0.12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := map[string]interface{}{"inversion_inicial": inversion, "flujos_ingresos": flujos, "tasa_descuento": 0.12}
+			for k, v := range tt.extra {
+				body[k] = v
+			}
+			w, resp := doJSON(t, CalculateMetrics, body)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200 (%v)", w.Code, resp)
+			}
+			metrics := metricsOf(t, resp)
+			if want := finance.NPV(tt.tasaPeriod, inversion, flujos); math.Abs(metrics["van"].(float64)-want) > 1e-9 {
+				t.Errorf("van = %v, want %v", metrics["van"], want)
+			}
+			if tt.extra == nil {
+				if _, ok := metrics["tasa_descuento"]; ok {
+					t.Errorf("tasa_descuento = %v, want it omitted for a per-period rate", metrics["tasa_descuento"])
+				}
+			} else if tasa, _ := metrics["tasa_descuento"].(float64); math.Abs(tasa-tt.tasaPeriod) > 1e-12 {
+				t.Errorf("tasa_descuento = %v, want %v", metrics["tasa_descuento"], tt.tasaPeriod)
+			}
+		})
+	}
+
+	for _, extra := range []map[string]interface{}{
+		{"tasa_es_anual": true, "frecuencia_capitalizacion": "quincenal"},
+		{"frecuencia_capitalizacion": "mensual"},
+	} {
+		body := map[string]interface{}{"inversion_inicial": inversion, "flujos_ingresos": flujos, "tasa_descuento": 0.12}
+		for k, v := range extra {
+			body[k] = v
+		}
+		if w, _ := doJSON(t, CalculateMetrics, body); w.Code != http.StatusBadRequest {
+			t.Errorf("%v: status = %d, want 400", extra, w.Code)
+		}
+	}
+}
+
+func TestRedondearBancario(t *testing.T) {
+	tests := []struct {
+		valor float64
+		want  float64
+	}{
+		{2.345, 2.34},
+		{2.355, 2.36},
+		{-2.345, -2.34},
+		{2.3451, 2.35},
+	}
+	for _, tt := range tests {
+		if got := redondearBancario(tt.valor, 2); got != tt.want {
+			t.Errorf("redondearBancario(%v, 2) = %v, want %v", tt.valor, got, tt.want)
+		}
+	}
+}
+
+func TestCreateLedgerEntryConcurrentSequences(t *testing.T) {
+	useMockLedger(t)
+
+	const total = 1000
+	router := gin.New()
+	router.POST("/", CreateLedgerEntry)
+
+	sequences := make([]int64, total)
+	var wg sync.WaitGroup
+	for i := 0; i < total; i++ {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"entry_type":"deposit","amount":"10"}`))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			var resp struct {
+				Entry LedgerEntry `json:"entry"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Errorf("decode response: %v", err)
+				return
+			}
+			sequences[index] = resp.Entry.SequenceNumber
+		}(i)
+	}
+	wg.Wait()
+
+	sort.Slice(sequences, func(i, j int) bool { return sequences[i] < sequences[j] })
+	for i, got := range sequences {
+		if got != int64(i+1) {
+			t.Fatalf("sequences[%d] = %d, want %d", i, got, i+1)
+		}
+	}
+}
+
+func TestVerifyLedgerIntegrityValidChain(t *testing.T) {
+	useMockLedger(t)
+	for i := 0; i < 3; i++ {
+		if w, _ := doJSON(t, CreateLedgerEntry, map[string]interface{}{"entry_type": "deposit", "amount": "10"}); w.Code != http.StatusCreated {
+			t.Fatalf("create entry: status = %d", w.Code)
+		}
+	}
+
+	_, resp := doGet(t, "/", VerifyLedgerIntegrity, "/")
+	if resp["is_valid"] != true || resp["entries_verified"] != float64(3) {
+		t.Errorf("response = %v, want is_valid=true entries_verified=3", resp)
+	}
+}
+
+func TestVerifyLedgerIntegrityTamperedStore(t *testing.T) {
+	store := useMockLedger(t)
+	for i := 0; i < 3; i++ {
+		doJSON(t, CreateLedgerEntry, map[string]interface{}{"entry_type": "deposit", "amount": "10"})
+	}
+
+	entries, _ := store.List(context.Background())
+	tampered := entries[1]
+	tampered.Amount = tampered.Amount.Add(tampered.Amount)
+	store.Tamper(1, tampered)
+
+	_, resp := doGet(t, "/", VerifyLedgerIntegrity, "/")
+	if resp["is_valid"] != false || resp["first_invalid_sequence"] != float64(2) {
+		t.Errorf("response = %v, want is_valid=false first_invalid_sequence=2", resp)
+	}
+}
+
+func TestGetLedgerEntry(t *testing.T) {
+	useMockLedger(t)
+	_, created := doJSON(t, CreateLedgerEntry, map[string]interface{}{
+		"entry_type":  "deposit",
+		"amount":      "10.5",
+		"currency":    "MXN",
+		"description": "initial deposit",
+	})
+	createdEntry := created["entry"].(map[string]interface{})
+
+	tests := []struct {
+		name   string
+		target string
+		want   int
+	}{
+		{"existente", "/entry/1", http.StatusOK},
+		{"inexistente", "/entry/99", http.StatusNotFound},
+		{"no numerica", "/entry/abc", http.StatusBadRequest},
+		{"fuera de rango", "/entry/99999999999999999999", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, resp := doGet(t, "/entry/:sequence", GetLedgerEntry, tt.target)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if tt.want != http.StatusOK {
+				return
+			}
+
+			entry := resp["entry"].(map[string]interface{})
+			for _, field := range []string{"sequence_number", "entry_hash", "previous_hash", "amount", "description", "created_at"} {
+				if entry[field] != createdEntry[field] {
+					t.Errorf("%s = %v, want %v", field, entry[field], createdEntry[field])
+				}
+			}
+		})
+	}
+}
+
+func TestLedgerEndpointsWithInMemoryStore(t *testing.T) {
+	store := ledger.NewInMemoryLedgerStore(2)
+	chain, err := ledger.NewLedgerChain(context.Background(), store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetLedgerChain(chain)
+
+	for i := 0; i < 2; i++ {
+		if w, resp := doJSON(t, CreateLedgerEntry, map[string]interface{}{"entry_type": "deposit", "amount": "10"}); w.Code != http.StatusCreated {
+			t.Fatalf("create entry: status = %d (%v)", w.Code, resp)
+		}
+	}
+
+	_, resp := doGet(t, "/", VerifyLedgerIntegrity, "/")
+	if resp["is_valid"] != true || resp["entries_verified"] != float64(2) {
+		t.Errorf("verify response = %v, want is_valid=true entries_verified=2", resp)
+	}
+	if w, resp := doGet(t, "/entry/:sequence", GetLedgerEntry, "/entry/2"); w.Code != http.StatusOK || resp["entry"].(map[string]interface{})["sequence_number"] != float64(2) {
+		t.Errorf("get entry: status = %d, response = %v, want sequence 2", w.Code, resp)
+	}
+	if w, _ := doGet(t, "/entry/:sequence", GetLedgerEntry, "/entry/3"); w.Code != http.StatusNotFound {
+		t.Errorf("get missing entry: status = %d, want 404", w.Code)
+	}
+
+	w, resp := doJSON(t, CreateLedgerEntry, map[string]interface{}{"entry_type": "deposit", "amount": "10"})
+	if w.Code != http.StatusInsufficientStorage || resp["code"] != apierror.LedgerFull {
+		t.Errorf("full ledger: status/code = %d/%v, want 507/%s", w.Code, resp["code"], apierror.LedgerFull)
+	}
+}
+
+func TestCreateLedgerEntryStoreFailure(t *testing.T) {
+	store := useMockLedger(t)
+	store.Err = errors.New("database down")
+
+	w, _ := doJSON(t, CreateLedgerEntry, map[string]interface{}{"entry_type": "deposit", "amount": "10"})
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", w.Code)
+	}
+}
+
+func TestCreateLedgerEntryClientRef(t *testing.T) {
+	useMockLedger(t)
+
+	entryOf := func(resp map[string]interface{}) map[string]interface{} {
+		return resp["entry"].(map[string]interface{})
+	}
+
+	w, resp := doJSON(t, CreateLedgerEntry, map[string]interface{}{"entry_type": "deposit", "amount": "10", "client_ref": "ref-a"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("first insert status = %d, want 201", w.Code)
+	}
+	first := entryOf(resp)
+
+	w, resp = doJSON(t, CreateLedgerEntry, map[string]interface{}{"entry_type": "deposit", "amount": "10", "client_ref": "ref-a"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("duplicate ref status = %d, want 200", w.Code)
+	}
+	if dup := entryOf(resp); dup["sequence_number"] != first["sequence_number"] || dup["entry_hash"] != first["entry_hash"] {
+		t.Errorf("duplicate ref returned %v, want the original entry %v", dup["sequence_number"], first["sequence_number"])
+	}
+
+	w, resp = doJSON(t, CreateLedgerEntry, map[string]interface{}{"entry_type": "deposit", "amount": "10", "client_ref": "ref-b"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("distinct ref status = %d, want 201", w.Code)
+	}
+	if entryOf(resp)["sequence_number"] == first["sequence_number"] {
+		t.Error("distinct refs must create distinct entries")
+	}
+
+	// Sin referencia se mantiene el comportamiento anterior
+	for i := 0; i < 2; i++ {
+		if w, _ := doJSON(t, CreateLedgerEntry, map[string]interface{}{"entry_type": "deposit", "amount": "10"}); w.Code != http.StatusCreated {
+			t.Errorf("entry without ref: status = %d, want 201", w.Code)
+		}
+	}
+}
+
+func TestProcessTransactionIntegrityHash(t *testing.T) {
+	router := gin.New()
+	router.POST("/", ProcessTransaction)
+	w := postTransaction(router, "")
+
+	var resp struct {
+		Transaction Transaction `json:"transaction"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	tx := resp.Transaction
+	if tx.IntegrityHash == "" {
+		t.Fatal("integrity_hash must be populated")
+	}
+	if !verifyTransactionIntegrity(tx) {
+		t.Error("untouched transaction must verify")
+	}
+
+	tx.Amount = tx.Amount.Add(tx.Amount)
+	if verifyTransactionIntegrity(tx) {
+		t.Error("altered amount must fail verification")
+	}
+}
+
+func TestProcessTransactionReceipt(t *testing.T) {
+	SetIdempotencyStore(NewMemoryIdempotencyStore(time.Hour))
+	router := gin.New()
+	router.POST("/", ProcessTransaction)
+
+	decode := func(w *httptest.ResponseRecorder) (Transaction, security.Receipt) {
+		t.Helper()
+		var resp struct {
+			Transaction Transaction      `json:"transaction"`
+			Receipt     security.Receipt `json:"receipt"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp.Transaction, resp.Receipt
+	}
+
+	tx, receipt := decode(postTransaction(router, "receipt-key"))
+	if err := securityManager.VerifyReceipt(receipt); err != nil {
+		t.Fatalf("valid receipt rejected: %v", err)
+	}
+
+	// El payload es la transacción procesada, sin depender de la respuesta
+	var signed Transaction
+	if err := json.Unmarshal([]byte(receipt.Payload), &signed); err != nil {
+		t.Fatal(err)
+	}
+	if signed.ID != tx.ID || !signed.Amount.Equal(tx.Amount) || signed.Status != "completed" || signed.IntegrityHash != tx.IntegrityHash {
+		t.Errorf("signed transaction = %+v, want %+v", signed, tx)
+	}
+
+	tampered := receipt
+	tampered.Payload = strings.Replace(receipt.Payload, `"status":"completed"`, `"status":"reversed"`, 1)
+	if err := securityManager.VerifyReceipt(tampered); !errors.Is(err, security.ErrInvalidReceipt) {
+		t.Errorf("tampered receipt: err = %v, want ErrInvalidReceipt", err)
+	}
+
+	// Un reintento con la misma clave retorna el mismo comprobante
+	if _, replayed := decode(postTransaction(router, "receipt-key")); replayed != receipt {
+		t.Errorf("replayed receipt = %+v, want %+v", replayed, receipt)
+	}
+}
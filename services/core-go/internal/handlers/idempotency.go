@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fincore/core-go/internal/canonical"
+)
+
+const (
+	// IdempotencyKeyHeader es el header con el que los clientes identifican reintentos
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// DefaultIdempotencyTTL es el tiempo durante el cual se recuerda una clave
+	DefaultIdempotencyTTL = 24 * time.Hour
+	// maxIdempotencyKeyLength evita claves arbitrariamente grandes en el store
+	maxIdempotencyKeyLength = 255
+)
+
+// IdempotencyStore recuerda qué transacción produjo cada clave de idempotencia
+// y el hash del request que la reservó. Reserve/Complete/Release permiten
+// implementarlo sobre Redis (SETNX + EXPIRE).
+type IdempotencyStore interface {
+	// Lookup retorna la transacción registrada para la clave y el hash del
+	// request original si existe y no expiró
+	Lookup(key string) (tx Transaction, requestHash string, ok bool)
+	// Reserve marca la clave como en curso para el request con requestHash;
+	// retorna false si ya estaba reservada o completada
+	Reserve(key, requestHash string) bool
+	// Complete asocia la transacción resultante a una clave reservada
+	Complete(key string, tx Transaction)
+	// Release libera una reserva cuando la operación no llegó a completarse
+	Release(key string)
+}
+
+type idempotencyRecord struct {
+	transaction *Transaction
+	requestHash string
+	expiresAt   time.Time
+}
+
+// MemoryIdempotencyStore implementa IdempotencyStore en memoria con expiración
+type MemoryIdempotencyStore struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	records   map[string]idempotencyRecord
+	lastPurge time.Time
+	now       func() time.Time
+}
+
+// NewMemoryIdempotencyStore crea un store en memoria con el TTL dado
+func NewMemoryIdempotencyStore(ttl time.Duration) *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		ttl:     ttl,
+		records: make(map[string]idempotencyRecord),
+		now:     time.Now,
+	}
+}
+
+// Lookup retorna la transacción completada para la clave
+func (s *MemoryIdempotencyStore) Lookup(key string) (Transaction, string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[key]
+	if !ok || record.transaction == nil || s.now().After(record.expiresAt) {
+		return Transaction{}, "", false
+	}
+	return *record.transaction, record.requestHash, true
+}
+
+// Reserve reserva la clave si no existe o si su registro expiró
+func (s *MemoryIdempotencyStore) Reserve(key, requestHash string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.purgeExpired(now)
+	if record, ok := s.records[key]; ok && !now.After(record.expiresAt) {
+		return false
+	}
+	s.records[key] = idempotencyRecord{requestHash: requestHash, expiresAt: now.Add(s.ttl)}
+	return true
+}
+
+// Complete registra la transacción y reinicia el TTL de la clave
+func (s *MemoryIdempotencyStore) Complete(key string, tx Transaction) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[key] = idempotencyRecord{transaction: &tx, requestHash: s.records[key].requestHash, expiresAt: s.now().Add(s.ttl)}
+}
+
+// Release elimina una reserva pendiente
+func (s *MemoryIdempotencyStore) Release(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if record, ok := s.records[key]; ok && record.transaction == nil {
+		delete(s.records, key)
+	}
+}
+
+// purgeExpired elimina registros vencidos como máximo una vez por minuto;
+// se llama con el lock tomado
+func (s *MemoryIdempotencyStore) purgeExpired(now time.Time) {
+	if now.Sub(s.lastPurge) < time.Minute {
+		return
+	}
+	s.lastPurge = now
+
+	for key, record := range s.records {
+		if now.After(record.expiresAt) {
+			delete(s.records, key)
+		}
+	}
+}
+
+// idempotencyStore es el store usado por ProcessTransaction
+var idempotencyStore IdempotencyStore = NewMemoryIdempotencyStore(DefaultIdempotencyTTL)
+
+// SetIdempotencyStore reemplaza el store de claves de idempotencia
+func SetIdempotencyStore(store IdempotencyStore) {
+	idempotencyStore = store
+}
+
+// idempotencyStoreKey acota la clave del cliente a quien la envía: el
+// client_id de la firma HMAC si está activa, o el user_id de la transacción.
+// Así dos clientes que eligen la misma clave no reciben la transacción del
+// otro. Las longitudes evitan que "a:b" + "c" colisione con "a" + "b:c".
+func idempotencyStoreKey(clientID, userID, key string) string {
+	scope, owner := "user", userID
+	if clientID != "" {
+		scope, owner = "client", clientID
+	}
+	return fmt.Sprintf("%s:%d:%s:%s", scope, len(owner), owner, key)
+}
+
+// idempotencyRequestHash resume los campos ya normalizados del request; un
+// reintento sólo es legítimo si coincide con el request original
+func idempotencyRequestHash(fields map[string]interface{}) (string, error) {
+	data, err := canonical.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
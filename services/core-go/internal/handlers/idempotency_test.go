@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fincore/core-go/internal/apierror"
+	"github.com/gin-gonic/gin"
+)
+
+const sampleTransaction = `{"type":"deposit","user_id":"user-1","amount":"100"}`
+
+func postTransaction(router *gin.Engine, key string) *httptest.ResponseRecorder {
+	return postTransactionBody(router, key, sampleTransaction)
+}
+
+func postTransactionBody(router *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func transactionID(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var resp struct {
+		Transaction Transaction `json:"transaction"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	return resp.Transaction.ID
+}
+
+func TestProcessTransactionIdempotencyKey(t *testing.T) {
+	SetIdempotencyStore(NewMemoryIdempotencyStore(time.Hour))
+	router := gin.New()
+	router.POST("/", ProcessTransaction)
+
+	first := postTransaction(router, "key-1")
+	second := postTransaction(router, "key-1")
+	if first.Code != http.StatusOK || second.Code != http.StatusOK {
+		t.Fatalf("status = %d/%d, want 200/200", first.Code, second.Code)
+	}
+	if transactionID(t, first) != transactionID(t, second) {
+		t.Error("same Idempotency-Key must return the same transaction id")
+	}
+	if second.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("replayed response must set Idempotent-Replayed")
+	}
+
+	if other := postTransaction(router, "key-2"); transactionID(t, other) == transactionID(t, first) {
+		t.Error("a different key must create a new transaction")
+	}
+	if a, b := postTransaction(router, ""), postTransaction(router, ""); transactionID(t, a) == transactionID(t, b) {
+		t.Error("requests without key must not be deduplicated")
+	}
+}
+
+func TestProcessTransactionIdempotencyKeyScopedPerUser(t *testing.T) {
+	SetIdempotencyStore(NewMemoryIdempotencyStore(time.Hour))
+	router := gin.New()
+	router.POST("/", ProcessTransaction)
+
+	first := postTransactionBody(router, "1", `{"type":"deposit","user_id":"user-1","amount":"100"}`)
+	other := postTransactionBody(router, "1", `{"type":"deposit","user_id":"user-2","amount":"100"}`)
+	if first.Code != http.StatusOK || other.Code != http.StatusOK {
+		t.Fatalf("status = %d/%d, want 200/200", first.Code, other.Code)
+	}
+	if other.Header().Get("Idempotent-Replayed") == "true" || transactionID(t, other) == transactionID(t, first) {
+		t.Error("another user's request with the same key received the first user's transaction")
+	}
+
+	// Con la firma activa la clave es del client_id, no del usuario
+	signed := gin.New()
+	signed.POST("/", func(c *gin.Context) {
+		c.Set("client_id", c.GetHeader("X-Client-ID"))
+		ProcessTransaction(c)
+	})
+	post := func(clientID string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(sampleTransaction))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(IdempotencyKeyHeader, "signed-key")
+		req.Header.Set("X-Client-ID", clientID)
+		signed.ServeHTTP(w, req)
+		return w
+	}
+	a, b := post("client-a"), post("client-b")
+	if transactionID(t, a) == transactionID(t, b) {
+		t.Error("different clients with the same key must not share a transaction")
+	}
+	if again := post("client-a"); transactionID(t, again) != transactionID(t, a) {
+		t.Error("a retry from the same client must return its transaction")
+	}
+}
+
+func TestProcessTransactionIdempotencyKeyDifferentRequest(t *testing.T) {
+	SetIdempotencyStore(NewMemoryIdempotencyStore(time.Hour))
+	router := gin.New()
+	router.POST("/", ProcessTransaction)
+
+	if w := postTransaction(router, "key-1"); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	w := postTransactionBody(router, "key-1", `{"type":"deposit","user_id":"user-1","amount":"250"}`)
+	var resp map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusUnprocessableEntity || resp["code"] != apierror.IdempotencyKeyReused {
+		t.Errorf("status/code = %d/%v, want 422/%s", w.Code, resp["code"], apierror.IdempotencyKeyReused)
+	}
+
+	// Un reintento equivalente tras normalizar (moneda y tipo) sigue siendo el mismo request
+	equivalent := postTransactionBody(router, "key-1", `{"type":"DEPOSIT","user_id":"user-1","amount":"100.00","currency":"`+DefaultCurrency+`"}`)
+	if equivalent.Code != http.StatusOK || equivalent.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("equivalent retry: status = %d, replayed = %q, want 200 replayed", equivalent.Code, equivalent.Header().Get("Idempotent-Replayed"))
+	}
+}
+
+func TestProcessTransactionConcurrentDuplicates(t *testing.T) {
+	SetIdempotencyStore(NewMemoryIdempotencyStore(time.Hour))
+	router := gin.New()
+	router.POST("/", ProcessTransaction)
+
+	const total = 50
+	ids := make(chan string, total)
+	var wg sync.WaitGroup
+	for i := 0; i < total; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := postTransaction(router, "concurrent-key")
+			switch w.Code {
+			case http.StatusOK:
+				ids <- transactionID(t, w)
+			case http.StatusConflict:
+				// Petición concurrente mientras la original seguía en curso
+			default:
+				t.Errorf("unexpected status %d", w.Code)
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	unique := map[string]struct{}{}
+	for id := range ids {
+		unique[id] = struct{}{}
+	}
+	if len(unique) != 1 {
+		t.Errorf("created %d distinct transactions, want 1", len(unique))
+	}
+}
+
+func TestMemoryIdempotencyStoreExpiry(t *testing.T) {
+	store := NewMemoryIdempotencyStore(time.Minute)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	if !store.Reserve("k", "hash") {
+		t.Fatal("first reserve must succeed")
+	}
+	store.Complete("k", Transaction{ID: "tx-1"})
+	if _, hash, ok := store.Lookup("k"); !ok || hash != "hash" {
+		t.Fatalf("lookup = %q/%v, want the reserved request hash", hash, ok)
+	}
+	if store.Reserve("k", "hash") {
+		t.Fatal("reserve of a live key must fail")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, _, ok := store.Lookup("k"); ok {
+		t.Error("expired key must not be returned")
+	}
+	if !store.Reserve("k", "hash") {
+		t.Error("expired key must be reservable again")
+	}
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fincore/core-go/internal/apierror"
+	"github.com/fincore/core-go/internal/logging"
+	"github.com/fincore/core-go/internal/tracing"
+	"github.com/gin-gonic/gin"
+)
+
+// ledgerExportFlushRows es cada cuántas filas se envía un chunk al cliente
+const ledgerExportFlushRows = 500
+
+// ledgerExportHeader son las columnas del CSV exportado
+var ledgerExportHeader = []string{
+	"sequence", "previous_hash", "entry_hash", "type", "amount",
+	"currency", "description", "created_at", "is_verified",
+}
+
+// ExportLedger exporta las entradas del ledger como CSV para auditoría. Las
+// filas se escriben a medida que se leen del store y se envían en chunks, sin
+// cargar el ledger completo en memoria. from y to acotan el rango de
+// secuencias (ambos inclusive).
+func ExportLedger(c *gin.Context) {
+	if format := c.DefaultQuery("format", "csv"); format != "csv" {
+		apierror.RespondWith(c, http.StatusBadRequest, apierror.UnsupportedFormat, "Unsupported export format", gin.H{
+			"supported_formats": []string{"csv"},
+		})
+		return
+	}
+
+	from, ok := parseSequenceQuery(c, "from", 0)
+	if !ok {
+		return
+	}
+	to, ok := parseSequenceQuery(c, "to", math.MaxInt64)
+	if !ok {
+		return
+	}
+	if from > to {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidRange, "from must not be greater than to")
+		return
+	}
+
+	// La cabecera HTTP se envía con la primera fila, de modo que un fallo del
+	// store antes de leer ninguna entrada todavía puede responder con JSON
+	writer := csv.NewWriter(c.Writer)
+	started := false
+	start := func() error {
+		started = true
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="ledger.csv"`)
+		c.Status(http.StatusOK)
+		return writer.Write(ledgerExportHeader)
+	}
+
+	rows := 0
+	ctx, span := tracing.Start(c.Request.Context(), "ledger.export")
+	err := ledgerChain.StreamEntries(ctx, from, to, func(entry LedgerEntry) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if err := writer.Write([]string{
+			strconv.FormatInt(entry.SequenceNumber, 10),
+			entry.PreviousHash,
+			entry.EntryHash,
+			csvSafe(entry.EntryType),
+			entry.Amount.String(),
+			entry.Currency,
+			csvSafe(entry.Description),
+			entry.CreatedAt.UTC().Format(time.RFC3339Nano),
+			strconv.FormatBool(entry.IsVerified),
+		}); err != nil {
+			return err
+		}
+
+		rows++
+		if rows%ledgerExportFlushRows == 0 {
+			writer.Flush()
+			c.Writer.Flush()
+			return writer.Error()
+		}
+		return nil
+	})
+	if err == nil && !started {
+		// Rango vacío: sólo la fila de encabezado
+		err = start()
+	}
+	if err == nil {
+		writer.Flush()
+		err = writer.Error()
+	}
+	tracing.EndSpan(span, err)
+
+	if err != nil {
+		if started {
+			// El status ya se envió; cortar la respuesta para que el cliente
+			// no tome un CSV truncado por completo
+			logging.FromContext(c).Error("ledger export interrupted", "rows", rows, "error", err)
+			abortResponse(c)
+			return
+		}
+		if respondLedgerUnavailable(c, err) {
+			return
+		}
+		if respondClientClosed(c, err) {
+			return
+		}
+		logging.FromContext(c).Error("failed to read ledger", "error", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.Internal, "Failed to read ledger")
+	}
+}
+
+// abortResponse corta una respuesta cuyo status ya se envió. Cerrar la
+// conexión sin el chunk final hace que el cliente vea un error de transferencia
+// en lugar de un body completo; sin Hijack (HTTP/2) se usa ErrAbortHandler,
+// con el que net/http resetea el stream.
+func abortResponse(c *gin.Context) {
+	c.Abort()
+	if conn, _, err := c.Writer.Hijack(); err == nil {
+		conn.Close()
+		return
+	}
+	panic(http.ErrAbortHandler)
+}
+
+// parseSequenceQuery lee un número de secuencia no negativo del query string;
+// responde 400 y retorna false si no es válido
+func parseSequenceQuery(c *gin.Context, key string, fallback int64) (int64, bool) {
+	value := c.Query(key)
+	if value == "" {
+		return fallback, true
+	}
+	sequence, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || sequence < 0 {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidSequence, "Invalid "+key+" sequence number")
+		return 0, false
+	}
+	return sequence, true
+}
+
+// csvSafe antepone un apóstrofo a los textos que una hoja de cálculo
+// interpretaría como fórmula (inyección CSV)
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
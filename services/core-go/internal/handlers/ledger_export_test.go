@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fincore/core-go/internal/ledger"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+// exportLedger ejecuta ExportLedger con el query string dado
+func exportLedger(t *testing.T, query string) *httptest.ResponseRecorder {
+	t.Helper()
+	router := gin.New()
+	router.GET("/export", ExportLedger)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/export"+query, nil))
+	return w
+}
+
+// appendLedgerEntries agrega n depósitos al ledger configurado
+func appendLedgerEntries(t *testing.T, n int) []LedgerEntry {
+	t.Helper()
+	var entries []LedgerEntry
+	for i := 1; i <= n; i++ {
+		entry, err := ledgerChain.Append(context.Background(), LedgerEntry{
+			EntryType:   "deposit",
+			Amount:      decimal.RequireFromString("1500.25"),
+			Currency:    "MXN",
+			Description: "deposit " + strconv.Itoa(i),
+			CreatedAt:   time.Date(2026, 1, i, 12, 0, 0, 0, time.UTC),
+			IsVerified:  true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func readExport(t *testing.T, w *httptest.ResponseRecorder) [][]string {
+	t.Helper()
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%s)", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("Content-Type = %q, want text/csv", ct)
+	}
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	return records
+}
+
+func TestExportLedgerCSV(t *testing.T) {
+	useMockLedger(t)
+	entries := appendLedgerEntries(t, 3)
+
+	records := readExport(t, exportLedger(t, "?format=csv"))
+	if got := strings.Join(records[0], ","); got != "sequence,previous_hash,entry_hash,type,amount,currency,description,created_at,is_verified" {
+		t.Errorf("header = %s", got)
+	}
+	if len(records) != 4 {
+		t.Fatalf("rows = %d, want header + 3 entries", len(records))
+	}
+
+	second := entries[1]
+	want := []string{
+		"2", second.PreviousHash, second.EntryHash, "deposit", "1500.25",
+		"MXN", "deposit 2", "2026-01-02T12:00:00Z", "true",
+	}
+	if got := records[2]; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("row = %v, want %v", got, want)
+	}
+	if records[2][1] != entries[0].EntryHash {
+		t.Error("previous_hash does not link to the previous row")
+	}
+}
+
+func TestExportLedgerRange(t *testing.T) {
+	useMockLedger(t)
+	appendLedgerEntries(t, 5)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"?from=2&to=4", []string{"2", "3", "4"}},
+		{"?from=4", []string{"4", "5"}},
+		{"?to=1", []string{"1"}},
+		{"?from=9", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			records := readExport(t, exportLedger(t, tt.query))
+			var got []string
+			for _, record := range records[1:] {
+				got = append(got, record[0])
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("sequences = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportLedgerEscapesFormulas(t *testing.T) {
+	useMockLedger(t)
+	if _, err := ledgerChain.Append(context.Background(), LedgerEntry{
+		EntryType:   "deposit",
+		Amount:      decimal.NewFromInt(-10),
+		Description: "=HYPERLINK(\"http://example.com\")",
+		CreatedAt:   time.Now(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	records := readExport(t, exportLedger(t, ""))
+	if got := records[1][6]; !strings.HasPrefix(got, "'=") {
+		t.Errorf("description = %q, want the formula prefixed with '", got)
+	}
+	if got := records[1][4]; got != "-10" {
+		t.Errorf("amount = %q, want -10 unchanged", got)
+	}
+}
+
+func TestExportLedgerInvalidRequests(t *testing.T) {
+	useMockLedger(t)
+	for _, query := range []string{"?format=xlsx", "?from=abc", "?to=-1", "?from=5&to=2"} {
+		t.Run(query, func(t *testing.T) {
+			if w := exportLedger(t, query); w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", w.Code)
+			}
+		})
+	}
+}
+
+func TestExportLedgerStoreFailure(t *testing.T) {
+	store := useMockLedger(t)
+	store.Err = errors.New("database down")
+
+	w := exportLedger(t, "")
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("Content-Type = %q, want a JSON error", ct)
+	}
+}
+
+// failingStreamStore entrega las primeras entradas del rango y luego falla
+type failingStreamStore struct {
+	*ledger.MockLedgerStore
+	after int
+}
+
+func (s *failingStreamStore) StreamRange(ctx context.Context, from, to int64, fn func(LedgerEntry) error) error {
+	sent := 0
+	return s.MockLedgerStore.StreamRange(ctx, from, to, func(entry LedgerEntry) error {
+		if sent == s.after {
+			return errors.New("connection lost")
+		}
+		sent++
+		return fn(entry)
+	})
+}
+
+func TestExportLedgerFailsMidStream(t *testing.T) {
+	store := &failingStreamStore{MockLedgerStore: ledger.NewMockLedgerStore(), after: ledgerExportFlushRows + 10}
+	chain, err := ledger.NewLedgerChain(context.Background(), store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetLedgerChain(chain)
+	appendLedgerEntries(t, 2*ledgerExportFlushRows)
+
+	router := gin.New()
+	router.GET("/export", ExportLedger)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200 before the failure", resp.StatusCode)
+	}
+	// El cliente debe ver la transferencia cortada, no un CSV completo
+	if _, err := io.ReadAll(resp.Body); err == nil {
+		t.Error("read the truncated export without error")
+	}
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fincore/core-go/internal/apierror"
+	"github.com/fincore/core-go/internal/tracing"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// DefaultVelocityWindow es la ventana móvil sobre la que se suma el volumen por usuario
+	DefaultVelocityWindow = 24 * time.Hour
+)
+
+// deviceSensitiveTypes son los tipos que mueven fondos fuera de la cuenta y
+// se rechazan desde un dispositivo que el usuario no tiene registrado
+var deviceSensitiveTypes = map[string]bool{
+	"withdrawal": true,
+	"payout":     true,
+}
+
+// maxAmountByType contiene el monto máximo por tipo de transacción; los tipos
+// sin entrada no tienen límite
+var maxAmountByType = map[string]decimal.Decimal{}
+
+// SetMaxAmounts reemplaza los montos máximos por tipo de transacción
+func SetMaxAmounts(limits map[string]decimal.Decimal) {
+	normalized := make(map[string]decimal.Decimal, len(limits))
+	for txType, limit := range limits {
+		normalized[strings.ToLower(strings.TrimSpace(txType))] = limit
+	}
+	maxAmountByType = normalized
+}
+
+// maxAmountFor retorna el monto máximo configurado para el tipo
+func maxAmountFor(txType string) (decimal.Decimal, bool) {
+	limit, ok := maxAmountByType[txType]
+	return limit, ok
+}
+
+// policyViolation es el incumplimiento de una política de fraude o de
+// aprobación. ProcessTransaction lo responde con status y code; un elemento
+// de lote se rechaza con message como motivo.
+type policyViolation struct {
+	status  int
+	code    string
+	message string
+	details gin.H
+}
+
+// checkTransactionLimits aplica el monto máximo por tipo y el control de
+// dispositivo; es común a ProcessTransaction y a los lotes
+func checkTransactionLimits(txType string, amount decimal.Decimal, deviceRisk bool) *policyViolation {
+	if limit, ok := maxAmountFor(txType); ok && amount.GreaterThan(limit) {
+		return &policyViolation{
+			status:  http.StatusUnprocessableEntity,
+			code:    apierror.AmountLimitExceeded,
+			message: "Amount exceeds the limit for this transaction type",
+			details: gin.H{"max_amount": limit},
+		}
+	}
+	// Sacar fondos desde un dispositivo no reconocido requiere verificarlo antes
+	if deviceSensitiveTypes[txType] && deviceRisk {
+		return &policyViolation{status: http.StatusForbidden, code: apierror.DeviceRisk, message: "Unrecognized device"}
+	}
+	return nil
+}
+
+// reserveVelocity suma amount al volumen del usuario en la moneda. Retorna
+// una violación si supera el límite y un error si el store falló; un monto
+// reservado se devuelve con releaseVelocity si la transacción no se procesa.
+func reserveVelocity(ctx context.Context, userID, currency string, amount decimal.Decimal) (*policyViolation, error) {
+	if !velocityLimit.IsPositive() {
+		return nil, nil
+	}
+	velocityCtx, span := tracing.Start(ctx, "velocity_store.add")
+	_, accepted, err := velocityStore.Add(velocityCtx, velocityKey(userID, currency), amount, velocityLimit)
+	tracing.EndSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
+	if !accepted {
+		return &policyViolation{
+			status:  http.StatusUnprocessableEntity,
+			code:    apierror.VelocityLimitExceeded,
+			message: "Transaction volume exceeds the velocity limit",
+		}, nil
+	}
+	return nil, nil
+}
+
+// respondPolicyViolation responde la violación de una transacción individual
+func respondPolicyViolation(c *gin.Context, violation *policyViolation) {
+	if violation.details != nil {
+		apierror.RespondWith(c, violation.status, violation.code, violation.message, violation.details)
+		return
+	}
+	apierror.Respond(c, violation.status, violation.code, violation.message)
+}
+
+// VelocityStore acumula el volumen reciente por clave. Add debe ser atómico
+// para que dos requests concurrentes no superen juntos el límite; sobre Redis
+// se implementa con un script Lua sobre un sorted set por clave.
+type VelocityStore interface {
+	// Add suma amount al volumen de la ventana si el total resultante no
+	// supera limit; retorna el total vigente y si el monto fue aceptado
+	Add(ctx context.Context, key string, amount, limit decimal.Decimal) (decimal.Decimal, bool, error)
+	// Release descuenta un monto aceptado por Add cuya transacción no llegó
+	// a procesarse; si el monto ya salió de la ventana no hace nada
+	Release(ctx context.Context, key string, amount decimal.Decimal) error
+}
+
+type velocityEntry struct {
+	amount decimal.Decimal
+	at     time.Time
+}
+
+// MemoryVelocityStore implementa VelocityStore en memoria con una ventana móvil
+type MemoryVelocityStore struct {
+	mu      sync.Mutex
+	window  time.Duration
+	entries map[string][]velocityEntry
+	now     func() time.Time
+}
+
+// NewMemoryVelocityStore crea un store en memoria con la ventana dada
+func NewMemoryVelocityStore(window time.Duration) *MemoryVelocityStore {
+	return &MemoryVelocityStore{
+		window:  window,
+		entries: make(map[string][]velocityEntry),
+		now:     time.Now,
+	}
+}
+
+// Add descarta los montos fuera de la ventana y registra el nuevo si cabe en el límite
+func (s *MemoryVelocityStore) Add(_ context.Context, key string, amount, limit decimal.Decimal) (decimal.Decimal, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	cutoff := now.Add(-s.window)
+
+	entries := s.entries[key]
+	first := 0
+	for first < len(entries) && !entries[first].at.After(cutoff) {
+		first++
+	}
+	entries = entries[first:]
+
+	total := decimal.Zero
+	for _, entry := range entries {
+		total = total.Add(entry.amount)
+	}
+
+	if total.Add(amount).GreaterThan(limit) {
+		if len(entries) == 0 {
+			delete(s.entries, key)
+		} else {
+			s.entries[key] = entries
+		}
+		return total, false, nil
+	}
+
+	s.entries[key] = append(entries, velocityEntry{amount: amount, at: now})
+	return total.Add(amount), true, nil
+}
+
+// Release elimina el registro más reciente de la clave con ese monto
+func (s *MemoryVelocityStore) Release(_ context.Context, key string, amount decimal.Decimal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := s.entries[key]
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].amount.Equal(amount) {
+			entries = append(entries[:i], entries[i+1:]...)
+			break
+		}
+	}
+	if len(entries) == 0 {
+		delete(s.entries, key)
+	} else {
+		s.entries[key] = entries
+	}
+	return nil
+}
+
+// velocityLimit es el volumen máximo por usuario y moneda dentro de la
+// ventana; cero desactiva el control
+var velocityLimit decimal.Decimal
+
+// velocityStore es el store usado por ProcessTransaction
+var velocityStore VelocityStore = NewMemoryVelocityStore(DefaultVelocityWindow)
+
+// SetVelocityLimit configura el volumen máximo por ventana; cero lo desactiva
+func SetVelocityLimit(limit decimal.Decimal) {
+	velocityLimit = limit
+}
+
+// SetVelocityStore reemplaza el store de volumen por usuario
+func SetVelocityStore(store VelocityStore) {
+	velocityStore = store
+}
+
+// velocityKey agrupa el volumen por usuario y moneda para no sumar montos
+// en monedas distintas
+func velocityKey(userID, currency string) string {
+	return userID + ":" + strings.ToUpper(currency)
+}
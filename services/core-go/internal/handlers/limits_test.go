@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fincore/core-go/internal/apierror"
+	"github.com/fincore/core-go/internal/security"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+func TestProcessTransactionMaxAmountPerType(t *testing.T) {
+	SetMaxAmounts(map[string]decimal.Decimal{"Withdrawal": decimal.NewFromInt(1000)})
+	defer SetMaxAmounts(nil)
+
+	w, resp := doJSON(t, ProcessTransaction, map[string]interface{}{
+		"type":    "withdrawal",
+		"user_id": "user-limit",
+		"amount":  "1000.01",
+	})
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want 422", w.Code)
+	}
+	if resp["code"] != apierror.AmountLimitExceeded {
+		t.Errorf("code = %v, want %s", resp["code"], apierror.AmountLimitExceeded)
+	}
+
+	// Otros tipos y montos dentro del límite se procesan
+	for _, body := range []map[string]interface{}{
+		{"type": "withdrawal", "user_id": "user-limit", "amount": "1000"},
+		{"type": "deposit", "user_id": "user-limit", "amount": "5000"},
+	} {
+		if w, _ := doJSON(t, ProcessTransaction, body); w.Code != http.StatusOK {
+			t.Errorf("%v: status = %d, want 200", body, w.Code)
+		}
+	}
+}
+
+func TestProcessTransactionVelocityLimit(t *testing.T) {
+	SetVelocityStore(NewMemoryVelocityStore(time.Hour))
+	SetVelocityLimit(decimal.NewFromInt(1000))
+	defer func() {
+		SetVelocityLimit(decimal.Zero)
+		SetVelocityStore(NewMemoryVelocityStore(DefaultVelocityWindow))
+	}()
+
+	deposit := func(userID, amount string) (int, map[string]interface{}) {
+		w, resp := doJSON(t, ProcessTransaction, map[string]interface{}{
+			"type":    "deposit",
+			"user_id": userID,
+			"amount":  amount,
+		})
+		return w.Code, resp
+	}
+
+	for i, amount := range []string{"400", "400", "200"} {
+		if code, _ := deposit("user-velocity", amount); code != http.StatusOK {
+			t.Fatalf("deposit %d: status = %d, want 200", i, code)
+		}
+	}
+
+	code, resp := deposit("user-velocity", "0.01")
+	if code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want 422", code)
+	}
+	if resp["code"] != apierror.VelocityLimitExceeded {
+		t.Errorf("code = %v, want %s", resp["code"], apierror.VelocityLimitExceeded)
+	}
+
+	// El volumen es por usuario
+	if code, _ := deposit("other-user", "500"); code != http.StatusOK {
+		t.Errorf("other user: status = %d, want 200", code)
+	}
+}
+
+func TestProcessTransactionVelocityLimitIgnoresInventedCurrencies(t *testing.T) {
+	SetVelocityStore(NewMemoryVelocityStore(time.Hour))
+	SetVelocityLimit(decimal.NewFromInt(1000))
+	defer func() {
+		SetVelocityLimit(decimal.Zero)
+		SetVelocityStore(NewMemoryVelocityStore(DefaultVelocityWindow))
+	}()
+
+	deposit := func(currency string) (int, map[string]interface{}) {
+		w, resp := doJSON(t, ProcessTransaction, map[string]interface{}{
+			"type":     "deposit",
+			"user_id":  "user-currency",
+			"amount":   "1000",
+			"currency": currency,
+		})
+		return w.Code, resp
+	}
+
+	if code, _ := deposit("MXN"); code != http.StatusOK {
+		t.Fatalf("first deposit: status = %d, want 200", code)
+	}
+	for _, currency := range []string{"ZZ1", "not-a-currency", "btc1"} {
+		if code, resp := deposit(currency); code != http.StatusBadRequest || resp["code"] != apierror.InvalidCurrency {
+			t.Errorf("currency %q: status/code = %d/%v, want 400/%s", currency, code, resp["code"], apierror.InvalidCurrency)
+		}
+	}
+
+	// Mayúsculas y espacios no crean otra clave de velocidad
+	if code, resp := deposit(" mxn "); code != http.StatusUnprocessableEntity || resp["code"] != apierror.VelocityLimitExceeded {
+		t.Errorf("lowercase MXN: status/code = %d/%v, want 422/%s", code, resp["code"], apierror.VelocityLimitExceeded)
+	}
+	code, resp := deposit("btc")
+	if code != http.StatusOK {
+		t.Fatalf("btc: status = %d, want 200", code)
+	}
+	if got := resp["transaction"].(map[string]interface{})["currency"]; got != "BTC" {
+		t.Errorf("currency = %v, want BTC", got)
+	}
+}
+
+func TestProcessTransactionReleasesVelocityOnFailure(t *testing.T) {
+	SetVelocityStore(NewMemoryVelocityStore(time.Hour))
+	SetVelocityLimit(decimal.NewFromInt(1000))
+	defer func() {
+		SetVelocityLimit(decimal.Zero)
+		SetVelocityStore(NewMemoryVelocityStore(DefaultVelocityWindow))
+	}()
+	store := useMockTransactionStore(t)
+	ledgerStore := useMockLedger(t)
+
+	body := map[string]interface{}{
+		"type":                "deposit",
+		"user_id":             "user-release",
+		"amount":              "1000",
+		"create_ledger_entry": true,
+	}
+
+	// Falla al guardar la transacción
+	store.Err = errors.New("connection refused")
+	if w, _ := doJSON(t, ProcessTransaction, body); w.Code != http.StatusInternalServerError {
+		t.Fatalf("insert failure: status = %d, want 500", w.Code)
+	}
+	store.Err = nil
+
+	// Falla el ledger y la transacción se compensa
+	ledgerStore.Err = errors.New("connection refused")
+	if w, _ := doJSON(t, ProcessTransaction, body); w.Code != http.StatusInternalServerError {
+		t.Fatalf("ledger failure: status = %d, want 500", w.Code)
+	}
+	ledgerStore.Err = nil
+
+	// Ninguno de los intentos fallidos cuenta para el límite
+	if w, resp := doJSON(t, ProcessTransaction, body); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%v)", w.Code, resp)
+	}
+	if w, _ := doJSON(t, ProcessTransaction, body); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("over the limit: status = %d, want 422", w.Code)
+	}
+}
+
+func TestMemoryVelocityStoreRelease(t *testing.T) {
+	store := NewMemoryVelocityStore(time.Hour)
+	ctx := context.Background()
+	limit := decimal.NewFromInt(100)
+
+	for _, amount := range []int64{30, 50} {
+		if _, ok, _ := store.Add(ctx, "user", decimal.NewFromInt(amount), limit); !ok {
+			t.Fatalf("amount %d must be accepted", amount)
+		}
+	}
+	if err := store.Release(ctx, "user", decimal.NewFromInt(50)); err != nil {
+		t.Fatal(err)
+	}
+	// Un monto que no está registrado no cambia el total
+	if err := store.Release(ctx, "user", decimal.NewFromInt(7)); err != nil {
+		t.Fatal(err)
+	}
+	if total, ok, _ := store.Add(ctx, "user", decimal.NewFromInt(70), limit); !ok || !total.Equal(limit) {
+		t.Errorf("after release: ok = %v, total = %s, want 100", ok, total)
+	}
+}
+
+func TestMemoryVelocityStoreWindowExpiry(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryVelocityStore(time.Hour)
+	store.now = func() time.Time { return now }
+
+	limit := decimal.NewFromInt(100)
+	if _, ok, _ := store.Add(context.Background(), "user", decimal.NewFromInt(100), limit); !ok {
+		t.Fatal("first amount must be accepted")
+	}
+	if total, ok, _ := store.Add(context.Background(), "user", decimal.NewFromInt(1), limit); ok || !total.Equal(decimal.NewFromInt(100)) {
+		t.Fatalf("over-limit amount: ok = %v, total = %s", ok, total)
+	}
+
+	now = now.Add(time.Hour + time.Second)
+	if total, ok, _ := store.Add(context.Background(), "user", decimal.NewFromInt(1), limit); !ok || !total.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("after window: ok = %v, total = %s", ok, total)
+	}
+}
+
+// fromUnknownDevice registra un dispositivo para userID y retorna handler
+// invocado desde otro dispositivo
+func fromUnknownDevice(t *testing.T, userID string, handler gin.HandlerFunc) gin.HandlerFunc {
+	t.Helper()
+	SetDeviceStore(security.NewMemoryDeviceStore(0, 0))
+	if _, err := deviceStore.Bind(context.Background(), userID, "known-device"); err != nil {
+		t.Fatal(err)
+	}
+	return func(c *gin.Context) {
+		c.Set("device_fingerprint", "unknown-device")
+		handler(c)
+	}
+}
+
+func TestProcessTransactionRejectsRiskyDevice(t *testing.T) {
+	risky := fromUnknownDevice(t, "user-device", ProcessTransaction)
+
+	w, resp := doJSON(t, risky, map[string]interface{}{
+		"type":    "withdrawal",
+		"user_id": "user-device",
+		"amount":  "10",
+	})
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403", w.Code)
+	}
+	if resp["code"] != apierror.DeviceRisk {
+		t.Errorf("code = %v, want %s", resp["code"], apierror.DeviceRisk)
+	}
+
+	// Los depósitos no sacan fondos y se procesan igual
+	if w, _ := doJSON(t, risky, map[string]interface{}{
+		"type":    "deposit",
+		"user_id": "user-device",
+		"amount":  "10",
+	}); w.Code != http.StatusOK {
+		t.Errorf("deposit: status = %d, want 200", w.Code)
+	}
+}
+
+func TestBatchProcessAppliesTransactionPolicies(t *testing.T) {
+	SetMaxAmounts(map[string]decimal.Decimal{"Withdrawal": decimal.NewFromInt(10)})
+	defer SetMaxAmounts(nil)
+	useApprovalPolicy(t, "500", 2)
+	SetVelocityStore(NewMemoryVelocityStore(time.Hour))
+	SetVelocityLimit(decimal.NewFromInt(1000))
+	defer func() {
+		SetVelocityLimit(decimal.Zero)
+		SetVelocityStore(NewMemoryVelocityStore(DefaultVelocityWindow))
+	}()
+
+	risky := fromUnknownDevice(t, "user-batch", BatchProcess)
+	w, resp := doJSON(t, risky, map[string]interface{}{
+		"transactions": []map[string]interface{}{
+			{"type": "withdrawal", "user_id": "user-batch", "amount": "1000000"},
+			{"type": "withdrawal", "user_id": "user-batch", "amount": "5"},
+			{"type": "deposit", "user_id": "user-batch", "amount": "600"},
+			{"type": "deposit", "user_id": "user-batch", "amount": "400"},
+		},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	want := []string{
+		"Amount exceeds the limit for this transaction type",
+		"Unrecognized device",
+		"Amount requires approval; submit it as an individual transaction",
+		"",
+	}
+	results := resp["transactions"].([]interface{})
+	for i, reason := range want {
+		tx := results[i].(map[string]interface{})
+		status := "rejected"
+		if reason == "" {
+			status = "completed"
+		}
+		if tx["status"] != status || (reason != "" && tx["rejection_reason"] != reason) {
+			t.Errorf("transaction %d = %v/%v, want %s/%q", i, tx["status"], tx["rejection_reason"], status, reason)
+		}
+	}
+
+	// Lotes, stream y transacciones individuales comparten el volumen: 400 +
+	// 400 + 300 supera el límite
+	if w, _ := doJSON(t, ProcessTransaction, map[string]interface{}{
+		"type": "deposit", "user_id": "user-batch", "amount": "400",
+	}); w.Code != http.StatusOK {
+		t.Fatalf("single deposit: status = %d, want 200", w.Code)
+	}
+	router := gin.New()
+	router.POST("/", BatchProcessStream)
+	stream := httptest.NewRecorder()
+	router.ServeHTTP(stream, httptest.NewRequest(http.MethodPost, "/",
+		strings.NewReader(`{"type":"deposit","user_id":"user-batch","amount":"300"}`)))
+	streamed, _ := decodeStream(t, stream.Body)
+	if tx := streamed[1].Transaction; tx == nil || tx.Status != "rejected" || tx.RejectionReason != "Transaction volume exceeds the velocity limit" {
+		t.Errorf("stream line = %+v, want velocity rejection", streamed[1])
+	}
+}
@@ -0,0 +1,350 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/fincore/core-go/internal/finance"
+)
+
+// periodosPorAnioPorDefecto corresponde a flujos mensuales
+const periodosPorAnioPorDefecto = 12
+
+// frecuenciasCapitalizacion son las capitalizaciones por año aceptadas para
+// una tasa nominal anual
+var frecuenciasCapitalizacion = map[string]int{
+	"anual":      1,
+	"semestral":  2,
+	"trimestral": 4,
+	"mensual":    12,
+	"diaria":     365,
+}
+
+// SolicitudMetricas son los parámetros de CalcularMetricas, compartidos por el
+// endpoint HTTP y el servicio gRPC
+type SolicitudMetricas struct {
+	// InversionInicial es el desembolso del periodo 0 con signo de costo:
+	// positiva es una inversión (flujo inicial -inversion_inicial) y
+	// negativa un ingreso inicial neto, como un subsidio o una subvención.
+	// Es puntero para que un cero explícito llegue a CalcularMetricas y se
+	// rechace con 400 en lugar de tomarse como campo ausente.
+	InversionInicial *float64  `json:"inversion_inicial" binding:"required"`
+	FlujosIngresos   []float64 `json:"flujos_ingresos" binding:"required"`
+	FlujosCostos     []float64 `json:"flujos_costos"`
+	// TasaDescuento puede omitirse si se envía wacc, del que se deriva
+	TasaDescuento *float64        `json:"tasa_descuento"`
+	WACC          *ParametrosWACC `json:"wacc"`
+
+	// TasaEsAnual indica que la tasa de descuento (explícita o del WACC) es
+	// nominal anual y se convierte a la tasa efectiva de cada periodo de
+	// flujos; por defecto ya es la tasa por periodo. FrecuenciaCapitalizacion
+	// ("anual", "semestral", "trimestral", "mensual" o "diaria") es la de la
+	// tasa nominal y por defecto coincide con periodos_por_anio.
+	TasaEsAnual              bool   `json:"tasa_es_anual"`
+	FrecuenciaCapitalizacion string `json:"frecuencia_capitalizacion"`
+
+	// Tasas opcionales para la TIR modificada
+	TasaFinanciamiento *float64 `json:"tasa_financiamiento"`
+	TasaReinversion    *float64 `json:"tasa_reinversion"`
+
+	// Strict rechaza flujos_costos más largos que flujos_ingresos (por defecto true);
+	// con strict=false los costos sobrantes se ignoran
+	Strict *bool `json:"strict"`
+
+	// Moneda opcional de los flujos (ISO-4217), se devuelve con el resultado
+	Currency string `json:"currency"`
+
+	// Barrido opcional de tasas para el análisis de sensibilidad del VAN
+	Sensibilidad *RangoSensibilidad `json:"sensibilidad"`
+
+	// Convencion de descuento: "fin_periodo" (por defecto) o "mitad_periodo"
+	Convencion string `json:"convencion"`
+
+	// Redondeo opcional (número de decimales) de los montos de salida; los
+	// valores sin redondear se siguen devolviendo
+	Redondeo *int `json:"redondeo" binding:"omitempty,min=0,max=8"`
+
+	// PeriodosPorAnio convierte el número de flujos en años para el ROI
+	// anualizado (por defecto 12, flujos mensuales)
+	PeriodosPorAnio *int `json:"periodos_por_anio" binding:"omitempty,min=1"`
+
+	// InflacionAnual opcional deflacta los flujos netos a moneda de hoy para
+	// calcular además el VAN real. tasa_descuento es nominal: el VAN real
+	// descuenta los flujos reales con la tasa real (1+tasa)/(1+inflación del
+	// periodo) - 1, de modo que ambos VAN coinciden salvo redondeo
+	InflacionAnual *float64 `json:"inflacion_anual" binding:"omitempty,gt=-1"`
+}
+
+// ResultadoMetricas son las métricas calculadas por CalcularMetricas. TIR,
+// MIRR, EAA y ROIAnualizado son nil cuando no están definidas y el motivo va en el campo
+// de error correspondiente.
+type ResultadoMetricas struct {
+	VAN                    float64   `json:"van"`
+	ROI                    float64   `json:"roi"`
+	ROIAnualizado          *float64  `json:"roi_anualizado"`
+	TIR                    *float64  `json:"tir"`
+	TIRTolerancia          float64   `json:"tir_tolerancia"`
+	MIRR                   *float64  `json:"mirr"`
+	PaybackMeses           float64   `json:"payback_meses"`
+	RecuperaInversion      bool      `json:"recupera_inversion"`
+	PaybackDescontadoMeses float64   `json:"payback_descontado_meses"`
+	RecuperaDescontado     bool      `json:"recupera_descontado"`
+	IndiceRentabilidad     float64   `json:"profitability_index"`
+	EAA                    *float64  `json:"eaa"`
+	Convencion             string    `json:"convencion"`
+	EsViable               bool      `json:"es_viable"`
+	FlujosNetos            []float64 `json:"flujos_netos"`
+
+	Currency  string `json:"currency,omitempty"`
+	TIRError  string `json:"tir_error,omitempty"`
+	MIRRError string `json:"mirr_error,omitempty"`
+	EAAError  string `json:"eaa_error,omitempty"`
+
+	ROIAnualizadoError string `json:"roi_anualizado_error,omitempty"`
+
+	// TasaDescuento es la tasa por periodo aplicada; sólo se informa cuando
+	// se derivó del WACC o de una tasa anual. WACC sólo con el WACC.
+	TasaDescuento *float64       `json:"tasa_descuento,omitempty"`
+	WACC          *ResultadoWACC `json:"wacc,omitempty"`
+
+	// VANNominal y VANReal sólo se informan con inflacion_anual; VAN sigue
+	// siendo el nominal
+	VANNominal *float64 `json:"van_nominal,omitempty"`
+	VANReal    *float64 `json:"van_real,omitempty"`
+
+	Sensibilidad *ResultadoSensibilidad `json:"sensibilidad,omitempty"`
+	Redondeado   *MontosRedondeados     `json:"redondeado,omitempty"`
+}
+
+// ResultadoSensibilidad es el barrido del VAN y la tasa en que cruza cero
+type ResultadoSensibilidad struct {
+	Puntos         []PuntoSensibilidad `json:"puntos"`
+	TasaEquilibrio *float64            `json:"tasa_equilibrio"`
+}
+
+// MontosRedondeados son los montos de ResultadoMetricas con redondeo bancario
+type MontosRedondeados struct {
+	Decimales   int32     `json:"decimales"`
+	VAN         float64   `json:"van"`
+	EAA         *float64  `json:"eaa"`
+	FlujosNetos []float64 `json:"flujos_netos"`
+}
+
+// CalcularMetricas calcula VAN, ROI, TIR, MIRR, payback, índice de
+// rentabilidad y EAA. Sólo retorna error cuando la solicitud es inválida; las
+// métricas no definidas para los flujos dados se informan en el resultado.
+func CalcularMetricas(req SolicitudMetricas) (ResultadoMetricas, error) {
+	if req.InversionInicial == nil {
+		return ResultadoMetricas{}, errors.New("inversion_inicial is required")
+	}
+	inversionInicial := *req.InversionInicial
+	// El índice de rentabilidad no está definido sin inversión inicial
+	if inversionInicial == 0 {
+		return ResultadoMetricas{}, errors.New("inversion_inicial must be non-zero")
+	}
+	if req.Redondeo != nil && (*req.Redondeo < 0 || *req.Redondeo > 8) {
+		return ResultadoMetricas{}, errors.New("redondeo must be between 0 and 8")
+	}
+	periodosPorAnio := periodosPorAnioPorDefecto
+	if req.PeriodosPorAnio != nil {
+		if *req.PeriodosPorAnio < 1 {
+			return ResultadoMetricas{}, errors.New("periodos_por_anio must be at least 1")
+		}
+		periodosPorAnio = *req.PeriodosPorAnio
+	}
+
+	// Una tasa explícita tiene prioridad sobre el WACC
+	var tasaDescuento float64
+	var wacc *ResultadoWACC
+	switch {
+	case req.TasaDescuento != nil:
+		tasaDescuento = *req.TasaDescuento
+	case req.WACC != nil:
+		resultado, err := calcularWACC(*req.WACC)
+		if err != nil {
+			return ResultadoMetricas{}, err
+		}
+		wacc = &resultado
+		tasaDescuento = resultado.WACC.InexactFloat64()
+	default:
+		return ResultadoMetricas{}, errors.New("tasa_descuento or wacc is required")
+	}
+
+	// Una tasa nominal anual se descuenta con su equivalente por periodo
+	if req.TasaEsAnual {
+		capitalizaciones := periodosPorAnio
+		if frecuencia := strings.ToLower(strings.TrimSpace(req.FrecuenciaCapitalizacion)); frecuencia != "" {
+			var ok bool
+			if capitalizaciones, ok = frecuenciasCapitalizacion[frecuencia]; !ok {
+				return ResultadoMetricas{}, errors.New("frecuencia_capitalizacion must be 'anual', 'semestral', 'trimestral', 'mensual' or 'diaria'")
+			}
+		}
+		tasaPeriodo, err := finance.PeriodRate(tasaDescuento, capitalizaciones, periodosPorAnio)
+		if err != nil {
+			return ResultadoMetricas{}, err
+		}
+		tasaDescuento = tasaPeriodo
+	} else if req.FrecuenciaCapitalizacion != "" {
+		return ResultadoMetricas{}, errors.New("frecuencia_capitalizacion requires tasa_es_anual")
+	}
+
+	if (req.TasaFinanciamiento == nil) != (req.TasaReinversion == nil) {
+		return ResultadoMetricas{}, errors.New("tasa_financiamiento and tasa_reinversion must be provided together")
+	}
+
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if currency != "" && !esCodigoISO4217(currency) {
+		return ResultadoMetricas{}, ErrInvalidCurrency
+	}
+
+	strict := req.Strict == nil || *req.Strict
+	if strict && len(req.FlujosCostos) > len(req.FlujosIngresos) {
+		return ResultadoMetricas{}, errors.New("flujos_costos cannot be longer than flujos_ingresos")
+	}
+
+	convencion := strings.ToLower(strings.TrimSpace(req.Convencion))
+	if convencion == "" {
+		convencion = convencionFinPeriodo
+	}
+	if convencion != convencionFinPeriodo && convencion != convencionMitadPeriodo {
+		return ResultadoMetricas{}, errors.New("convencion must be 'fin_periodo' or 'mitad_periodo'")
+	}
+
+	if req.Sensibilidad != nil {
+		if err := req.Sensibilidad.validar(); err != nil {
+			return ResultadoMetricas{}, err
+		}
+	}
+
+	flujosNetos := finance.NetFlows(req.FlujosIngresos, req.FlujosCostos)
+
+	desplazamiento := desplazamientoConvencion(convencion)
+	van := finance.NPVOffset(tasaDescuento, inversionInicial, flujosNetos, desplazamiento)
+
+	// VAN real: la inversión ya está en moneda del periodo 0 y los flujos
+	// reales se descuentan con la tasa real, no con la nominal
+	var vanReal *float64
+	if req.InflacionAnual != nil {
+		flujosReales, err := finance.DeflateFlowsOffset(flujosNetos, *req.InflacionAnual, periodosPorAnio, desplazamiento)
+		if err != nil {
+			return ResultadoMetricas{}, err
+		}
+		tasaReal, err := finance.RealRate(tasaDescuento, *req.InflacionAnual, periodosPorAnio)
+		if err != nil {
+			return ResultadoMetricas{}, err
+		}
+		resultado := finance.NPVOffset(tasaReal, inversionInicial, flujosReales, desplazamiento)
+		vanReal = &resultado
+	}
+
+	// Anualidad equivalente: permite comparar proyectos de distinta duración
+	var eaa *float64
+	eaaResultado, eaaErr := finance.EAA(van, tasaDescuento, len(flujosNetos))
+	if eaaErr == nil {
+		eaa = &eaaResultado
+	}
+
+	indiceRentabilidad := finance.ProfitabilityIndex(van, inversionInicial)
+
+	// TIR (null cuando los flujos no la admiten), con la misma convención que
+	// el VAN para que coincida con la tasa de equilibrio
+	var tir *float64
+	tirResultado, tirTolerancia, tirErr := finance.IRROffset(inversionInicial, flujosNetos, desplazamiento)
+	if tirErr == nil {
+		tir = &tirResultado
+	}
+
+	// TIR modificada sólo cuando se proporcionan ambas tasas
+	var mirr *float64
+	var mirrErr error
+	if req.TasaFinanciamiento != nil {
+		var resultado float64
+		resultado, mirrErr = finance.MIRR(inversionInicial, flujosNetos, *req.TasaFinanciamiento, *req.TasaReinversion)
+		if mirrErr == nil {
+			mirr = &resultado
+		}
+	}
+
+	// Payback simple y descontado
+	payback, recuperaInversion := finance.Payback(inversionInicial, flujosNetos)
+	flujosDescontados := finance.DiscountFlows(tasaDescuento, flujosNetos, desplazamiento)
+	paybackDescontado, recuperaDescontado := finance.Payback(inversionInicial, flujosDescontados)
+
+	roi := finance.ROI(inversionInicial, flujosNetos)
+
+	// ROI anual compuesto (null con pérdida total o sin flujos)
+	var roiAnualizado *float64
+	roiAnualizadoResultado, roiAnualizadoErr := finance.AnnualizedROI(roi, len(flujosNetos), periodosPorAnio)
+	if roiAnualizadoErr == nil {
+		roiAnualizado = &roiAnualizadoResultado
+	}
+
+	resultado := ResultadoMetricas{
+		VAN:                    van,
+		ROI:                    roi,
+		ROIAnualizado:          roiAnualizado,
+		TIR:                    tir,
+		TIRTolerancia:          tirTolerancia,
+		MIRR:                   mirr,
+		PaybackMeses:           payback,
+		RecuperaInversion:      recuperaInversion,
+		PaybackDescontadoMeses: paybackDescontado,
+		RecuperaDescontado:     recuperaDescontado,
+		IndiceRentabilidad:     indiceRentabilidad,
+		EAA:                    eaa,
+		Convencion:             convencion,
+		EsViable:               van > 0 && indiceRentabilidad > 1,
+		FlujosNetos:            flujosNetos,
+		Currency:               currency,
+	}
+	if tirErr != nil {
+		resultado.TIRError = tirErr.Error()
+	}
+	if mirrErr != nil {
+		resultado.MIRRError = mirrErr.Error()
+	}
+	if eaaErr != nil {
+		resultado.EAAError = eaaErr.Error()
+	}
+	if roiAnualizadoErr != nil {
+		resultado.ROIAnualizadoError = roiAnualizadoErr.Error()
+	}
+	if wacc != nil || req.TasaEsAnual {
+		resultado.TasaDescuento = &tasaDescuento
+		resultado.WACC = wacc
+	}
+	if vanReal != nil {
+		resultado.VANNominal = &van
+		resultado.VANReal = vanReal
+	}
+	if req.Redondeo != nil {
+		resultado.Redondeado = redondearMontos(int32(*req.Redondeo), van, eaa, flujosNetos)
+	}
+	if req.Sensibilidad != nil {
+		puntos, tasaEquilibrio := calcularSensibilidad(inversionInicial, flujosNetos, *req.Sensibilidad, desplazamiento)
+		resultado.Sensibilidad = &ResultadoSensibilidad{
+			Puntos:         puntos,
+			TasaEquilibrio: tasaEquilibrio,
+		}
+	}
+	return resultado, nil
+}
+
+// redondearMontos retorna los montos de CalcularMetricas redondeados
+func redondearMontos(decimales int32, van float64, eaa *float64, flujosNetos []float64) *MontosRedondeados {
+	flujos := make([]float64, len(flujosNetos))
+	for i, flujo := range flujosNetos {
+		flujos[i] = redondearBancario(flujo, decimales)
+	}
+	var eaaRedondeado *float64
+	if eaa != nil {
+		valor := redondearBancario(*eaa, decimales)
+		eaaRedondeado = &valor
+	}
+	return &MontosRedondeados{
+		Decimales:   decimales,
+		VAN:         redondearBancario(van, decimales),
+		EAA:         eaaRedondeado,
+		FlujosNetos: flujos,
+	}
+}
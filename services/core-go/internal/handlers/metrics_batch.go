@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fincore/core-go/internal/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// CalculateMetricsBatch calcula las métricas de varios proyectos con el pool
+// de workers. Cada proyecto se valida y calcula por separado: uno inválido se
+// informa en errors y su posición en results queda en null, sin afectar al
+// resto del lote.
+func CalculateMetricsBatch(c *gin.Context) {
+	startTime := time.Now()
+
+	var req struct {
+		Proyectos []json.RawMessage `json:"proyectos" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request")
+		return
+	}
+
+	if len(req.Proyectos) > maxBatchSize {
+		apierror.RespondWith(c, http.StatusRequestEntityTooLarge, apierror.BatchTooLarge, "Batch too large", gin.H{
+			"max_batch_size": maxBatchSize,
+		})
+		return
+	}
+
+	// Cada worker escribe sólo en su índice, igual que BatchProcess
+	results := make([]*ResultadoMetricas, len(req.Proyectos))
+	itemErrs := make([]error, len(req.Proyectos))
+	err := runWorkerPool(c.Request.Context(), len(req.Proyectos), func(index int) {
+		resultado, err := calcularMetricasLote(req.Proyectos[index])
+		if err != nil {
+			itemErrs[index] = err
+			return
+		}
+		results[index] = &resultado
+	})
+	if err != nil {
+		if respondClientClosed(c, err) {
+			return
+		}
+		apierror.Respond(c, http.StatusGatewayTimeout, apierror.ProcessingTimeout, "Batch processing timed out")
+		return
+	}
+
+	itemErrors := []BatchItemError{}
+	for i, err := range itemErrs {
+		if err != nil {
+			itemErrors = append(itemErrors, BatchItemError{Index: i, Error: err.Error()})
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":            len(itemErrors) == 0,
+		"results":            results,
+		"errors":             itemErrors,
+		"total_processed":    len(results) - len(itemErrors),
+		"total_rejected":     len(itemErrors),
+		"processing_time_us": time.Since(startTime).Microseconds(),
+	})
+}
+
+// calcularMetricasLote decodifica y valida un proyecto del lote con las mismas
+// reglas de binding que CalculateMetrics antes de calcular sus métricas
+func calcularMetricasLote(raw json.RawMessage) (ResultadoMetricas, error) {
+	var req SolicitudMetricas
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return ResultadoMetricas{}, errors.New("invalid project: " + err.Error())
+	}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		var validationErrors validator.ValidationErrors
+		if !errors.As(err, &validationErrors) {
+			return ResultadoMetricas{}, err
+		}
+		messages := make([]string, 0, len(validationErrors))
+		for _, fe := range validationErrors {
+			messages = append(messages, fieldErrorMessage(fe))
+		}
+		return ResultadoMetricas{}, errors.New(strings.Join(messages, "; "))
+	}
+	return CalcularMetricas(req)
+}
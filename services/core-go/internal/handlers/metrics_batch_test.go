@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/fincore/core-go/internal/finance"
+)
+
+func TestCalculateMetricsBatchIsolatesErrors(t *testing.T) {
+	flujos := []float64{500, 500, 500}
+	w, resp := doJSON(t, CalculateMetricsBatch, map[string]interface{}{
+		"proyectos": []interface{}{
+			map[string]interface{}{"inversion_inicial": 1000, "flujos_ingresos": flujos, "tasa_descuento": 0.1},
+			map[string]interface{}{"inversion_inicial": 1000, "flujos_ingresos": flujos},
+			map[string]interface{}{"flujos_ingresos": flujos, "tasa_descuento": 0.1},
+			map[string]interface{}{"inversion_inicial": 1000, "flujos_ingresos": "no-es-un-arreglo"},
+			map[string]interface{}{"inversion_inicial": 2000, "flujos_ingresos": flujos, "tasa_descuento": 0.05},
+		},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%v)", w.Code, resp)
+	}
+	if resp["success"] != false || resp["total_processed"] != 2.0 || resp["total_rejected"] != 3.0 {
+		t.Errorf("summary = success %v, processed %v, rejected %v; want false, 2, 3",
+			resp["success"], resp["total_processed"], resp["total_rejected"])
+	}
+
+	results, _ := resp["results"].([]interface{})
+	if len(results) != 5 {
+		t.Fatalf("results = %v, want one per project", resp["results"])
+	}
+	for _, tt := range []struct {
+		index int
+		van   float64
+	}{
+		{0, finance.NPV(0.1, 1000, flujos)},
+		{4, finance.NPV(0.05, 2000, flujos)},
+	} {
+		metrics, ok := results[tt.index].(map[string]interface{})
+		if !ok {
+			t.Fatalf("results[%d] = %v, want metrics", tt.index, results[tt.index])
+		}
+		if metrics["van"] != tt.van {
+			t.Errorf("results[%d].van = %v, want %v", tt.index, metrics["van"], tt.van)
+		}
+	}
+
+	wantErrors := map[int]string{
+		1: "tasa_descuento or wacc is required",
+		2: "inversion_inicial is required",
+		3: "invalid project",
+	}
+	itemErrors, _ := resp["errors"].([]interface{})
+	if len(itemErrors) != len(wantErrors) {
+		t.Fatalf("errors = %v, want %d", itemErrors, len(wantErrors))
+	}
+	for _, raw := range itemErrors {
+		item := raw.(map[string]interface{})
+		index := int(item["index"].(float64))
+		if results[index] != nil {
+			t.Errorf("results[%d] = %v, want null for a failed project", index, results[index])
+		}
+		if msg, _ := item["error"].(string); !strings.HasPrefix(msg, wantErrors[index]) || wantErrors[index] == "" {
+			t.Errorf("errors[%d] = %q, want %q", index, msg, wantErrors[index])
+		}
+	}
+}
+
+func TestCalculateMetricsBatchLimits(t *testing.T) {
+	if w, _ := doJSON(t, CalculateMetricsBatch, map[string]interface{}{}); w.Code != http.StatusBadRequest {
+		t.Errorf("missing proyectos: status = %d, want 400", w.Code)
+	}
+
+	SetMaxBatchSize(2)
+	t.Cleanup(func() { SetMaxBatchSize(DefaultMaxBatchSize) })
+	proyecto := map[string]interface{}{"inversion_inicial": 1000, "flujos_ingresos": []float64{500}, "tasa_descuento": 0.1}
+	w, _ := doJSON(t, CalculateMetricsBatch, map[string]interface{}{
+		"proyectos": []interface{}{proyecto, proyecto, proyecto},
+	})
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want 413", w.Code)
+	}
+}
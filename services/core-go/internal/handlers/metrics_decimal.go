@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fincore/core-go/internal/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/shopspring/decimal"
+)
+
+// SolicitudMetricasDecimal es SolicitudMetricas con la inversión y los flujos
+// como decimales, igual que los montos de las transacciones. Cada monto puede
+// enviarse como número o como string ("1234.56"); los campos de igual nombre
+// ocultan a los float64 de SolicitudMetricas al decodificar.
+type SolicitudMetricasDecimal struct {
+	SolicitudMetricas
+	InversionInicial json.RawMessage   `json:"inversion_inicial"`
+	FlujosIngresos   []json.RawMessage `json:"flujos_ingresos"`
+	FlujosCostos     []json.RawMessage `json:"flujos_costos"`
+}
+
+// CalculateMetricsDecimal calcula las mismas métricas que CalculateMetrics a
+// partir de montos decimales. Un monto que no es un decimal válido (por
+// ejemplo "1,234.56") se informa con 422 y el campo exacto, como
+// flujos_ingresos[2]. metrics sigue siendo el cálculo en float64; con la
+// convención fin_periodo, metrics_decimal trae además VAN, ROI y flujos netos
+// calculados en decimal sobre los montos tal como llegaron, con la tasa por
+// periodo aplicada. Con mitad_periodo el descuento usa potencias
+// fraccionarias y sólo se devuelve metrics.
+func CalculateMetricsDecimal(c *gin.Context) {
+	startTime := time.Now()
+
+	// Las reglas de binding se verifican después de convertir los montos
+	var req SolicitudMetricasDecimal
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request")
+		return
+	}
+
+	solicitud, montos, fields := req.aSolicitudMetricas()
+	if len(fields) > 0 {
+		apierror.RespondWith(c, http.StatusUnprocessableEntity, apierror.ValidationFailed, "Validation failed", gin.H{
+			"fields": fields,
+		})
+		return
+	}
+	if err := binding.Validator.ValidateStruct(&solicitud); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+	// El VAN decimal tiene el mismo límite que el cálculo preciso
+	if len(montos.flujosIngresos) > MaxMetricsPreciseFlows || len(montos.flujosCostos) > MaxMetricsPreciseFlows {
+		apierror.RespondWith(c, http.StatusBadRequest, apierror.InvalidParameter, fmt.Sprintf("flujos_ingresos and flujos_costos cannot have more than %d flows", MaxMetricsPreciseFlows), gin.H{
+			"max_flows": MaxMetricsPreciseFlows,
+		})
+		return
+	}
+
+	metrics, err := CalcularMetricas(solicitud)
+	if err != nil {
+		respondInvalidParameter(c, err)
+		return
+	}
+
+	response := gin.H{
+		"success": true,
+		"metrics": metrics,
+	}
+	if metrics.Convencion == convencionFinPeriodo {
+		// La tasa aplicada es la derivada del WACC o de la tasa anual si la hubo
+		tasa := metrics.TasaDescuento
+		if tasa == nil {
+			tasa = solicitud.TasaDescuento
+		}
+		flujosNetos := flujosNetosDecimal(montos.flujosIngresos, montos.flujosCostos)
+		van := calcularVANDecimal(montos.inversionInicial, decimal.NewFromFloat(*tasa), flujosNetos, DefaultMetricsPrecision)
+		response["metrics_decimal"] = gin.H{
+			"van":          van,
+			"roi":          calcularROIDecimal(montos.inversionInicial, flujosNetos, DefaultMetricsPrecision),
+			"es_viable":    van.IsPositive(),
+			"flujos_netos": flujosNetos,
+			"precision":    DefaultMetricsPrecision,
+		}
+	}
+	response["processing_time_us"] = time.Since(startTime).Microseconds()
+	c.JSON(http.StatusOK, response)
+}
+
+// montosDecimales son la inversión y los flujos de SolicitudMetricasDecimal
+// sin convertir a float64
+type montosDecimales struct {
+	inversionInicial decimal.Decimal
+	flujosIngresos   []decimal.Decimal
+	flujosCostos     []decimal.Decimal
+}
+
+// aSolicitudMetricas convierte los montos decimales a la solicitud de
+// CalcularMetricas y los conserva también como decimales; retorna un
+// FieldError por cada monto inválido
+func (req SolicitudMetricasDecimal) aSolicitudMetricas() (SolicitudMetricas, montosDecimales, []FieldError) {
+	solicitud := req.SolicitudMetricas
+	var montos montosDecimales
+	var fields []FieldError
+
+	if req.InversionInicial != nil {
+		value, converted, fieldErr := parseMontoDecimal("inversion_inicial", req.InversionInicial)
+		if fieldErr != nil {
+			fields = append(fields, *fieldErr)
+		}
+		montos.inversionInicial = value
+		solicitud.InversionInicial = &converted
+	}
+
+	convertir := func(field string, raws []json.RawMessage) ([]float64, []decimal.Decimal) {
+		if raws == nil {
+			return nil, nil
+		}
+		flujos := make([]float64, len(raws))
+		decimales := make([]decimal.Decimal, len(raws))
+		for i, raw := range raws {
+			value, converted, fieldErr := parseMontoDecimal(fmt.Sprintf("%s[%d]", field, i), raw)
+			if fieldErr != nil {
+				fields = append(fields, *fieldErr)
+			}
+			flujos[i], decimales[i] = converted, value
+		}
+		return flujos, decimales
+	}
+	solicitud.FlujosIngresos, montos.flujosIngresos = convertir("flujos_ingresos", req.FlujosIngresos)
+	solicitud.FlujosCostos, montos.flujosCostos = convertir("flujos_costos", req.FlujosCostos)
+	return solicitud, montos, fields
+}
+
+// parseMontoDecimal interpreta un monto JSON, número o string, como decimal
+// con punto decimal y sin separadores de miles; retorna el decimal y su
+// conversión a float64
+func parseMontoDecimal(field string, raw json.RawMessage) (decimal.Decimal, float64, *FieldError) {
+	var text string
+	if err := json.Unmarshal(raw, &text); err != nil {
+		text = string(raw)
+	}
+
+	value, err := decimal.NewFromString(strings.TrimSpace(text))
+	if err != nil {
+		return decimal.Zero, 0, &FieldError{
+			Field:   field,
+			Rule:    "decimal",
+			Message: fmt.Sprintf("%s must be a decimal number with '.' as decimal separator and no thousands separators, got %s", field, raw),
+		}
+	}
+	converted := value.InexactFloat64()
+	if math.IsInf(converted, 0) {
+		return decimal.Zero, 0, &FieldError{Field: field, Rule: "range", Message: fmt.Sprintf("%s is out of range", field)}
+	}
+	return value, converted, nil
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/fincore/core-go/internal/apierror"
+)
+
+func TestCalculateMetricsDecimalMatchesFloatVariant(t *testing.T) {
+	_, want := doJSON(t, CalculateMetrics, map[string]interface{}{
+		"inversion_inicial": 1000,
+		"flujos_ingresos":   []float64{400, 450.5, 500},
+		"flujos_costos":     []float64{50, 50},
+		"tasa_descuento":    0.1,
+	})
+
+	// Números y strings decimales son equivalentes
+	w, resp := doJSON(t, CalculateMetricsDecimal, map[string]interface{}{
+		"inversion_inicial": "1000.00",
+		"flujos_ingresos":   []interface{}{"400", 450.5, " 500 "},
+		"flujos_costos":     []interface{}{50, "50.0"},
+		"tasa_descuento":    0.1,
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%v)", w.Code, resp)
+	}
+	got, expected := resp["metrics"].(map[string]interface{}), want["metrics"].(map[string]interface{})
+	for _, field := range []string{"van", "roi", "tir", "payback_meses"} {
+		if got[field] != expected[field] {
+			t.Errorf("%s = %v, want %v", field, got[field], expected[field])
+		}
+	}
+}
+
+func TestCalculateMetricsDecimalReportsMalformedFields(t *testing.T) {
+	w, resp := doJSON(t, CalculateMetricsDecimal, map[string]interface{}{
+		"inversion_inicial": "1.000,00",
+		"flujos_ingresos":   []interface{}{"400", "1,234.56", true},
+		"flujos_costos":     []interface{}{nil},
+		"tasa_descuento":    0.1,
+	})
+	if w.Code != http.StatusUnprocessableEntity || resp["code"] != apierror.ValidationFailed {
+		t.Fatalf("status/code = %d/%v, want 422/%s", w.Code, resp["code"], apierror.ValidationFailed)
+	}
+
+	fields := map[string]string{}
+	for _, f := range resp["fields"].([]interface{}) {
+		field := f.(map[string]interface{})
+		fields[field["field"].(string)] = field["rule"].(string)
+	}
+	want := map[string]string{
+		"inversion_inicial":  "decimal",
+		"flujos_ingresos[1]": "decimal",
+		"flujos_ingresos[2]": "decimal",
+		"flujos_costos[0]":   "decimal",
+	}
+	if len(fields) != len(want) {
+		t.Fatalf("fields = %v, want %v", fields, want)
+	}
+	for field, rule := range want {
+		if fields[field] != rule {
+			t.Errorf("field %s rule = %q, want %q", field, fields[field], rule)
+		}
+	}
+}
+
+func TestCalculateMetricsDecimalValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   map[string]interface{}
+		status int
+		code   string
+	}{
+		{"sin flujos", map[string]interface{}{"inversion_inicial": "1000"}, http.StatusUnprocessableEntity, apierror.ValidationFailed},
+		{"fuera de rango", map[string]interface{}{"inversion_inicial": "1e400", "flujos_ingresos": []string{"1"}}, http.StatusUnprocessableEntity, apierror.ValidationFailed},
+		{"inversión cero", map[string]interface{}{"inversion_inicial": "0", "flujos_ingresos": []string{"1"}, "tasa_descuento": 0.1}, http.StatusBadRequest, apierror.InvalidParameter},
+		{"sin inversión", map[string]interface{}{"flujos_ingresos": []string{"1"}, "tasa_descuento": 0.1}, http.StatusUnprocessableEntity, apierror.ValidationFailed},
+		{"demasiados flujos", map[string]interface{}{"inversion_inicial": "1000", "flujos_ingresos": make([]int, MaxMetricsPreciseFlows+1), "tasa_descuento": 0.1}, http.StatusBadRequest, apierror.InvalidParameter},
+		{"costos más largos", map[string]interface{}{"inversion_inicial": "1000", "flujos_ingresos": []string{"1"}, "flujos_costos": []string{"1", "2"}}, http.StatusBadRequest, apierror.InvalidParameter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, resp := doJSON(t, CalculateMetricsDecimal, tt.body)
+			if w.Code != tt.status || resp["code"] != tt.code {
+				t.Errorf("status/code = %d/%v, want %d/%s (%v)", w.Code, resp["code"], tt.status, tt.code, resp)
+			}
+		})
+	}
+}
+
+func TestCalculateMetricsDecimalKeepsDecimalAmounts(t *testing.T) {
+	// 0.1 + 0.2 no es exacto en float64; el resultado decimal sí lo es
+	body := map[string]interface{}{
+		"inversion_inicial": "0.3",
+		"flujos_ingresos":   []string{"0.1", "0.2"},
+		"tasa_descuento":    0,
+	}
+	w, resp := doJSON(t, CalculateMetricsDecimal, body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%v)", w.Code, resp)
+	}
+	metrics, ok := resp["metrics_decimal"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("metrics_decimal missing: %v", resp)
+	}
+	if metrics["van"] != "0" || metrics["roi"] != "0" || metrics["es_viable"] != false {
+		t.Errorf("metrics_decimal = %v, want van and roi exactly 0", metrics)
+	}
+	// Las métricas en float64 se siguen devolviendo
+	if _, ok := resp["metrics"].(map[string]interface{})["tir"]; !ok {
+		t.Errorf("metrics = %v, want the float64 metrics", resp["metrics"])
+	}
+
+	// Con la tasa aplicada igual que el cálculo preciso
+	body = map[string]interface{}{
+		"inversion_inicial": "1000",
+		"flujos_ingresos":   []string{"550", "605"},
+		"tasa_descuento":    0.1,
+	}
+	_, resp = doJSON(t, CalculateMetricsDecimal, body)
+	_, precise := doJSON(t, CalculateMetricsPrecise, body)
+	got, want := resp["metrics_decimal"].(map[string]interface{}), precise["metrics"].(map[string]interface{})
+	if got["van"] != want["van"] || got["roi"] != want["roi"] {
+		t.Errorf("metrics_decimal van/roi = %v/%v, want %v/%v", got["van"], got["roi"], want["van"], want["roi"])
+	}
+
+	// Con mitad_periodo sólo hay métricas en float64
+	body["convencion"] = "mitad_periodo"
+	if _, resp := doJSON(t, CalculateMetricsDecimal, body); resp["metrics_decimal"] != nil {
+		t.Errorf("metrics_decimal = %v, want omitted for mitad_periodo", resp["metrics_decimal"])
+	}
+}
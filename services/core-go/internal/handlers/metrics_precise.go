@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fincore/core-go/internal/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// DefaultMetricsPrecision es el número de decimales de VAN y ROI en el cálculo preciso
+	DefaultMetricsPrecision = 18
+	// MaxMetricsPrecision acota el costo de las divisiones
+	MaxMetricsPrecision = 40
+	// MaxMetricsPreciseFlows acota el número de flujos del cálculo preciso
+	// (100 años de flujos mensuales)
+	MaxMetricsPreciseFlows = 1200
+	// digitosGuarda son los decimales extra que se conservan en los pasos
+	// intermedios para que el redondeo final sea exacto
+	digitosGuarda = 4
+)
+
+// CalculateMetricsPrecise calcula VAN y ROI con aritmética decimal. Es más
+// lento que CalculateMetrics pero no acumula error de punto flotante, por lo
+// que conviene cuando el resultado se usa para decisiones sobre dinero.
+func CalculateMetricsPrecise(c *gin.Context) {
+	startTime := time.Now()
+
+	var req struct {
+		InversionInicial decimal.Decimal   `json:"inversion_inicial" binding:"required"`
+		FlujosIngresos   []decimal.Decimal `json:"flujos_ingresos" binding:"required"`
+		FlujosCostos     []decimal.Decimal `json:"flujos_costos"`
+		TasaDescuento    decimal.Decimal   `json:"tasa_descuento"`
+
+		// Precision es el número de decimales del resultado (por defecto 18)
+		Precision *int32 `json:"precision"`
+
+		// Strict rechaza flujos_costos más largos que flujos_ingresos (por defecto true)
+		Strict *bool `json:"strict"`
+
+		Currency string `json:"currency"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request")
+		return
+	}
+
+	if req.InversionInicial.IsZero() {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidParameter, "inversion_inicial must be non-zero")
+		return
+	}
+	if req.TasaDescuento.LessThanOrEqual(decimal.NewFromInt(-1)) {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidParameter, "tasa_descuento must be greater than -1")
+		return
+	}
+
+	precision := int32(DefaultMetricsPrecision)
+	if req.Precision != nil {
+		precision = *req.Precision
+	}
+	if precision < 0 || precision > MaxMetricsPrecision {
+		apierror.RespondWith(c, http.StatusBadRequest, apierror.InvalidParameter, "Invalid precision", gin.H{
+			"max_precision": MaxMetricsPrecision,
+		})
+		return
+	}
+
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if currency != "" && !esCodigoISO4217(currency) {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidCurrency, "Invalid currency code")
+		return
+	}
+
+	strict := req.Strict == nil || *req.Strict
+	if strict && len(req.FlujosCostos) > len(req.FlujosIngresos) {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidParameter, "flujos_costos cannot be longer than flujos_ingresos")
+		return
+	}
+	if len(req.FlujosIngresos) > MaxMetricsPreciseFlows || len(req.FlujosCostos) > MaxMetricsPreciseFlows {
+		apierror.RespondWith(c, http.StatusBadRequest, apierror.InvalidParameter, fmt.Sprintf("flujos_ingresos and flujos_costos cannot have more than %d flows", MaxMetricsPreciseFlows), gin.H{
+			"max_flows": MaxMetricsPreciseFlows,
+		})
+		return
+	}
+
+	flujosNetos := flujosNetosDecimal(req.FlujosIngresos, req.FlujosCostos)
+	van := calcularVANDecimal(req.InversionInicial, req.TasaDescuento, flujosNetos, precision)
+	roi := calcularROIDecimal(req.InversionInicial, flujosNetos, precision)
+
+	metrics := gin.H{
+		"van":          van,
+		"roi":          roi,
+		"es_viable":    van.IsPositive(),
+		"flujos_netos": flujosNetos,
+		"precision":    precision,
+	}
+	if currency != "" {
+		metrics["currency"] = currency
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":            true,
+		"metrics":            metrics,
+		"processing_time_us": time.Since(startTime).Microseconds(),
+	})
+}
+
+// flujosNetosDecimal es la versión decimal de finance.NetFlows; los costos
+// sin ingreso correspondiente se ignoran
+func flujosNetosDecimal(ingresos, costos []decimal.Decimal) []decimal.Decimal {
+	flujosNetos := make([]decimal.Decimal, len(ingresos))
+	for i, ingreso := range ingresos {
+		flujosNetos[i] = ingreso
+		if i < len(costos) {
+			flujosNetos[i] = ingreso.Sub(costos[i])
+		}
+	}
+	return flujosNetos
+}
+
+// calcularROIDecimal es la versión decimal de finance.ROI. Igual que en
+// CalculateMetrics, una inversión negativa (subsidio) no invierte el signo
+func calcularROIDecimal(inversionInicial decimal.Decimal, flujos []decimal.Decimal, precision int32) decimal.Decimal {
+	totalFlujos := decimal.Zero
+	for _, flujo := range flujos {
+		totalFlujos = totalFlujos.Add(flujo)
+	}
+	return totalFlujos.Sub(inversionInicial).DivRound(inversionInicial.Abs(), precision)
+}
+
+// calcularVANDecimal es la versión decimal de finance.NPV. El factor de
+// descuento (1+tasa)^(i+1) se acumula por multiplicación redondeada a las
+// cifras significativas que necesita el resultado: sin redondeo ganaría los
+// decimales de la tasa en cada periodo. Esas cifras cubren la parte entera
+// del mayor flujo, precision decimales con dígitos de guarda y el error que
+// se acumula en len(flujos) pasos; el resultado se redondea a precision
+// decimales.
+func calcularVANDecimal(inversionInicial, tasa decimal.Decimal, flujos []decimal.Decimal, precision int32) decimal.Decimal {
+	var mayor decimal.Decimal
+	for _, flujo := range flujos {
+		if flujo.Abs().GreaterThan(mayor) {
+			mayor = flujo.Abs()
+		}
+	}
+	cifrasFactor := precision + digitosGuarda + cifrasEnteras(mayor) + cifrasEnteras(decimal.NewFromInt(int64(len(flujos))))
+
+	base := decimal.NewFromInt(1).Add(tasa)
+	factor := decimal.NewFromInt(1)
+
+	van := inversionInicial.Neg()
+	for _, flujo := range flujos {
+		factor = redondearSignificativos(factor.Mul(base), cifrasFactor)
+		van = van.Add(flujo.DivRound(factor, precision+digitosGuarda))
+	}
+	return van.Round(precision)
+}
+
+// cifrasEnteras retorna el número de cifras de la parte entera de |d|
+func cifrasEnteras(d decimal.Decimal) int32 {
+	if cifras := cifrasCoeficiente(d) + d.Exponent(); cifras > 0 {
+		return cifras
+	}
+	return 0
+}
+
+// redondearSignificativos redondea d a cifras cifras significativas
+func redondearSignificativos(d decimal.Decimal, cifras int32) decimal.Decimal {
+	coeficiente := cifrasCoeficiente(d)
+	if d.IsZero() || coeficiente <= cifras {
+		return d
+	}
+	return d.Round(cifras - coeficiente - d.Exponent())
+}
+
+// cifrasCoeficiente retorna el número de dígitos del coeficiente de d
+func cifrasCoeficiente(d decimal.Decimal) int32 {
+	return int32(len(new(big.Int).Abs(d.Coefficient()).Text(10)))
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fincore/core-go/internal/finance"
+	"github.com/shopspring/decimal"
+)
+
+// flujosTreintaPeriodos retorna 30 flujos crecientes con centavos
+func flujosTreintaPeriodos() []decimal.Decimal {
+	flujos := make([]decimal.Decimal, 30)
+	for i := range flujos {
+		flujos[i] = decimal.RequireFromString("10234.57").Add(decimal.RequireFromString("101.13").Mul(decimal.NewFromInt(int64(i))))
+	}
+	return flujos
+}
+
+func TestCalcularVANDecimalThirtyPeriods(t *testing.T) {
+	flujos := flujosTreintaPeriodos()
+	inversion := decimal.NewFromInt(250000)
+	tasa := decimal.RequireFromString("0.0125")
+
+	// Referencia calculada a mano con 60 dígitos significativos
+	referencia := decimal.RequireFromString("38886.912625514527")
+
+	precisa := calcularVANDecimal(inversion, tasa, flujos, 12)
+	if !precisa.Equal(referencia.Round(12)) {
+		t.Errorf("decimal VAN = %s, want %s", precisa, referencia.Round(12))
+	}
+
+	flotantes := make([]float64, len(flujos))
+	for i, flujo := range flujos {
+		flotantes[i] = flujo.InexactFloat64()
+	}
+	flotante := finance.NPV(0.0125, 250000, flotantes)
+	if diff := math.Abs(flotante - referencia.InexactFloat64()); diff > 1e-6 {
+		t.Errorf("float VAN = %v differs from reference by %g", flotante, diff)
+	}
+	t.Logf("float VAN = %.12f, decimal VAN = %s", flotante, precisa)
+}
+
+func TestCalcularVANDecimalManyPeriodsStaysBounded(t *testing.T) {
+	flujos := make([]decimal.Decimal, MaxMetricsPreciseFlows)
+	for i := range flujos {
+		flujos[i] = decimal.RequireFromString("98765432.19")
+	}
+	tasa := decimal.RequireFromString("0.012345678901234567")
+
+	// Con el factor exacto cada periodo le suma los 18 decimales de la tasa
+	start := time.Now()
+	van := calcularVANDecimal(decimal.NewFromInt(1000000), tasa, flujos, 18)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("calcularVANDecimal took %v for %d flows", elapsed, len(flujos))
+	}
+
+	// Referencia: anualidad F·(1-(1+r)^-n)/r - I con el factor exacto y 80 decimales
+	uno := decimal.NewFromInt(1)
+	exacto := uno.Add(tasa).Pow(decimal.NewFromInt(int64(len(flujos))))
+	anualidad := uno.Sub(uno.DivRound(exacto, 80)).DivRound(tasa, 80)
+	referencia := decimal.RequireFromString("98765432.19").Mul(anualidad).Sub(decimal.NewFromInt(1000000)).Round(18)
+	if !van.Equal(referencia) {
+		t.Errorf("VAN = %s, want %s", van, referencia)
+	}
+}
+
+func TestCalculateMetricsPrecise(t *testing.T) {
+	w, resp := doJSON(t, CalculateMetricsPrecise, map[string]interface{}{
+		"inversion_inicial": "250000",
+		"flujos_ingresos":   flujosTreintaPeriodos(),
+		"tasa_descuento":    "0.0125",
+		"precision":         6,
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+
+	metrics := metricsOf(t, resp)
+	if metrics["van"] != "38886.912626" {
+		t.Errorf("van = %v, want 38886.912626", metrics["van"])
+	}
+	// ROI = (351028.65 - 250000) / 250000 = 0.4041146, redondeado a 6 decimales
+	if metrics["roi"] != "0.404115" {
+		t.Errorf("roi = %v, want 0.404115", metrics["roi"])
+	}
+	if metrics["es_viable"] != true {
+		t.Error("positive VAN must be viable")
+	}
+}
+
+func TestCalculateMetricsPreciseSubsidioInicial(t *testing.T) {
+	_, resp := doJSON(t, CalculateMetricsPrecise, map[string]interface{}{
+		"inversion_inicial": "-200",
+		"flujos_ingresos":   []string{"100", "100"},
+		"tasa_descuento":    "0",
+		"precision":         2,
+	})
+
+	metrics := metricsOf(t, resp)
+	if metrics["van"] != "400" || metrics["roi"] != "2" {
+		t.Errorf("van/roi = %v/%v, want 400/2", metrics["van"], metrics["roi"])
+	}
+}
+
+func TestCalculateMetricsPreciseValidation(t *testing.T) {
+	cases := []map[string]interface{}{
+		{"inversion_inicial": "0", "flujos_ingresos": []string{"1"}, "tasa_descuento": "0.1"},
+		{"inversion_inicial": "100", "flujos_ingresos": []string{"1"}, "tasa_descuento": "-1"},
+		{"inversion_inicial": "100", "flujos_ingresos": []string{"1"}, "tasa_descuento": "0.1", "precision": MaxMetricsPrecision + 1},
+		{"inversion_inicial": "100", "flujos_ingresos": []string{"1"}, "flujos_costos": []string{"1", "2"}, "tasa_descuento": "0.1"},
+		{"inversion_inicial": "100", "flujos_ingresos": make([]string, MaxMetricsPreciseFlows+1), "tasa_descuento": "0.1"},
+	}
+	for _, body := range cases {
+		if w, _ := doJSON(t, CalculateMetricsPrecise, body); w.Code != http.StatusBadRequest {
+			t.Errorf("%v: status = %d, want 400", body, w.Code)
+		}
+	}
+}
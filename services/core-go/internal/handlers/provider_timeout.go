@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// DefaultProviderTimeout es la espera máxima por defecto de cada proveedor externo
+const DefaultProviderTimeout = 2 * time.Second
+
+// ErrProviderTimeout indica que un proveedor externo no respondió a tiempo
+var ErrProviderTimeout = errors.New("provider timed out")
+
+// Espera máxima por proveedor en ValidateTransfer; se fija al iniciar el servicio
+var (
+	exchangeRateTimeout = DefaultProviderTimeout
+	balanceTimeout      = DefaultProviderTimeout
+)
+
+// SetExchangeRateTimeout configura la espera máxima del proveedor de tipos de
+// cambio; valores no positivos restauran el valor por defecto
+func SetExchangeRateTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultProviderTimeout
+	}
+	exchangeRateTimeout = timeout
+}
+
+// SetBalanceTimeout configura la espera máxima del proveedor de saldos;
+// valores no positivos restauran el valor por defecto
+func SetBalanceTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultProviderTimeout
+	}
+	balanceTimeout = timeout
+}
+
+// callProvider llama a fn con un contexto acotado por timeout y deja de
+// esperarla cuando vence o cuando se cancela ctx, aunque el proveedor ignore
+// el contexto. Retorna ErrProviderTimeout si vence el plazo propio y
+// ctx.Err() si terminó el request.
+func callProvider[T any](ctx context.Context, timeout time.Duration, fn func(ctx context.Context) (T, error)) (T, error) {
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		value T
+		err   error
+	}
+	// Con buffer para que la goroutine termine aunque ya nadie la espere
+	done := make(chan result, 1)
+	go func() {
+		value, err := fn(callCtx)
+		done <- result{value, err}
+	}()
+
+	select {
+	case r := <-done:
+		// Un proveedor que respeta el contexto retorna su propio error de plazo
+		if errors.Is(r.err, context.DeadlineExceeded) && ctx.Err() == nil {
+			r.err = ErrProviderTimeout
+		}
+		return r.value, r.err
+	case <-callCtx.Done():
+		var zero T
+		if err := ctx.Err(); err != nil {
+			return zero, err
+		}
+		return zero, ErrProviderTimeout
+	}
+}
+
+// isProviderUnavailable indica si el proveedor no llegó a responder, a
+// diferencia de una respuesta negativa como ErrRateUnavailable
+func isProviderUnavailable(err error) bool {
+	return errors.Is(err, ErrProviderTimeout) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// slowRateProvider tarda delay en responder sin mirar el contexto
+type slowRateProvider struct{ delay time.Duration }
+
+func (p slowRateProvider) Rate(_ context.Context, _, _ string) (decimal.Decimal, error) {
+	time.Sleep(p.delay)
+	return decimal.NewFromInt(17), nil
+}
+
+// slowBalanceProvider tarda delay en responder o hasta que se cancela el contexto
+type slowBalanceProvider struct{ delay time.Duration }
+
+func (p slowBalanceProvider) Balance(ctx context.Context, _, _ string) (decimal.Decimal, error) {
+	select {
+	case <-time.After(p.delay):
+		return decimal.NewFromInt(1000), nil
+	case <-ctx.Done():
+		return decimal.Zero, ctx.Err()
+	}
+}
+
+func useProviderTimeouts(t *testing.T, timeout time.Duration) {
+	t.Helper()
+	SetExchangeRateTimeout(timeout)
+	SetBalanceTimeout(timeout)
+	t.Cleanup(func() {
+		SetExchangeRateTimeout(DefaultProviderTimeout)
+		SetBalanceTimeout(DefaultProviderTimeout)
+	})
+}
+
+func TestValidateTransferSlowProvidersDegrade(t *testing.T) {
+	useProviderTimeouts(t, 20*time.Millisecond)
+	previous := exchangeRateProvider
+	SetExchangeRateProvider(slowRateProvider{delay: time.Second})
+	t.Cleanup(func() { SetExchangeRateProvider(previous) })
+	SetBalanceProvider(slowBalanceProvider{delay: time.Second})
+	t.Cleanup(func() { SetBalanceProvider(nil) })
+
+	start := time.Now()
+	validation, err := ValidateTransferRequest(context.Background(), TransferRequest{
+		FromAccount: "acc-1", ToAccount: "acc-2", Amount: decimal.NewFromInt(10),
+		Currency: "USD", ToCurrency: "MXN",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("validation took %v, want it bounded by the provider timeouts", elapsed)
+	}
+
+	if validation.IsValid || !validation.Degraded {
+		t.Errorf("is_valid/degraded = %v/%v, want false/true", validation.IsValid, validation.Degraded)
+	}
+	want := []string{"Source account balance unavailable", "Exchange rate unavailable for USD/MXN, try again later"}
+	if len(validation.Validations) != len(want) || validation.Validations[0] != want[0] || validation.Validations[1] != want[1] {
+		t.Errorf("validations = %v, want %v", validation.Validations, want)
+	}
+	if validation.Rate != nil || validation.ConvertedAmount != nil {
+		t.Errorf("rate/converted = %v/%v, want none", validation.Rate, validation.ConvertedAmount)
+	}
+}
+
+func TestValidateTransferProviderWithinTimeout(t *testing.T) {
+	useProviderTimeouts(t, time.Second)
+	previous := exchangeRateProvider
+	SetExchangeRateProvider(slowRateProvider{delay: 5 * time.Millisecond})
+	t.Cleanup(func() { SetExchangeRateProvider(previous) })
+
+	validation, err := ValidateTransferRequest(context.Background(), TransferRequest{
+		FromAccount: "acc-1", ToAccount: "acc-2", Amount: decimal.NewFromInt(10),
+		Currency: "USD", ToCurrency: "MXN",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !validation.IsValid || validation.Degraded || validation.ConvertedAmount == nil || !validation.ConvertedAmount.Equal(decimal.NewFromInt(170)) {
+		t.Errorf("validation = %+v, want a valid conversion of 170", validation)
+	}
+}
+
+func TestCallProviderRespectsRequestContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := callProvider(ctx, time.Minute, func(context.Context) (int, error) {
+		time.Sleep(time.Second)
+		return 1, nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+
+	// El propio error de plazo del proveedor se normaliza
+	_, err = callProvider(context.Background(), 10*time.Millisecond, func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+	if !errors.Is(err, ErrProviderTimeout) {
+		t.Errorf("err = %v, want ErrProviderTimeout", err)
+	}
+}
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fincore/core-go/internal/apierror"
+	"github.com/fincore/core-go/internal/logging"
+	"github.com/fincore/core-go/internal/tracing"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+// Tipos de discrepancia de la conciliación
+const (
+	DiscrepancyMissingLedgerEntry = "missing_ledger_entry"
+	DiscrepancyMissingTransaction = "missing_transaction"
+	DiscrepancyAmountMismatch     = "amount_mismatch"
+)
+
+// MaxReconcileRange es el rango de fechas más amplio que se concilia en un request
+const MaxReconcileRange = 31 * 24 * time.Hour
+
+// reconcilePageSize es cuántas transacciones se leen del store por página
+const reconcilePageSize = 500
+
+// maxTransactionID es mayor que cualquier UUID, de modo que un cursor con
+// ProcessedAt = to incluye las transacciones procesadas exactamente en to
+const maxTransactionID = "ffffffff-ffff-ffff-ffff-ffffffffffff"
+
+// ReconciliationDiscrepancy describe una transacción y su entrada del ledger
+// que no coinciden; falta el lado que no existe
+type ReconciliationDiscrepancy struct {
+	Kind                string           `json:"kind"`
+	ClientRef           string           `json:"client_ref"`
+	TransactionID       string           `json:"transaction_id,omitempty"`
+	LedgerSequence      *int64           `json:"ledger_sequence,omitempty"`
+	TransactionAmount   *decimal.Decimal `json:"transaction_amount,omitempty"`
+	LedgerAmount        *decimal.Decimal `json:"ledger_amount,omitempty"`
+	TransactionCurrency string           `json:"transaction_currency,omitempty"`
+	LedgerCurrency      string           `json:"ledger_currency,omitempty"`
+}
+
+// ReconciliationSummary cuenta lo revisado y las discrepancias por tipo
+type ReconciliationSummary struct {
+	TransactionsChecked  int `json:"transactions_checked"`
+	LedgerEntriesChecked int `json:"ledger_entries_checked"`
+	Matched              int `json:"matched"`
+	MissingLedgerEntries int `json:"missing_ledger_entries"`
+	MissingTransactions  int `json:"missing_transactions"`
+	AmountMismatches     int `json:"amount_mismatches"`
+}
+
+// ReconciliationReport es el resultado de conciliar un rango de fechas
+type ReconciliationReport struct {
+	From          time.Time                   `json:"from"`
+	To            time.Time                   `json:"to"`
+	Reconciled    bool                        `json:"reconciled"`
+	Summary       ReconciliationSummary       `json:"summary"`
+	Discrepancies []ReconciliationDiscrepancy `json:"discrepancies"`
+}
+
+// Reconcile cruza las transacciones procesadas entre from y to (inclusive)
+// con sus entradas del ledger. Cada entrada guarda como created_at el
+// processed_at de su transacción, así que ambos lados se acotan con el mismo
+// rango y del ledger sólo se lee ese rango de fechas. Las transacciones
+// rechazadas o pendientes de aprobación no tienen entrada y no se concilian;
+// las que no pidieron create_ledger_entry sólo se comparan si tienen entrada.
+// Las entradas creadas directamente en el ledger, sin client_ref de
+// transacción, tampoco se concilian.
+func Reconcile(ctx context.Context, from, to time.Time) (ReconciliationReport, error) {
+	report := ReconciliationReport{From: from, To: to, Discrepancies: []ReconciliationDiscrepancy{}}
+
+	// Entradas del ledger del rango indexadas por client_ref
+	entries := make(map[string]LedgerEntry)
+	err := ledgerChain.StreamEntriesCreatedBetween(ctx, from, to, func(entry LedgerEntry) error {
+		if isTransactionLedgerRef(entry.ClientRef) {
+			entries[entry.ClientRef] = entry
+		}
+		return nil
+	})
+	if err != nil {
+		return ReconciliationReport{}, err
+	}
+	report.Summary.LedgerEntriesChecked = len(entries)
+
+	filter := TransactionFilter{
+		After: &TransactionCursor{ProcessedAt: to, ID: maxTransactionID},
+		Limit: reconcilePageSize,
+	}
+	for {
+		page, err := transactionStore.List(ctx, filter)
+		if err != nil {
+			return ReconciliationReport{}, err
+		}
+		for _, tx := range page {
+			if tx.ProcessedAt.Before(from) {
+				break
+			}
+			if tx.Status == "rejected" || tx.Status == TransactionStatusPendingApproval {
+				continue
+			}
+			report.addTransaction(tx, entries)
+		}
+		if len(page) < reconcilePageSize || page[len(page)-1].ProcessedAt.Before(from) {
+			break
+		}
+		last := page[len(page)-1]
+		filter.After = &TransactionCursor{ProcessedAt: last.ProcessedAt, ID: last.ID}
+	}
+
+	// Las entradas que quedan no corresponden a ninguna transacción
+	for ref, entry := range entries {
+		sequence, amount := entry.SequenceNumber, entry.Amount
+		discrepancy := ReconciliationDiscrepancy{
+			Kind:           DiscrepancyMissingTransaction,
+			ClientRef:      ref,
+			LedgerSequence: &sequence,
+			LedgerAmount:   &amount,
+			LedgerCurrency: entry.Currency,
+		}
+		// El client_ref de una reversa lleva el ID de la original, no el suyo
+		if id, ok := strings.CutPrefix(ref, transactionLedgerRef("")); ok {
+			discrepancy.TransactionID = id
+		}
+		report.Discrepancies = append(report.Discrepancies, discrepancy)
+		report.Summary.MissingTransactions++
+	}
+
+	sort.SliceStable(report.Discrepancies, func(i, j int) bool {
+		return report.Discrepancies[i].ClientRef < report.Discrepancies[j].ClientRef
+	})
+	report.Reconciled = len(report.Discrepancies) == 0
+	return report, nil
+}
+
+// addTransaction concilia una transacción con su entrada y la quita de entries
+func (r *ReconciliationReport) addTransaction(tx Transaction, entries map[string]LedgerEntry) {
+	ref := transactionLedgerRef(tx.ID)
+	if tx.Type == TransactionTypeReversal && tx.ReversesID != "" {
+		ref = reversalLedgerRef(tx.ReversesID)
+	}
+	amount := tx.Amount
+
+	entry, ok := entries[ref]
+	if !ok && !tx.CreateLedgerEntry {
+		// No se pidió entrada, así que no falta ninguna
+		return
+	}
+	r.Summary.TransactionsChecked++
+	if !ok {
+		r.Discrepancies = append(r.Discrepancies, ReconciliationDiscrepancy{
+			Kind:                DiscrepancyMissingLedgerEntry,
+			ClientRef:           ref,
+			TransactionID:       tx.ID,
+			TransactionAmount:   &amount,
+			TransactionCurrency: tx.Currency,
+		})
+		r.Summary.MissingLedgerEntries++
+		return
+	}
+	delete(entries, ref)
+
+	if entry.Amount.Equal(tx.Amount) && entry.Currency == tx.Currency {
+		r.Summary.Matched++
+		return
+	}
+	sequence, ledgerAmount := entry.SequenceNumber, entry.Amount
+	r.Discrepancies = append(r.Discrepancies, ReconciliationDiscrepancy{
+		Kind:                DiscrepancyAmountMismatch,
+		ClientRef:           ref,
+		TransactionID:       tx.ID,
+		LedgerSequence:      &sequence,
+		TransactionAmount:   &amount,
+		LedgerAmount:        &ledgerAmount,
+		TransactionCurrency: tx.Currency,
+		LedgerCurrency:      entry.Currency,
+	})
+	r.Summary.AmountMismatches++
+}
+
+// isTransactionLedgerRef indica si el client_ref es el de una transacción o reversa
+func isTransactionLedgerRef(ref string) bool {
+	return strings.HasPrefix(ref, transactionLedgerRef("")) || strings.HasPrefix(ref, reversalLedgerRef(""))
+}
+
+// ReconcileTransactions concilia las transacciones con el ledger en el rango
+// from-to (RFC 3339) y reporta las discrepancias encontradas
+func ReconcileTransactions(c *gin.Context) {
+	from, ok := parseTimestampQuery(c, "from")
+	if !ok {
+		return
+	}
+	to, ok := parseTimestampQuery(c, "to")
+	if !ok {
+		return
+	}
+	if from.After(to) {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidRange, "from must not be after to")
+		return
+	}
+	if to.Sub(from) > MaxReconcileRange {
+		apierror.RespondWith(c, http.StatusBadRequest, apierror.InvalidRange, "Date range too large", gin.H{
+			"max_range_hours": int(MaxReconcileRange.Hours()),
+		})
+		return
+	}
+
+	ctx, span := tracing.Start(c.Request.Context(), "reconcile")
+	report, err := Reconcile(ctx, from, to)
+	tracing.EndSpan(span, err)
+	if err != nil {
+		if respondLedgerUnavailable(c, err) {
+			return
+		}
+		if respondClientClosed(c, err) {
+			return
+		}
+		logging.FromContext(c).Error("failed to reconcile transactions", "error", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.Internal, "Failed to reconcile transactions")
+		return
+	}
+
+	if !report.Reconciled {
+		logging.FromContext(c).Warn("reconciliation found discrepancies",
+			"from", from, "to", to, "discrepancies", len(report.Discrepancies))
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// parseTimestampQuery lee un timestamp RFC 3339 obligatorio del query string;
+// responde 400 y retorna false si falta o no es válido
+func parseTimestampQuery(c *gin.Context, key string) (time.Time, bool) {
+	value, err := time.Parse(time.RFC3339Nano, c.Query(key))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidTimestamp, "Invalid "+key+" timestamp, expected RFC 3339")
+		return time.Time{}, false
+	}
+	return value.UTC(), true
+}
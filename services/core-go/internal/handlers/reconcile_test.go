@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fincore/core-go/internal/apierror"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var reconcileFrom = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+// seedTransaction guarda una transacción procesada en at y, si ledgerAmount no
+// es vacío, su entrada del ledger con ese monto
+func seedTransaction(t *testing.T, store *MockTransactionStore, tx Transaction, at time.Time, ledgerAmount string) Transaction {
+	t.Helper()
+	if tx.ID == "" {
+		tx.ID = uuid.New().String()
+	}
+	if tx.Type == "" {
+		tx.Type = "deposit"
+	}
+	if tx.Status == "" {
+		tx.Status = "completed"
+	}
+	tx.Currency = "USD"
+	tx.ProcessedAt = at
+	if err := store.Insert(context.Background(), tx); err != nil {
+		t.Fatal(err)
+	}
+	if ledgerAmount != "" {
+		entry := ledgerEntryForTransaction(tx)
+		if tx.ReversesID != "" {
+			entry.ClientRef = reversalLedgerRef(tx.ReversesID)
+		}
+		entry.Amount = decimal.RequireFromString(ledgerAmount)
+		if _, err := ledgerChain.Append(context.Background(), entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return tx
+}
+
+func TestReconcileReportsEachDiscrepancyKind(t *testing.T) {
+	useMockLedger(t)
+	store := useMockTransactionStore(t)
+	at := reconcileFrom.Add(time.Hour)
+	ten := decimal.NewFromInt(10)
+
+	matched := seedTransaction(t, store, Transaction{Amount: ten}, at, "10")
+	seedTransaction(t, store, Transaction{Amount: ten.Neg(), Type: TransactionTypeReversal, ReversesID: matched.ID}, at.Add(time.Minute), "-10")
+	unledgered := seedTransaction(t, store, Transaction{Amount: ten, CreateLedgerEntry: true}, at, "")
+	mismatched := seedTransaction(t, store, Transaction{Amount: ten}, at, "10.01")
+	seedTransaction(t, store, Transaction{Amount: ten, Status: "rejected"}, at, "")
+	// Sin create_ledger_entry no se espera entrada
+	seedTransaction(t, store, Transaction{Amount: ten}, at, "")
+	// Fuera del rango: no se revisa
+	seedTransaction(t, store, Transaction{Amount: ten}, reconcileFrom.Add(-time.Hour), "")
+
+	// Entrada de una transacción que no existe y otra creada a mano en el ledger
+	orphanID := uuid.New().String()
+	for _, ref := range []string{transactionLedgerRef(orphanID), "manual-entry"} {
+		if _, err := ledgerChain.Append(context.Background(), LedgerEntry{
+			EntryType: "deposit", Amount: ten, Currency: "USD", CreatedAt: at, ClientRef: ref,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := Reconcile(context.Background(), reconcileFrom, reconcileFrom.Add(24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := ReconciliationSummary{
+		TransactionsChecked:  4,
+		LedgerEntriesChecked: 4,
+		Matched:              2,
+		MissingLedgerEntries: 1,
+		MissingTransactions:  1,
+		AmountMismatches:     1,
+	}
+	if report.Reconciled || report.Summary != want {
+		t.Fatalf("reconciled/summary = %v/%+v, want false/%+v", report.Reconciled, report.Summary, want)
+	}
+
+	kinds := map[string]ReconciliationDiscrepancy{}
+	for _, d := range report.Discrepancies {
+		kinds[d.Kind] = d
+	}
+	if d := kinds[DiscrepancyMissingLedgerEntry]; d.TransactionID != unledgered.ID || d.LedgerSequence != nil {
+		t.Errorf("missing ledger entry = %+v, want transaction %s", d, unledgered.ID)
+	}
+	if d := kinds[DiscrepancyMissingTransaction]; d.TransactionID != orphanID || d.LedgerSequence == nil || d.TransactionAmount != nil {
+		t.Errorf("missing transaction = %+v, want ledger entry for %s", d, orphanID)
+	}
+	d := kinds[DiscrepancyAmountMismatch]
+	if d.TransactionID != mismatched.ID || !d.TransactionAmount.Equal(ten) || !d.LedgerAmount.Equal(decimal.RequireFromString("10.01")) {
+		t.Errorf("amount mismatch = %+v, want 10 vs 10.01 for %s", d, mismatched.ID)
+	}
+}
+
+func TestReconcilePagesThroughTransactions(t *testing.T) {
+	useMockLedger(t)
+	store := useMockTransactionStore(t)
+
+	// Más de una página, con varias transacciones en el mismo instante y una
+	// justo en el límite superior
+	to := reconcileFrom.Add(time.Hour)
+	count := reconcilePageSize + 20
+	for i := 0; i < count-1; i++ {
+		seedTransaction(t, store, Transaction{Amount: decimal.NewFromInt(1)}, reconcileFrom.Add(time.Duration(i%7)*time.Second), "1")
+	}
+	seedTransaction(t, store, Transaction{Amount: decimal.NewFromInt(1)}, to, "1")
+
+	report, err := Reconcile(context.Background(), reconcileFrom, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Reconciled || report.Summary.TransactionsChecked != count || report.Summary.Matched != count {
+		t.Errorf("reconciled/summary = %v/%+v, want %d matched", report.Reconciled, report.Summary, count)
+	}
+}
+
+func TestReconcileProcessedTransactions(t *testing.T) {
+	useMockLedger(t)
+	useMockTransactionStore(t)
+
+	// Sólo la transacción que pidió entrada en el ledger se concilia
+	for _, ledgerEntry := range []bool{false, true} {
+		w, resp := doJSON(t, ProcessTransaction, map[string]interface{}{
+			"type": "deposit", "user_id": "user-1", "amount": "10", "create_ledger_entry": ledgerEntry,
+		})
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200 (%v)", w.Code, resp)
+		}
+	}
+
+	now := time.Now().UTC()
+	report, err := Reconcile(context.Background(), now.Add(-time.Hour), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Reconciled || report.Summary.TransactionsChecked != 1 || report.Summary.Matched != 1 {
+		t.Errorf("reconciled/summary = %v/%+v, want the ledgered transaction matched", report.Reconciled, report.Summary)
+	}
+}
+
+func TestReconcileTransactionsEndpoint(t *testing.T) {
+	useMockLedger(t)
+	store := useMockTransactionStore(t)
+	seedTransaction(t, store, Transaction{Amount: decimal.NewFromInt(10), CreateLedgerEntry: true}, reconcileFrom.Add(time.Hour), "")
+
+	w, resp := doGet(t, "/reconcile", ReconcileTransactions, "/reconcile?from=2026-03-01T00:00:00Z&to=2026-03-02T00:00:00Z")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%v)", w.Code, resp)
+	}
+	summary := resp["summary"].(map[string]interface{})
+	discrepancies := resp["discrepancies"].([]interface{})
+	if resp["reconciled"] != false || summary["missing_ledger_entries"] != float64(1) || len(discrepancies) != 1 {
+		t.Errorf("response = %v, want one missing ledger entry", resp)
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		code  string
+	}{
+		{"sin from", "?to=2026-03-02T00:00:00Z", apierror.InvalidTimestamp},
+		{"to inválido", "?from=2026-03-01T00:00:00Z&to=ayer", apierror.InvalidTimestamp},
+		{"rango invertido", "?from=2026-03-02T00:00:00Z&to=2026-03-01T00:00:00Z", apierror.InvalidRange},
+		{"rango demasiado amplio", "?from=2026-01-01T00:00:00Z&to=2026-03-01T00:00:00Z", apierror.InvalidRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, resp := doGet(t, "/reconcile", ReconcileTransactions, "/reconcile"+tt.query)
+			if w.Code != http.StatusBadRequest || resp["code"] != tt.code {
+				t.Errorf("status/code = %d/%v, want 400/%s", w.Code, resp["code"], tt.code)
+			}
+		})
+	}
+}
+
+func TestReconcileTransactionsStoreFailure(t *testing.T) {
+	useMockLedger(t).Err = errors.New("connection refused")
+	useMockTransactionStore(t)
+
+	w, resp := doGet(t, "/reconcile", ReconcileTransactions, "/reconcile?from=2026-03-01T00:00:00Z&to=2026-03-02T00:00:00Z")
+	if w.Code != http.StatusInternalServerError || resp["code"] != apierror.Internal {
+		t.Errorf("status/code = %d/%v, want 500/%s", w.Code, resp["code"], apierror.Internal)
+	}
+}
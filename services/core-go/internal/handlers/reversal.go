@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fincore/core-go/internal/apierror"
+	"github.com/fincore/core-go/internal/logging"
+	"github.com/fincore/core-go/internal/metrics"
+	"github.com/fincore/core-go/internal/tracing"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// TransactionTypeReversal es el tipo de las transacciones compensatorias
+	TransactionTypeReversal = "reversal"
+	// TransactionStatusReversed es el estado de una transacción ya revertida
+	TransactionStatusReversed = "reversed"
+)
+
+// reversalLedgerRef es el client_ref de la entrada del ledger que documenta la
+// reversa de una transacción
+func reversalLedgerRef(transactionID string) string {
+	return "reversal:" + transactionID
+}
+
+// ReverseTransaction revierte una transacción completada: crea una
+// transacción compensatoria con el monto de signo opuesto que referencia a la
+// original, marca la original como reversed y registra la reversa en el
+// ledger. El ledger es inmutable, así que si su escritura falla se deshacen
+// los cambios en el store de transacciones.
+//
+// El header opcional If-Match lleva la versión que el cliente leyó; si la
+// transacción cambió desde entonces responde 409 con VERSION_CONFLICT.
+func ReverseTransaction(c *gin.Context) {
+	transactionID := c.Param("id")
+
+	if _, err := uuid.Parse(transactionID); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidTransactionID, "Invalid transaction ID")
+		return
+	}
+
+	expectedVersion, hasExpectedVersion, err := parseIfMatchVersion(c.GetHeader("If-Match"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidIfMatch, "Invalid If-Match version")
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	getCtx, getSpan := tracing.Start(ctx, "transaction_store.get")
+	original, err := transactionStore.Get(getCtx, transactionID)
+	tracing.EndSpan(getSpan, err)
+	if errors.Is(err, ErrTransactionNotFound) {
+		apierror.Respond(c, http.StatusNotFound, apierror.TransactionNotFound, "Transaction not found")
+		return
+	}
+	if err != nil {
+		if respondClientClosed(c, err) {
+			return
+		}
+		logging.FromContext(c).Error("failed to read transaction", "transaction_id", transactionID, "error", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.Internal, "Failed to read transaction")
+		return
+	}
+
+	if hasExpectedVersion && original.Version != expectedVersion {
+		respondVersionConflict(c, gin.H{
+			"expected_version": expectedVersion,
+			"current_version":  original.Version,
+		})
+		return
+	}
+
+	if original.Type == TransactionTypeReversal {
+		apierror.Respond(c, http.StatusConflict, apierror.TransactionNotReversible, "Reversal transactions cannot be reversed")
+		return
+	}
+	if original.Status == TransactionStatusReversed {
+		respondAlreadyReversed(c)
+		return
+	}
+	if original.Status != "completed" {
+		apierror.RespondWith(c, http.StatusConflict, apierror.TransactionNotReversible, "Only completed transactions can be reversed", gin.H{
+			"status": original.Status,
+		})
+		return
+	}
+
+	// El cambio de estado condicionado a la versión leída impide que dos
+	// actualizaciones concurrentes de la misma transacción avancen
+	updateCtx, updateSpan := tracing.Start(ctx, "transaction_store.update_status")
+	version, err := transactionStore.UpdateStatus(updateCtx, original.ID, original.Version, TransactionStatusReversed)
+	tracing.EndSpan(updateSpan, err)
+	if errors.Is(err, ErrTransactionVersionConflict) {
+		respondVersionConflict(c, gin.H{"expected_version": original.Version})
+		return
+	}
+	if err != nil {
+		if respondClientClosed(c, err) {
+			return
+		}
+		logging.FromContext(c).Error("failed to mark transaction as reversed", "transaction_id", original.ID, "error", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.Internal, "Failed to reverse transaction")
+		return
+	}
+	original.Status = TransactionStatusReversed
+	original.Version = version
+
+	reversal := Transaction{
+		ID:           uuid.New().String(),
+		Type:         TransactionTypeReversal,
+		UserID:       original.UserID,
+		ProjectID:    original.ProjectID,
+		InvestmentID: original.InvestmentID,
+		Amount:       original.Amount.Neg(),
+		Currency:     original.Currency,
+		Status:       "completed",
+		Version:      initialTransactionVersion,
+		ReversesID:   original.ID,
+		// Toda reversa se registra en el ledger
+		CreateLedgerEntry: true,
+		// Igual que en ProcessTransaction, truncar a microsegundos para que
+		// el hash siga verificando tras leer del store
+		ProcessedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+	reversal.IntegrityHash = calculateTransactionHash(reversal)
+
+	insertCtx, insertSpan := tracing.Start(ctx, "transaction_store.insert")
+	err = transactionStore.Insert(insertCtx, reversal)
+	tracing.EndSpan(insertSpan, err)
+	if err != nil {
+		restoreReversedStatus(c, original)
+		if respondClientClosed(c, err) {
+			return
+		}
+		logging.FromContext(c).Error("failed to persist reversal", "transaction_id", original.ID, "error", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.Internal, "Failed to persist reversal")
+		return
+	}
+
+	ledgerCtx, ledgerSpan := tracing.Start(ctx, "ledger.append")
+	entry, created, err := ledgerChain.AppendOnce(ledgerCtx, LedgerEntry{
+		EntryType:   TransactionTypeReversal,
+		Amount:      reversal.Amount,
+		Currency:    reversal.Currency,
+		Description: fmt.Sprintf("reversal %s of %s transaction %s for user %s", reversal.ID, original.Type, original.ID, original.UserID),
+		CreatedAt:   reversal.ProcessedAt,
+		IsVerified:  true,
+		ClientRef:   reversalLedgerRef(original.ID),
+	})
+	tracing.EndSpan(ledgerSpan, err)
+	if err != nil {
+		deleteCtx, deleteSpan := tracing.Start(context.WithoutCancel(ctx), "transaction_store.delete")
+		deleteErr := transactionStore.Delete(deleteCtx, reversal.ID)
+		tracing.EndSpan(deleteSpan, deleteErr)
+		if deleteErr != nil {
+			logging.FromContext(c).Error("failed to compensate reversal", "transaction_id", reversal.ID, "error", deleteErr)
+		}
+		restoreReversedStatus(c, original)
+		if respondLedgerUnavailable(c, err) {
+			return
+		}
+		if respondClientClosed(c, err) {
+			return
+		}
+		logging.FromContext(c).Error("failed to create ledger entry for reversal", "transaction_id", original.ID, "error", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.Internal, "Failed to create ledger entry")
+		return
+	}
+	if created {
+		metrics.LedgerEntriesCreated.Inc()
+	}
+
+	metrics.TransactionsProcessed.WithLabelValues(TransactionStatusReversed).Inc()
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":         true,
+		"transaction":     reversal,
+		"original":        original,
+		"ledger_sequence": entry.SequenceNumber,
+		"message":         "Transaction reversed successfully",
+	})
+}
+
+func respondAlreadyReversed(c *gin.Context) {
+	apierror.Respond(c, http.StatusConflict, apierror.AlreadyReversed, "Transaction already reversed")
+}
+
+// respondVersionConflict responde 409 cuando la transacción fue modificada
+// por otra operación; details agrega las versiones conocidas
+func respondVersionConflict(c *gin.Context, details gin.H) {
+	apierror.RespondWith(c, http.StatusConflict, apierror.VersionConflict, "Transaction was modified concurrently, reload it and retry", details)
+}
+
+// parseIfMatchVersion interpreta If-Match como una versión entera, con o sin
+// comillas de ETag; ok es false si el header no viene
+func parseIfMatchVersion(header string) (version int64, ok bool, err error) {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0, false, nil
+	}
+	version, err = strconv.ParseInt(strings.Trim(header, `"`), 10, 64)
+	if err != nil || version < 0 {
+		return 0, false, errors.New("invalid If-Match version")
+	}
+	return version, true, nil
+}
+
+// restoreReversedStatus devuelve la transacción original a completed cuando la
+// reversa no se pudo completar, aunque el cliente ya se haya desconectado.
+// original debe llevar la versión que dejó el cambio a reversed.
+func restoreReversedStatus(c *gin.Context, original Transaction) {
+	restoreCtx, restoreSpan := tracing.Start(context.WithoutCancel(c.Request.Context()), "transaction_store.update_status")
+	_, err := transactionStore.UpdateStatus(restoreCtx, original.ID, original.Version, "completed")
+	tracing.EndSpan(restoreSpan, err)
+	if err != nil {
+		logging.FromContext(c).Error("failed to restore transaction status", "transaction_id", original.ID, "error", err)
+	}
+}
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/fincore/core-go/internal/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// doReverse ejecuta POST /reverse/:id contra ReverseTransaction
+func doReverse(t *testing.T, id string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	t.Helper()
+	return doReverseIfMatch(t, id, "")
+}
+
+// doReverseIfMatch es doReverse con el header If-Match dado (vacío lo omite)
+func doReverseIfMatch(t *testing.T, id, ifMatch string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	t.Helper()
+
+	router := gin.New()
+	router.POST("/reverse/:id", ReverseTransaction)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/reverse/"+id, nil)
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+	router.ServeHTTP(w, req)
+
+	var resp map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response %q: %v", w.Body.String(), err)
+	}
+	return w, resp
+}
+
+func TestReverseTransactionCreatesCompensatingTransaction(t *testing.T) {
+	store := useMockTransactionStore(t)
+	useMockLedger(t)
+
+	router := gin.New()
+	router.POST("/", ProcessTransaction)
+	id := transactionID(t, postTransaction(router, ""))
+
+	w, resp := doReverse(t, id)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+
+	original, err := store.Get(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if original.Status != TransactionStatusReversed {
+		t.Errorf("original status = %q, want reversed", original.Status)
+	}
+
+	reversalData, _ := resp["transaction"].(map[string]interface{})
+	reversalID, _ := reversalData["id"].(string)
+	reversal, err := store.Get(context.Background(), reversalID)
+	if err != nil {
+		t.Fatalf("reversal %q not persisted: %v", reversalID, err)
+	}
+	if reversal.ReversesID != id || reversal.Type != TransactionTypeReversal {
+		t.Errorf("reversal = %s/%q, want reversal of %s", reversal.Type, reversal.ReversesID, id)
+	}
+	if !reversal.Amount.Equal(original.Amount.Neg()) {
+		t.Errorf("reversal amount = %s, want %s", reversal.Amount, original.Amount.Neg())
+	}
+	if !verifyTransactionIntegrity(reversal) {
+		t.Error("reversal integrity hash does not verify")
+	}
+
+	entry, err := ledgerChain.GetByClientRef(context.Background(), reversalLedgerRef(id))
+	if err != nil {
+		t.Fatalf("ledger entry for reversal: %v", err)
+	}
+	if entry.EntryType != TransactionTypeReversal || !entry.Amount.Equal(reversal.Amount) {
+		t.Errorf("ledger entry = %s %s, want reversal %s", entry.EntryType, entry.Amount, reversal.Amount)
+	}
+	if resp["ledger_sequence"] != float64(entry.SequenceNumber) {
+		t.Errorf("ledger_sequence = %v, want %d", resp["ledger_sequence"], entry.SequenceNumber)
+	}
+}
+
+func TestReverseTransactionRejectsDoubleReversal(t *testing.T) {
+	store := useMockTransactionStore(t)
+	useMockLedger(t)
+
+	tx := Transaction{
+		ID: uuid.New().String(), Type: "deposit", UserID: "user-1",
+		Amount: decimal.NewFromInt(100), Currency: "MXN", Status: "completed",
+	}
+	if err := store.Insert(context.Background(), tx); err != nil {
+		t.Fatal(err)
+	}
+
+	if w, _ := doReverse(t, tx.ID); w.Code != http.StatusOK {
+		t.Fatalf("first reversal status = %d, want 200", w.Code)
+	}
+	w, resp := doReverse(t, tx.ID)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("second reversal status = %d, want 409", w.Code)
+	}
+	if resp["error"] != "Transaction already reversed" {
+		t.Errorf("error = %v", resp["error"])
+	}
+
+	all, err := store.List(context.Background(), TransactionFilter{UserID: "user-1", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 {
+		t.Errorf("stored transactions = %d, want original plus one reversal", len(all))
+	}
+}
+
+func TestReverseTransactionUnknownID(t *testing.T) {
+	useMockTransactionStore(t)
+	useMockLedger(t)
+
+	w, resp := doReverse(t, uuid.New().String())
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", w.Code)
+	}
+	if resp["error"] != "Transaction not found" {
+		t.Errorf("error = %v", resp["error"])
+	}
+}
+
+// barrierTransactionStore retrasa cada Get hasta que llegan readers lecturas,
+// para que las operaciones concurrentes lean la misma versión antes de que
+// alguna actualice
+type barrierTransactionStore struct {
+	*MockTransactionStore
+	readers sync.WaitGroup
+}
+
+func (s *barrierTransactionStore) Get(ctx context.Context, id string) (Transaction, error) {
+	tx, err := s.MockTransactionStore.Get(ctx, id)
+	s.readers.Done()
+	s.readers.Wait()
+	return tx, err
+}
+
+func TestReverseTransactionConcurrentUpdateConflict(t *testing.T) {
+	mock := useMockTransactionStore(t)
+	useMockLedger(t)
+
+	tx := Transaction{
+		ID: uuid.New().String(), Type: "deposit", UserID: "user-1",
+		Amount: decimal.NewFromInt(100), Currency: "MXN", Status: "completed",
+		Version: initialTransactionVersion,
+	}
+	if err := mock.Insert(context.Background(), tx); err != nil {
+		t.Fatal(err)
+	}
+	store := &barrierTransactionStore{MockTransactionStore: mock}
+	store.readers.Add(2)
+	SetTransactionStore(store)
+
+	var wg sync.WaitGroup
+	codes := make([]int, 2)
+	responses := make([]map[string]interface{}, 2)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w, resp := doReverse(t, tx.ID)
+			codes[i], responses[i] = w.Code, resp
+		}(i)
+	}
+	wg.Wait()
+
+	winner, loser := 0, 1
+	if codes[0] != http.StatusOK {
+		winner, loser = 1, 0
+	}
+	if codes[winner] != http.StatusOK || codes[loser] != http.StatusConflict {
+		t.Fatalf("statuses = %v, want one 200 and one 409", codes)
+	}
+	if responses[loser]["code"] != apierror.VersionConflict || responses[loser]["expected_version"] != float64(1) {
+		t.Errorf("conflict response = %v, want code %s for version 1", responses[loser], apierror.VersionConflict)
+	}
+	if original := responses[winner]["original"].(map[string]interface{}); original["version"] != float64(2) {
+		t.Errorf("original version = %v, want 2 after the update", original["version"])
+	}
+
+	all, err := mock.List(context.Background(), TransactionFilter{UserID: "user-1", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 {
+		t.Errorf("stored transactions = %d, want original plus one reversal", len(all))
+	}
+}
+
+func TestReverseTransactionIfMatch(t *testing.T) {
+	store := useMockTransactionStore(t)
+	useMockLedger(t)
+
+	tx := Transaction{
+		ID: uuid.New().String(), Type: "deposit", UserID: "user-1",
+		Amount: decimal.NewFromInt(100), Currency: "MXN", Status: "completed",
+		Version: 3,
+	}
+	if err := store.Insert(context.Background(), tx); err != nil {
+		t.Fatal(err)
+	}
+
+	w, resp := doReverseIfMatch(t, tx.ID, `"2"`)
+	if w.Code != http.StatusConflict || resp["code"] != apierror.VersionConflict || resp["current_version"] != float64(3) {
+		t.Fatalf("stale If-Match = %d %v, want 409 with current_version 3", w.Code, resp)
+	}
+	if w, _ := doReverseIfMatch(t, tx.ID, "abc"); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid If-Match status = %d, want 400", w.Code)
+	}
+	if w, _ := doReverseIfMatch(t, tx.ID, "3"); w.Code != http.StatusOK {
+		t.Errorf("matching If-Match status = %d, want 200", w.Code)
+	}
+}
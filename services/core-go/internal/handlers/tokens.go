@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fincore/core-go/internal/apierror"
+	"github.com/fincore/core-go/internal/logging"
+	"github.com/fincore/core-go/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+// Límites del TTL de los tokens emitidos por MintServiceToken (segundos)
+const (
+	MinServiceTokenTTL = 1
+	MaxServiceTokenTTL = security.MaxServiceTokenTTL
+)
+
+// MintServiceToken emite un token de servicio firmado para que un operador
+// pueda dar de alta servicios nuevos. Debe montarse detrás de zeroTrustMiddleware
+// y de un permiso de administrador; el token emitido no puede otorgar permisos
+// que el token del solicitante no tenga.
+func MintServiceToken(c *gin.Context) {
+	var req struct {
+		Source      string   `json:"source" binding:"required"`
+		Target      string   `json:"target" binding:"required"`
+		Permissions []string `json:"permissions" binding:"required"`
+		TTL         int      `json:"ttl"`
+		// SingleUse emite un token con nonce que sólo verifica una vez
+		SingleUse bool `json:"single_use"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondWith(c, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request", gin.H{
+			"details": err.Error(),
+		})
+		return
+	}
+
+	req.Source = strings.TrimSpace(req.Source)
+	req.Target = strings.TrimSpace(req.Target)
+	if req.Source == "" || req.Target == "" {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidParameter, "source and target are required")
+		return
+	}
+	if len(req.Permissions) == 0 {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidParameter, "permissions must not be empty")
+		return
+	}
+	if req.TTL < MinServiceTokenTTL || req.TTL > MaxServiceTokenTTL {
+		apierror.RespondWith(c, http.StatusBadRequest, apierror.TokenTTLOutOfRange, "ttl out of range", gin.H{
+			"min_ttl": MinServiceTokenTTL,
+			"max_ttl": MaxServiceTokenTTL,
+		})
+		return
+	}
+
+	value, _ := c.Get("service_claims")
+	issuer, ok := value.(*security.ServiceTokenClaims)
+	if !ok {
+		apierror.Respond(c, http.StatusUnauthorized, apierror.TokenRequired, "Service token required")
+		return
+	}
+	for _, permission := range req.Permissions {
+		if strings.TrimSpace(permission) == "" || !issuer.HasPermission(permission) {
+			apierror.RespondWith(c, http.StatusForbidden, apierror.InsufficientPermissions, "Cannot grant a permission the issuing token does not hold", gin.H{
+				"permission": permission,
+			})
+			return
+		}
+	}
+
+	generate := securityManager.GenerateServiceToken
+	if req.SingleUse {
+		generate = securityManager.GenerateSingleUseServiceToken
+	}
+	token, err := generate(req.Source, req.Target, req.Permissions, req.TTL)
+	if err != nil {
+		logging.FromContext(c).Error("failed to mint service token", "error", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.Internal, "Failed to generate service token")
+		return
+	}
+
+	logging.FromContext(c).Info("service token minted",
+		"issued_by", issuer.Source, "source", req.Source, "target", req.Target,
+		"permissions", req.Permissions, "ttl", req.TTL, "single_use", req.SingleUse)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success":    true,
+		"token":      token,
+		"expires_at": time.Now().Add(time.Duration(req.TTL) * time.Second).UTC().Format(time.RFC3339),
+	})
+}
+
+// RotateSigningKey reemplaza la clave que firma los tokens de servicio por la
+// enviada en base64. Los tokens firmados con la clave anterior siguen
+// verificando durante la ventana de rotación. Debe montarse detrás de
+// zeroTrustMiddleware y de un permiso de administrador; la clave nueva no se
+// devuelve ni se registra.
+func RotateSigningKey(c *gin.Context) {
+	var req struct {
+		Key string `json:"key" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	key, err := base64.StdEncoding.DecodeString(req.Key)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidParameter, "key must be base64 encoded")
+		return
+	}
+
+	rotatedAt := time.Now()
+	if err := securityManager.RotateSigningKey(key); err != nil {
+		if errors.Is(err, security.ErrSigningKeyTooShort) || errors.Is(err, security.ErrSigningKeyUnchanged) {
+			apierror.Respond(c, http.StatusBadRequest, apierror.InvalidParameter, err.Error())
+			return
+		}
+		logging.FromContext(c).Error("failed to rotate signing key", "error", err)
+		apierror.Respond(c, http.StatusInternalServerError, apierror.Internal, "Failed to rotate signing key")
+		return
+	}
+
+	value, _ := c.Get("service_claims")
+	rotatedBy := ""
+	if claims, ok := value.(*security.ServiceTokenClaims); ok {
+		rotatedBy = claims.Source
+	}
+	logging.FromContext(c).Warn("service signing key rotated", "rotated_by", rotatedBy)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":                  true,
+		"rotated_at":               rotatedAt.UTC().Format(time.RFC3339),
+		"previous_key_valid_until": rotatedAt.Add(securityManager.SigningKeyRotationWindow()).UTC().Format(time.RFC3339),
+	})
+}
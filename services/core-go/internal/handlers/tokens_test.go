@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fincore/core-go/internal/apierror"
+	"github.com/fincore/core-go/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+// mintAs llama a MintServiceToken como si zeroTrustMiddleware hubiera
+// verificado un token con los permisos dados
+func mintAs(t *testing.T, permissions []string, body map[string]interface{}) (*httptest.ResponseRecorder, map[string]interface{}) {
+	t.Helper()
+
+	router := gin.New()
+	router.POST("/", func(c *gin.Context) {
+		c.Set("service_claims", &security.ServiceTokenClaims{Source: "ops-cli", Permissions: permissions})
+	}, MintServiceToken)
+
+	payload, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	var resp map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response %q: %v", w.Body.String(), err)
+	}
+	return w, resp
+}
+
+func TestMintServiceTokenVerifies(t *testing.T) {
+	w, resp := mintAs(t, []string{security.PermissionWildcard}, map[string]interface{}{
+		"source":      "payments-service",
+		"target":      "fincore-core-go",
+		"permissions": []string{"calculate:metrics", "validate:transfers"},
+		"ttl":         300,
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201 (%v)", w.Code, resp)
+	}
+
+	claims, err := securityManager.VerifyServiceToken(resp["token"].(string))
+	if err != nil {
+		t.Fatalf("VerifyServiceToken: %v", err)
+	}
+	if claims.Source != "payments-service" || claims.Target != "fincore-core-go" {
+		t.Errorf("claims = %s -> %s", claims.Source, claims.Target)
+	}
+	if !claims.HasPermission("validate:transfers") || claims.HasPermission("admin:tokens") {
+		t.Errorf("permissions = %v", claims.Permissions)
+	}
+}
+
+func TestMintServiceTokenSingleUse(t *testing.T) {
+	body := map[string]interface{}{
+		"source":      "payments-service",
+		"target":      "fincore-core-go",
+		"permissions": []string{"calculate:metrics"},
+		"ttl":         300,
+	}
+	_, resp := mintAs(t, []string{security.PermissionWildcard}, body)
+	reusable := resp["token"].(string)
+	for i := 0; i < 2; i++ {
+		if _, err := securityManager.VerifyServiceToken(reusable); err != nil {
+			t.Fatalf("reusable token use %d: %v", i+1, err)
+		}
+	}
+
+	body["single_use"] = true
+	_, resp = mintAs(t, []string{security.PermissionWildcard}, body)
+	singleUse := resp["token"].(string)
+	if _, err := securityManager.VerifyServiceToken(singleUse); err != nil {
+		t.Fatalf("single-use token first use: %v", err)
+	}
+	if _, err := securityManager.VerifyServiceToken(singleUse); !errors.Is(err, security.ErrTokenReplay) {
+		t.Errorf("single-use token second use: err = %v, want ErrTokenReplay", err)
+	}
+}
+
+func TestMintServiceTokenValidation(t *testing.T) {
+	valid := func() map[string]interface{} {
+		return map[string]interface{}{
+			"source":      "payments-service",
+			"target":      "fincore-core-go",
+			"permissions": []string{"calculate:metrics"},
+			"ttl":         60,
+		}
+	}
+
+	cases := map[string]func(map[string]interface{}){
+		"missing source":      func(b map[string]interface{}) { delete(b, "source") },
+		"blank target":        func(b map[string]interface{}) { b["target"] = "  " },
+		"empty permissions":   func(b map[string]interface{}) { b["permissions"] = []string{} },
+		"zero ttl":            func(b map[string]interface{}) { b["ttl"] = 0 },
+		"ttl above maximum":   func(b map[string]interface{}) { b["ttl"] = MaxServiceTokenTTL + 1 },
+		"missing permissions": func(b map[string]interface{}) { delete(b, "permissions") },
+	}
+	for name, mutate := range cases {
+		body := valid()
+		mutate(body)
+		if w, _ := mintAs(t, []string{security.PermissionWildcard}, body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", name, w.Code)
+		}
+	}
+}
+
+func TestMintServiceTokenCannotEscalate(t *testing.T) {
+	w, _ := mintAs(t, []string{"admin:tokens", "calculate:*"}, map[string]interface{}{
+		"source":      "payments-service",
+		"target":      "fincore-core-go",
+		"permissions": []string{"calculate:metrics", "validate:transfers"},
+		"ttl":         60,
+	})
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want 403", w.Code)
+	}
+}
+
+func TestRotateSigningKeyEndpoint(t *testing.T) {
+	// Manager propio para no alterar la clave que usan las demás pruebas
+	original := securityManager
+	sm, err := security.NewSecurityManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetSecurityManager(sm)
+	t.Cleanup(func() { SetSecurityManager(original) })
+
+	oldToken, err := sm.GenerateServiceToken("payments-service", "fincore-core-go", []string{"calculate:metrics"}, 300)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		key  string
+		code string
+	}{
+		{"no base64", "not base64!", apierror.InvalidParameter},
+		{"demasiado corta", base64.StdEncoding.EncodeToString([]byte("short")), apierror.InvalidParameter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, resp := doJSON(t, RotateSigningKey, map[string]interface{}{"key": tt.key})
+			if w.Code != http.StatusBadRequest || resp["code"] != tt.code {
+				t.Errorf("status/code = %d/%v, want 400/%s", w.Code, resp["code"], tt.code)
+			}
+		})
+	}
+
+	newKey := base64.StdEncoding.EncodeToString([]byte("rotated-signing-key-for-handlers-0123"))
+	w, resp := doJSON(t, RotateSigningKey, map[string]interface{}{"key": newKey})
+	if w.Code != http.StatusOK || resp["previous_key_valid_until"] == nil {
+		t.Fatalf("status = %d, want 200 (%v)", w.Code, resp)
+	}
+	if _, ok := resp["key"]; ok {
+		t.Error("response must not echo the new key")
+	}
+	if _, err := sm.VerifyServiceTokenFor(oldToken, "fincore-core-go"); err != nil {
+		t.Errorf("token signed before rotation must verify during the window: %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// encryptedFieldPrefix marca los valores cifrados en el store. Los valores sin
+// prefijo, escritos antes de activar el cifrado, se leen tal cual.
+const encryptedFieldPrefix = "enc:"
+
+// encryptableFields son los campos de Transaction que pueden guardarse
+// cifrados, por su nombre JSON
+var encryptableFields = map[string]func(tx *Transaction) *string{
+	"user_id":       func(tx *Transaction) *string { return &tx.UserID },
+	"project_id":    func(tx *Transaction) *string { return &tx.ProjectID },
+	"investment_id": func(tx *Transaction) *string { return &tx.InvestmentID },
+}
+
+// EncryptableTransactionFields retorna los campos que admite
+// NewEncryptedTransactionStore, ordenados
+func EncryptableTransactionFields() []string {
+	fields := make([]string, 0, len(encryptableFields))
+	for field := range encryptableFields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// FieldCipher cifra los campos sensibles y calcula el índice ciego con el que
+// se busca un valor cifrado. security.SecurityManager lo implementa.
+type FieldCipher interface {
+	Encrypt(plaintext []byte) (string, error)
+	Decrypt(ciphertext string) ([]byte, error)
+	BlindIndex(value string) string
+}
+
+// EncryptedTransactionStore envuelve un TransactionStore cifrando los campos
+// configurados al escribir y descifrándolos al leer, de modo que el texto
+// plano sólo existe en memoria. Cuando user_id está cifrado guarda además su
+// índice ciego para que List pueda seguir filtrando por usuario.
+type EncryptedTransactionStore struct {
+	store  TransactionStore
+	cipher FieldCipher
+	fields []string
+}
+
+// NewEncryptedTransactionStore crea el store; fields son nombres JSON de
+// EncryptableTransactionFields y un nombre desconocido retorna error
+func NewEncryptedTransactionStore(store TransactionStore, cipher FieldCipher, fields []string) (*EncryptedTransactionStore, error) {
+	seen := make(map[string]bool, len(fields))
+	var normalized []string
+	for _, field := range fields {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field == "" || seen[field] {
+			continue
+		}
+		if _, ok := encryptableFields[field]; !ok {
+			return nil, fmt.Errorf("field %q cannot be encrypted (allowed: %s)", field, strings.Join(EncryptableTransactionFields(), ", "))
+		}
+		seen[field] = true
+		normalized = append(normalized, field)
+	}
+	return &EncryptedTransactionStore{store: store, cipher: cipher, fields: normalized}, nil
+}
+
+// encryptsUserID indica si user_id se guarda cifrado
+func (s *EncryptedTransactionStore) encryptsUserID() bool {
+	for _, field := range s.fields {
+		if field == "user_id" {
+			return true
+		}
+	}
+	return false
+}
+
+// encrypt retorna una copia de tx con los campos configurados cifrados
+func (s *EncryptedTransactionStore) encrypt(tx Transaction) (Transaction, error) {
+	if s.encryptsUserID() && tx.UserID != "" {
+		tx.UserIDIndex = s.cipher.BlindIndex(tx.UserID)
+	}
+	for _, field := range s.fields {
+		value := encryptableFields[field](&tx)
+		if *value == "" {
+			continue
+		}
+		ciphertext, err := s.cipher.Encrypt([]byte(*value))
+		if err != nil {
+			return Transaction{}, fmt.Errorf("failed to encrypt transaction field %s: %w", field, err)
+		}
+		*value = encryptedFieldPrefix + ciphertext
+	}
+	return tx, nil
+}
+
+// decrypt descifra todos los campos con prefijo, estén o no configurados, para
+// que desactivar el cifrado de un campo no impida leer lo ya escrito
+func (s *EncryptedTransactionStore) decrypt(tx Transaction) (Transaction, error) {
+	for field, get := range encryptableFields {
+		value := get(&tx)
+		ciphertext, ok := strings.CutPrefix(*value, encryptedFieldPrefix)
+		if !ok {
+			continue
+		}
+		plaintext, err := s.cipher.Decrypt(ciphertext)
+		if err != nil {
+			return Transaction{}, fmt.Errorf("failed to decrypt transaction field %s of %s: %w", field, tx.ID, err)
+		}
+		*value = string(plaintext)
+	}
+	tx.UserIDIndex = ""
+	return tx, nil
+}
+
+// Insert cifra los campos configurados antes de guardar
+func (s *EncryptedTransactionStore) Insert(ctx context.Context, tx Transaction) error {
+	encrypted, err := s.encrypt(tx)
+	if err != nil {
+		return err
+	}
+	return s.store.Insert(ctx, encrypted)
+}
+
+// Get descifra la transacción leída
+func (s *EncryptedTransactionStore) Get(ctx context.Context, id string) (Transaction, error) {
+	tx, err := s.store.Get(ctx, id)
+	if err != nil {
+		return Transaction{}, err
+	}
+	return s.decrypt(tx)
+}
+
+// GetMany descifra cada transacción leída
+func (s *EncryptedTransactionStore) GetMany(ctx context.Context, ids []string) (map[string]Transaction, error) {
+	found, err := s.store.GetMany(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for id, tx := range found {
+		if found[id], err = s.decrypt(tx); err != nil {
+			return nil, err
+		}
+	}
+	return found, nil
+}
+
+// List filtra por el índice ciego cuando user_id está cifrado; el filtro por
+// el valor en claro se mantiene para las transacciones anteriores al cifrado
+func (s *EncryptedTransactionStore) List(ctx context.Context, filter TransactionFilter) ([]Transaction, error) {
+	if filter.UserID != "" && s.encryptsUserID() {
+		filter.UserIDIndex = s.cipher.BlindIndex(filter.UserID)
+	}
+	page, err := s.store.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	for i, tx := range page {
+		if page[i], err = s.decrypt(tx); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// Delete elimina la transacción en el store envuelto
+func (s *EncryptedTransactionStore) Delete(ctx context.Context, id string) error {
+	return s.store.Delete(ctx, id)
+}
+
+// UpdateStatus actualiza el estado en el store envuelto; no toca campos cifrados
+func (s *EncryptedTransactionStore) UpdateStatus(ctx context.Context, id string, version int64, status string) (int64, error) {
+	return s.store.UpdateStatus(ctx, id, version, status)
+}
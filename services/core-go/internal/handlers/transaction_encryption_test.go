@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// newEncryptedStore cifra fields sobre un store en memoria y retorna ambos
+func newEncryptedStore(t *testing.T, fields ...string) (*EncryptedTransactionStore, *MockTransactionStore) {
+	t.Helper()
+	inner := NewMockTransactionStore()
+	store, err := NewEncryptedTransactionStore(inner, securityManager, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store, inner
+}
+
+func sensitiveTransaction() Transaction {
+	return Transaction{
+		ID: uuid.New().String(), Type: "investment", UserID: "user-42",
+		ProjectID: "project-7", InvestmentID: "inv-9",
+		Amount: decimal.NewFromInt(100), Currency: "MXN", Status: "completed",
+		ProcessedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+}
+
+func TestEncryptedTransactionStoreCiphertextAtRest(t *testing.T) {
+	ctx := context.Background()
+	store, inner := newEncryptedStore(t, "user_id", "investment_id")
+	tx := sensitiveTransaction()
+	if err := store.Insert(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := inner.Get(ctx, tx.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, value := range map[string]string{"user_id": stored.UserID, "investment_id": stored.InvestmentID} {
+		if !strings.HasPrefix(value, encryptedFieldPrefix) || strings.Contains(value, "user-42") || strings.Contains(value, "inv-9") {
+			t.Errorf("stored %s = %q, want ciphertext", name, value)
+		}
+	}
+	if stored.ProjectID != "project-7" {
+		t.Errorf("stored project_id = %q, want plaintext: it is not configured", stored.ProjectID)
+	}
+	if stored.UserIDIndex != securityManager.BlindIndex("user-42") {
+		t.Errorf("stored user_id_index = %q, want the blind index", stored.UserIDIndex)
+	}
+
+	got, err := store.Get(ctx, tx.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.UserID != tx.UserID || got.ProjectID != tx.ProjectID || got.InvestmentID != tx.InvestmentID || got.UserIDIndex != "" {
+		t.Errorf("round trip = %s/%s/%s, want %s/%s/%s", got.UserID, got.ProjectID, got.InvestmentID, tx.UserID, tx.ProjectID, tx.InvestmentID)
+	}
+
+	many, err := store.GetMany(ctx, []string{tx.ID})
+	if err != nil || many[tx.ID].UserID != tx.UserID {
+		t.Errorf("GetMany = %v, %v; want decrypted user_id", many[tx.ID].UserID, err)
+	}
+}
+
+func TestEncryptedTransactionStoreListByUser(t *testing.T) {
+	ctx := context.Background()
+	store, inner := newEncryptedStore(t, "user_id")
+
+	encrypted := sensitiveTransaction()
+	if err := store.Insert(ctx, encrypted); err != nil {
+		t.Fatal(err)
+	}
+	// Una transacción escrita antes de activar el cifrado sigue en claro
+	legacy := sensitiveTransaction()
+	legacy.ProcessedAt = legacy.ProcessedAt.Add(-time.Minute)
+	if err := inner.Insert(ctx, legacy); err != nil {
+		t.Fatal(err)
+	}
+	other := sensitiveTransaction()
+	other.UserID = "user-1"
+	if err := store.Insert(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := store.List(ctx, TransactionFilter{UserID: "user-42", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 2 || page[0].ID != encrypted.ID || page[1].ID != legacy.ID {
+		t.Fatalf("page = %v, want the encrypted and the legacy transactions of user-42", page)
+	}
+	for _, tx := range page {
+		if tx.UserID != "user-42" {
+			t.Errorf("listed user_id = %q, want plaintext", tx.UserID)
+		}
+	}
+}
+
+func TestEncryptedTransactionStoreKeepsIntegrity(t *testing.T) {
+	store, inner := newEncryptedStore(t, "user_id", "project_id", "investment_id")
+	SetTransactionStore(store)
+	t.Cleanup(func() { SetTransactionStore(NewMockTransactionStore()) })
+
+	router := gin.New()
+	router.POST("/", ProcessTransaction)
+	id := transactionID(t, postTransaction(router, ""))
+
+	if stored, _ := inner.Get(context.Background(), id); !strings.HasPrefix(stored.UserID, encryptedFieldPrefix) {
+		t.Fatalf("stored user_id = %q, want ciphertext", stored.UserID)
+	}
+
+	// El hash se calcula sobre el texto plano, que el store restaura al leer
+	w, resp := doGet(t, "/verify/:id", VerifyTransaction, "/verify/"+id)
+	if w.Code != http.StatusOK || resp["integrity_valid"] != true {
+		t.Errorf("verify = %d %v, want integrity_valid=true", w.Code, resp)
+	}
+}
+
+func TestEncryptedTransactionStoreTamperedCiphertext(t *testing.T) {
+	ctx := context.Background()
+	store, inner := newEncryptedStore(t, "user_id")
+	tx := sensitiveTransaction()
+	tx.UserID = ""
+	if err := inner.Insert(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	tampered, _ := inner.Get(ctx, tx.ID)
+	_ = inner.Delete(ctx, tx.ID)
+	tampered.UserID = encryptedFieldPrefix + "bm90LWEtY2lwaGVydGV4dA=="
+	_ = inner.Insert(ctx, tampered)
+
+	if _, err := store.Get(ctx, tx.ID); err == nil {
+		t.Error("expected an error reading a tampered ciphertext")
+	}
+}
+
+func TestNewEncryptedTransactionStoreRejectsUnknownField(t *testing.T) {
+	if _, err := NewEncryptedTransactionStore(NewMockTransactionStore(), securityManager, []string{"user_id", "amount"}); err == nil {
+		t.Error("expected error for a field that cannot be encrypted")
+	}
+	store, err := NewEncryptedTransactionStore(NewMockTransactionStore(), securityManager, []string{" User_ID ", "", "user_id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(store.fields) != 1 || store.fields[0] != "user_id" {
+		t.Errorf("fields = %v, want [user_id]", store.fields)
+	}
+}
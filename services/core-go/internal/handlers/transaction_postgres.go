@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+)
+
+// transactionsSchema crea la tabla de transacciones procesadas
+const transactionsSchema = `
+CREATE TABLE IF NOT EXISTS core_transactions (
+    id                 UUID PRIMARY KEY,
+    type               VARCHAR(50) NOT NULL,
+    user_id            VARCHAR(255) NOT NULL,
+    project_id         VARCHAR(255) NOT NULL DEFAULT '',
+    investment_id      VARCHAR(255) NOT NULL DEFAULT '',
+    amount             NUMERIC(28, 8) NOT NULL,
+    currency           VARCHAR(10) NOT NULL,
+    status             VARCHAR(20) NOT NULL,
+    integrity_hash     CHAR(64) NOT NULL,
+    processed_at       TIMESTAMP WITH TIME ZONE NOT NULL,
+    processing_time_ms BIGINT NOT NULL DEFAULT 0,
+    rejection_reason   TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS core_transactions_user_page_idx
+    ON core_transactions (user_id, processed_at DESC, id DESC);
+
+ALTER TABLE core_transactions
+    ADD COLUMN IF NOT EXISTS reverses_id VARCHAR(36) NOT NULL DEFAULT '';
+
+-- Una transacción sólo puede tener una reversa
+CREATE UNIQUE INDEX IF NOT EXISTS core_transactions_reverses_id_key
+    ON core_transactions (reverses_id) WHERE reverses_id <> '';
+
+-- Versión para control de concurrencia optimista en las actualizaciones
+ALTER TABLE core_transactions
+    ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+-- Los campos cifrados en reposo no caben en VARCHAR(255); user_id_index es
+-- el índice ciego con el que se filtra por un user_id cifrado
+ALTER TABLE core_transactions
+    ALTER COLUMN user_id TYPE TEXT,
+    ALTER COLUMN project_id TYPE TEXT,
+    ALTER COLUMN investment_id TYPE TEXT,
+    ADD COLUMN IF NOT EXISTS user_id_index VARCHAR(64) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS core_transactions_user_index_page_idx
+    ON core_transactions (user_id_index, processed_at DESC, id DESC) WHERE user_id_index <> '';
+
+-- Si se pidió entrada en el ledger; las filas anteriores quedan en FALSE y la
+-- conciliación sólo compara las que sí tienen entrada
+ALTER TABLE core_transactions
+    ADD COLUMN IF NOT EXISTS create_ledger_entry BOOLEAN NOT NULL DEFAULT FALSE;
+`
+
+const transactionColumns = `id::text, type, user_id, project_id, investment_id, amount::text,
+    currency, status, integrity_hash, processed_at, processing_time_ms, rejection_reason, reverses_id, version, user_id_index,
+    create_ledger_entry`
+
+// pgUniqueViolation es el SQLSTATE de una clave duplicada
+const pgUniqueViolation = "23505"
+
+// PostgresTransactionStore persiste las transacciones en PostgreSQL
+type PostgresTransactionStore struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresTransactionStore crea el store y asegura que el esquema exista
+func NewPostgresTransactionStore(ctx context.Context, pool *pgxpool.Pool) (*PostgresTransactionStore, error) {
+	if _, err := pool.Exec(ctx, transactionsSchema); err != nil {
+		return nil, fmt.Errorf("failed to create transactions schema: %w", err)
+	}
+	return &PostgresTransactionStore{pool: pool}, nil
+}
+
+// Insert guarda la transacción; la clave primaria impide IDs duplicados
+func (s *PostgresTransactionStore) Insert(ctx context.Context, tx Transaction) error {
+	_, err := s.pool.Exec(ctx, `
+        INSERT INTO core_transactions (
+            id, type, user_id, project_id, investment_id, amount, currency,
+            status, integrity_hash, processed_at, processing_time_ms, rejection_reason, reverses_id, version,
+            user_id_index, create_ledger_entry
+        ) VALUES ($1, $2, $3, $4, $5, $6::numeric, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`,
+		tx.ID, tx.Type, tx.UserID, tx.ProjectID, tx.InvestmentID, tx.Amount.String(), tx.Currency,
+		tx.Status, tx.IntegrityHash, tx.ProcessedAt, tx.ProcessingTime, tx.RejectionReason, tx.ReversesID, tx.Version,
+		tx.UserIDIndex, tx.CreateLedgerEntry,
+	)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return ErrDuplicateTransaction
+	}
+	if err != nil {
+		return fmt.Errorf("failed to insert transaction: %w", err)
+	}
+	return nil
+}
+
+// Get lee una transacción por ID
+func (s *PostgresTransactionStore) Get(ctx context.Context, id string) (Transaction, error) {
+	row := s.pool.QueryRow(ctx,
+		`SELECT `+transactionColumns+` FROM core_transactions WHERE id = $1`, id)
+
+	tx, err := scanTransaction(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Transaction{}, ErrTransactionNotFound
+	}
+	return tx, err
+}
+
+// GetMany busca todas las transacciones en una sola consulta
+func (s *PostgresTransactionStore) GetMany(ctx context.Context, ids []string) (map[string]Transaction, error) {
+	rows, err := s.pool.Query(ctx,
+		`SELECT `+transactionColumns+` FROM core_transactions WHERE id = ANY($1::uuid[])`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transactions: %w", err)
+	}
+	defer rows.Close()
+
+	found := make(map[string]Transaction, len(ids))
+	for rows.Next() {
+		tx, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		found[tx.ID] = tx
+	}
+	return found, rows.Err()
+}
+
+// Delete elimina una transacción por ID
+func (s *PostgresTransactionStore) Delete(ctx context.Context, id string) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM core_transactions WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete transaction: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrTransactionNotFound
+	}
+	return nil
+}
+
+// UpdateStatus cambia el estado con un UPDATE condicionado a la versión
+func (s *PostgresTransactionStore) UpdateStatus(ctx context.Context, id string, version int64, status string) (int64, error) {
+	var newVersion int64
+	err := s.pool.QueryRow(ctx,
+		`UPDATE core_transactions SET status = $3, version = version + 1
+		 WHERE id = $1 AND version = $2 RETURNING version`, id, version, status).Scan(&newVersion)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if _, err := s.Get(ctx, id); err != nil {
+			return 0, err
+		}
+		return 0, ErrTransactionVersionConflict
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to update transaction status: %w", err)
+	}
+	return newVersion, nil
+}
+
+// List usa paginación por keyset sobre (processed_at, id), que aprovecha el
+// índice core_transactions_user_page_idx sin importar la profundidad de la página
+func (s *PostgresTransactionStore) List(ctx context.Context, filter TransactionFilter) ([]Transaction, error) {
+	var conditions []string
+	var args []interface{}
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if filter.UserID != "" && filter.UserIDIndex != "" {
+		conditions = append(conditions, fmt.Sprintf("(user_id = %s OR user_id_index = %s)",
+			addArg(filter.UserID), addArg(filter.UserIDIndex)))
+	} else if filter.UserID != "" {
+		conditions = append(conditions, "user_id = "+addArg(filter.UserID))
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "status = "+addArg(filter.Status))
+	}
+	if filter.After != nil {
+		conditions = append(conditions, fmt.Sprintf("(processed_at, id) < (%s, %s::uuid)",
+			addArg(filter.After.ProcessedAt), addArg(filter.After.ID)))
+	}
+
+	query := `SELECT ` + transactionColumns + ` FROM core_transactions`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY processed_at DESC, id DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ` + addArg(filter.Limit)
+	}
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var page []Transaction
+	for rows.Next() {
+		tx, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		page = append(page, tx)
+	}
+	return page, rows.Err()
+}
+
+// Ping comprueba la conexión con PostgreSQL
+func (s *PostgresTransactionStore) Ping(ctx context.Context) error {
+	return s.pool.Ping(ctx)
+}
+
+func scanTransaction(row pgx.Row) (Transaction, error) {
+	var tx Transaction
+	var amount string
+	err := row.Scan(
+		&tx.ID, &tx.Type, &tx.UserID, &tx.ProjectID, &tx.InvestmentID, &amount,
+		&tx.Currency, &tx.Status, &tx.IntegrityHash, &tx.ProcessedAt, &tx.ProcessingTime, &tx.RejectionReason,
+		&tx.ReversesID, &tx.Version, &tx.UserIDIndex, &tx.CreateLedgerEntry,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Transaction{}, err
+	}
+	if err != nil {
+		return Transaction{}, fmt.Errorf("failed to read transaction: %w", err)
+	}
+
+	if tx.Amount, err = decimal.NewFromString(amount); err != nil {
+		return Transaction{}, fmt.Errorf("invalid stored amount: %w", err)
+	}
+	tx.ProcessedAt = tx.ProcessedAt.UTC()
+	return tx, nil
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrTransactionNotFound indica que no existe una transacción con el ID solicitado
+	ErrTransactionNotFound = errors.New("transaction not found")
+	// ErrDuplicateTransaction indica que ya existe una transacción con el mismo ID
+	ErrDuplicateTransaction = errors.New("transaction already exists")
+	// ErrTransactionVersionConflict indica que la transacción cambió desde que
+	// se leyó: su versión actual no es la esperada
+	ErrTransactionVersionConflict = errors.New("transaction version changed concurrently")
+)
+
+// initialTransactionVersion es la versión de una transacción recién creada
+const initialTransactionVersion = 1
+
+// TransactionStore persiste las transacciones procesadas
+type TransactionStore interface {
+	// Insert guarda una transacción nueva; un ID repetido retorna ErrDuplicateTransaction
+	Insert(ctx context.Context, tx Transaction) error
+	// Get retorna la transacción con el ID dado o ErrTransactionNotFound
+	Get(ctx context.Context, id string) (Transaction, error)
+	// GetMany retorna las transacciones existentes de ids indexadas por ID;
+	// los IDs inexistentes no aparecen en el resultado
+	GetMany(ctx context.Context, ids []string) (map[string]Transaction, error)
+	// List retorna las transacciones que cumplen el filtro, de la más
+	// reciente a la más antigua (processed_at DESC, id DESC)
+	List(ctx context.Context, filter TransactionFilter) ([]Transaction, error)
+	// Delete elimina una transacción; sólo se usa para compensar un Insert
+	// cuya escritura asociada en el ledger falló
+	Delete(ctx context.Context, id string) error
+	// UpdateStatus cambia el estado si la versión actual es version
+	// (compare-and-swap) y retorna la nueva versión; si la transacción cambió
+	// entretanto retorna ErrTransactionVersionConflict
+	UpdateStatus(ctx context.Context, id string, version int64, status string) (int64, error)
+}
+
+// TransactionCursor identifica la última transacción de una página; la
+// siguiente página empieza en la transacción inmediatamente anterior
+type TransactionCursor struct {
+	ProcessedAt time.Time
+	ID          string
+}
+
+// TransactionFilter selecciona una página de transacciones
+type TransactionFilter struct {
+	UserID string
+	// UserIDIndex, si no es vacío, también selecciona las transacciones cuyo
+	// índice ciego coincide; lo usa EncryptedTransactionStore cuando user_id
+	// se guarda cifrado
+	UserIDIndex string
+	// Status vacío incluye todos los estados
+	Status string
+	// After es nil para la primera página
+	After *TransactionCursor
+	Limit int
+}
+
+// matches indica si la transacción cumple el filtro
+func (f TransactionFilter) matches(tx Transaction) bool {
+	if f.UserID != "" && tx.UserID != f.UserID && (f.UserIDIndex == "" || tx.UserIDIndex != f.UserIDIndex) {
+		return false
+	}
+	if f.Status != "" && tx.Status != f.Status {
+		return false
+	}
+	return f.After == nil || transactionBefore(tx, *f.After)
+}
+
+// transactionBefore indica si tx va después del cursor en orden descendente
+func transactionBefore(tx Transaction, cursor TransactionCursor) bool {
+	if !tx.ProcessedAt.Equal(cursor.ProcessedAt) {
+		return tx.ProcessedAt.Before(cursor.ProcessedAt)
+	}
+	return tx.ID < cursor.ID
+}
+
+// MockTransactionStore es un TransactionStore en memoria para pruebas y desarrollo.
+// Err permite simular fallos de la base de datos en todas las operaciones.
+type MockTransactionStore struct {
+	mu           sync.RWMutex
+	transactions map[string]Transaction
+	Err          error
+}
+
+// NewMockTransactionStore crea un store en memoria vacío
+func NewMockTransactionStore() *MockTransactionStore {
+	return &MockTransactionStore{transactions: make(map[string]Transaction)}
+}
+
+// Insert guarda la transacción si su ID no existe
+func (m *MockTransactionStore) Insert(_ context.Context, tx Transaction) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return m.Err
+	}
+	if _, exists := m.transactions[tx.ID]; exists {
+		return ErrDuplicateTransaction
+	}
+	m.transactions[tx.ID] = tx
+	return nil
+}
+
+// Get busca la transacción por ID
+func (m *MockTransactionStore) Get(_ context.Context, id string) (Transaction, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.Err != nil {
+		return Transaction{}, m.Err
+	}
+	tx, ok := m.transactions[id]
+	if !ok {
+		return Transaction{}, ErrTransactionNotFound
+	}
+	return tx, nil
+}
+
+// GetMany busca cada ID en memoria
+func (m *MockTransactionStore) GetMany(_ context.Context, ids []string) (map[string]Transaction, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	found := make(map[string]Transaction, len(ids))
+	for _, id := range ids {
+		if tx, ok := m.transactions[id]; ok {
+			found[id] = tx
+		}
+	}
+	return found, nil
+}
+
+// List filtra y ordena las transacciones en memoria
+func (m *MockTransactionStore) List(_ context.Context, filter TransactionFilter) ([]Transaction, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.Err != nil {
+		return nil, m.Err
+	}
+
+	var page []Transaction
+	for _, tx := range m.transactions {
+		if filter.matches(tx) {
+			page = append(page, tx)
+		}
+	}
+	sort.Slice(page, func(i, j int) bool {
+		return transactionBefore(page[j], TransactionCursor{ProcessedAt: page[i].ProcessedAt, ID: page[i].ID})
+	})
+	if filter.Limit > 0 && len(page) > filter.Limit {
+		page = page[:filter.Limit]
+	}
+	return page, nil
+}
+
+// Delete elimina la transacción por ID
+func (m *MockTransactionStore) Delete(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return m.Err
+	}
+	if _, ok := m.transactions[id]; !ok {
+		return ErrTransactionNotFound
+	}
+	delete(m.transactions, id)
+	return nil
+}
+
+// UpdateStatus cambia el estado si la versión coincide e incrementa la versión
+func (m *MockTransactionStore) UpdateStatus(_ context.Context, id string, version int64, status string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return 0, m.Err
+	}
+	tx, ok := m.transactions[id]
+	if !ok {
+		return 0, ErrTransactionNotFound
+	}
+	if tx.Version != version {
+		return 0, ErrTransactionVersionConflict
+	}
+	tx.Status = status
+	tx.Version++
+	m.transactions[id] = tx
+	return tx.Version, nil
+}
+
+// transactionStore es el store usado por ProcessTransaction y VerifyTransaction.
+// Por defecto es en memoria; main lo reemplaza por el store configurado.
+var transactionStore TransactionStore = NewMockTransactionStore()
+
+// SetTransactionStore reemplaza el store de transacciones
+func SetTransactionStore(store TransactionStore) {
+	transactionStore = store
+}
@@ -0,0 +1,387 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// useMockTransactionStore configura los handlers con un store de transacciones vacío
+func useMockTransactionStore(t *testing.T) *MockTransactionStore {
+	t.Helper()
+	store := NewMockTransactionStore()
+	SetTransactionStore(store)
+	t.Cleanup(func() { SetTransactionStore(NewMockTransactionStore()) })
+	return store
+}
+
+func TestProcessTransactionPersistsAndVerifies(t *testing.T) {
+	store := useMockTransactionStore(t)
+
+	router := gin.New()
+	router.POST("/", ProcessTransaction)
+	id := transactionID(t, postTransaction(router, ""))
+
+	stored, err := store.Get(context.Background(), id)
+	if err != nil {
+		t.Fatalf("transaction %s not persisted: %v", id, err)
+	}
+	if stored.Status != "completed" {
+		t.Errorf("stored status = %q, want completed", stored.Status)
+	}
+
+	w, resp := doGet(t, "/verify/:id", VerifyTransaction, "/verify/"+id)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if resp["status"] != "completed" || resp["integrity_valid"] != true {
+		t.Errorf("verify = %v/%v, want completed/true", resp["status"], resp["integrity_valid"])
+	}
+}
+
+func TestVerifyTransactionReportsStoredStatus(t *testing.T) {
+	store := useMockTransactionStore(t)
+
+	tx := Transaction{ID: uuid.New().String(), Type: "deposit", UserID: "user-1", Currency: "MXN", Status: "rejected"}
+	tx.IntegrityHash = "tampered"
+	if err := store.Insert(context.Background(), tx); err != nil {
+		t.Fatal(err)
+	}
+
+	_, resp := doGet(t, "/verify/:id", VerifyTransaction, "/verify/"+tx.ID)
+	if resp["status"] != "rejected" || resp["integrity_valid"] != false {
+		t.Errorf("verify = %v/%v, want rejected/false", resp["status"], resp["integrity_valid"])
+	}
+}
+
+func TestVerifyTransactionUnknownID(t *testing.T) {
+	useMockTransactionStore(t)
+
+	w, resp := doGet(t, "/verify/:id", VerifyTransaction, "/verify/"+uuid.New().String())
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", w.Code)
+	}
+	if resp["error"] != "Transaction not found" {
+		t.Errorf("error = %v", resp["error"])
+	}
+}
+
+func TestProcessTransactionStoreFailure(t *testing.T) {
+	store := useMockTransactionStore(t)
+	store.Err = errors.New("database unavailable")
+
+	router := gin.New()
+	router.POST("/", ProcessTransaction)
+	w := postTransaction(router, "store-failure-key")
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", w.Code)
+	}
+
+	// La clave de idempotencia se libera para permitir el reintento
+	store.Err = nil
+	w = postTransaction(router, "store-failure-key")
+	if w.Code != http.StatusOK {
+		t.Fatalf("retry status = %d, want 200", w.Code)
+	}
+	var resp map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp["success"] != true {
+		t.Errorf("retry response = %v", resp)
+	}
+}
+
+func TestMockTransactionStoreDuplicateID(t *testing.T) {
+	store := NewMockTransactionStore()
+	tx := Transaction{ID: uuid.New().String()}
+	if err := store.Insert(context.Background(), tx); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Insert(context.Background(), tx); !errors.Is(err, ErrDuplicateTransaction) {
+		t.Errorf("second insert error = %v, want ErrDuplicateTransaction", err)
+	}
+}
+
+func TestMockTransactionStoreUpdateStatusVersion(t *testing.T) {
+	ctx := context.Background()
+	store := NewMockTransactionStore()
+	tx := Transaction{ID: uuid.New().String(), Status: "completed", Version: initialTransactionVersion}
+	if err := store.Insert(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+
+	version, err := store.UpdateStatus(ctx, tx.ID, 1, TransactionStatusReversed)
+	if err != nil || version != 2 {
+		t.Fatalf("UpdateStatus = %d, %v; want version 2", version, err)
+	}
+	// Una actualización con la versión anterior no pisa el cambio
+	if _, err := store.UpdateStatus(ctx, tx.ID, 1, "completed"); !errors.Is(err, ErrTransactionVersionConflict) {
+		t.Errorf("stale update error = %v, want ErrTransactionVersionConflict", err)
+	}
+	if stored, _ := store.Get(ctx, tx.ID); stored.Status != TransactionStatusReversed || stored.Version != 2 {
+		t.Errorf("stored = %s v%d, want reversed v2", stored.Status, stored.Version)
+	}
+	if _, err := store.UpdateStatus(ctx, uuid.New().String(), 1, "completed"); !errors.Is(err, ErrTransactionNotFound) {
+		t.Errorf("unknown id error = %v, want ErrTransactionNotFound", err)
+	}
+}
+
+// seedTransactions inserta n transacciones del usuario con processed_at
+// decreciente; las de índice impar quedan "rejected"
+func seedTransactions(t *testing.T, store *MockTransactionStore, userID string, n int) []Transaction {
+	t.Helper()
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	seeded := make([]Transaction, n)
+	for i := range seeded {
+		status := "completed"
+		if i%2 == 1 {
+			status = "rejected"
+		}
+		seeded[i] = Transaction{
+			ID:          uuid.New().String(),
+			Type:        "deposit",
+			UserID:      userID,
+			Currency:    "MXN",
+			Status:      status,
+			ProcessedAt: base.Add(-time.Duration(i) * time.Minute),
+		}
+		if err := store.Insert(context.Background(), seeded[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return seeded
+}
+
+// listPage consulta ListTransactions y retorna los IDs y el next_cursor
+func listPage(t *testing.T, query url.Values) (int, []string, string) {
+	t.Helper()
+	w, resp := doGet(t, "/transactions", ListTransactions, "/transactions?"+query.Encode())
+	if w.Code != http.StatusOK {
+		return w.Code, nil, ""
+	}
+	var ids []string
+	for _, item := range resp["transactions"].([]interface{}) {
+		ids = append(ids, item.(map[string]interface{})["id"].(string))
+	}
+	return w.Code, ids, resp["next_cursor"].(string)
+}
+
+func TestListTransactionsPagination(t *testing.T) {
+	store := useMockTransactionStore(t)
+	seeded := seedTransactions(t, store, "user-list", 5)
+	seedTransactions(t, store, "other-user", 3)
+
+	code, first, cursor := listPage(t, url.Values{"user_id": {"user-list"}, "limit": {"2"}})
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	if len(first) != 2 || first[0] != seeded[0].ID || first[1] != seeded[1].ID {
+		t.Fatalf("first page = %v, want newest two", first)
+	}
+	if cursor == "" {
+		t.Fatal("first page must return next_cursor")
+	}
+
+	var all []string
+	all = append(all, first...)
+	for cursor != "" {
+		_, ids, next := listPage(t, url.Values{"user_id": {"user-list"}, "limit": {"2"}, "cursor": {cursor}})
+		all = append(all, ids...)
+		cursor = next
+	}
+	if len(all) != len(seeded) {
+		t.Fatalf("paged through %d transactions, want %d", len(all), len(seeded))
+	}
+	for i, tx := range seeded {
+		if all[i] != tx.ID {
+			t.Errorf("position %d = %s, want %s", i, all[i], tx.ID)
+		}
+	}
+}
+
+func TestListTransactionsSameTimestampCursor(t *testing.T) {
+	store := useMockTransactionStore(t)
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		tx := Transaction{ID: uuid.New().String(), UserID: "user-tie", Status: "completed", ProcessedAt: at}
+		if err := store.Insert(context.Background(), tx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	seen := map[string]bool{}
+	cursor := ""
+	for page := 0; page < 5; page++ {
+		query := url.Values{"user_id": {"user-tie"}, "limit": {"1"}}
+		if cursor != "" {
+			query.Set("cursor", cursor)
+		}
+		_, ids, next := listPage(t, query)
+		for _, id := range ids {
+			if seen[id] {
+				t.Fatalf("transaction %s returned twice", id)
+			}
+			seen[id] = true
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if len(seen) != 3 {
+		t.Errorf("saw %d transactions, want 3", len(seen))
+	}
+}
+
+func TestListTransactionsFilterByStatus(t *testing.T) {
+	store := useMockTransactionStore(t)
+	seedTransactions(t, store, "user-status", 6)
+
+	_, ids, cursor := listPage(t, url.Values{"user_id": {"user-status"}, "status": {"rejected"}})
+	if len(ids) != 3 || cursor != "" {
+		t.Fatalf("rejected page = %d ids, cursor %q; want 3 ids and no cursor", len(ids), cursor)
+	}
+	for _, id := range ids {
+		tx, _ := store.Get(context.Background(), id)
+		if tx.Status != "rejected" {
+			t.Errorf("transaction %s status = %s, want rejected", id, tx.Status)
+		}
+	}
+}
+
+func TestListTransactionsValidation(t *testing.T) {
+	useMockTransactionStore(t)
+
+	cases := []url.Values{
+		{},
+		{"user_id": {"u"}, "limit": {"0"}},
+		{"user_id": {"u"}, "limit": {fmt.Sprint(MaxListLimit + 1)}},
+		{"user_id": {"u"}, "limit": {"abc"}},
+		{"user_id": {"u"}, "cursor": {"not-a-cursor"}},
+	}
+	for _, query := range cases {
+		if code, _, _ := listPage(t, query); code != http.StatusBadRequest {
+			t.Errorf("%v: status = %d, want 400", query, code)
+		}
+	}
+}
+
+func TestProcessTransactionCreatesLedgerEntry(t *testing.T) {
+	store := useMockTransactionStore(t)
+	useMockLedger(t)
+
+	w, resp := doJSON(t, ProcessTransaction, map[string]interface{}{
+		"type":                "investment",
+		"user_id":             "user-ledger",
+		"amount":              "1500.25",
+		"currency":            "USD",
+		"create_ledger_entry": true,
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	sequence, ok := resp["ledger_sequence"].(float64)
+	if !ok {
+		t.Fatalf("ledger_sequence missing from %v", resp)
+	}
+
+	id := resp["transaction"].(map[string]interface{})["id"].(string)
+	tx, err := store.Get(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := ledgerChain.Get(context.Background(), int64(sequence))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !entry.Amount.Equal(tx.Amount) || entry.Currency != tx.Currency || entry.EntryType != tx.Type {
+		t.Errorf("entry = %s %s %s, want %s %s %s", entry.EntryType, entry.Amount, entry.Currency, tx.Type, tx.Amount, tx.Currency)
+	}
+	if want := ledgerEntryForTransaction(tx).Description; entry.Description != want {
+		t.Errorf("description = %q, want %q", entry.Description, want)
+	}
+	if entry.ClientRef != transactionLedgerRef(tx.ID) {
+		t.Errorf("client_ref = %q, want %q", entry.ClientRef, transactionLedgerRef(tx.ID))
+	}
+
+	// Sin la bandera no se toca el ledger
+	_, resp = doJSON(t, ProcessTransaction, map[string]interface{}{"type": "deposit", "user_id": "user-ledger", "amount": "10"})
+	if _, ok := resp["ledger_sequence"]; ok {
+		t.Error("ledger_sequence present without create_ledger_entry")
+	}
+}
+
+func TestProcessTransactionLedgerFailureCompensates(t *testing.T) {
+	store := useMockTransactionStore(t)
+	ledgerStore := useMockLedger(t)
+	ledgerStore.Err = errors.New("ledger unavailable")
+
+	w, _ := doJSON(t, ProcessTransaction, map[string]interface{}{
+		"type":                "deposit",
+		"user_id":             "user-compensate",
+		"amount":              "10",
+		"create_ledger_entry": true,
+	})
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", w.Code)
+	}
+
+	page, err := store.List(context.Background(), TransactionFilter{UserID: "user-compensate"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 0 {
+		t.Errorf("transaction kept after ledger failure: %+v", page)
+	}
+}
+
+func TestVerifyTransactionsBatch(t *testing.T) {
+	store := useMockTransactionStore(t)
+
+	existing := Transaction{ID: uuid.New().String(), Type: "deposit", UserID: "user-1", Currency: "MXN", Status: "completed", ProcessedAt: time.Now().UTC()}
+	existing.IntegrityHash = calculateTransactionHash(existing)
+	if err := store.Insert(context.Background(), existing); err != nil {
+		t.Fatal(err)
+	}
+	missing := uuid.New().String()
+
+	w, resp := doJSON(t, VerifyTransactions, map[string]interface{}{
+		"ids": []string{existing.ID, missing, "not-a-uuid", strings.ToUpper(existing.ID)},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+
+	transactions := resp["transactions"].(map[string]interface{})
+	if len(transactions) != 1 {
+		t.Fatalf("transactions = %v, want only the existing ID", transactions)
+	}
+	result, ok := transactions[existing.ID].(map[string]interface{})
+	if !ok || result["status"] != "completed" || result["integrity_valid"] != true {
+		t.Errorf("transactions[%s] = %v, want completed and valid", existing.ID, transactions[existing.ID])
+	}
+	if notFound := resp["not_found"].([]interface{}); len(notFound) != 1 || notFound[0] != missing {
+		t.Errorf("not_found = %v, want [%s]", notFound, missing)
+	}
+	if invalid := resp["invalid_ids"].([]interface{}); len(invalid) != 1 || invalid[0] != "not-a-uuid" {
+		t.Errorf("invalid_ids = %v, want [not-a-uuid]", invalid)
+	}
+}
+
+func TestVerifyTransactionsRequiresIDs(t *testing.T) {
+	useMockTransactionStore(t)
+
+	if w, _ := doJSON(t, VerifyTransactions, map[string]interface{}{"ids": []string{}}); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("empty ids: status = %d, want 422", w.Code)
+	}
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"sort"
+	"strings"
+)
+
+// DefaultTransactionTypes son los tipos de transacción aceptados por defecto
+var DefaultTransactionTypes = []string{"deposit", "withdrawal", "investment", "payout", "fee"}
+
+// allowedTransactionTypes contiene los tipos aceptados en minúsculas
+var allowedTransactionTypes = transactionTypeSet(DefaultTransactionTypes)
+
+func transactionTypeSet(types []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(types))
+	for _, txType := range types {
+		if normalized := strings.ToLower(strings.TrimSpace(txType)); normalized != "" {
+			set[normalized] = struct{}{}
+		}
+	}
+	return set
+}
+
+// SetTransactionTypes reemplaza la lista de tipos aceptados; una lista sin
+// tipos válidos restaura DefaultTransactionTypes
+func SetTransactionTypes(types []string) {
+	set := transactionTypeSet(types)
+	if len(set) == 0 {
+		set = transactionTypeSet(DefaultTransactionTypes)
+	}
+	allowedTransactionTypes = set
+}
+
+// normalizeTransactionType retorna el tipo en minúsculas y si está permitido
+func normalizeTransactionType(txType string) (string, bool) {
+	normalized := strings.ToLower(strings.TrimSpace(txType))
+	_, ok := allowedTransactionTypes[normalized]
+	return normalized, ok
+}
+
+// allowedTransactionTypeList retorna los tipos aceptados en orden alfabético
+func allowedTransactionTypeList() []string {
+	types := make([]string, 0, len(allowedTransactionTypes))
+	for txType := range allowedTransactionTypes {
+		types = append(types, txType)
+	}
+	sort.Strings(types)
+	return types
+}
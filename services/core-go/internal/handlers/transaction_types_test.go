@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestProcessTransactionRejectsUnknownType(t *testing.T) {
+	w, resp := doJSON(t, ProcessTransaction, map[string]interface{}{
+		"type":    "refund",
+		"user_id": "user-1",
+		"amount":  "100",
+	})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", w.Code)
+	}
+	if resp["error"] != "Invalid transaction type" {
+		t.Errorf("error = %v", resp["error"])
+	}
+	allowed, ok := resp["allowed_types"].([]interface{})
+	if !ok || len(allowed) != len(DefaultTransactionTypes) {
+		t.Errorf("allowed_types = %v, want %d types", resp["allowed_types"], len(DefaultTransactionTypes))
+	}
+}
+
+func TestProcessTransactionNormalizesTypeCase(t *testing.T) {
+	w, resp := doJSON(t, ProcessTransaction, map[string]interface{}{
+		"type":    "Deposit",
+		"user_id": "user-1",
+		"amount":  "100",
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	tx := resp["transaction"].(map[string]interface{})
+	if tx["type"] != "deposit" {
+		t.Errorf("type = %v, want deposit", tx["type"])
+	}
+}
+
+func TestBatchProcessRejectsUnknownType(t *testing.T) {
+	w, resp := doJSON(t, BatchProcess, map[string]interface{}{
+		"transactions": []map[string]interface{}{
+			{"type": "WITHDRAWAL", "user_id": "user-1", "amount": "10"},
+			{"type": "refund", "user_id": "user-2", "amount": "10"},
+		},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+
+	results := resp["transactions"].([]interface{})
+	first := results[0].(map[string]interface{})
+	if first["status"] != "completed" || first["type"] != "withdrawal" {
+		t.Errorf("transactions[0] = %v/%v, want completed/withdrawal", first["status"], first["type"])
+	}
+	if second := results[1].(map[string]interface{}); second["status"] != "rejected" {
+		t.Errorf("transactions[1].status = %v, want rejected", second["status"])
+	}
+}
+
+func TestSetTransactionTypes(t *testing.T) {
+	SetTransactionTypes([]string{" Refund ", ""})
+	defer SetTransactionTypes(nil)
+
+	if txType, ok := normalizeTransactionType("REFUND"); !ok || txType != "refund" {
+		t.Errorf("normalizeTransactionType(REFUND) = %q, %v", txType, ok)
+	}
+	if _, ok := normalizeTransactionType("deposit"); ok {
+		t.Error("deposit must be rejected once the list is replaced")
+	}
+
+	SetTransactionTypes(nil)
+	if _, ok := normalizeTransactionType("deposit"); !ok {
+		t.Error("empty list must restore the default types")
+	}
+}
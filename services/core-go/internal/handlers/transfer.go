@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// TransferRequest son los datos de una transferencia a validar, compartidos
+// por el endpoint HTTP y el servicio gRPC
+type TransferRequest struct {
+	FromAccount string          `json:"from_account" binding:"required"`
+	ToAccount   string          `json:"to_account" binding:"required"`
+	Amount      decimal.Decimal `json:"amount" binding:"required"`
+	Currency    string          `json:"currency"`
+	ToCurrency  string          `json:"to_currency"`
+}
+
+// TransferValidation es el resultado de ValidateTransferRequest. Los campos
+// de conversión sólo se informan cuando se pidió una moneda de destino.
+// Degraded indica que algún proveedor no respondió a tiempo, por lo que la
+// transferencia no pudo validarse por completo. RequiredApprovals indica
+// cuántos aprobadores exigirá el monto al procesarse; cero si no requiere.
+type TransferValidation struct {
+	Currency          string           `json:"currency,omitempty"`
+	ToCurrency        string           `json:"to_currency,omitempty"`
+	Rate              *decimal.Decimal `json:"rate,omitempty"`
+	ConvertedAmount   *decimal.Decimal `json:"converted_amount,omitempty"`
+	IsValid           bool             `json:"is_valid"`
+	Validations       []string         `json:"validations"`
+	ValidatedAt       time.Time        `json:"validated_at"`
+	Degraded          bool             `json:"degraded,omitempty"`
+	RequiresApproval  bool             `json:"requires_approval,omitempty"`
+	RequiredApprovals int              `json:"required_approvals,omitempty"`
+}
+
+// ValidateTransferRequest valida una transferencia antes de ejecutarla. Sólo
+// retorna error cuando la solicitud es inválida; las reglas de negocio no
+// cumplidas van en Validations.
+func ValidateTransferRequest(ctx context.Context, req TransferRequest) (TransferValidation, error) {
+	req.Currency = monedaPorDefecto(strings.ToUpper(req.Currency))
+	req.ToCurrency = strings.ToUpper(req.ToCurrency)
+	for _, currency := range []string{req.Currency, req.ToCurrency} {
+		if currency != "" && !esCodigoISO4217(currency) {
+			return TransferValidation{}, ErrInvalidCurrency
+		}
+	}
+
+	// Validaciones
+	result := TransferValidation{
+		IsValid:     true,
+		Validations: []string{},
+	}
+	reject := func(reason string) {
+		result.Validations = append(result.Validations, reason)
+		result.IsValid = false
+	}
+
+	if req.Amount.LessThanOrEqual(decimal.Zero) {
+		reject("Amount must be positive")
+	}
+
+	if req.FromAccount == req.ToAccount {
+		reject("Source and destination accounts must be different")
+	}
+
+	// Saldo suficiente en la cuenta origen, si hay proveedor de saldos. Los
+	// proveedores externos se esperan con un plazo propio para que uno lento
+	// no bloquee la validación hasta el timeout del request.
+	if provider := balanceProvider; provider != nil {
+		balance, err := callProvider(ctx, balanceTimeout, func(ctx context.Context) (decimal.Decimal, error) {
+			return provider.Balance(ctx, req.FromAccount, req.Currency)
+		})
+		if err != nil {
+			result.Degraded = result.Degraded || isProviderUnavailable(err)
+			reject("Source account balance unavailable")
+		} else if req.Amount.GreaterThan(balance) {
+			reject("Insufficient funds")
+		}
+	}
+
+	// Conversión opcional a la moneda de destino
+	if req.ToCurrency != "" {
+		result.Currency = req.Currency
+		result.ToCurrency = req.ToCurrency
+
+		provider := exchangeRateProvider
+		rate, err := callProvider(ctx, exchangeRateTimeout, func(ctx context.Context) (decimal.Decimal, error) {
+			return provider.Rate(ctx, req.Currency, req.ToCurrency)
+		})
+		switch {
+		case isProviderUnavailable(err):
+			result.Degraded = true
+			reject(fmt.Sprintf("Exchange rate unavailable for %s/%s, try again later", req.Currency, req.ToCurrency))
+		case err != nil:
+			reject(fmt.Sprintf("No exchange rate available for %s/%s", req.Currency, req.ToCurrency))
+		default:
+			converted := req.Amount.Mul(rate).Round(decimalesMoneda)
+			result.Rate = &rate
+			result.ConvertedAmount = &converted
+		}
+	}
+
+	// No invalida la transferencia, pero el cliente debe esperar aprobadores
+	if requiresApproval(req.Amount) {
+		result.RequiresApproval = true
+		result.RequiredApprovals = requiredApprovals
+	}
+
+	result.ValidatedAt = time.Now()
+	return result, nil
+}
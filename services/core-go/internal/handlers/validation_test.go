@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fincore/core-go/internal/apierror"
+	"github.com/gin-gonic/gin"
+)
+
+func TestBindingErrorsReturnFieldDetails(t *testing.T) {
+	w, resp := doJSON(t, ProcessTransaction, map[string]interface{}{
+		"type":   "deposit",
+		"amount": "10",
+	})
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want 422", w.Code)
+	}
+
+	fields, ok := resp["fields"].([]interface{})
+	if !ok || len(fields) != 1 {
+		t.Fatalf("fields = %v, want one entry", resp["fields"])
+	}
+	field := fields[0].(map[string]interface{})
+	if field["field"] != "user_id" || field["rule"] != "required" || field["message"] != "user_id is required" {
+		t.Errorf("field error = %v, want user_id/required", field)
+	}
+}
+
+func TestBindingErrorsPerHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler gin.HandlerFunc
+		body    map[string]interface{}
+		field   string
+	}{
+		{"ledger sin entry_type", CreateLedgerEntry, map[string]interface{}{"amount": "10"}, "entry_type"},
+		{"métricas sin flujos", CalculateMetrics, map[string]interface{}{"inversion_inicial": 1000, "tasa_descuento": 0.1}, "flujos_ingresos"},
+		{"transferencia sin destino", ValidateTransfer, map[string]interface{}{"from_account": "a", "amount": "10"}, "to_account"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, resp := doJSON(t, tt.handler, tt.body)
+			if w.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("status = %d, want 422", w.Code)
+			}
+			fields, _ := resp["fields"].([]interface{})
+			if len(fields) == 0 || fields[0].(map[string]interface{})["field"] != tt.field {
+				t.Errorf("fields = %v, want %s first", resp["fields"], tt.field)
+			}
+		})
+	}
+}
+
+func TestMalformedJSONStillReturns400(t *testing.T) {
+	router := gin.New()
+	router.POST("/", ProcessTransaction)
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"type":`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+}
+
+func TestErrorResponsesCarryCode(t *testing.T) {
+	metricas := func(extra map[string]interface{}) map[string]interface{} {
+		body := map[string]interface{}{
+			"inversion_inicial": 1000,
+			"flujos_ingresos":   []float64{500, 500, 500},
+			"tasa_descuento":    0.1,
+		}
+		for k, v := range extra {
+			body[k] = v
+		}
+		return body
+	}
+
+	tests := []struct {
+		name    string
+		handler gin.HandlerFunc
+		body    map[string]interface{}
+		status  int
+		code    string
+	}{
+		{"monto no positivo", ProcessTransaction, map[string]interface{}{"user_id": "u1", "type": "deposit", "amount": "0"}, http.StatusBadRequest, apierror.AmountNotPositive},
+		{"tipo inválido", ProcessTransaction, map[string]interface{}{"user_id": "u1", "type": "gift", "amount": "10"}, http.StatusBadRequest, apierror.InvalidTransactionType},
+		{"campo requerido", ProcessTransaction, map[string]interface{}{"type": "deposit", "amount": "10"}, http.StatusUnprocessableEntity, apierror.ValidationFailed},
+		{"moneda inválida", CalculateMetrics, metricas(map[string]interface{}{"currency": "XXX"}), http.StatusBadRequest, apierror.InvalidCurrency},
+		{"parámetro inválido", CalculateMetrics, metricas(map[string]interface{}{"frecuencia_capitalizacion": "mensual"}), http.StatusBadRequest, apierror.InvalidParameter},
+		{"principal no positivo", GenerateAmortization, map[string]interface{}{"principal": "0", "tasa_anual": "0.1", "plazo_meses": 12}, http.StatusBadRequest, apierror.InvalidParameter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, resp := doJSON(t, tt.handler, tt.body)
+			if w.Code != tt.status || resp["code"] != tt.code {
+				t.Errorf("status/code = %d/%v, want %d/%s (%v)", w.Code, resp["code"], tt.status, tt.code, resp["error"])
+			}
+		})
+	}
+}
+
+func TestErrorResponsesCarryCodeOnGet(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		handler gin.HandlerFunc
+		target  string
+		status  int
+		code    string
+	}{
+		{"id inválido", "/verify/:id", VerifyTransaction, "/verify/not-a-uuid", http.StatusBadRequest, apierror.InvalidTransactionID},
+		{"transacción inexistente", "/verify/:id", VerifyTransaction, "/verify/00000000-0000-4000-8000-000000000000", http.StatusNotFound, apierror.TransactionNotFound},
+		{"formato no soportado", "/export", ExportLedger, "/export?format=xml", http.StatusBadRequest, apierror.UnsupportedFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, resp := doGet(t, tt.pattern, tt.handler, tt.target)
+			if w.Code != tt.status || resp["code"] != tt.code {
+				t.Errorf("status/code = %d/%v, want %d/%s (%v)", w.Code, resp["code"], tt.status, tt.code, resp["error"])
+			}
+		})
+	}
+}
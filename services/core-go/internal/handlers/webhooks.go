@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fincore/core-go/internal/metrics"
+	"github.com/fincore/core-go/internal/security"
+	"github.com/google/uuid"
+)
+
+const (
+	// WebhookSignatureHeader lleva la firma HMAC-SHA256 en hex de "timestamp.body"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	// WebhookTimestampHeader lleva el Unix timestamp (segundos) incluido en la firma
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	// WebhookIDHeader identifica el evento para que el receptor descarte duplicados
+	WebhookIDHeader = "X-Webhook-ID"
+
+	// WebhookEventTransactionCompleted se emite al completar ProcessTransaction
+	WebhookEventTransactionCompleted = "transaction.completed"
+
+	// DefaultWebhookMaxAttempts es el número de intentos de entrega por defecto
+	DefaultWebhookMaxAttempts = 5
+	// DefaultWebhookBackoff es la espera antes del segundo intento; se duplica en cada reintento
+	DefaultWebhookBackoff = 500 * time.Millisecond
+
+	// webhookQueueSize limita los eventos pendientes; con la cola llena se descartan
+	webhookQueueSize = 1000
+	// webhookWorkers es el número de entregas simultáneas
+	webhookWorkers = 4
+	// webhookTimeout limita cada intento de entrega
+	webhookTimeout = 10 * time.Second
+)
+
+// WebhookEvent es el cuerpo JSON de un webhook
+type WebhookEvent struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// webhookDelivery es un evento ya serializado pendiente para un endpoint
+type webhookDelivery struct {
+	endpoint string
+	eventID  string
+	payload  []byte
+}
+
+// webhookStatusError indica que el endpoint respondió con un status no 2xx
+type webhookStatusError struct {
+	status int
+}
+
+func (e webhookStatusError) Error() string {
+	return fmt.Sprintf("webhook endpoint responded with status %d", e.status)
+}
+
+// WebhookDispatcher entrega webhooks firmados a los endpoints registrados en
+// segundo plano. Enqueue nunca bloquea: los eventos se encolan y un pool de
+// workers los envía con reintentos y backoff exponencial.
+type WebhookDispatcher struct {
+	endpoints   []string
+	signer      *security.SecurityManager
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+	now         func() time.Time
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan webhookDelivery
+	wg     sync.WaitGroup
+
+	// ctx se cancela cuando Close vence, abortando esperas y envíos en curso
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewWebhookDispatcher crea el dispatcher e inicia sus workers. Valores no
+// positivos de maxAttempts o backoff usan los valores por defecto.
+func NewWebhookDispatcher(signer *security.SecurityManager, endpoints []string, maxAttempts int, backoff time.Duration) *WebhookDispatcher {
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultWebhookMaxAttempts
+	}
+	if backoff <= 0 {
+		backoff = DefaultWebhookBackoff
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &WebhookDispatcher{
+		endpoints:   append([]string(nil), endpoints...),
+		signer:      signer,
+		client:      &http.Client{Timeout: webhookTimeout},
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+		now:         time.Now,
+		queue:       make(chan webhookDelivery, webhookQueueSize),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+	for i := 0; i < webhookWorkers; i++ {
+		d.wg.Add(1)
+		go d.work()
+	}
+	return d
+}
+
+// Enqueue encola el evento para cada endpoint sin esperar la entrega.
+// Retorna false si el dispatcher está cerrado o alguna entrega se descartó
+// por tener la cola llena.
+func (d *WebhookDispatcher) Enqueue(eventType string, data interface{}) bool {
+	event := WebhookEvent{
+		ID:        uuid.New().String(),
+		Type:      eventType,
+		CreatedAt: d.now().UTC(),
+		Data:      data,
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		slog.Error("failed to marshal webhook event", "event_type", eventType, "error", err)
+		return false
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return false
+	}
+
+	queued := true
+	for _, endpoint := range d.endpoints {
+		select {
+		case d.queue <- webhookDelivery{endpoint: endpoint, eventID: event.ID, payload: payload}:
+		default:
+			metrics.WebhookDeliveries.WithLabelValues(metrics.WebhookDropped).Inc()
+			slog.Warn("webhook queue full, dropping event", "event_id", event.ID, "endpoint", endpoint)
+			queued = false
+		}
+	}
+	return queued
+}
+
+// Close deja de aceptar eventos y espera a que se entreguen los pendientes.
+// Si ctx vence antes, cancela los envíos y reintentos en curso.
+func (d *WebhookDispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.queue)
+	}
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		d.cancel()
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// work entrega los eventos de la cola hasta que se cierra
+func (d *WebhookDispatcher) work() {
+	defer d.wg.Done()
+	for delivery := range d.queue {
+		d.deliver(delivery)
+	}
+}
+
+// deliver intenta la entrega hasta maxAttempts veces. Se reintentan los
+// errores de red, 429 y 5xx; el resto de respuestas no 2xx son definitivas.
+func (d *WebhookDispatcher) deliver(delivery webhookDelivery) {
+	var err error
+	wait := d.backoff
+attempts:
+	for attempt := 1; attempt <= d.maxAttempts; attempt++ {
+		if err = d.send(delivery); err == nil {
+			metrics.WebhookDeliveries.WithLabelValues(metrics.WebhookDelivered).Inc()
+			return
+		}
+		if !retryableWebhookError(err) || attempt == d.maxAttempts {
+			break
+		}
+
+		select {
+		case <-time.After(wait):
+			wait *= 2
+		case <-d.ctx.Done():
+			break attempts
+		}
+	}
+
+	metrics.WebhookDeliveries.WithLabelValues(metrics.WebhookFailed).Inc()
+	slog.Error("webhook delivery failed", "event_id", delivery.eventID, "endpoint", delivery.endpoint, "error", err)
+}
+
+// send firma y envía un intento. El timestamp se renueva en cada intento
+// para que el receptor pueda rechazar firmas antiguas.
+func (d *WebhookDispatcher) send(delivery webhookDelivery) error {
+	timestamp := strconv.FormatInt(d.now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, delivery.endpoint, bytes.NewReader(delivery.payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookIDHeader, delivery.eventID)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, d.signer.SignWebhook(timestamp, delivery.payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Consumir el cuerpo permite reutilizar la conexión
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return webhookStatusError{status: resp.StatusCode}
+	}
+	return nil
+}
+
+// retryableWebhookError indica si vale la pena reintentar la entrega
+func retryableWebhookError(err error) bool {
+	var statusErr webhookStatusError
+	if !errors.As(err, &statusErr) {
+		return true
+	}
+	return statusErr.status == http.StatusTooManyRequests || statusErr.status >= 500
+}
+
+// webhookDispatcher emite los eventos de transacciones; nil desactiva los webhooks
+var webhookDispatcher *WebhookDispatcher
+
+// SetWebhookDispatcher configura el dispatcher usado por los handlers
+func SetWebhookDispatcher(d *WebhookDispatcher) {
+	webhookDispatcher = d
+}
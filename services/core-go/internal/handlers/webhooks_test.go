@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fincore/core-go/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+// receivedWebhook es un request recibido por el endpoint de prueba
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+// newWebhookEndpoint levanta un endpoint que responde con statuses en orden
+// (el último se repite) y publica cada request recibido
+func newWebhookEndpoint(t *testing.T, statuses ...int) (*httptest.Server, <-chan receivedWebhook, *atomic.Int32) {
+	t.Helper()
+	received := make(chan receivedWebhook, 16)
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		body, _ := io.ReadAll(r.Body)
+		received <- receivedWebhook{header: r.Header.Clone(), body: body}
+		w.WriteHeader(statuses[min(n, len(statuses))-1])
+	}))
+	t.Cleanup(server.Close)
+	return server, received, &calls
+}
+
+// useWebhookDispatcher configura los handlers con un dispatcher hacia endpoint
+func useWebhookDispatcher(t *testing.T, endpoint string, maxAttempts int) *WebhookDispatcher {
+	t.Helper()
+	d := NewWebhookDispatcher(securityManager, []string{endpoint}, maxAttempts, time.Millisecond)
+	SetWebhookDispatcher(d)
+	t.Cleanup(func() {
+		SetWebhookDispatcher(nil)
+		d.Close(context.Background())
+	})
+	return d
+}
+
+func waitWebhook(t *testing.T, received <-chan receivedWebhook) receivedWebhook {
+	t.Helper()
+	select {
+	case hook := <-received:
+		return hook
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook not delivered")
+		return receivedWebhook{}
+	}
+}
+
+func TestProcessTransactionEmitsWebhook(t *testing.T) {
+	useMockTransactionStore(t)
+	server, received, _ := newWebhookEndpoint(t, http.StatusOK)
+	useWebhookDispatcher(t, server.URL, 1)
+
+	router := gin.New()
+	router.POST("/", ProcessTransaction)
+	id := transactionID(t, postTransaction(router, ""))
+
+	hook := waitWebhook(t, received)
+	var event struct {
+		ID   string      `json:"id"`
+		Type string      `json:"type"`
+		Data Transaction `json:"data"`
+	}
+	if err := json.Unmarshal(hook.body, &event); err != nil {
+		t.Fatalf("decode webhook %q: %v", hook.body, err)
+	}
+	if event.Type != WebhookEventTransactionCompleted || event.Data.ID != id || event.Data.Status != "completed" {
+		t.Errorf("event = %s/%s/%s, want %s/%s/completed", event.Type, event.Data.ID, event.Data.Status, WebhookEventTransactionCompleted, id)
+	}
+	if event.ID == "" || hook.header.Get(WebhookIDHeader) != event.ID {
+		t.Errorf("%s = %q, want event id %q", WebhookIDHeader, hook.header.Get(WebhookIDHeader), event.ID)
+	}
+}
+
+func TestWebhookSignature(t *testing.T) {
+	server, received, _ := newWebhookEndpoint(t, http.StatusNoContent)
+	d := useWebhookDispatcher(t, server.URL, 1)
+
+	if !d.Enqueue(WebhookEventTransactionCompleted, Transaction{ID: "tx-1"}) {
+		t.Fatal("Enqueue = false, want true")
+	}
+	hook := waitWebhook(t, received)
+
+	// El receptor verifica con SECRET_KEY sobre "timestamp.body"
+	secret := []byte(os.Getenv("SECRET_KEY"))
+	timestamp := hook.header.Get(WebhookTimestampHeader)
+	signature := hook.header.Get(WebhookSignatureHeader)
+	if !security.VerifyRequestSignature(secret, timestamp, hook.body, signature) {
+		t.Errorf("signature %q does not verify for timestamp %q", signature, timestamp)
+	}
+	if security.VerifyRequestSignature(secret, timestamp, append(hook.body, ' '), signature) {
+		t.Error("signature verified for a modified body")
+	}
+	if security.VerifyRequestSignature([]byte("other-secret"), timestamp, hook.body, signature) {
+		t.Error("signature verified with another secret")
+	}
+}
+
+func TestWebhookRetriesFailingEndpoint(t *testing.T) {
+	server, received, calls := newWebhookEndpoint(t, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusOK)
+	d := useWebhookDispatcher(t, server.URL, 5)
+
+	d.Enqueue(WebhookEventTransactionCompleted, Transaction{ID: "tx-1"})
+	var ids []string
+	for i := 0; i < 3; i++ {
+		ids = append(ids, waitWebhook(t, received).header.Get(WebhookIDHeader))
+	}
+	if err := d.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := calls.Load(); got != 3 {
+		t.Errorf("attempts = %d, want 3 (two failures then success)", got)
+	}
+	if ids[0] != ids[1] || ids[1] != ids[2] {
+		t.Errorf("retries changed the event id: %v", ids)
+	}
+}
+
+func TestWebhookRetryLimits(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		maxAttempts int
+		want        int32
+	}{
+		{"agota los intentos", http.StatusBadGateway, 3, 3},
+		{"429 se reintenta", http.StatusTooManyRequests, 2, 2},
+		{"4xx es definitivo", http.StatusBadRequest, 3, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _, calls := newWebhookEndpoint(t, tt.status)
+			d := NewWebhookDispatcher(securityManager, []string{server.URL}, tt.maxAttempts, time.Millisecond)
+			d.Enqueue(WebhookEventTransactionCompleted, Transaction{ID: "tx-1"})
+			if err := d.Close(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if got := calls.Load(); got != tt.want {
+				t.Errorf("attempts = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhookEnqueueAfterClose(t *testing.T) {
+	d := NewWebhookDispatcher(securityManager, []string{"http://127.0.0.1:0"}, 1, time.Millisecond)
+	if err := d.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if d.Enqueue(WebhookEventTransactionCompleted, Transaction{ID: "tx-1"}) {
+		t.Error("Enqueue after Close = true, want false")
+	}
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+
+	"github.com/fincore/core-go/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// DefaultBatchWorkers es el tamaño por defecto del pool de workers de lotes
+	DefaultBatchWorkers = 32
+	// DefaultMaxBatchSize es el número máximo de transacciones por lote por defecto
+	DefaultMaxBatchSize = 1000
+)
+
+// Configuración de lotes; se fija una vez al iniciar el servicio
+var (
+	batchWorkers = DefaultBatchWorkers
+	maxBatchSize = DefaultMaxBatchSize
+)
+
+// SetMaxBatchSize configura el tamaño máximo de lote; valores no positivos
+// restauran el valor por defecto
+func SetMaxBatchSize(n int) {
+	if n <= 0 {
+		n = DefaultMaxBatchSize
+	}
+	maxBatchSize = n
+}
+
+// SetBatchWorkers configura el tamaño del pool de workers; valores no
+// positivos restauran el valor por defecto
+func SetBatchWorkers(n int) {
+	if n <= 0 {
+		n = DefaultBatchWorkers
+	}
+	batchWorkers = n
+}
+
+// runWorkerPool ejecuta fn para cada índice en [0, total) usando como máximo
+// batchWorkers goroutines. Cada índice se procesa a lo sumo una vez, por lo
+// que fn puede escribir en su posición de un slice sin sincronización extra.
+// Cada worker registra un span hijo del span presente en ctx. Si ctx se
+// cancela deja de repartir índices y retorna ctx.Err() cuando terminan los
+// que estaban en curso; los índices restantes no se procesan.
+func runWorkerPool(ctx context.Context, total int, fn func(index int)) error {
+	workers := batchWorkers
+	if total < workers {
+		workers = total
+	}
+
+	jobs := make(chan int)
+	go func() {
+		defer close(jobs)
+		for i := 0; i < total; i++ {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	runWorkers(ctx, workers, jobs, fn)
+	return ctx.Err()
+}
+
+// runWorkers consume jobs con workers goroutines hasta que el canal se cierra
+// y espera a que terminen. Permite alimentar el pool a medida que llegan los
+// trabajos, sin conocer el total de antemano. Una vez cancelado ctx los
+// trabajos pendientes se descartan sin llamar a fn.
+func runWorkers[T any](ctx context.Context, workers int, jobs <-chan T, fn func(job T)) {
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			_, span := tracing.Start(ctx, "batch.worker", attribute.Int("worker", worker))
+			defer span.End()
+
+			processed, skipped := 0, 0
+			for job := range jobs {
+				if ctx.Err() != nil {
+					skipped++
+					continue
+				}
+				fn(job)
+				processed++
+			}
+			span.SetAttributes(attribute.Int("items_processed", processed), attribute.Int("items_skipped", skipped))
+		}(w)
+	}
+	wg.Wait()
+}
+
+// batchTracker cuenta los lotes en curso para que el apagado espere a que
+// terminen; una vez iniciado el drenado rechaza lotes nuevos
+type batchTracker struct {
+	mu       sync.Mutex
+	wg       sync.WaitGroup
+	active   int
+	draining bool
+}
+
+var batchJobs batchTracker
+
+// begin registra un lote en curso; retorna false si el servicio se está apagando
+func (t *batchTracker) begin() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.draining {
+		return false
+	}
+	t.active++
+	t.wg.Add(1)
+	return true
+}
+
+// end marca el lote como terminado
+func (t *batchTracker) end() {
+	t.mu.Lock()
+	t.active--
+	t.mu.Unlock()
+	t.wg.Done()
+}
+
+// drain rechaza lotes nuevos y espera a los que estaban en curso
+func (t *batchTracker) drain(ctx context.Context) (int, error) {
+	t.mu.Lock()
+	t.draining = true
+	inFlight := t.active
+	t.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return inFlight, nil
+	case <-ctx.Done():
+		return inFlight, ctx.Err()
+	}
+}
+
+// DrainBatches deja de aceptar lotes (responden 503) y espera hasta que
+// terminen los que están en curso o expire ctx. Retorna cuántos lotes había
+// en curso al iniciar el drenado.
+func DrainBatches(ctx context.Context) (int, error) {
+	return batchJobs.drain(ctx)
+}
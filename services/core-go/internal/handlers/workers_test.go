@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRunWorkerPoolBoundsConcurrency(t *testing.T) {
+	SetBatchWorkers(4)
+	defer SetBatchWorkers(0)
+
+	var active, maxActive atomic.Int32
+	processed := make([]int, 100)
+	runWorkerPool(context.Background(), len(processed), func(index int) {
+		current := active.Add(1)
+		for {
+			prev := maxActive.Load()
+			if current <= prev || maxActive.CompareAndSwap(prev, current) {
+				break
+			}
+		}
+		processed[index]++
+		active.Add(-1)
+	})
+
+	if maxActive.Load() > 4 {
+		t.Errorf("max concurrent workers = %d, want <= 4", maxActive.Load())
+	}
+	for i, n := range processed {
+		if n != 1 {
+			t.Fatalf("index %d processed %d times, want 1", i, n)
+		}
+	}
+}
+
+func TestRunWorkerPoolStopsWhenCanceled(t *testing.T) {
+	SetBatchWorkers(2)
+	defer SetBatchWorkers(0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var processed atomic.Int32
+	err := runWorkerPool(ctx, 1000, func(index int) {
+		if processed.Add(1) == 10 {
+			cancel()
+		}
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	// Sólo pueden terminar los elementos que ya estaban en curso al cancelar
+	if n := processed.Load(); n > 10+2 {
+		t.Errorf("processed %d items after cancellation, want at most 12", n)
+	}
+}
+
+func TestBatchProcessClientClosedRequest(t *testing.T) {
+	transactions := make([]map[string]interface{}, 50)
+	for i := range transactions {
+		transactions[i] = map[string]interface{}{"type": "deposit", "user_id": "user", "amount": "10"}
+	}
+	payload, _ := json.Marshal(map[string]interface{}{"transactions": transactions})
+
+	router := gin.New()
+	router.POST("/", BatchProcess)
+
+	// El cliente se desconectó antes de que el pool empezara
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(payload)).WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != StatusClientClosedRequest {
+		t.Fatalf("status = %d, want 499", w.Code)
+	}
+	if strings.Contains(w.Body.String(), "transactions") {
+		t.Errorf("canceled batch returned results: %s", w.Body.String())
+	}
+}
+
+func TestBatchProcessPreservesOrder(t *testing.T) {
+	SetBatchWorkers(8)
+	defer SetBatchWorkers(0)
+
+	transactions := make([]map[string]interface{}, 200)
+	for i := range transactions {
+		transactions[i] = map[string]interface{}{
+			"type":    "deposit",
+			"user_id": fmt.Sprintf("user-%d", i),
+			"amount":  "10",
+		}
+	}
+
+	w, resp := doJSON(t, BatchProcess, map[string]interface{}{"transactions": transactions})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+
+	results := resp["transactions"].([]interface{})
+	for i, result := range results {
+		if got := result.(map[string]interface{})["user_id"]; got != fmt.Sprintf("user-%d", i) {
+			t.Fatalf("results[%d].user_id = %v, want user-%d", i, got, i)
+		}
+	}
+}
+
+// benchmarkBatch envía un lote de 10k transacciones con el número de workers dado
+func benchmarkBatch(b *testing.B, workers int) {
+	SetBatchWorkers(workers)
+	defer SetBatchWorkers(0)
+
+	transactions := make([]batchTransaction, 10000)
+	for i := range transactions {
+		transactions[i] = batchTransaction{Type: "deposit", UserID: "user"}
+	}
+	payload, _ := json.Marshal(map[string]interface{}{"transactions": transactions})
+
+	router := gin.New()
+	router.POST("/", BatchProcess)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+}
+
+// Con un worker por transacción se aproxima el fan-out sin límite anterior
+func BenchmarkBatchProcessUnbounded(b *testing.B) { benchmarkBatch(b, 10000) }
+
+func BenchmarkBatchProcessPool(b *testing.B) { benchmarkBatch(b, DefaultBatchWorkers) }
+
+func TestBatchProcessMaxBatchSize(t *testing.T) {
+	SetMaxBatchSize(5)
+	defer SetMaxBatchSize(0)
+
+	batch := func(n int) map[string]interface{} {
+		transactions := make([]map[string]interface{}, n)
+		for i := range transactions {
+			transactions[i] = map[string]interface{}{"type": "deposit", "user_id": "user", "amount": "1"}
+		}
+		return map[string]interface{}{"transactions": transactions}
+	}
+
+	if w, _ := doJSON(t, BatchProcess, batch(5)); w.Code != http.StatusOK {
+		t.Errorf("batch at the limit: status = %d, want 200", w.Code)
+	}
+	if w, _ := doJSON(t, BatchProcess, batch(6)); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("batch over the limit: status = %d, want 413", w.Code)
+	}
+}
+
+func TestBatchProcessPerItemErrors(t *testing.T) {
+	w, resp := doJSON(t, BatchProcess, map[string]interface{}{
+		"transactions": []map[string]interface{}{
+			{"type": "deposit", "user_id": "user-1", "amount": "10"},
+			{"type": "deposit", "user_id": "user-2", "amount": "-5"},
+			{"type": "", "user_id": "user-3", "amount": "10"},
+			{"type": "deposit", "user_id": "", "amount": "10"},
+			{"type": "deposit", "user_id": "user-5", "amount": "10", "currency": "ZZZ"},
+			{"type": "deposit", "user_id": "user-6", "amount": "10", "currency": "USD"},
+		},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+
+	results := resp["transactions"].([]interface{})
+	wantStatus := []string{"completed", "rejected", "rejected", "rejected", "rejected", "completed"}
+	for i, want := range wantStatus {
+		tx := results[i].(map[string]interface{})
+		if tx["status"] != want {
+			t.Errorf("transactions[%d].status = %v, want %s", i, tx["status"], want)
+		}
+		if want == "rejected" && tx["rejection_reason"] == nil {
+			t.Errorf("transactions[%d] has no rejection_reason", i)
+		}
+	}
+
+	itemErrors := resp["errors"].([]interface{})
+	if len(itemErrors) != 4 {
+		t.Fatalf("len(errors) = %d, want 4", len(itemErrors))
+	}
+	if index := itemErrors[0].(map[string]interface{})["index"]; index != float64(1) {
+		t.Errorf("errors[0].index = %v, want 1", index)
+	}
+	if resp["total_processed"] != float64(2) || resp["total_rejected"] != float64(4) {
+		t.Errorf("totals = %v/%v, want 2/4", resp["total_processed"], resp["total_rejected"])
+	}
+}
+
+func TestDrainBatchesWaitsForInFlightBatch(t *testing.T) {
+	defer func() {
+		batchJobs.mu.Lock()
+		batchJobs.draining = false
+		batchJobs.mu.Unlock()
+	}()
+
+	router := gin.New()
+	router.POST("/", BatchProcess)
+
+	// El body llega por un pipe para mantener el lote en curso mientras
+	// se inicia el apagado
+	bodyReader, bodyWriter := io.Pipe()
+	inFlight := httptest.NewRecorder()
+	finished := make(chan struct{})
+	go func() {
+		req := httptest.NewRequest(http.MethodPost, "/", bodyReader)
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(inFlight, req)
+		close(finished)
+	}()
+
+	for deadline := time.Now().Add(time.Second); ; {
+		batchJobs.mu.Lock()
+		active := batchJobs.active
+		batchJobs.mu.Unlock()
+		if active == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("batch never started")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	type drainResult struct {
+		count int
+		err   error
+	}
+	drained := make(chan drainResult, 1)
+	go func() {
+		count, err := DrainBatches(context.Background())
+		drained <- drainResult{count, err}
+	}()
+
+	// Mientras drena, los lotes nuevos se rechazan con 503
+	for deadline := time.Now().Add(time.Second); ; {
+		w, _ := doJSON(t, BatchProcess, map[string]interface{}{"transactions": []interface{}{}})
+		if w.Code == http.StatusServiceUnavailable {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("new batch during drain: status = %d, want 503", w.Code)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case <-drained:
+		t.Fatal("drain returned before the in-flight batch finished")
+	default:
+	}
+
+	fmt.Fprint(bodyWriter, `{"transactions":[{"type":"deposit","user_id":"user","amount":"10"}]}`)
+	bodyWriter.Close()
+	<-finished
+
+	if inFlight.Code != http.StatusOK {
+		t.Errorf("in-flight batch status = %d, want 200", inFlight.Code)
+	}
+	result := <-drained
+	if result.err != nil || result.count != 1 {
+		t.Errorf("DrainBatches = %d, %v, want 1, nil", result.count, result.err)
+	}
+}
+
+func TestDrainBatchesTimeout(t *testing.T) {
+	defer func() {
+		batchJobs.mu.Lock()
+		batchJobs.draining = false
+		batchJobs.mu.Unlock()
+	}()
+
+	if !batchJobs.begin() {
+		t.Fatal("begin must succeed before draining")
+	}
+	defer batchJobs.end()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := DrainBatches(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/fincore/core-go/internal/apierror"
+	"github.com/fincore/core-go/internal/finance"
+	"github.com/gin-gonic/gin"
+)
+
+// diasPorAnio es la base Act/365 con la que se anualiza la XIRR
+const diasPorAnio = 365.0
+
+// FlujoFechado es un flujo de efectivo con su fecha (AAAA-MM-DD)
+type FlujoFechado struct {
+	Fecha string  `json:"fecha"`
+	Monto float64 `json:"monto"`
+}
+
+// CalculateXIRR calcula la TIR anualizada de flujos con fechas irregulares
+func CalculateXIRR(c *gin.Context) {
+	var req struct {
+		Flujos []FlujoFechado `json:"flujos" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidRequest, "Invalid request")
+		return
+	}
+	if len(req.Flujos) < 2 {
+		apierror.Respond(c, http.StatusBadRequest, apierror.InvalidParameter, "flujos must contain at least two dated cash flows")
+		return
+	}
+
+	fechas := make([]time.Time, len(req.Flujos))
+	montos := make([]float64, len(req.Flujos))
+	for i, flujo := range req.Flujos {
+		fecha, err := time.Parse(time.DateOnly, flujo.Fecha)
+		if err != nil {
+			apierror.RespondWith(c, http.StatusBadRequest, apierror.InvalidParameter, "Invalid fecha, expected YYYY-MM-DD", gin.H{
+				"index": i,
+			})
+			return
+		}
+		fechas[i] = fecha
+		montos[i] = flujo.Monto
+	}
+
+	xirr, tolerancia, err := calcularXIRR(fechas, montos)
+	if err != nil {
+		respondInvalidParameter(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"xirr":       xirr,
+		"tolerancia": tolerancia,
+	})
+}
+
+// calcularXNPV descuenta cada flujo por los años Act/365 transcurridos desde
+// la fecha más antigua
+func calcularXNPV(tasa float64, anios, montos []float64) float64 {
+	xnpv := 0.0
+	for i, monto := range montos {
+		xnpv += monto / math.Pow(1+tasa, anios[i])
+	}
+	return xnpv
+}
+
+// calcularXIRR obtiene la tasa donde el XNPV es cero. Igual que finance.IRR,
+// primero acota un intervalo con cambio de signo y luego biseca, lo que
+// siempre converge, a diferencia de Newton-Raphson.
+func calcularXIRR(fechas []time.Time, montos []float64) (float64, float64, error) {
+	positivo, negativo := false, false
+	for _, monto := range montos {
+		positivo = positivo || monto > 0
+		negativo = negativo || monto < 0
+	}
+	if !positivo || !negativo {
+		return 0, 0, errors.New("cash flows must include both positive and negative amounts, XIRR is undefined")
+	}
+
+	inicio := fechas[0]
+	for _, fecha := range fechas[1:] {
+		if fecha.Before(inicio) {
+			inicio = fecha
+		}
+	}
+	anios := make([]float64, len(fechas))
+	for i, fecha := range fechas {
+		anios[i] = fecha.Sub(inicio).Hours() / 24 / diasPorAnio
+	}
+
+	xnpv := func(tasa float64) float64 { return calcularXNPV(tasa, anios, montos) }
+
+	bajo, alto := finance.IRRMinRate, 1.0
+	xnpvBajo, xnpvAlto := xnpv(bajo), xnpv(alto)
+	for xnpvBajo*xnpvAlto > 0 {
+		if alto >= finance.IRRMaxRate {
+			return 0, 0, errors.New("could not bracket XIRR within the search range")
+		}
+		alto *= 2
+		xnpvAlto = xnpv(alto)
+	}
+
+	medio, tolerancia := finance.Bisect(xnpv, bajo, alto, xnpvBajo)
+	return medio, tolerancia, nil
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"testing"
+)
+
+// flujosXIRRReferencia es el ejemplo de la documentación de XIRR de las hojas
+// de cálculo, cuyo resultado es 0.373362535
+var flujosXIRRReferencia = []map[string]interface{}{
+	{"fecha": "2008-01-01", "monto": -10000},
+	{"fecha": "2008-03-01", "monto": 2750},
+	{"fecha": "2008-10-30", "monto": 4250},
+	{"fecha": "2009-02-15", "monto": 3250},
+	{"fecha": "2009-04-01", "monto": 2750},
+}
+
+func TestCalculateXIRRMatchesSpreadsheet(t *testing.T) {
+	w, resp := doJSON(t, CalculateXIRR, map[string]interface{}{"flujos": flujosXIRRReferencia})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%v)", w.Code, resp)
+	}
+	if xirr := resp["xirr"].(float64); math.Abs(xirr-0.373362535) > 1e-6 {
+		t.Errorf("xirr = %v, want ≈ 0.373362535", xirr)
+	}
+}
+
+func TestCalculateXIRRUnorderedDates(t *testing.T) {
+	desordenados := []map[string]interface{}{
+		flujosXIRRReferencia[3], flujosXIRRReferencia[0], flujosXIRRReferencia[4],
+		flujosXIRRReferencia[1], flujosXIRRReferencia[2],
+	}
+	_, resp := doJSON(t, CalculateXIRR, map[string]interface{}{"flujos": desordenados})
+	if xirr, _ := resp["xirr"].(float64); math.Abs(xirr-0.373362535) > 1e-6 {
+		t.Errorf("xirr = %v, want ≈ 0.373362535 regardless of order", resp["xirr"])
+	}
+}
+
+func TestCalculateXIRRSameSign(t *testing.T) {
+	w, resp := doJSON(t, CalculateXIRR, map[string]interface{}{
+		"flujos": []map[string]interface{}{
+			{"fecha": "2024-01-01", "monto": 100},
+			{"fecha": "2024-06-01", "monto": 200},
+		},
+	})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", w.Code)
+	}
+	if resp["error"] == nil {
+		t.Error("expected an error message")
+	}
+}
+
+func TestCalculateXIRRInvalidInput(t *testing.T) {
+	cases := []map[string]interface{}{
+		{"flujos": []map[string]interface{}{{"fecha": "2024-01-01", "monto": -100}}},
+		{"flujos": []map[string]interface{}{{"fecha": "01/02/2024", "monto": -100}, {"fecha": "2024-06-01", "monto": 200}}},
+	}
+	for _, body := range cases {
+		if w, _ := doJSON(t, CalculateXIRR, body); w.Code != http.StatusBadRequest {
+			t.Errorf("%v: status = %d, want 400", body, w.Code)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sony/gobreaker"
+)
+
+// ErrCircuitOpen indica que el circuit breaker del store está abierto y la
+// operación se rechazó sin llegar a la base de datos
+var ErrCircuitOpen = errors.New("ledger store circuit open")
+
+const (
+	// DefaultBreakerFailures es el número de fallos consecutivos que abre el circuito
+	DefaultBreakerFailures = 5
+	// DefaultBreakerTimeout es cuánto permanece abierto antes de pasar a half-open
+	DefaultBreakerTimeout = 30 * time.Second
+)
+
+// CircuitStater es implementado por los stores protegidos por un circuit
+// breaker para informar su estado
+type CircuitStater interface {
+	CircuitState() string
+}
+
+// BreakerStore envuelve un LedgerStore con un circuit breaker. Tras
+// maxFailures fallos consecutivos las operaciones fallan de inmediato con
+// ErrCircuitOpen durante timeout, en lugar de acumular goroutines esperando a
+// una base de datos que no responde. Después deja pasar una operación de
+// prueba (half-open) que cierra el circuito si tiene éxito.
+type BreakerStore struct {
+	store   LedgerStore
+	breaker *gobreaker.CircuitBreaker
+}
+
+// NewBreakerStore crea el store protegido; valores no positivos usan
+// DefaultBreakerFailures y DefaultBreakerTimeout
+func NewBreakerStore(store LedgerStore, maxFailures int, timeout time.Duration) *BreakerStore {
+	if maxFailures <= 0 {
+		maxFailures = DefaultBreakerFailures
+	}
+	if timeout <= 0 {
+		timeout = DefaultBreakerTimeout
+	}
+	return &BreakerStore{
+		store: store,
+		breaker: gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "ledger",
+			Timeout: timeout,
+			ReadyToTrip: func(counts gobreaker.Counts) bool {
+				return counts.ConsecutiveFailures >= uint32(maxFailures)
+			},
+			IsSuccessful: isBreakerSuccess,
+		}),
+	}
+}
+
+// isBreakerSuccess cuenta como éxito los errores que no indican un problema
+// de la base de datos: entradas inexistentes, duplicados, un store en memoria
+// lleno o requests cancelados por el cliente
+func isBreakerSuccess(err error) bool {
+	return err == nil ||
+		errors.Is(err, ErrEntryNotFound) ||
+		errors.Is(err, ErrDuplicateClientRef) ||
+		errors.Is(err, ErrLedgerFull) ||
+		errors.Is(err, context.Canceled)
+}
+
+// execute ejecuta fn a través del breaker
+func (s *BreakerStore) execute(fn func() (interface{}, error)) (interface{}, error) {
+	result, err := s.breaker.Execute(fn)
+	if errors.Is(err, gobreaker.ErrOpenState) || errors.Is(err, gobreaker.ErrTooManyRequests) {
+		return nil, ErrCircuitOpen
+	}
+	return result, err
+}
+
+// Append persiste la entrada a través del breaker
+func (s *BreakerStore) Append(ctx context.Context, entry LedgerEntry) error {
+	_, err := s.execute(func() (interface{}, error) {
+		return nil, s.store.Append(ctx, entry)
+	})
+	return err
+}
+
+// Get lee la entrada a través del breaker
+func (s *BreakerStore) Get(ctx context.Context, sequence int64) (LedgerEntry, error) {
+	result, err := s.execute(func() (interface{}, error) {
+		return s.store.Get(ctx, sequence)
+	})
+	if err != nil {
+		return LedgerEntry{}, err
+	}
+	return result.(LedgerEntry), nil
+}
+
+// GetByClientRef lee la entrada a través del breaker
+func (s *BreakerStore) GetByClientRef(ctx context.Context, clientRef string) (LedgerEntry, error) {
+	result, err := s.execute(func() (interface{}, error) {
+		return s.store.GetByClientRef(ctx, clientRef)
+	})
+	if err != nil {
+		return LedgerEntry{}, err
+	}
+	return result.(LedgerEntry), nil
+}
+
+// List lee todas las entradas a través del breaker
+func (s *BreakerStore) List(ctx context.Context) ([]LedgerEntry, error) {
+	result, err := s.execute(func() (interface{}, error) {
+		return s.store.List(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]LedgerEntry), nil
+}
+
+// StreamRange recorre el rango a través del breaker. Los errores de fn (por
+// ejemplo, un cliente que dejó de leer la exportación) no cuentan como fallos
+// de la base de datos. Si el store no implementa RangeStreamer filtra List.
+func (s *BreakerStore) StreamRange(ctx context.Context, from, to int64, fn func(LedgerEntry) error) error {
+	var fnErr error
+	callback := func(entry LedgerEntry) error {
+		fnErr = fn(entry)
+		return fnErr
+	}
+
+	_, err := s.execute(func() (interface{}, error) {
+		err := streamRange(ctx, s.store, from, to, callback)
+		if fnErr != nil {
+			return nil, nil
+		}
+		return nil, err
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	return err
+}
+
+// StreamCreatedBetween recorre las entradas del rango de fechas a través del
+// breaker; como en StreamRange, los errores de fn no cuentan como fallos
+func (s *BreakerStore) StreamCreatedBetween(ctx context.Context, from, to time.Time, fn func(LedgerEntry) error) error {
+	var fnErr error
+	callback := func(entry LedgerEntry) error {
+		fnErr = fn(entry)
+		return fnErr
+	}
+
+	_, err := s.execute(func() (interface{}, error) {
+		err := streamCreatedBetween(ctx, s.store, from, to, callback)
+		if fnErr != nil {
+			return nil, nil
+		}
+		return nil, err
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	return err
+}
+
+// Last lee la última entrada a través del breaker
+func (s *BreakerStore) Last(ctx context.Context) (LedgerEntry, bool, error) {
+	type lastResult struct {
+		entry LedgerEntry
+		ok    bool
+	}
+	result, err := s.execute(func() (interface{}, error) {
+		entry, ok, err := s.store.Last(ctx)
+		return lastResult{entry, ok}, err
+	})
+	if err != nil {
+		return LedgerEntry{}, false, err
+	}
+	last := result.(lastResult)
+	return last.entry, last.ok, nil
+}
+
+// Ping consulta el store directamente, sin pasar por el breaker, para que el
+// readiness refleje el estado real de la base de datos
+func (s *BreakerStore) Ping(ctx context.Context) error {
+	if pinger, ok := s.store.(Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// CircuitState retorna "closed", "half-open" u "open"
+func (s *BreakerStore) CircuitState() string {
+	return s.breaker.State().String()
+}
@@ -0,0 +1,112 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBreakerStoreOpensAfterConsecutiveFailures(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockLedgerStore()
+	store := NewBreakerStore(mock, 3, time.Minute)
+
+	mock.Err = errors.New("connection refused")
+	for i := 0; i < 3; i++ {
+		if err := store.Append(ctx, LedgerEntry{SequenceNumber: 1}); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("failure %d: err = %v, want store error", i+1, err)
+		}
+	}
+	if state := store.CircuitState(); state != "open" {
+		t.Fatalf("state = %q after 3 failures, want open", state)
+	}
+
+	// Con el circuito abierto no se consulta el store aunque ya funcione
+	mock.Err = nil
+	if err := store.Append(ctx, LedgerEntry{SequenceNumber: 1}); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("err = %v, want ErrCircuitOpen", err)
+	}
+	if _, err := store.List(ctx); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("List err = %v, want ErrCircuitOpen", err)
+	}
+	if entries, _ := mock.List(ctx); len(entries) != 0 {
+		t.Errorf("store received %d entries while open", len(entries))
+	}
+}
+
+func TestBreakerStoreHalfOpensAfterTimeout(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockLedgerStore()
+	store := NewBreakerStore(mock, 1, 50*time.Millisecond)
+
+	mock.Err = errors.New("timeout")
+	_ = store.Append(ctx, LedgerEntry{SequenceNumber: 1})
+	if state := store.CircuitState(); state != "open" {
+		t.Fatalf("state = %q, want open", state)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if state := store.CircuitState(); state != "half-open" {
+		t.Fatalf("state = %q after timeout, want half-open", state)
+	}
+
+	// La operación de prueba exitosa cierra el circuito
+	mock.Err = nil
+	if err := store.Append(ctx, LedgerEntry{SequenceNumber: 1}); err != nil {
+		t.Fatalf("probe append: %v", err)
+	}
+	if state := store.CircuitState(); state != "closed" {
+		t.Errorf("state = %q after successful probe, want closed", state)
+	}
+}
+
+func TestBreakerStoreIgnoresNotFound(t *testing.T) {
+	store := NewBreakerStore(NewMockLedgerStore(), 1, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		if _, err := store.Get(context.Background(), 42); !errors.Is(err, ErrEntryNotFound) {
+			t.Fatalf("err = %v, want ErrEntryNotFound", err)
+		}
+	}
+	if state := store.CircuitState(); state != "closed" {
+		t.Errorf("state = %q, want closed: not found is not a store failure", state)
+	}
+}
+
+func TestBreakerStoreStreamRangeCallbackErrors(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockLedgerStore()
+	for seq := int64(1); seq <= 3; seq++ {
+		_ = mock.Append(ctx, LedgerEntry{SequenceNumber: seq})
+	}
+	store := NewBreakerStore(mock, 1, time.Minute)
+
+	// Un consumidor que falla (cliente desconectado) no abre el circuito
+	errStop := errors.New("client went away")
+	for i := 0; i < 3; i++ {
+		if err := store.StreamRange(ctx, 1, 3, func(LedgerEntry) error { return errStop }); !errors.Is(err, errStop) {
+			t.Fatalf("err = %v, want the callback error", err)
+		}
+	}
+	if state := store.CircuitState(); state != "closed" {
+		t.Fatalf("state = %q, want closed: callback errors are not store failures", state)
+	}
+
+	var got []int64
+	if err := store.StreamRange(ctx, 2, 3, func(entry LedgerEntry) error {
+		got = append(got, entry.SequenceNumber)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != 2 || got[1] != 3 {
+		t.Errorf("streamed sequences = %v, want [2 3]", got)
+	}
+
+	mock.Err = errors.New("connection refused")
+	_ = store.StreamRange(ctx, 1, 3, func(LedgerEntry) error { return nil })
+	if state := store.CircuitState(); state != "open" {
+		t.Errorf("state = %q, want open after a store failure", state)
+	}
+}
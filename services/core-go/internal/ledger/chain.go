@@ -0,0 +1,322 @@
+package ledger
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fincore/core-go/internal/canonical"
+	"github.com/shopspring/decimal"
+)
+
+// GenesisHash es el PreviousHash de la primera entrada del ledger
+var GenesisHash = strings.Repeat("0", 64)
+
+// LedgerEntry representa una entrada en el ledger inmutable
+type LedgerEntry struct {
+	SequenceNumber int64           `json:"sequence_number"`
+	PreviousHash   string          `json:"previous_hash"`
+	EntryHash      string          `json:"entry_hash"`
+	EntryType      string          `json:"entry_type"`
+	Amount         decimal.Decimal `json:"amount"`
+	Currency       string          `json:"currency"`
+	Description    string          `json:"description"`
+	CreatedAt      time.Time       `json:"created_at"`
+	IsVerified     bool            `json:"is_verified"`
+
+	// ClientRef es la referencia opcional con la que el cliente deduplica
+	// reintentos. No forma parte del hash para no alterar la cadena existente.
+	ClientRef string `json:"client_ref,omitempty"`
+}
+
+// ComputeEntryHash calcula el hash SHA-256 del JSON canónico de los campos de
+// la entrada encadenado con el hash de la entrada anterior. Son los mismos
+// campos y la misma serialización que firma una cadena con EntrySigner.
+func ComputeEntryHash(entry LedgerEntry) string {
+	// entryHashData sólo contiene strings y enteros, que siempre se serializan
+	data, _ := canonical.Marshal(entryHashData(entry))
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// EntrySigner calcula un HMAC con clave sobre los datos canónicos de una
+// entrada. security.SecurityManager lo implementa.
+type EntrySigner interface {
+	CalculateIntegrityHMAC(data map[string]interface{}) string
+}
+
+// entryHashData retorna los campos encadenados de la entrada para el HMAC
+func entryHashData(entry LedgerEntry) map[string]interface{} {
+	return map[string]interface{}{
+		"sequence_number": entry.SequenceNumber,
+		"entry_type":      entry.EntryType,
+		"amount":          entry.Amount.String(),
+		"currency":        entry.Currency,
+		"description":     entry.Description,
+		"created_at":      entry.CreatedAt.UTC().Format(time.RFC3339Nano),
+		"previous_hash":   entry.PreviousHash,
+	}
+}
+
+// LedgerChain mantiene el último hash del ledger y encadena nuevas entradas
+// sobre un LedgerStore. Es seguro para uso concurrente: la secuencia y el
+// encadenamiento se asignan bajo el mismo lock para que el orden de la cadena
+// coincida con la secuencia.
+type LedgerChain struct {
+	mu       sync.Mutex
+	store    LedgerStore
+	seq      *LedgerSequencer
+	lastHash string
+	signer   EntrySigner
+}
+
+// NewLedgerChain crea una cadena sobre el store, continuando a partir de la
+// última entrada persistida (o del hash génesis si está vacío). Las entradas
+// se encadenan con ComputeEntryHash, sin clave.
+func NewLedgerChain(ctx context.Context, store LedgerStore) (*LedgerChain, error) {
+	return NewSignedLedgerChain(ctx, store, nil)
+}
+
+// NewSignedLedgerChain crea una cadena cuyo EntryHash es un HMAC calculado por
+// signer, de modo que quien modifique el store no pueda recalcular la cadena
+// sin la clave. Un ledger debe usar el mismo modo desde su génesis: las
+// entradas escritas sin clave no verifican en una cadena con signer.
+func NewSignedLedgerChain(ctx context.Context, store LedgerStore, signer EntrySigner) (*LedgerChain, error) {
+	last, ok, err := store.Last(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load last ledger entry: %w", err)
+	}
+
+	chain := &LedgerChain{
+		store:    store,
+		seq:      NewLedgerSequencer(0),
+		lastHash: GenesisHash,
+		signer:   signer,
+	}
+	if ok {
+		chain.seq = NewLedgerSequencer(last.SequenceNumber)
+		chain.lastHash = last.EntryHash
+	}
+	return chain, nil
+}
+
+// Append asigna secuencia, PreviousHash y EntryHash a la entrada y la persiste.
+// Si el store falla, la secuencia y el último hash no avanzan.
+func (c *LedgerChain) Append(ctx context.Context, entry LedgerEntry) (LedgerEntry, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Las bases de datos guardan microsegundos; se normaliza antes de calcular
+	// el hash para que la entrada leída de vuelta produzca el mismo valor
+	entry.CreatedAt = entry.CreatedAt.UTC().Truncate(time.Microsecond)
+	entry.SequenceNumber = c.seq.Current() + 1
+	entry.PreviousHash = c.lastHash
+	entry.EntryHash = c.hashEntry(entry)
+
+	if err := c.store.Append(ctx, entry); err != nil {
+		return LedgerEntry{}, err
+	}
+
+	c.seq.Next()
+	c.lastHash = entry.EntryHash
+	return entry, nil
+}
+
+// AppendOnce agrega la entrada salvo que ya exista una con el mismo
+// ClientRef, en cuyo caso retorna la existente y created es false. Las
+// entradas sin ClientRef siempre se agregan.
+func (c *LedgerChain) AppendOnce(ctx context.Context, entry LedgerEntry) (stored LedgerEntry, created bool, err error) {
+	if entry.ClientRef == "" {
+		stored, err = c.Append(ctx, entry)
+		return stored, err == nil, err
+	}
+
+	// El lock de Append no cubre la búsqueda, así que dos requests con la
+	// misma referencia pueden llegar a Append; la restricción única del store
+	// rechaza el segundo y se retorna la entrada ganadora
+	existing, err := c.store.GetByClientRef(ctx, entry.ClientRef)
+	if err == nil {
+		return existing, false, nil
+	}
+	if !errors.Is(err, ErrEntryNotFound) {
+		return LedgerEntry{}, false, err
+	}
+
+	stored, err = c.Append(ctx, entry)
+	if errors.Is(err, ErrDuplicateClientRef) {
+		existing, err = c.store.GetByClientRef(ctx, entry.ClientRef)
+		return existing, false, err
+	}
+	if err != nil {
+		return LedgerEntry{}, false, err
+	}
+	return stored, true, nil
+}
+
+// hashEntry calcula el EntryHash según el modo de la cadena
+func (c *LedgerChain) hashEntry(entry LedgerEntry) string {
+	if c.signer == nil {
+		return ComputeEntryHash(entry)
+	}
+	return c.signer.CalculateIntegrityHMAC(entryHashData(entry))
+}
+
+// LastHash retorna el hash de la última entrada (o el génesis si está vacía)
+func (c *LedgerChain) LastHash() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastHash
+}
+
+// Get retorna la entrada con la secuencia dada
+func (c *LedgerChain) Get(ctx context.Context, sequence int64) (LedgerEntry, error) {
+	return c.store.Get(ctx, sequence)
+}
+
+// GetByClientRef retorna la entrada registrada con el ClientRef dado
+func (c *LedgerChain) GetByClientRef(ctx context.Context, clientRef string) (LedgerEntry, error) {
+	return c.store.GetByClientRef(ctx, clientRef)
+}
+
+// Ping comprueba que el store esté disponible; los stores sin Ping se
+// consideran siempre disponibles
+func (c *LedgerChain) Ping(ctx context.Context) error {
+	if pinger, ok := c.store.(Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// CircuitState retorna el estado del circuit breaker del store; ok es false
+// si el store no tiene breaker
+func (c *LedgerChain) CircuitState() (state string, ok bool) {
+	if stater, ok := c.store.(CircuitStater); ok {
+		return stater.CircuitState(), true
+	}
+	return "", false
+}
+
+// Entries retorna todas las entradas persistidas en orden de secuencia
+func (c *LedgerChain) Entries(ctx context.Context) ([]LedgerEntry, error) {
+	return c.store.List(ctx)
+}
+
+// StreamEntries llama a fn con cada entrada cuya secuencia está en
+// [from, to], en orden de secuencia
+func (c *LedgerChain) StreamEntries(ctx context.Context, from, to int64, fn func(LedgerEntry) error) error {
+	return streamRange(ctx, c.store, from, to, fn)
+}
+
+// streamRange usa el RangeStreamer del store si lo tiene; si no, filtra el
+// resultado de List
+func streamRange(ctx context.Context, store LedgerStore, from, to int64, fn func(LedgerEntry) error) error {
+	if streamer, ok := store.(RangeStreamer); ok {
+		return streamer.StreamRange(ctx, from, to, fn)
+	}
+	entries, err := store.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.SequenceNumber < from || entry.SequenceNumber > to {
+			continue
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StreamEntriesCreatedBetween llama a fn con cada entrada cuyo created_at
+// está en [from, to], en orden de secuencia
+func (c *LedgerChain) StreamEntriesCreatedBetween(ctx context.Context, from, to time.Time, fn func(LedgerEntry) error) error {
+	return streamCreatedBetween(ctx, c.store, from, to, fn)
+}
+
+// streamCreatedBetween usa el CreatedRangeStreamer del store si lo tiene; si
+// no, filtra el resultado de List
+func streamCreatedBetween(ctx context.Context, store LedgerStore, from, to time.Time, fn func(LedgerEntry) error) error {
+	if streamer, ok := store.(CreatedRangeStreamer); ok {
+		return streamer.StreamCreatedBetween(ctx, from, to, fn)
+	}
+	entries, err := store.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !createdBetween(entry, from, to) {
+			continue
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createdBetween indica si el created_at de la entrada está en [from, to]
+func createdBetween(entry LedgerEntry, from, to time.Time) bool {
+	return !entry.CreatedAt.Before(from) && !entry.CreatedAt.After(to)
+}
+
+// VerificationResult resume la verificación de la cadena del ledger
+type VerificationResult struct {
+	Valid                bool
+	EntriesVerified      int
+	FirstInvalidSequence int64
+	Reason               string
+}
+
+// Verify verifica las entradas con el mismo modo de hash que usa la cadena
+func (c *LedgerChain) Verify(entries []LedgerEntry) VerificationResult {
+	return verifyChain(entries, c.hashEntry)
+}
+
+// VerifyChain recorre las entradas en orden, recalcula cada EntryHash y
+// comprueba que cada PreviousHash apunte a la entrada anterior y que no
+// falten secuencias. Se detiene en la primera ruptura de la cadena.
+// Sólo aplica a cadenas sin clave; las firmadas se verifican con Verify.
+func VerifyChain(entries []LedgerEntry) VerificationResult {
+	return verifyChain(entries, ComputeEntryHash)
+}
+
+func verifyChain(entries []LedgerEntry, hashEntry func(LedgerEntry) string) VerificationResult {
+	previousHash := GenesisHash
+	previousSequence := int64(0)
+
+	for i, entry := range entries {
+		if expected := previousSequence + 1; entry.SequenceNumber != expected {
+			return VerificationResult{
+				EntriesVerified:      i,
+				FirstInvalidSequence: expected,
+				Reason:               fmt.Sprintf("entry %d is missing from the chain", expected),
+			}
+		}
+		if entry.PreviousHash != previousHash {
+			return VerificationResult{
+				EntriesVerified:      i,
+				FirstInvalidSequence: entry.SequenceNumber,
+				Reason:               fmt.Sprintf("entry %d: previous hash does not match prior entry", entry.SequenceNumber),
+			}
+		}
+		if !hmac.Equal([]byte(hashEntry(entry)), []byte(entry.EntryHash)) {
+			return VerificationResult{
+				EntriesVerified:      i,
+				FirstInvalidSequence: entry.SequenceNumber,
+				Reason:               fmt.Sprintf("entry %d: entry hash mismatch", entry.SequenceNumber),
+			}
+		}
+
+		previousHash = entry.EntryHash
+		previousSequence = entry.SequenceNumber
+	}
+
+	return VerificationResult{Valid: true, EntriesVerified: len(entries)}
+}
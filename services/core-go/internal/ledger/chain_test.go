@@ -0,0 +1,231 @@
+package ledger
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func newTestChain(t *testing.T) (*LedgerChain, *MockLedgerStore) {
+	t.Helper()
+	store := NewMockLedgerStore()
+	chain, err := NewLedgerChain(context.Background(), store)
+	if err != nil {
+		t.Fatalf("NewLedgerChain: %v", err)
+	}
+	return chain, store
+}
+
+func appendSample(t *testing.T, chain *LedgerChain, amounts ...string) {
+	t.Helper()
+	for _, amount := range amounts {
+		_, err := chain.Append(context.Background(), LedgerEntry{
+			EntryType:   "deposit",
+			Amount:      decimal.RequireFromString(amount),
+			Currency:    "MXN",
+			Description: "sample",
+			CreatedAt:   time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC),
+		})
+		if err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+}
+
+func entriesOf(t *testing.T, chain *LedgerChain) []LedgerEntry {
+	t.Helper()
+	entries, err := chain.Entries(context.Background())
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	return entries
+}
+
+func TestLedgerChainLinksEntries(t *testing.T) {
+	chain, _ := newTestChain(t)
+	appendSample(t, chain, "100", "200", "300")
+
+	entries := entriesOf(t, chain)
+	if entries[0].PreviousHash != GenesisHash {
+		t.Errorf("first PreviousHash = %s, want genesis", entries[0].PreviousHash)
+	}
+	for i := 1; i < len(entries); i++ {
+		if entries[i].PreviousHash != entries[i-1].EntryHash {
+			t.Errorf("entry %d is not linked to entry %d", i, i-1)
+		}
+	}
+	if chain.LastHash() != entries[2].EntryHash {
+		t.Error("LastHash does not match last entry")
+	}
+
+	if result := VerifyChain(entries); !result.Valid || result.EntriesVerified != 3 {
+		t.Errorf("VerifyChain = %+v, want valid with 3 entries", result)
+	}
+}
+
+func TestVerifyChainDetectsTamperedAmount(t *testing.T) {
+	chain, _ := newTestChain(t)
+	appendSample(t, chain, "100", "200", "300")
+
+	entries := entriesOf(t, chain)
+	entries[1].Amount = decimal.RequireFromString("250")
+
+	result := VerifyChain(entries)
+	if result.Valid {
+		t.Fatal("expected tampered chain to fail verification")
+	}
+	if result.EntriesVerified != 1 || result.FirstInvalidSequence != 2 {
+		t.Errorf("result = %+v, want 1 verified and first invalid sequence 2", result)
+	}
+}
+
+func TestVerifyChainDetectsMissingEntry(t *testing.T) {
+	chain, _ := newTestChain(t)
+	appendSample(t, chain, "100", "200", "300")
+
+	entries := entriesOf(t, chain)
+	entries = append(entries[:1], entries[2:]...)
+
+	result := VerifyChain(entries)
+	if result.Valid {
+		t.Fatal("expected chain with a missing entry to fail verification")
+	}
+	if result.FirstInvalidSequence != 2 {
+		t.Errorf("FirstInvalidSequence = %d, want 2", result.FirstInvalidSequence)
+	}
+}
+
+func TestVerifyChainEmpty(t *testing.T) {
+	if result := VerifyChain(nil); !result.Valid || result.EntriesVerified != 0 {
+		t.Errorf("VerifyChain(nil) = %+v, want valid with 0 entries", result)
+	}
+}
+
+func TestLedgerChainResumesFromStore(t *testing.T) {
+	chain, store := newTestChain(t)
+	appendSample(t, chain, "100", "200")
+
+	resumed, err := NewLedgerChain(context.Background(), store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendSample(t, resumed, "300")
+
+	entries := entriesOf(t, resumed)
+	if entries[2].SequenceNumber != 3 || entries[2].PreviousHash != entries[1].EntryHash {
+		t.Errorf("resumed chain did not continue from the last persisted entry: %+v", entries[2])
+	}
+	if result := VerifyChain(entries); !result.Valid {
+		t.Errorf("VerifyChain = %+v, want valid", result)
+	}
+}
+
+func TestLedgerChainStoreFailureDoesNotAdvance(t *testing.T) {
+	chain, store := newTestChain(t)
+	appendSample(t, chain, "100")
+
+	store.Err = errors.New("database down")
+	if _, err := chain.Append(context.Background(), LedgerEntry{EntryType: "deposit"}); err == nil {
+		t.Fatal("expected store error")
+	}
+	store.Err = nil
+
+	appendSample(t, chain, "200")
+	if result := VerifyChain(entriesOf(t, chain)); !result.Valid {
+		t.Errorf("chain broken after a failed append: %+v", result)
+	}
+}
+
+func TestMockLedgerStoreGetNotFound(t *testing.T) {
+	if _, err := NewMockLedgerStore().Get(context.Background(), 1); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("err = %v, want ErrEntryNotFound", err)
+	}
+}
+
+func TestAppendOnceDeduplicatesClientRef(t *testing.T) {
+	chain, _ := newTestChain(t)
+	ctx := context.Background()
+	entry := LedgerEntry{EntryType: "deposit", Amount: decimal.NewFromInt(10), CreatedAt: time.Now(), ClientRef: "ref-1"}
+
+	first, created, err := chain.AppendOnce(ctx, entry)
+	if err != nil || !created {
+		t.Fatalf("first AppendOnce: created = %v, err = %v", created, err)
+	}
+
+	entry.Amount = decimal.NewFromInt(99)
+	again, created, err := chain.AppendOnce(ctx, entry)
+	if err != nil || created {
+		t.Fatalf("repeated AppendOnce: created = %v, err = %v", created, err)
+	}
+	if again.SequenceNumber != first.SequenceNumber || again.EntryHash != first.EntryHash {
+		t.Errorf("repeated ref returned entry %d, want %d", again.SequenceNumber, first.SequenceNumber)
+	}
+
+	entry.ClientRef = "ref-2"
+	other, created, err := chain.AppendOnce(ctx, entry)
+	if err != nil || !created || other.SequenceNumber != first.SequenceNumber+1 {
+		t.Errorf("distinct ref: sequence = %d, created = %v, err = %v", other.SequenceNumber, created, err)
+	}
+	if entries := entriesOf(t, chain); len(entries) != 2 || !VerifyChain(entries).Valid {
+		t.Errorf("chain has %d entries, want 2 valid entries", len(entries))
+	}
+}
+
+func TestMockLedgerStoreRejectsDuplicateClientRef(t *testing.T) {
+	store := NewMockLedgerStore()
+	ctx := context.Background()
+	if err := store.Append(ctx, LedgerEntry{SequenceNumber: 1, ClientRef: "ref"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Append(ctx, LedgerEntry{SequenceNumber: 2, ClientRef: "ref"}); !errors.Is(err, ErrDuplicateClientRef) {
+		t.Errorf("duplicate ref error = %v, want ErrDuplicateClientRef", err)
+	}
+	// Las entradas sin referencia nunca chocan
+	for seq := int64(2); seq <= 3; seq++ {
+		if err := store.Append(ctx, LedgerEntry{SequenceNumber: seq}); err != nil {
+			t.Errorf("entry without ref: %v", err)
+		}
+	}
+}
+
+// keySigner firma con HMAC-SHA256 sobre el JSON de los datos
+type keySigner []byte
+
+func (k keySigner) CalculateIntegrityHMAC(data map[string]interface{}) string {
+	raw, _ := json.Marshal(data)
+	mac := hmac.New(sha256.New, k)
+	mac.Write(raw)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestSignedLedgerChainRequiresKey(t *testing.T) {
+	store := NewMockLedgerStore()
+	chain, err := NewSignedLedgerChain(context.Background(), store, keySigner("key-a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendSample(t, chain, "100", "200")
+	entries := entriesOf(t, chain)
+
+	if result := chain.Verify(entries); !result.Valid {
+		t.Fatalf("signed chain must verify with its key: %+v", result)
+	}
+	if result := VerifyChain(entries); result.Valid {
+		t.Error("signed chain must not verify as unkeyed")
+	}
+
+	other, err := NewSignedLedgerChain(context.Background(), NewMockLedgerStore(), keySigner("key-b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := other.Verify(entries); result.Valid {
+		t.Error("signed chain must not verify with a different key")
+	}
+}
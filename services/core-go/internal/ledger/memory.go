@@ -0,0 +1,172 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultInMemoryCapacity es la cantidad máxima de entradas por defecto de
+// un InMemoryLedgerStore
+const DefaultInMemoryCapacity = 100000
+
+var (
+	// ErrLedgerFull indica que el store en memoria alcanzó su capacidad
+	ErrLedgerFull = errors.New("in-memory ledger is full")
+	// ErrBrokenChain indica que la entrada no continúa la cadena del store:
+	// su secuencia no es la siguiente o su PreviousHash no es el último hash
+	ErrBrokenChain = errors.New("ledger entry does not extend the chain")
+)
+
+// InMemoryLedgerStore es un LedgerStore en memoria para desarrollo local.
+// A diferencia de MockLedgerStore valida que cada entrada continúe la cadena
+// y acota la cantidad de entradas: como el ledger es de solo-anexar, al
+// llenarse rechaza nuevas entradas con ErrLedgerFull en lugar de descartar
+// las antiguas. Es seguro para uso concurrente.
+type InMemoryLedgerStore struct {
+	mu         sync.RWMutex
+	entries    []LedgerEntry
+	clientRefs map[string]int
+	capacity   int
+}
+
+// NewInMemoryLedgerStore crea un store vacío con la capacidad dada; una
+// capacidad no positiva usa DefaultInMemoryCapacity
+func NewInMemoryLedgerStore(capacity int) *InMemoryLedgerStore {
+	if capacity <= 0 {
+		capacity = DefaultInMemoryCapacity
+	}
+	return &InMemoryLedgerStore{clientRefs: make(map[string]int), capacity: capacity}
+}
+
+// Append agrega la entrada si continúa la cadena y queda capacidad
+func (s *InMemoryLedgerStore) Append(ctx context.Context, entry LedgerEntry) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.entries) >= s.capacity {
+		return ErrLedgerFull
+	}
+	if entry.ClientRef != "" {
+		if _, ok := s.clientRefs[entry.ClientRef]; ok {
+			return ErrDuplicateClientRef
+		}
+	}
+
+	wantSequence, wantPrevious := int64(1), GenesisHash
+	if n := len(s.entries); n > 0 {
+		wantSequence = s.entries[n-1].SequenceNumber + 1
+		wantPrevious = s.entries[n-1].EntryHash
+	}
+	if entry.SequenceNumber != wantSequence || entry.PreviousHash != wantPrevious {
+		return fmt.Errorf("%w: got sequence %d, want %d", ErrBrokenChain, entry.SequenceNumber, wantSequence)
+	}
+
+	if entry.ClientRef != "" {
+		s.clientRefs[entry.ClientRef] = len(s.entries)
+	}
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+// Get retorna la entrada por secuencia; las secuencias son contiguas desde 1
+func (s *InMemoryLedgerStore) Get(_ context.Context, sequence int64) (LedgerEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if sequence < 1 || sequence > int64(len(s.entries)) {
+		return LedgerEntry{}, ErrEntryNotFound
+	}
+	return s.entries[sequence-1], nil
+}
+
+// GetByClientRef busca la entrada en el índice de referencias del cliente
+func (s *InMemoryLedgerStore) GetByClientRef(_ context.Context, clientRef string) (LedgerEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	index, ok := s.clientRefs[clientRef]
+	if clientRef == "" || !ok {
+		return LedgerEntry{}, ErrEntryNotFound
+	}
+	return s.entries[index], nil
+}
+
+// List retorna una copia de todas las entradas
+func (s *InMemoryLedgerStore) List(_ context.Context) ([]LedgerEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entries := make([]LedgerEntry, len(s.entries))
+	copy(entries, s.entries)
+	return entries, nil
+}
+
+// StreamRange recorre una copia de las entradas del rango, de modo que fn
+// puede ejecutarse sin mantener el lock
+func (s *InMemoryLedgerStore) StreamRange(ctx context.Context, from, to int64, fn func(LedgerEntry) error) error {
+	s.mu.RLock()
+	from = max(from, 1)
+	to = min(to, int64(len(s.entries)))
+	var entries []LedgerEntry
+	if from <= to {
+		entries = append(entries, s.entries[from-1:to]...)
+	}
+	s.mu.RUnlock()
+
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StreamCreatedBetween recorre una copia de las entradas creadas en el rango
+func (s *InMemoryLedgerStore) StreamCreatedBetween(ctx context.Context, from, to time.Time, fn func(LedgerEntry) error) error {
+	s.mu.RLock()
+	var entries []LedgerEntry
+	for _, entry := range s.entries {
+		if createdBetween(entry, from, to) {
+			entries = append(entries, entry)
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Last retorna la última entrada agregada
+func (s *InMemoryLedgerStore) Last(_ context.Context) (LedgerEntry, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.entries) == 0 {
+		return LedgerEntry{}, false, nil
+	}
+	return s.entries[len(s.entries)-1], true, nil
+}
+
+// Len retorna la cantidad de entradas guardadas
+func (s *InMemoryLedgerStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.entries)
+}
+
+// Capacity retorna la cantidad máxima de entradas del store
+func (s *InMemoryLedgerStore) Capacity() int {
+	return s.capacity
+}
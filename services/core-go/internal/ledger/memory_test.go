@@ -0,0 +1,178 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func newInMemoryChain(t *testing.T, capacity int) (*LedgerChain, *InMemoryLedgerStore) {
+	t.Helper()
+	store := NewInMemoryLedgerStore(capacity)
+	chain, err := NewLedgerChain(context.Background(), store)
+	if err != nil {
+		t.Fatalf("NewLedgerChain: %v", err)
+	}
+	return chain, store
+}
+
+func TestInMemoryLedgerStoreChainsAndVerifies(t *testing.T) {
+	chain, store := newInMemoryChain(t, 10)
+	appendSample(t, chain, "100", "200", "300")
+
+	entries := entriesOf(t, chain)
+	if len(entries) != 3 || entries[0].PreviousHash != GenesisHash {
+		t.Fatalf("entries = %+v, want 3 starting at genesis", entries)
+	}
+	for i := 1; i < len(entries); i++ {
+		if entries[i].SequenceNumber != int64(i+1) || entries[i].PreviousHash != entries[i-1].EntryHash {
+			t.Errorf("entry %d does not link to the previous one", i)
+		}
+	}
+	if result := chain.Verify(entries); !result.Valid || result.EntriesVerified != 3 {
+		t.Errorf("Verify = %+v, want valid with 3 entries", result)
+	}
+
+	// Una cadena nueva sobre el mismo store continúa donde quedó
+	reopened, err := NewLedgerChain(context.Background(), store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendSample(t, reopened, "400")
+	if result := reopened.Verify(entriesOf(t, reopened)); !result.Valid || result.EntriesVerified != 4 {
+		t.Errorf("Verify after reopen = %+v, want valid with 4 entries", result)
+	}
+}
+
+func TestInMemoryLedgerStoreRejectsBrokenChain(t *testing.T) {
+	chain, store := newInMemoryChain(t, 10)
+	appendSample(t, chain, "100")
+	last, _, _ := store.Last(context.Background())
+
+	tests := []struct {
+		name  string
+		entry LedgerEntry
+	}{
+		{"secuencia repetida", LedgerEntry{SequenceNumber: 1, PreviousHash: last.EntryHash}},
+		{"secuencia salteada", LedgerEntry{SequenceNumber: 3, PreviousHash: last.EntryHash}},
+		{"hash anterior distinto", LedgerEntry{SequenceNumber: 2, PreviousHash: GenesisHash}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := store.Append(context.Background(), tt.entry); !errors.Is(err, ErrBrokenChain) {
+				t.Errorf("err = %v, want ErrBrokenChain", err)
+			}
+		})
+	}
+	if store.Len() != 1 {
+		t.Errorf("len = %d, want 1", store.Len())
+	}
+}
+
+func TestInMemoryLedgerStoreRetrieval(t *testing.T) {
+	chain, store := newInMemoryChain(t, 10)
+	ctx := context.Background()
+	appendSample(t, chain, "100")
+	stored, created, err := chain.AppendOnce(ctx, LedgerEntry{
+		EntryType: "deposit", Amount: decimal.NewFromInt(5), Currency: "MXN", CreatedAt: time.Now(), ClientRef: "ref-1",
+	})
+	if err != nil || !created {
+		t.Fatalf("AppendOnce = %v, %v; want created", created, err)
+	}
+
+	if got, err := store.Get(ctx, 2); err != nil || got.EntryHash != stored.EntryHash {
+		t.Errorf("Get(2) = %+v, %v; want the stored entry", got, err)
+	}
+	for _, sequence := range []int64{0, 3, -1} {
+		if _, err := store.Get(ctx, sequence); !errors.Is(err, ErrEntryNotFound) {
+			t.Errorf("Get(%d) err = %v, want ErrEntryNotFound", sequence, err)
+		}
+	}
+	if got, err := store.GetByClientRef(ctx, "ref-1"); err != nil || got.SequenceNumber != 2 {
+		t.Errorf("GetByClientRef = %+v, %v; want sequence 2", got, err)
+	}
+	if _, err := store.GetByClientRef(ctx, ""); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("GetByClientRef(\"\") err = %v, want ErrEntryNotFound", err)
+	}
+
+	// Un reintento con la misma referencia retorna la entrada existente
+	again, created, err := chain.AppendOnce(ctx, LedgerEntry{EntryType: "deposit", Amount: decimal.NewFromInt(5), ClientRef: "ref-1"})
+	if err != nil || created || again.SequenceNumber != 2 {
+		t.Errorf("AppendOnce retry = %+v, %v, %v; want existing entry", again, created, err)
+	}
+
+	var streamed []int64
+	err = store.StreamRange(ctx, 2, 10, func(entry LedgerEntry) error {
+		streamed = append(streamed, entry.SequenceNumber)
+		return nil
+	})
+	if err != nil || len(streamed) != 1 || streamed[0] != 2 {
+		t.Errorf("StreamRange(2, 10) = %v, %v; want [2]", streamed, err)
+	}
+}
+
+func TestStreamEntriesCreatedBetween(t *testing.T) {
+	chain, _ := newInMemoryChain(t, 10)
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 12, 0, 0, 0, time.UTC) }
+	// La entrada de una transacción aprobada tarde conserva una fecha anterior
+	for _, d := range []int{1, 5, 2, 9} {
+		if _, err := chain.Append(ctx, LedgerEntry{EntryType: "deposit", Amount: decimal.NewFromInt(1), CreatedAt: day(d)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var streamed []int64
+	err := chain.StreamEntriesCreatedBetween(ctx, day(1), day(4), func(entry LedgerEntry) error {
+		streamed = append(streamed, entry.SequenceNumber)
+		return nil
+	})
+	if err != nil || len(streamed) != 2 || streamed[0] != 1 || streamed[1] != 3 {
+		t.Errorf("StreamEntriesCreatedBetween = %v, %v; want [1 3]", streamed, err)
+	}
+}
+
+func TestInMemoryLedgerStoreCapacity(t *testing.T) {
+	chain, store := newInMemoryChain(t, 2)
+	appendSample(t, chain, "1", "2")
+
+	_, err := chain.Append(context.Background(), LedgerEntry{EntryType: "deposit", Amount: decimal.NewFromInt(3)})
+	if !errors.Is(err, ErrLedgerFull) {
+		t.Fatalf("err = %v, want ErrLedgerFull", err)
+	}
+	// El rechazo no altera la cadena ni las entradas existentes
+	if result := chain.Verify(entriesOf(t, chain)); !result.Valid || result.EntriesVerified != 2 || store.Len() != store.Capacity() {
+		t.Errorf("Verify = %+v, len = %d; want 2 valid entries", result, store.Len())
+	}
+	if NewInMemoryLedgerStore(0).Capacity() != DefaultInMemoryCapacity {
+		t.Error("non-positive capacity must use DefaultInMemoryCapacity")
+	}
+}
+
+func TestInMemoryLedgerStoreConcurrentAppends(t *testing.T) {
+	chain, _ := newInMemoryChain(t, 100)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, _, err := chain.AppendOnce(context.Background(), LedgerEntry{
+				EntryType: "deposit", Amount: decimal.NewFromInt(1), ClientRef: fmt.Sprintf("ref-%d", i%25),
+			})
+			if err != nil {
+				t.Errorf("AppendOnce: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if result := chain.Verify(entriesOf(t, chain)); !result.Valid || result.EntriesVerified != 25 {
+		t.Errorf("Verify = %+v, want 25 valid entries", result)
+	}
+}
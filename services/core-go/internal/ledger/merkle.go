@@ -0,0 +1,112 @@
+package ledger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+)
+
+// Prefijos de dominio (estilo RFC 6962) para que una hoja no pueda hacerse
+// pasar por un nodo interno
+const (
+	merkleLeafPrefix = 0x00
+	merkleNodePrefix = 0x01
+)
+
+// Posición del hash hermano respecto al nodo que se está verificando
+const (
+	SiblingLeft  = "left"
+	SiblingRight = "right"
+)
+
+// ErrLeafOutOfRange indica que el índice solicitado no existe en el árbol
+var ErrLeafOutOfRange = errors.New("leaf index out of range")
+
+// ProofStep es un hash hermano en el camino de una hoja hacia la raíz
+type ProofStep struct {
+	Hash     string `json:"hash"`
+	Position string `json:"position"`
+}
+
+// MerkleLeafHash calcula el hash de hoja de una entrada a partir de su EntryHash
+func MerkleLeafHash(entry LedgerEntry) string {
+	entryHash, _ := hex.DecodeString(entry.EntryHash)
+	return hashWithPrefix(merkleLeafPrefix, entryHash)
+}
+
+// MerkleRoot calcula la raíz del árbol formado por los hashes de hoja.
+// Un nodo sin pareja sube sin cambios al siguiente nivel.
+func MerkleRoot(leaves []string) string {
+	if len(leaves) == 0 {
+		return hashWithPrefix(merkleLeafPrefix, nil)
+	}
+
+	level := leaves
+	for len(level) > 1 {
+		level = nextMerkleLevel(level)
+	}
+	return level[0]
+}
+
+// MerkleProof genera la prueba de inclusión de la hoja en la posición index
+func MerkleProof(leaves []string, index int) ([]ProofStep, error) {
+	if index < 0 || index >= len(leaves) {
+		return nil, ErrLeafOutOfRange
+	}
+
+	proof := []ProofStep{}
+	level := leaves
+	for len(level) > 1 {
+		if index%2 == 1 {
+			proof = append(proof, ProofStep{Hash: level[index-1], Position: SiblingLeft})
+		} else if index+1 < len(level) {
+			proof = append(proof, ProofStep{Hash: level[index+1], Position: SiblingRight})
+		}
+
+		level = nextMerkleLevel(level)
+		index /= 2
+	}
+	return proof, nil
+}
+
+// VerifyMerkleProof recompone la raíz a partir de la hoja y la prueba y la
+// compara con la raíz publicada
+func VerifyMerkleProof(leaf string, proof []ProofStep, root string) bool {
+	current := leaf
+	for _, step := range proof {
+		switch step.Position {
+		case SiblingLeft:
+			current = hashMerkleNode(step.Hash, current)
+		case SiblingRight:
+			current = hashMerkleNode(current, step.Hash)
+		default:
+			return false
+		}
+	}
+	return current == root
+}
+
+func nextMerkleLevel(level []string) []string {
+	next := make([]string, 0, (len(level)+1)/2)
+	for i := 0; i < len(level); i += 2 {
+		if i+1 < len(level) {
+			next = append(next, hashMerkleNode(level[i], level[i+1]))
+		} else {
+			next = append(next, level[i])
+		}
+	}
+	return next
+}
+
+func hashMerkleNode(left, right string) string {
+	l, _ := hex.DecodeString(left)
+	r, _ := hex.DecodeString(right)
+	return hashWithPrefix(merkleNodePrefix, append(l, r...))
+}
+
+func hashWithPrefix(prefix byte, data []byte) string {
+	h := sha256.New()
+	h.Write([]byte{prefix})
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
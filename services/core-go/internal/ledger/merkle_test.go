@@ -0,0 +1,58 @@
+package ledger
+
+import "testing"
+
+func sampleLeaves(t *testing.T, n int) []string {
+	chain, _ := newTestChain(t)
+	for i := 0; i < n; i++ {
+		appendSample(t, chain, "100")
+	}
+
+	var leaves []string
+	for _, entry := range entriesOf(t, chain) {
+		leaves = append(leaves, MerkleLeafHash(entry))
+	}
+	return leaves
+}
+
+func TestMerkleProofVerifiesEveryLeaf(t *testing.T) {
+	// Tamaños pares, impares y potencias de dos
+	for _, size := range []int{1, 2, 3, 5, 8, 13} {
+		leaves := sampleLeaves(t, size)
+		root := MerkleRoot(leaves)
+
+		for i, leaf := range leaves {
+			proof, err := MerkleProof(leaves, i)
+			if err != nil {
+				t.Fatalf("size %d leaf %d: %v", size, i, err)
+			}
+			if !VerifyMerkleProof(leaf, proof, root) {
+				t.Errorf("size %d leaf %d: valid proof rejected", size, i)
+			}
+		}
+	}
+}
+
+func TestMerkleProofRejectsTamperedLeaf(t *testing.T) {
+	leaves := sampleLeaves(t, 5)
+	root := MerkleRoot(leaves)
+
+	proof, err := MerkleProof(leaves, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := hashWithPrefix(merkleLeafPrefix, []byte("tampered"))
+	if VerifyMerkleProof(tampered, proof, root) {
+		t.Error("tampered leaf must not verify")
+	}
+	if VerifyMerkleProof(leaves[3], proof, root) {
+		t.Error("proof for leaf 2 must not verify leaf 3")
+	}
+}
+
+func TestMerkleProofOutOfRange(t *testing.T) {
+	if _, err := MerkleProof(sampleLeaves(t, 2), 5); err != ErrLeafOutOfRange {
+		t.Errorf("err = %v, want ErrLeafOutOfRange", err)
+	}
+}
@@ -0,0 +1,222 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+)
+
+// postgresSchema crea la tabla del ledger y bloquea UPDATE/DELETE a nivel de
+// base de datos, de modo que ni un cliente con permisos de escritura pueda
+// reescribir la historia
+const postgresSchema = `
+CREATE TABLE IF NOT EXISTS core_ledger_entries (
+    sequence_number BIGINT PRIMARY KEY,
+    previous_hash   CHAR(64) NOT NULL,
+    entry_hash      CHAR(64) NOT NULL UNIQUE,
+    entry_type      VARCHAR(50) NOT NULL,
+    amount          NUMERIC(28, 8) NOT NULL,
+    currency        VARCHAR(3) NOT NULL DEFAULT '',
+    description     TEXT NOT NULL DEFAULT '',
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL,
+    is_verified     BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+-- Referencia del cliente para deduplicar reintentos; NULL cuando no se envía
+ALTER TABLE core_ledger_entries ADD COLUMN IF NOT EXISTS client_ref VARCHAR(255)
+    CONSTRAINT core_ledger_entries_client_ref_key UNIQUE;
+
+-- La conciliación lee las entradas de un rango de fechas
+CREATE INDEX IF NOT EXISTS core_ledger_entries_created_at_idx
+    ON core_ledger_entries (created_at);
+
+CREATE OR REPLACE FUNCTION core_ledger_prevent_mutation()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'core_ledger_entries is append-only: % not allowed', TG_OP;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS core_ledger_append_only ON core_ledger_entries;
+CREATE TRIGGER core_ledger_append_only
+    BEFORE UPDATE OR DELETE ON core_ledger_entries
+    FOR EACH ROW EXECUTE FUNCTION core_ledger_prevent_mutation();
+`
+
+const ledgerColumns = `sequence_number, previous_hash, entry_hash, entry_type,
+    amount::text, currency, description, created_at, is_verified, COALESCE(client_ref, '')`
+
+const (
+	// pgUniqueViolation es el SQLSTATE de una clave duplicada
+	pgUniqueViolation = "23505"
+	// clientRefConstraint es la restricción única de client_ref
+	clientRefConstraint = "core_ledger_entries_client_ref_key"
+)
+
+// PostgresLedgerStore persiste el ledger en PostgreSQL
+type PostgresLedgerStore struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresLedgerStore crea el store y asegura que el esquema exista
+func NewPostgresLedgerStore(ctx context.Context, pool *pgxpool.Pool) (*PostgresLedgerStore, error) {
+	if _, err := pool.Exec(ctx, postgresSchema); err != nil {
+		return nil, fmt.Errorf("failed to create ledger schema: %w", err)
+	}
+	return &PostgresLedgerStore{pool: pool}, nil
+}
+
+// Append inserta la entrada; la clave primaria impide secuencias duplicadas
+// y la restricción única de client_ref, referencias repetidas
+func (s *PostgresLedgerStore) Append(ctx context.Context, entry LedgerEntry) error {
+	_, err := s.pool.Exec(ctx, `
+        INSERT INTO core_ledger_entries (
+            sequence_number, previous_hash, entry_hash, entry_type,
+            amount, currency, description, created_at, is_verified, client_ref
+        ) VALUES ($1, $2, $3, $4, $5::numeric, $6, $7, $8, $9, NULLIF($10, ''))`,
+		entry.SequenceNumber, entry.PreviousHash, entry.EntryHash, entry.EntryType,
+		entry.Amount.String(), entry.Currency, entry.Description, entry.CreatedAt, entry.IsVerified,
+		entry.ClientRef,
+	)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation && pgErr.ConstraintName == clientRefConstraint {
+		return ErrDuplicateClientRef
+	}
+	if err != nil {
+		return fmt.Errorf("failed to insert ledger entry: %w", err)
+	}
+	return nil
+}
+
+// Get lee una entrada por secuencia
+func (s *PostgresLedgerStore) Get(ctx context.Context, sequence int64) (LedgerEntry, error) {
+	row := s.pool.QueryRow(ctx,
+		`SELECT `+ledgerColumns+` FROM core_ledger_entries WHERE sequence_number = $1`, sequence)
+
+	entry, err := scanLedgerEntry(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return LedgerEntry{}, ErrEntryNotFound
+	}
+	return entry, err
+}
+
+// GetByClientRef lee una entrada por referencia del cliente
+func (s *PostgresLedgerStore) GetByClientRef(ctx context.Context, clientRef string) (LedgerEntry, error) {
+	row := s.pool.QueryRow(ctx,
+		`SELECT `+ledgerColumns+` FROM core_ledger_entries WHERE client_ref = $1`, clientRef)
+
+	entry, err := scanLedgerEntry(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return LedgerEntry{}, ErrEntryNotFound
+	}
+	return entry, err
+}
+
+// List lee todas las entradas en orden de secuencia
+func (s *PostgresLedgerStore) List(ctx context.Context) ([]LedgerEntry, error) {
+	rows, err := s.pool.Query(ctx,
+		`SELECT `+ledgerColumns+` FROM core_ledger_entries ORDER BY sequence_number`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ledger entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []LedgerEntry
+	for rows.Next() {
+		entry, err := scanLedgerEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// StreamRange recorre las entradas del rango fila a fila, sin acumularlas
+func (s *PostgresLedgerStore) StreamRange(ctx context.Context, from, to int64, fn func(LedgerEntry) error) error {
+	rows, err := s.pool.Query(ctx,
+		`SELECT `+ledgerColumns+` FROM core_ledger_entries
+		 WHERE sequence_number BETWEEN $1 AND $2 ORDER BY sequence_number`, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to query ledger entries: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := scanLedgerEntry(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// StreamCreatedBetween recorre fila a fila las entradas creadas en el rango,
+// usando core_ledger_entries_created_at_idx
+func (s *PostgresLedgerStore) StreamCreatedBetween(ctx context.Context, from, to time.Time, fn func(LedgerEntry) error) error {
+	rows, err := s.pool.Query(ctx,
+		`SELECT `+ledgerColumns+` FROM core_ledger_entries
+		 WHERE created_at BETWEEN $1 AND $2 ORDER BY sequence_number`, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to query ledger entries: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := scanLedgerEntry(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Last lee la entrada con la secuencia más alta
+func (s *PostgresLedgerStore) Last(ctx context.Context) (LedgerEntry, bool, error) {
+	row := s.pool.QueryRow(ctx,
+		`SELECT `+ledgerColumns+` FROM core_ledger_entries ORDER BY sequence_number DESC LIMIT 1`)
+
+	entry, err := scanLedgerEntry(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return LedgerEntry{}, false, nil
+	}
+	if err != nil {
+		return LedgerEntry{}, false, err
+	}
+	return entry, true, nil
+}
+
+// Ping comprueba la conexión con PostgreSQL
+func (s *PostgresLedgerStore) Ping(ctx context.Context) error {
+	return s.pool.Ping(ctx)
+}
+
+func scanLedgerEntry(row pgx.Row) (LedgerEntry, error) {
+	var entry LedgerEntry
+	var amount string
+	err := row.Scan(
+		&entry.SequenceNumber, &entry.PreviousHash, &entry.EntryHash, &entry.EntryType,
+		&amount, &entry.Currency, &entry.Description, &entry.CreatedAt, &entry.IsVerified, &entry.ClientRef,
+	)
+	if err != nil {
+		return LedgerEntry{}, err
+	}
+
+	if entry.Amount, err = decimal.NewFromString(amount); err != nil {
+		return LedgerEntry{}, fmt.Errorf("invalid stored amount: %w", err)
+	}
+	entry.CreatedAt = entry.CreatedAt.UTC()
+	return entry, nil
+}
@@ -0,0 +1,36 @@
+/*
+Ledger inmutable de FinCore
+
+Implementa:
+- Secuencias monotónicas para las entradas del ledger
+- Encadenamiento de hashes (cada entrada incluye el hash de la anterior)
+- Pruebas de inclusión Merkle para auditoría
+- Persistencia de solo-anexar (PostgreSQL)
+*/
+package ledger
+
+import "sync/atomic"
+
+// LedgerSequencer entrega números de secuencia estrictamente crecientes.
+// Es seguro para uso concurrente entre goroutines.
+type LedgerSequencer struct {
+	last atomic.Int64
+}
+
+// NewLedgerSequencer crea un secuenciador que continúa a partir de la última
+// secuencia persistida (0 para un ledger vacío)
+func NewLedgerSequencer(lastPersisted int64) *LedgerSequencer {
+	s := &LedgerSequencer{}
+	s.last.Store(lastPersisted)
+	return s
+}
+
+// Next reserva y retorna el siguiente número de secuencia
+func (s *LedgerSequencer) Next() int64 {
+	return s.last.Add(1)
+}
+
+// Current retorna la última secuencia entregada
+func (s *LedgerSequencer) Current() int64 {
+	return s.last.Load()
+}
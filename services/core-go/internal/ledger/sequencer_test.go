@@ -0,0 +1,34 @@
+package ledger
+
+import (
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestLedgerSequencerConcurrent(t *testing.T) {
+	const total = 1000
+	seq := NewLedgerSequencer(41)
+
+	results := make([]int64, total)
+	var wg sync.WaitGroup
+	for i := 0; i < total; i++ {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			results[index] = seq.Next()
+		}(i)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i] < results[j] })
+	for i, got := range results {
+		if want := int64(42 + i); got != want {
+			t.Fatalf("results[%d] = %d, want %d (sequences must be unique and contiguous)", i, got, want)
+		}
+	}
+
+	if seq.Current() != 41+total {
+		t.Errorf("Current() = %d, want %d", seq.Current(), 41+total)
+	}
+}
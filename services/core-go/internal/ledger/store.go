@@ -0,0 +1,204 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrEntryNotFound indica que no existe una entrada con la secuencia solicitada
+	ErrEntryNotFound = errors.New("ledger entry not found")
+	// ErrDuplicateClientRef indica que ya existe una entrada con el mismo ClientRef
+	ErrDuplicateClientRef = errors.New("ledger entry with this client_ref already exists")
+)
+
+// LedgerStore persiste las entradas del ledger. Las implementaciones deben
+// ser de solo-anexar: una entrada escrita nunca se modifica ni se elimina.
+type LedgerStore interface {
+	// Append persiste una nueva entrada ya encadenada; un ClientRef no vacío
+	// repetido retorna ErrDuplicateClientRef
+	Append(ctx context.Context, entry LedgerEntry) error
+	// Get retorna la entrada con la secuencia dada o ErrEntryNotFound
+	Get(ctx context.Context, sequence int64) (LedgerEntry, error)
+	// GetByClientRef retorna la entrada con el ClientRef dado o ErrEntryNotFound
+	GetByClientRef(ctx context.Context, clientRef string) (LedgerEntry, error)
+	// List retorna todas las entradas en orden de secuencia
+	List(ctx context.Context) ([]LedgerEntry, error)
+	// Last retorna la última entrada; ok es false si el ledger está vacío
+	Last(ctx context.Context) (entry LedgerEntry, ok bool, err error)
+}
+
+// Pinger es implementado por los stores que pueden comprobar su conexión
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// RangeStreamer es implementado por los stores que pueden recorrer un rango
+// de secuencias sin cargar el ledger completo en memoria
+type RangeStreamer interface {
+	// StreamRange llama a fn con cada entrada cuya secuencia está en
+	// [from, to], en orden de secuencia. Un error de fn detiene el recorrido
+	// y se retorna tal cual.
+	StreamRange(ctx context.Context, from, to int64, fn func(LedgerEntry) error) error
+}
+
+// CreatedRangeStreamer es implementado por los stores que pueden recorrer las
+// entradas creadas en un rango de fechas sin leer el resto del ledger
+type CreatedRangeStreamer interface {
+	// StreamCreatedBetween llama a fn con cada entrada cuyo created_at está
+	// en [from, to], en orden de secuencia. El created_at no crece con la
+	// secuencia (una transacción aprobada tarde conserva su fecha), así que
+	// el rango no se puede traducir a secuencias. Un error de fn detiene el
+	// recorrido y se retorna tal cual.
+	StreamCreatedBetween(ctx context.Context, from, to time.Time, fn func(LedgerEntry) error) error
+}
+
+// MockLedgerStore es un LedgerStore en memoria para pruebas.
+// Err permite simular fallos de la base de datos en todas las operaciones.
+type MockLedgerStore struct {
+	mu      sync.RWMutex
+	entries []LedgerEntry
+	Err     error
+}
+
+// NewMockLedgerStore crea un store en memoria vacío
+func NewMockLedgerStore() *MockLedgerStore {
+	return &MockLedgerStore{}
+}
+
+// Append agrega la entrada al final
+func (m *MockLedgerStore) Append(_ context.Context, entry LedgerEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return m.Err
+	}
+	if entry.ClientRef != "" {
+		for _, existing := range m.entries {
+			if existing.ClientRef == entry.ClientRef {
+				return ErrDuplicateClientRef
+			}
+		}
+	}
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+// Get busca la entrada por secuencia
+func (m *MockLedgerStore) Get(_ context.Context, sequence int64) (LedgerEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.Err != nil {
+		return LedgerEntry{}, m.Err
+	}
+	for _, entry := range m.entries {
+		if entry.SequenceNumber == sequence {
+			return entry, nil
+		}
+	}
+	return LedgerEntry{}, ErrEntryNotFound
+}
+
+// GetByClientRef busca la entrada por referencia del cliente
+func (m *MockLedgerStore) GetByClientRef(_ context.Context, clientRef string) (LedgerEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.Err != nil {
+		return LedgerEntry{}, m.Err
+	}
+	for _, entry := range m.entries {
+		if clientRef != "" && entry.ClientRef == clientRef {
+			return entry, nil
+		}
+	}
+	return LedgerEntry{}, ErrEntryNotFound
+}
+
+// List retorna una copia de todas las entradas
+func (m *MockLedgerStore) List(_ context.Context) ([]LedgerEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	entries := make([]LedgerEntry, len(m.entries))
+	copy(entries, m.entries)
+	return entries, nil
+}
+
+// StreamRange recorre una copia de las entradas del rango, de modo que fn
+// puede ejecutarse sin mantener el lock
+func (m *MockLedgerStore) StreamRange(_ context.Context, from, to int64, fn func(LedgerEntry) error) error {
+	m.mu.RLock()
+	if m.Err != nil {
+		m.mu.RUnlock()
+		return m.Err
+	}
+	var entries []LedgerEntry
+	for _, entry := range m.entries {
+		if entry.SequenceNumber >= from && entry.SequenceNumber <= to {
+			entries = append(entries, entry)
+		}
+	}
+	m.mu.RUnlock()
+
+	for _, entry := range entries {
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StreamCreatedBetween recorre una copia de las entradas creadas en el rango
+func (m *MockLedgerStore) StreamCreatedBetween(_ context.Context, from, to time.Time, fn func(LedgerEntry) error) error {
+	m.mu.RLock()
+	if m.Err != nil {
+		m.mu.RUnlock()
+		return m.Err
+	}
+	var entries []LedgerEntry
+	for _, entry := range m.entries {
+		if createdBetween(entry, from, to) {
+			entries = append(entries, entry)
+		}
+	}
+	m.mu.RUnlock()
+
+	for _, entry := range entries {
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Last retorna la última entrada agregada
+func (m *MockLedgerStore) Last(_ context.Context) (LedgerEntry, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.Err != nil {
+		return LedgerEntry{}, false, m.Err
+	}
+	if len(m.entries) == 0 {
+		return LedgerEntry{}, false, nil
+	}
+	return m.entries[len(m.entries)-1], true, nil
+}
+
+// Ping falla con Err para simular una base de datos caída
+func (m *MockLedgerStore) Ping(_ context.Context) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.Err
+}
+
+// Tamper reemplaza una entrada existente sin recalcular hashes.
+// Sólo existe para simular manipulación en pruebas de integridad.
+func (m *MockLedgerStore) Tamper(index int, entry LedgerEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[index] = entry
+}
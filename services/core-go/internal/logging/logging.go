@@ -0,0 +1,75 @@
+/*
+Logging estructurado del servicio
+
+Implementa:
+- Logs JSON con log/slog
+- Middleware de acceso correlacionado por request_id
+- Logger por request disponible para los handlers
+*/
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// loggerKey es la clave del logger por request en el contexto de gin
+const loggerKey = "logger"
+
+type contextKey struct{}
+
+// New crea un logger que escribe JSON en w
+func New(w io.Writer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, nil))
+}
+
+// Middleware registra una línea por request con método, ruta, status, latencia
+// y request_id, y deja en el contexto un logger con el request_id ya asociado.
+// Debe registrarse después del middleware que genera el request_id.
+func Middleware(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestLogger := logger.With("request_id", c.GetString("request_id"))
+		c.Set(loggerKey, requestLogger)
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), contextKey{}, requestLogger))
+
+		c.Next()
+
+		level := slog.LevelInfo
+		if c.Writer.Status() >= 500 {
+			level = slog.LevelError
+		}
+		requestLogger.LogAttrs(c.Request.Context(), level, "request",
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", c.Writer.Status()),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client_ip", c.ClientIP()),
+		)
+	}
+}
+
+// FromContext retorna el logger del request, o el logger por defecto si el
+// middleware no está registrado
+func FromContext(c *gin.Context) *slog.Logger {
+	if value, ok := c.Get(loggerKey); ok {
+		if logger, ok := value.(*slog.Logger); ok {
+			return logger
+		}
+	}
+	return FromStdContext(c.Request.Context())
+}
+
+// FromStdContext retorna el logger guardado en un context.Context, para código
+// que no recibe el contexto de gin
+func FromStdContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
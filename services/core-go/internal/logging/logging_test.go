@@ -0,0 +1,60 @@
+package logging
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMiddlewareLogsRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("request_id", "req-123")
+		c.Header("X-Request-ID", "req-123")
+		c.Next()
+	})
+	router.Use(Middleware(New(&buf)))
+	router.GET("/ping", func(c *gin.Context) {
+		FromContext(c).Info("handler called")
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ping", nil))
+	requestID := w.Header().Get("X-Request-ID")
+
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("log line is not JSON: %s", scanner.Text())
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("got %d log lines, want 2 (handler + access)", len(lines))
+	}
+
+	for _, line := range lines {
+		if line["request_id"] != requestID {
+			t.Errorf("request_id = %v, want %q in %v", line["request_id"], requestID, line)
+		}
+	}
+
+	access := lines[1]
+	if access["method"] != "GET" || access["path"] != "/ping" || access["status"] != float64(http.StatusNoContent) {
+		t.Errorf("access log = %v", access)
+	}
+	if _, ok := access["latency_ms"]; !ok {
+		t.Error("access log must include latency_ms")
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Métricas Prometheus del servicio
+
+Expone:
+- Transacciones procesadas por estado
+- Tamaño de lotes y tiempos de procesamiento
+- Entradas creadas en el ledger
+- Fallos de verificación de tokens de servicio
+- Entregas de webhooks
+*/
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "fincore_core"
+
+var (
+	// TransactionsProcessed cuenta transacciones por estado (completed, rejected)
+	TransactionsProcessed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transactions_processed_total",
+		Help:      "Transactions processed, by resulting status.",
+	}, []string{"status"})
+
+	// BatchSize registra el número de transacciones por lote
+	BatchSize = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "batch_size",
+		Help:      "Number of transactions per batch request.",
+		Buckets:   []float64{1, 10, 50, 100, 250, 500, 1000},
+	})
+
+	// ProcessingDuration mide el tiempo de procesamiento por operación (single, batch)
+	ProcessingDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "processing_duration_seconds",
+		Help:      "Transaction processing time, by operation.",
+		Buckets:   prometheus.ExponentialBuckets(0.0001, 4, 10),
+	}, []string{"operation"})
+
+	// LedgerEntriesCreated cuenta entradas persistidas en el ledger
+	LedgerEntriesCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ledger_entries_created_total",
+		Help:      "Ledger entries appended to the chain.",
+	})
+
+	// TokenVerificationFailures cuenta tokens de servicio rechazados por motivo
+	TokenVerificationFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "token_verification_failures_total",
+		Help:      "Service tokens rejected, by reason.",
+	}, []string{"reason"})
+
+	// WebhookDeliveries cuenta webhooks por resultado (delivered, failed, dropped)
+	WebhookDeliveries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhook_deliveries_total",
+		Help:      "Outgoing webhook deliveries, by result.",
+	}, []string{"result"})
+)
+
+// Motivos de rechazo de tokens de servicio
+const (
+	TokenMissing   = "missing"
+	TokenInvalid   = "invalid"
+	TokenForbidden = "forbidden"
+)
+
+// Resultados de la entrega de webhooks
+const (
+	WebhookDelivered = "delivered"
+	WebhookFailed    = "failed"
+	WebhookDropped   = "dropped"
+)
+
+// NewRegistry crea un registro con los colectores del servicio y los del
+// runtime de Go. Los colectores son globales y pueden registrarse en varios
+// registros (por ejemplo, uno por router en pruebas).
+func NewRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		TransactionsProcessed,
+		BatchSize,
+		ProcessingDuration,
+		LedgerEntriesCreated,
+		TokenVerificationFailures,
+		WebhookDeliveries,
+	)
+	return registry
+}
+
+// Handler expone el registro en formato de texto de Prometheus
+func Handler(registry *prometheus.Registry) http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
@@ -0,0 +1,963 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.33.0
+// 	protoc        v4.25.3
+// source: fincore/core/v1/core.proto
+
+// Servicio gRPC de FinCore Core. Expone los mismos cálculos que los
+// endpoints internos HTTP /api/v1/internal/calculate y
+// /api/v1/internal/validate-transfer, con la misma autenticación por token
+// de servicio (metadata x-service-token o authorization: Bearer).
+//
+// Regenerar desde services/core-go con:
+//   protoc -I proto --go_out=. --go_opt=module=github.com/fincore/core-go \
+//     --go-grpc_out=. --go-grpc_opt=module=github.com/fincore/core-go \
+//     fincore/core/v1/core.proto
+
+package corev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CalculateMetricsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Positiva es una inversión; negativa un ingreso inicial neto (subsidio)
+	InversionInicial float64   `protobuf:"fixed64,1,opt,name=inversion_inicial,json=inversionInicial,proto3" json:"inversion_inicial,omitempty"`
+	FlujosIngresos   []float64 `protobuf:"fixed64,2,rep,packed,name=flujos_ingresos,json=flujosIngresos,proto3" json:"flujos_ingresos,omitempty"`
+	FlujosCostos     []float64 `protobuf:"fixed64,3,rep,packed,name=flujos_costos,json=flujosCostos,proto3" json:"flujos_costos,omitempty"`
+	TasaDescuento    float64   `protobuf:"fixed64,4,opt,name=tasa_descuento,json=tasaDescuento,proto3" json:"tasa_descuento,omitempty"`
+	// Tasas para la TIR modificada; deben enviarse juntas
+	TasaFinanciamiento *float64 `protobuf:"fixed64,5,opt,name=tasa_financiamiento,json=tasaFinanciamiento,proto3,oneof" json:"tasa_financiamiento,omitempty"`
+	TasaReinversion    *float64 `protobuf:"fixed64,6,opt,name=tasa_reinversion,json=tasaReinversion,proto3,oneof" json:"tasa_reinversion,omitempty"`
+	// Rechaza flujos_costos más largos que flujos_ingresos (por defecto true)
+	Strict *bool `protobuf:"varint,7,opt,name=strict,proto3,oneof" json:"strict,omitempty"`
+	// Moneda ISO-4217 opcional de los flujos
+	Currency string `protobuf:"bytes,8,opt,name=currency,proto3" json:"currency,omitempty"`
+	// "fin_periodo" (por defecto) o "mitad_periodo"
+	Convencion string `protobuf:"bytes,9,opt,name=convencion,proto3" json:"convencion,omitempty"`
+	// Decimales del redondeo bancario de los montos (0 a 8)
+	Redondeo *int32 `protobuf:"varint,10,opt,name=redondeo,proto3,oneof" json:"redondeo,omitempty"`
+	// Periodos por año para roi_anualizado (por defecto 12, flujos mensuales)
+	PeriodosPorAnio *int32 `protobuf:"varint,11,opt,name=periodos_por_anio,json=periodosPorAnio,proto3,oneof" json:"periodos_por_anio,omitempty"`
+	// Trata tasa_descuento como nominal anual, capitalizable según
+	// frecuencia_capitalizacion ("anual", "semestral", "trimestral", "mensual"
+	// o "diaria"; por defecto periodos_por_anio)
+	TasaEsAnual              bool   `protobuf:"varint,12,opt,name=tasa_es_anual,json=tasaEsAnual,proto3" json:"tasa_es_anual,omitempty"`
+	FrecuenciaCapitalizacion string `protobuf:"bytes,13,opt,name=frecuencia_capitalizacion,json=frecuenciaCapitalizacion,proto3" json:"frecuencia_capitalizacion,omitempty"`
+}
+
+func (x *CalculateMetricsRequest) Reset() {
+	*x = CalculateMetricsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fincore_core_v1_core_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CalculateMetricsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CalculateMetricsRequest) ProtoMessage() {}
+
+func (x *CalculateMetricsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fincore_core_v1_core_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CalculateMetricsRequest.ProtoReflect.Descriptor instead.
+func (*CalculateMetricsRequest) Descriptor() ([]byte, []int) {
+	return file_fincore_core_v1_core_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CalculateMetricsRequest) GetInversionInicial() float64 {
+	if x != nil {
+		return x.InversionInicial
+	}
+	return 0
+}
+
+func (x *CalculateMetricsRequest) GetFlujosIngresos() []float64 {
+	if x != nil {
+		return x.FlujosIngresos
+	}
+	return nil
+}
+
+func (x *CalculateMetricsRequest) GetFlujosCostos() []float64 {
+	if x != nil {
+		return x.FlujosCostos
+	}
+	return nil
+}
+
+func (x *CalculateMetricsRequest) GetTasaDescuento() float64 {
+	if x != nil {
+		return x.TasaDescuento
+	}
+	return 0
+}
+
+func (x *CalculateMetricsRequest) GetTasaFinanciamiento() float64 {
+	if x != nil && x.TasaFinanciamiento != nil {
+		return *x.TasaFinanciamiento
+	}
+	return 0
+}
+
+func (x *CalculateMetricsRequest) GetTasaReinversion() float64 {
+	if x != nil && x.TasaReinversion != nil {
+		return *x.TasaReinversion
+	}
+	return 0
+}
+
+func (x *CalculateMetricsRequest) GetStrict() bool {
+	if x != nil && x.Strict != nil {
+		return *x.Strict
+	}
+	return false
+}
+
+func (x *CalculateMetricsRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *CalculateMetricsRequest) GetConvencion() string {
+	if x != nil {
+		return x.Convencion
+	}
+	return ""
+}
+
+func (x *CalculateMetricsRequest) GetRedondeo() int32 {
+	if x != nil && x.Redondeo != nil {
+		return *x.Redondeo
+	}
+	return 0
+}
+
+func (x *CalculateMetricsRequest) GetPeriodosPorAnio() int32 {
+	if x != nil && x.PeriodosPorAnio != nil {
+		return *x.PeriodosPorAnio
+	}
+	return 0
+}
+
+func (x *CalculateMetricsRequest) GetTasaEsAnual() bool {
+	if x != nil {
+		return x.TasaEsAnual
+	}
+	return false
+}
+
+func (x *CalculateMetricsRequest) GetFrecuenciaCapitalizacion() string {
+	if x != nil {
+		return x.FrecuenciaCapitalizacion
+	}
+	return ""
+}
+
+type CalculateMetricsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Van float64 `protobuf:"fixed64,1,opt,name=van,proto3" json:"van,omitempty"`
+	Roi float64 `protobuf:"fixed64,2,opt,name=roi,proto3" json:"roi,omitempty"`
+	// Ausentes cuando no están definidas; el motivo va en el campo *_error
+	Tir                    *float64  `protobuf:"fixed64,3,opt,name=tir,proto3,oneof" json:"tir,omitempty"`
+	TirTolerancia          float64   `protobuf:"fixed64,4,opt,name=tir_tolerancia,json=tirTolerancia,proto3" json:"tir_tolerancia,omitempty"`
+	Mirr                   *float64  `protobuf:"fixed64,5,opt,name=mirr,proto3,oneof" json:"mirr,omitempty"`
+	PaybackMeses           float64   `protobuf:"fixed64,6,opt,name=payback_meses,json=paybackMeses,proto3" json:"payback_meses,omitempty"`
+	RecuperaInversion      bool      `protobuf:"varint,7,opt,name=recupera_inversion,json=recuperaInversion,proto3" json:"recupera_inversion,omitempty"`
+	PaybackDescontadoMeses float64   `protobuf:"fixed64,8,opt,name=payback_descontado_meses,json=paybackDescontadoMeses,proto3" json:"payback_descontado_meses,omitempty"`
+	RecuperaDescontado     bool      `protobuf:"varint,9,opt,name=recupera_descontado,json=recuperaDescontado,proto3" json:"recupera_descontado,omitempty"`
+	ProfitabilityIndex     float64   `protobuf:"fixed64,10,opt,name=profitability_index,json=profitabilityIndex,proto3" json:"profitability_index,omitempty"`
+	Eaa                    *float64  `protobuf:"fixed64,11,opt,name=eaa,proto3,oneof" json:"eaa,omitempty"`
+	Convencion             string    `protobuf:"bytes,12,opt,name=convencion,proto3" json:"convencion,omitempty"`
+	EsViable               bool      `protobuf:"varint,13,opt,name=es_viable,json=esViable,proto3" json:"es_viable,omitempty"`
+	FlujosNetos            []float64 `protobuf:"fixed64,14,rep,packed,name=flujos_netos,json=flujosNetos,proto3" json:"flujos_netos,omitempty"`
+	Currency               string    `protobuf:"bytes,15,opt,name=currency,proto3" json:"currency,omitempty"`
+	TirError               string    `protobuf:"bytes,16,opt,name=tir_error,json=tirError,proto3" json:"tir_error,omitempty"`
+	MirrError              string    `protobuf:"bytes,17,opt,name=mirr_error,json=mirrError,proto3" json:"mirr_error,omitempty"`
+	EaaError               string    `protobuf:"bytes,18,opt,name=eaa_error,json=eaaError,proto3" json:"eaa_error,omitempty"`
+	// Presente sólo si la solicitud incluía redondeo
+	Redondeado *MontosRedondeados `protobuf:"bytes,19,opt,name=redondeado,proto3" json:"redondeado,omitempty"`
+	// ROI anual compuesto; ausente con pérdida total (roi <= -1)
+	RoiAnualizado      *float64 `protobuf:"fixed64,20,opt,name=roi_anualizado,json=roiAnualizado,proto3,oneof" json:"roi_anualizado,omitempty"`
+	RoiAnualizadoError string   `protobuf:"bytes,21,opt,name=roi_anualizado_error,json=roiAnualizadoError,proto3" json:"roi_anualizado_error,omitempty"`
+}
+
+func (x *CalculateMetricsResponse) Reset() {
+	*x = CalculateMetricsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fincore_core_v1_core_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CalculateMetricsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CalculateMetricsResponse) ProtoMessage() {}
+
+func (x *CalculateMetricsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fincore_core_v1_core_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CalculateMetricsResponse.ProtoReflect.Descriptor instead.
+func (*CalculateMetricsResponse) Descriptor() ([]byte, []int) {
+	return file_fincore_core_v1_core_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CalculateMetricsResponse) GetVan() float64 {
+	if x != nil {
+		return x.Van
+	}
+	return 0
+}
+
+func (x *CalculateMetricsResponse) GetRoi() float64 {
+	if x != nil {
+		return x.Roi
+	}
+	return 0
+}
+
+func (x *CalculateMetricsResponse) GetTir() float64 {
+	if x != nil && x.Tir != nil {
+		return *x.Tir
+	}
+	return 0
+}
+
+func (x *CalculateMetricsResponse) GetTirTolerancia() float64 {
+	if x != nil {
+		return x.TirTolerancia
+	}
+	return 0
+}
+
+func (x *CalculateMetricsResponse) GetMirr() float64 {
+	if x != nil && x.Mirr != nil {
+		return *x.Mirr
+	}
+	return 0
+}
+
+func (x *CalculateMetricsResponse) GetPaybackMeses() float64 {
+	if x != nil {
+		return x.PaybackMeses
+	}
+	return 0
+}
+
+func (x *CalculateMetricsResponse) GetRecuperaInversion() bool {
+	if x != nil {
+		return x.RecuperaInversion
+	}
+	return false
+}
+
+func (x *CalculateMetricsResponse) GetPaybackDescontadoMeses() float64 {
+	if x != nil {
+		return x.PaybackDescontadoMeses
+	}
+	return 0
+}
+
+func (x *CalculateMetricsResponse) GetRecuperaDescontado() bool {
+	if x != nil {
+		return x.RecuperaDescontado
+	}
+	return false
+}
+
+func (x *CalculateMetricsResponse) GetProfitabilityIndex() float64 {
+	if x != nil {
+		return x.ProfitabilityIndex
+	}
+	return 0
+}
+
+func (x *CalculateMetricsResponse) GetEaa() float64 {
+	if x != nil && x.Eaa != nil {
+		return *x.Eaa
+	}
+	return 0
+}
+
+func (x *CalculateMetricsResponse) GetConvencion() string {
+	if x != nil {
+		return x.Convencion
+	}
+	return ""
+}
+
+func (x *CalculateMetricsResponse) GetEsViable() bool {
+	if x != nil {
+		return x.EsViable
+	}
+	return false
+}
+
+func (x *CalculateMetricsResponse) GetFlujosNetos() []float64 {
+	if x != nil {
+		return x.FlujosNetos
+	}
+	return nil
+}
+
+func (x *CalculateMetricsResponse) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *CalculateMetricsResponse) GetTirError() string {
+	if x != nil {
+		return x.TirError
+	}
+	return ""
+}
+
+func (x *CalculateMetricsResponse) GetMirrError() string {
+	if x != nil {
+		return x.MirrError
+	}
+	return ""
+}
+
+func (x *CalculateMetricsResponse) GetEaaError() string {
+	if x != nil {
+		return x.EaaError
+	}
+	return ""
+}
+
+func (x *CalculateMetricsResponse) GetRedondeado() *MontosRedondeados {
+	if x != nil {
+		return x.Redondeado
+	}
+	return nil
+}
+
+func (x *CalculateMetricsResponse) GetRoiAnualizado() float64 {
+	if x != nil && x.RoiAnualizado != nil {
+		return *x.RoiAnualizado
+	}
+	return 0
+}
+
+func (x *CalculateMetricsResponse) GetRoiAnualizadoError() string {
+	if x != nil {
+		return x.RoiAnualizadoError
+	}
+	return ""
+}
+
+type MontosRedondeados struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Decimales   int32     `protobuf:"varint,1,opt,name=decimales,proto3" json:"decimales,omitempty"`
+	Van         float64   `protobuf:"fixed64,2,opt,name=van,proto3" json:"van,omitempty"`
+	Eaa         *float64  `protobuf:"fixed64,3,opt,name=eaa,proto3,oneof" json:"eaa,omitempty"`
+	FlujosNetos []float64 `protobuf:"fixed64,4,rep,packed,name=flujos_netos,json=flujosNetos,proto3" json:"flujos_netos,omitempty"`
+}
+
+func (x *MontosRedondeados) Reset() {
+	*x = MontosRedondeados{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fincore_core_v1_core_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MontosRedondeados) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MontosRedondeados) ProtoMessage() {}
+
+func (x *MontosRedondeados) ProtoReflect() protoreflect.Message {
+	mi := &file_fincore_core_v1_core_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MontosRedondeados.ProtoReflect.Descriptor instead.
+func (*MontosRedondeados) Descriptor() ([]byte, []int) {
+	return file_fincore_core_v1_core_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *MontosRedondeados) GetDecimales() int32 {
+	if x != nil {
+		return x.Decimales
+	}
+	return 0
+}
+
+func (x *MontosRedondeados) GetVan() float64 {
+	if x != nil {
+		return x.Van
+	}
+	return 0
+}
+
+func (x *MontosRedondeados) GetEaa() float64 {
+	if x != nil && x.Eaa != nil {
+		return *x.Eaa
+	}
+	return 0
+}
+
+func (x *MontosRedondeados) GetFlujosNetos() []float64 {
+	if x != nil {
+		return x.FlujosNetos
+	}
+	return nil
+}
+
+type ValidateTransferRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FromAccount string `protobuf:"bytes,1,opt,name=from_account,json=fromAccount,proto3" json:"from_account,omitempty"`
+	ToAccount   string `protobuf:"bytes,2,opt,name=to_account,json=toAccount,proto3" json:"to_account,omitempty"`
+	// Monto decimal exacto, por ejemplo "1500.00"
+	Amount string `protobuf:"bytes,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	// Moneda ISO-4217 de origen (por defecto DEFAULT_CURRENCY o MXN)
+	Currency string `protobuf:"bytes,4,opt,name=currency,proto3" json:"currency,omitempty"`
+	// Moneda de destino opcional para convertir el monto
+	ToCurrency string `protobuf:"bytes,5,opt,name=to_currency,json=toCurrency,proto3" json:"to_currency,omitempty"`
+}
+
+func (x *ValidateTransferRequest) Reset() {
+	*x = ValidateTransferRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fincore_core_v1_core_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ValidateTransferRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateTransferRequest) ProtoMessage() {}
+
+func (x *ValidateTransferRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fincore_core_v1_core_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateTransferRequest.ProtoReflect.Descriptor instead.
+func (*ValidateTransferRequest) Descriptor() ([]byte, []int) {
+	return file_fincore_core_v1_core_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ValidateTransferRequest) GetFromAccount() string {
+	if x != nil {
+		return x.FromAccount
+	}
+	return ""
+}
+
+func (x *ValidateTransferRequest) GetToAccount() string {
+	if x != nil {
+		return x.ToAccount
+	}
+	return ""
+}
+
+func (x *ValidateTransferRequest) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+func (x *ValidateTransferRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *ValidateTransferRequest) GetToCurrency() string {
+	if x != nil {
+		return x.ToCurrency
+	}
+	return ""
+}
+
+type ValidateTransferResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IsValid     bool                   `protobuf:"varint,1,opt,name=is_valid,json=isValid,proto3" json:"is_valid,omitempty"`
+	Validations []string               `protobuf:"bytes,2,rep,name=validations,proto3" json:"validations,omitempty"`
+	ValidatedAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=validated_at,json=validatedAt,proto3" json:"validated_at,omitempty"`
+	// Sólo cuando se pidió to_currency; rate y converted_amount faltan si no
+	// hay tipo de cambio disponible
+	Currency        string `protobuf:"bytes,4,opt,name=currency,proto3" json:"currency,omitempty"`
+	ToCurrency      string `protobuf:"bytes,5,opt,name=to_currency,json=toCurrency,proto3" json:"to_currency,omitempty"`
+	Rate            string `protobuf:"bytes,6,opt,name=rate,proto3" json:"rate,omitempty"`
+	ConvertedAmount string `protobuf:"bytes,7,opt,name=converted_amount,json=convertedAmount,proto3" json:"converted_amount,omitempty"`
+	// Algún proveedor (saldos, tipos de cambio) no respondió a tiempo
+	Degraded bool `protobuf:"varint,8,opt,name=degraded,proto3" json:"degraded,omitempty"`
+	// El monto supera APPROVAL_THRESHOLD: la transacción quedará pendiente
+	// hasta reunir required_approvals aprobadores
+	RequiresApproval  bool  `protobuf:"varint,9,opt,name=requires_approval,json=requiresApproval,proto3" json:"requires_approval,omitempty"`
+	RequiredApprovals int32 `protobuf:"varint,10,opt,name=required_approvals,json=requiredApprovals,proto3" json:"required_approvals,omitempty"`
+}
+
+func (x *ValidateTransferResponse) Reset() {
+	*x = ValidateTransferResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fincore_core_v1_core_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ValidateTransferResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateTransferResponse) ProtoMessage() {}
+
+func (x *ValidateTransferResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fincore_core_v1_core_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateTransferResponse.ProtoReflect.Descriptor instead.
+func (*ValidateTransferResponse) Descriptor() ([]byte, []int) {
+	return file_fincore_core_v1_core_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ValidateTransferResponse) GetIsValid() bool {
+	if x != nil {
+		return x.IsValid
+	}
+	return false
+}
+
+func (x *ValidateTransferResponse) GetValidations() []string {
+	if x != nil {
+		return x.Validations
+	}
+	return nil
+}
+
+func (x *ValidateTransferResponse) GetValidatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ValidatedAt
+	}
+	return nil
+}
+
+func (x *ValidateTransferResponse) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *ValidateTransferResponse) GetToCurrency() string {
+	if x != nil {
+		return x.ToCurrency
+	}
+	return ""
+}
+
+func (x *ValidateTransferResponse) GetRate() string {
+	if x != nil {
+		return x.Rate
+	}
+	return ""
+}
+
+func (x *ValidateTransferResponse) GetConvertedAmount() string {
+	if x != nil {
+		return x.ConvertedAmount
+	}
+	return ""
+}
+
+func (x *ValidateTransferResponse) GetDegraded() bool {
+	if x != nil {
+		return x.Degraded
+	}
+	return false
+}
+
+func (x *ValidateTransferResponse) GetRequiresApproval() bool {
+	if x != nil {
+		return x.RequiresApproval
+	}
+	return false
+}
+
+func (x *ValidateTransferResponse) GetRequiredApprovals() int32 {
+	if x != nil {
+		return x.RequiredApprovals
+	}
+	return 0
+}
+
+var File_fincore_core_v1_core_proto protoreflect.FileDescriptor
+
+var file_fincore_core_v1_core_proto_rawDesc = []byte{
+	0x0a, 0x1a, 0x66, 0x69, 0x6e, 0x63, 0x6f, 0x72, 0x65, 0x2f, 0x63, 0x6f, 0x72, 0x65, 0x2f, 0x76,
+	0x31, 0x2f, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0f, 0x66, 0x69,
+	0x6e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x1a, 0x1f, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x88,
+	0x05, 0x0a, 0x17, 0x43, 0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x65, 0x4d, 0x65, 0x74, 0x72,
+	0x69, 0x63, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2b, 0x0a, 0x11, 0x69, 0x6e,
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x6e, 0x69, 0x63, 0x69, 0x61, 0x6c, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x10, 0x69, 0x6e, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x49, 0x6e, 0x69, 0x63, 0x69, 0x61, 0x6c, 0x12, 0x27, 0x0a, 0x0f, 0x66, 0x6c, 0x75, 0x6a, 0x6f,
+	0x73, 0x5f, 0x69, 0x6e, 0x67, 0x72, 0x65, 0x73, 0x6f, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x01,
+	0x52, 0x0e, 0x66, 0x6c, 0x75, 0x6a, 0x6f, 0x73, 0x49, 0x6e, 0x67, 0x72, 0x65, 0x73, 0x6f, 0x73,
+	0x12, 0x23, 0x0a, 0x0d, 0x66, 0x6c, 0x75, 0x6a, 0x6f, 0x73, 0x5f, 0x63, 0x6f, 0x73, 0x74, 0x6f,
+	0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x01, 0x52, 0x0c, 0x66, 0x6c, 0x75, 0x6a, 0x6f, 0x73, 0x43,
+	0x6f, 0x73, 0x74, 0x6f, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x74, 0x61, 0x73, 0x61, 0x5f, 0x64, 0x65,
+	0x73, 0x63, 0x75, 0x65, 0x6e, 0x74, 0x6f, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x74,
+	0x61, 0x73, 0x61, 0x44, 0x65, 0x73, 0x63, 0x75, 0x65, 0x6e, 0x74, 0x6f, 0x12, 0x34, 0x0a, 0x13,
+	0x74, 0x61, 0x73, 0x61, 0x5f, 0x66, 0x69, 0x6e, 0x61, 0x6e, 0x63, 0x69, 0x61, 0x6d, 0x69, 0x65,
+	0x6e, 0x74, 0x6f, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x48, 0x00, 0x52, 0x12, 0x74, 0x61, 0x73,
+	0x61, 0x46, 0x69, 0x6e, 0x61, 0x6e, 0x63, 0x69, 0x61, 0x6d, 0x69, 0x65, 0x6e, 0x74, 0x6f, 0x88,
+	0x01, 0x01, 0x12, 0x2e, 0x0a, 0x10, 0x74, 0x61, 0x73, 0x61, 0x5f, 0x72, 0x65, 0x69, 0x6e, 0x76,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x48, 0x01, 0x52, 0x0f,
+	0x74, 0x61, 0x73, 0x61, 0x52, 0x65, 0x69, 0x6e, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x88,
+	0x01, 0x01, 0x12, 0x1b, 0x0a, 0x06, 0x73, 0x74, 0x72, 0x69, 0x63, 0x74, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x08, 0x48, 0x02, 0x52, 0x06, 0x73, 0x74, 0x72, 0x69, 0x63, 0x74, 0x88, 0x01, 0x01, 0x12,
+	0x1a, 0x0a, 0x08, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x1e, 0x0a, 0x0a, 0x63,
+	0x6f, 0x6e, 0x76, 0x65, 0x6e, 0x63, 0x69, 0x6f, 0x6e, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0a, 0x63, 0x6f, 0x6e, 0x76, 0x65, 0x6e, 0x63, 0x69, 0x6f, 0x6e, 0x12, 0x1f, 0x0a, 0x08, 0x72,
+	0x65, 0x64, 0x6f, 0x6e, 0x64, 0x65, 0x6f, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x05, 0x48, 0x03, 0x52,
+	0x08, 0x72, 0x65, 0x64, 0x6f, 0x6e, 0x64, 0x65, 0x6f, 0x88, 0x01, 0x01, 0x12, 0x2f, 0x0a, 0x11,
+	0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x6f, 0x73, 0x5f, 0x70, 0x6f, 0x72, 0x5f, 0x61, 0x6e, 0x69,
+	0x6f, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x05, 0x48, 0x04, 0x52, 0x0f, 0x70, 0x65, 0x72, 0x69, 0x6f,
+	0x64, 0x6f, 0x73, 0x50, 0x6f, 0x72, 0x41, 0x6e, 0x69, 0x6f, 0x88, 0x01, 0x01, 0x12, 0x22, 0x0a,
+	0x0d, 0x74, 0x61, 0x73, 0x61, 0x5f, 0x65, 0x73, 0x5f, 0x61, 0x6e, 0x75, 0x61, 0x6c, 0x18, 0x0c,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x74, 0x61, 0x73, 0x61, 0x45, 0x73, 0x41, 0x6e, 0x75, 0x61,
+	0x6c, 0x12, 0x3b, 0x0a, 0x19, 0x66, 0x72, 0x65, 0x63, 0x75, 0x65, 0x6e, 0x63, 0x69, 0x61, 0x5f,
+	0x63, 0x61, 0x70, 0x69, 0x74, 0x61, 0x6c, 0x69, 0x7a, 0x61, 0x63, 0x69, 0x6f, 0x6e, 0x18, 0x0d,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x18, 0x66, 0x72, 0x65, 0x63, 0x75, 0x65, 0x6e, 0x63, 0x69, 0x61,
+	0x43, 0x61, 0x70, 0x69, 0x74, 0x61, 0x6c, 0x69, 0x7a, 0x61, 0x63, 0x69, 0x6f, 0x6e, 0x42, 0x16,
+	0x0a, 0x14, 0x5f, 0x74, 0x61, 0x73, 0x61, 0x5f, 0x66, 0x69, 0x6e, 0x61, 0x6e, 0x63, 0x69, 0x61,
+	0x6d, 0x69, 0x65, 0x6e, 0x74, 0x6f, 0x42, 0x13, 0x0a, 0x11, 0x5f, 0x74, 0x61, 0x73, 0x61, 0x5f,
+	0x72, 0x65, 0x69, 0x6e, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x42, 0x09, 0x0a, 0x07, 0x5f,
+	0x73, 0x74, 0x72, 0x69, 0x63, 0x74, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x72, 0x65, 0x64, 0x6f, 0x6e,
+	0x64, 0x65, 0x6f, 0x42, 0x14, 0x0a, 0x12, 0x5f, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x6f, 0x73,
+	0x5f, 0x70, 0x6f, 0x72, 0x5f, 0x61, 0x6e, 0x69, 0x6f, 0x22, 0xbf, 0x06, 0x0a, 0x18, 0x43, 0x61,
+	0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x65, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x76, 0x61, 0x6e, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x03, 0x76, 0x61, 0x6e, 0x12, 0x10, 0x0a, 0x03, 0x72, 0x6f, 0x69, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x72, 0x6f, 0x69, 0x12, 0x15, 0x0a, 0x03, 0x74, 0x69,
+	0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x48, 0x00, 0x52, 0x03, 0x74, 0x69, 0x72, 0x88, 0x01,
+	0x01, 0x12, 0x25, 0x0a, 0x0e, 0x74, 0x69, 0x72, 0x5f, 0x74, 0x6f, 0x6c, 0x65, 0x72, 0x61, 0x6e,
+	0x63, 0x69, 0x61, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x74, 0x69, 0x72, 0x54, 0x6f,
+	0x6c, 0x65, 0x72, 0x61, 0x6e, 0x63, 0x69, 0x61, 0x12, 0x17, 0x0a, 0x04, 0x6d, 0x69, 0x72, 0x72,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x48, 0x01, 0x52, 0x04, 0x6d, 0x69, 0x72, 0x72, 0x88, 0x01,
+	0x01, 0x12, 0x23, 0x0a, 0x0d, 0x70, 0x61, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x5f, 0x6d, 0x65, 0x73,
+	0x65, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0c, 0x70, 0x61, 0x79, 0x62, 0x61, 0x63,
+	0x6b, 0x4d, 0x65, 0x73, 0x65, 0x73, 0x12, 0x2d, 0x0a, 0x12, 0x72, 0x65, 0x63, 0x75, 0x70, 0x65,
+	0x72, 0x61, 0x5f, 0x69, 0x6e, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x11, 0x72, 0x65, 0x63, 0x75, 0x70, 0x65, 0x72, 0x61, 0x49, 0x6e, 0x76, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x38, 0x0a, 0x18, 0x70, 0x61, 0x79, 0x62, 0x61, 0x63, 0x6b,
+	0x5f, 0x64, 0x65, 0x73, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x64, 0x6f, 0x5f, 0x6d, 0x65, 0x73, 0x65,
+	0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x01, 0x52, 0x16, 0x70, 0x61, 0x79, 0x62, 0x61, 0x63, 0x6b,
+	0x44, 0x65, 0x73, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x64, 0x6f, 0x4d, 0x65, 0x73, 0x65, 0x73, 0x12,
+	0x2f, 0x0a, 0x13, 0x72, 0x65, 0x63, 0x75, 0x70, 0x65, 0x72, 0x61, 0x5f, 0x64, 0x65, 0x73, 0x63,
+	0x6f, 0x6e, 0x74, 0x61, 0x64, 0x6f, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x52, 0x12, 0x72, 0x65,
+	0x63, 0x75, 0x70, 0x65, 0x72, 0x61, 0x44, 0x65, 0x73, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x64, 0x6f,
+	0x12, 0x2f, 0x0a, 0x13, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x74, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74,
+	0x79, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x01, 0x52, 0x12, 0x70,
+	0x72, 0x6f, 0x66, 0x69, 0x74, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x49, 0x6e, 0x64, 0x65,
+	0x78, 0x12, 0x15, 0x0a, 0x03, 0x65, 0x61, 0x61, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x01, 0x48, 0x02,
+	0x52, 0x03, 0x65, 0x61, 0x61, 0x88, 0x01, 0x01, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x76,
+	0x65, 0x6e, 0x63, 0x69, 0x6f, 0x6e, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x6f,
+	0x6e, 0x76, 0x65, 0x6e, 0x63, 0x69, 0x6f, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x65, 0x73, 0x5f, 0x76,
+	0x69, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x65, 0x73, 0x56,
+	0x69, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x66, 0x6c, 0x75, 0x6a, 0x6f, 0x73, 0x5f,
+	0x6e, 0x65, 0x74, 0x6f, 0x73, 0x18, 0x0e, 0x20, 0x03, 0x28, 0x01, 0x52, 0x0b, 0x66, 0x6c, 0x75,
+	0x6a, 0x6f, 0x73, 0x4e, 0x65, 0x74, 0x6f, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x75, 0x72, 0x72,
+	0x65, 0x6e, 0x63, 0x79, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x75, 0x72, 0x72,
+	0x65, 0x6e, 0x63, 0x79, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x69, 0x72, 0x5f, 0x65, 0x72, 0x72, 0x6f,
+	0x72, 0x18, 0x10, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x69, 0x72, 0x45, 0x72, 0x72, 0x6f,
+	0x72, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x69, 0x72, 0x72, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18,
+	0x11, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6d, 0x69, 0x72, 0x72, 0x45, 0x72, 0x72, 0x6f, 0x72,
+	0x12, 0x1b, 0x0a, 0x09, 0x65, 0x61, 0x61, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x12, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x08, 0x65, 0x61, 0x61, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x42, 0x0a,
+	0x0a, 0x72, 0x65, 0x64, 0x6f, 0x6e, 0x64, 0x65, 0x61, 0x64, 0x6f, 0x18, 0x13, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x22, 0x2e, 0x66, 0x69, 0x6e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x63, 0x6f, 0x72, 0x65,
+	0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x6f, 0x6e, 0x74, 0x6f, 0x73, 0x52, 0x65, 0x64, 0x6f, 0x6e, 0x64,
+	0x65, 0x61, 0x64, 0x6f, 0x73, 0x52, 0x0a, 0x72, 0x65, 0x64, 0x6f, 0x6e, 0x64, 0x65, 0x61, 0x64,
+	0x6f, 0x12, 0x2a, 0x0a, 0x0e, 0x72, 0x6f, 0x69, 0x5f, 0x61, 0x6e, 0x75, 0x61, 0x6c, 0x69, 0x7a,
+	0x61, 0x64, 0x6f, 0x18, 0x14, 0x20, 0x01, 0x28, 0x01, 0x48, 0x03, 0x52, 0x0d, 0x72, 0x6f, 0x69,
+	0x41, 0x6e, 0x75, 0x61, 0x6c, 0x69, 0x7a, 0x61, 0x64, 0x6f, 0x88, 0x01, 0x01, 0x12, 0x30, 0x0a,
+	0x14, 0x72, 0x6f, 0x69, 0x5f, 0x61, 0x6e, 0x75, 0x61, 0x6c, 0x69, 0x7a, 0x61, 0x64, 0x6f, 0x5f,
+	0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x15, 0x20, 0x01, 0x28, 0x09, 0x52, 0x12, 0x72, 0x6f, 0x69,
+	0x41, 0x6e, 0x75, 0x61, 0x6c, 0x69, 0x7a, 0x61, 0x64, 0x6f, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x42,
+	0x06, 0x0a, 0x04, 0x5f, 0x74, 0x69, 0x72, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x6d, 0x69, 0x72, 0x72,
+	0x42, 0x06, 0x0a, 0x04, 0x5f, 0x65, 0x61, 0x61, 0x42, 0x11, 0x0a, 0x0f, 0x5f, 0x72, 0x6f, 0x69,
+	0x5f, 0x61, 0x6e, 0x75, 0x61, 0x6c, 0x69, 0x7a, 0x61, 0x64, 0x6f, 0x22, 0x85, 0x01, 0x0a, 0x11,
+	0x4d, 0x6f, 0x6e, 0x74, 0x6f, 0x73, 0x52, 0x65, 0x64, 0x6f, 0x6e, 0x64, 0x65, 0x61, 0x64, 0x6f,
+	0x73, 0x12, 0x1c, 0x0a, 0x09, 0x64, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x65, 0x73, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x64, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x65, 0x73, 0x12,
+	0x10, 0x0a, 0x03, 0x76, 0x61, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x76, 0x61,
+	0x6e, 0x12, 0x15, 0x0a, 0x03, 0x65, 0x61, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x48, 0x00,
+	0x52, 0x03, 0x65, 0x61, 0x61, 0x88, 0x01, 0x01, 0x12, 0x21, 0x0a, 0x0c, 0x66, 0x6c, 0x75, 0x6a,
+	0x6f, 0x73, 0x5f, 0x6e, 0x65, 0x74, 0x6f, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x01, 0x52, 0x0b,
+	0x66, 0x6c, 0x75, 0x6a, 0x6f, 0x73, 0x4e, 0x65, 0x74, 0x6f, 0x73, 0x42, 0x06, 0x0a, 0x04, 0x5f,
+	0x65, 0x61, 0x61, 0x22, 0xb0, 0x01, 0x0a, 0x17, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65,
+	0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x21, 0x0a, 0x0c, 0x66, 0x72, 0x6f, 0x6d, 0x5f, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x66, 0x72, 0x6f, 0x6d, 0x41, 0x63, 0x63, 0x6f, 0x75,
+	0x6e, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x74, 0x6f, 0x5f, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x74, 0x6f, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e,
+	0x74, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x75, 0x72,
+	0x72, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x75, 0x72,
+	0x72, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x6f, 0x5f, 0x63, 0x75, 0x72, 0x72,
+	0x65, 0x6e, 0x63, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x74, 0x6f, 0x43, 0x75,
+	0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x22, 0x8a, 0x03, 0x0a, 0x18, 0x56, 0x61, 0x6c, 0x69, 0x64,
+	0x61, 0x74, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x69, 0x73, 0x5f, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x69, 0x73, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x12, 0x20,
+	0x0a, 0x0b, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x0b, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x12, 0x3d, 0x0a, 0x0c, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x52, 0x0b, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12,
+	0x1a, 0x0a, 0x08, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x1f, 0x0a, 0x0b, 0x74,
+	0x6f, 0x5f, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0a, 0x74, 0x6f, 0x43, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x12, 0x0a, 0x04,
+	0x72, 0x61, 0x74, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x72, 0x61, 0x74, 0x65,
+	0x12, 0x29, 0x0a, 0x10, 0x63, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x6d,
+	0x6f, 0x75, 0x6e, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x63, 0x6f, 0x6e, 0x76,
+	0x65, 0x72, 0x74, 0x65, 0x64, 0x41, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x64,
+	0x65, 0x67, 0x72, 0x61, 0x64, 0x65, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x64,
+	0x65, 0x67, 0x72, 0x61, 0x64, 0x65, 0x64, 0x12, 0x2b, 0x0a, 0x11, 0x72, 0x65, 0x71, 0x75, 0x69,
+	0x72, 0x65, 0x73, 0x5f, 0x61, 0x70, 0x70, 0x72, 0x6f, 0x76, 0x61, 0x6c, 0x18, 0x09, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x10, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x73, 0x41, 0x70, 0x70, 0x72,
+	0x6f, 0x76, 0x61, 0x6c, 0x12, 0x2d, 0x0a, 0x12, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64,
+	0x5f, 0x61, 0x70, 0x70, 0x72, 0x6f, 0x76, 0x61, 0x6c, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x11, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x41, 0x70, 0x70, 0x72, 0x6f, 0x76,
+	0x61, 0x6c, 0x73, 0x32, 0xdf, 0x01, 0x0a, 0x0b, 0x43, 0x6f, 0x72, 0x65, 0x53, 0x65, 0x72, 0x76,
+	0x69, 0x63, 0x65, 0x12, 0x67, 0x0a, 0x10, 0x43, 0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x65,
+	0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x12, 0x28, 0x2e, 0x66, 0x69, 0x6e, 0x63, 0x6f, 0x72,
+	0x65, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6c, 0x63, 0x75, 0x6c,
+	0x61, 0x74, 0x65, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x29, 0x2e, 0x66, 0x69, 0x6e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x63, 0x6f, 0x72, 0x65,
+	0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6c, 0x63, 0x75, 0x6c, 0x61, 0x74, 0x65, 0x4d, 0x65, 0x74,
+	0x72, 0x69, 0x63, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x67, 0x0a, 0x10,
+	0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72,
+	0x12, 0x28, 0x2e, 0x66, 0x69, 0x6e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e,
+	0x76, 0x31, 0x2e, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73,
+	0x66, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x29, 0x2e, 0x66, 0x69, 0x6e,
+	0x63, 0x6f, 0x72, 0x65, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x61, 0x6c,
+	0x69, 0x64, 0x61, 0x74, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x36, 0x5a, 0x34, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e,
+	0x63, 0x6f, 0x6d, 0x2f, 0x66, 0x69, 0x6e, 0x63, 0x6f, 0x72, 0x65, 0x2f, 0x63, 0x6f, 0x72, 0x65,
+	0x2d, 0x67, 0x6f, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x70, 0x62, 0x2f,
+	0x63, 0x6f, 0x72, 0x65, 0x76, 0x31, 0x3b, 0x63, 0x6f, 0x72, 0x65, 0x76, 0x31, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_fincore_core_v1_core_proto_rawDescOnce sync.Once
+	file_fincore_core_v1_core_proto_rawDescData = file_fincore_core_v1_core_proto_rawDesc
+)
+
+func file_fincore_core_v1_core_proto_rawDescGZIP() []byte {
+	file_fincore_core_v1_core_proto_rawDescOnce.Do(func() {
+		file_fincore_core_v1_core_proto_rawDescData = protoimpl.X.CompressGZIP(file_fincore_core_v1_core_proto_rawDescData)
+	})
+	return file_fincore_core_v1_core_proto_rawDescData
+}
+
+var file_fincore_core_v1_core_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_fincore_core_v1_core_proto_goTypes = []interface{}{
+	(*CalculateMetricsRequest)(nil),  // 0: fincore.core.v1.CalculateMetricsRequest
+	(*CalculateMetricsResponse)(nil), // 1: fincore.core.v1.CalculateMetricsResponse
+	(*MontosRedondeados)(nil),        // 2: fincore.core.v1.MontosRedondeados
+	(*ValidateTransferRequest)(nil),  // 3: fincore.core.v1.ValidateTransferRequest
+	(*ValidateTransferResponse)(nil), // 4: fincore.core.v1.ValidateTransferResponse
+	(*timestamppb.Timestamp)(nil),    // 5: google.protobuf.Timestamp
+}
+var file_fincore_core_v1_core_proto_depIdxs = []int32{
+	2, // 0: fincore.core.v1.CalculateMetricsResponse.redondeado:type_name -> fincore.core.v1.MontosRedondeados
+	5, // 1: fincore.core.v1.ValidateTransferResponse.validated_at:type_name -> google.protobuf.Timestamp
+	0, // 2: fincore.core.v1.CoreService.CalculateMetrics:input_type -> fincore.core.v1.CalculateMetricsRequest
+	3, // 3: fincore.core.v1.CoreService.ValidateTransfer:input_type -> fincore.core.v1.ValidateTransferRequest
+	1, // 4: fincore.core.v1.CoreService.CalculateMetrics:output_type -> fincore.core.v1.CalculateMetricsResponse
+	4, // 5: fincore.core.v1.CoreService.ValidateTransfer:output_type -> fincore.core.v1.ValidateTransferResponse
+	4, // [4:6] is the sub-list for method output_type
+	2, // [2:4] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_fincore_core_v1_core_proto_init() }
+func file_fincore_core_v1_core_proto_init() {
+	if File_fincore_core_v1_core_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_fincore_core_v1_core_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CalculateMetricsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fincore_core_v1_core_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CalculateMetricsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fincore_core_v1_core_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MontosRedondeados); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fincore_core_v1_core_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ValidateTransferRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fincore_core_v1_core_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ValidateTransferResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_fincore_core_v1_core_proto_msgTypes[0].OneofWrappers = []interface{}{}
+	file_fincore_core_v1_core_proto_msgTypes[1].OneofWrappers = []interface{}{}
+	file_fincore_core_v1_core_proto_msgTypes[2].OneofWrappers = []interface{}{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_fincore_core_v1_core_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_fincore_core_v1_core_proto_goTypes,
+		DependencyIndexes: file_fincore_core_v1_core_proto_depIdxs,
+		MessageInfos:      file_fincore_core_v1_core_proto_msgTypes,
+	}.Build()
+	File_fincore_core_v1_core_proto = out.File
+	file_fincore_core_v1_core_proto_rawDesc = nil
+	file_fincore_core_v1_core_proto_goTypes = nil
+	file_fincore_core_v1_core_proto_depIdxs = nil
+}
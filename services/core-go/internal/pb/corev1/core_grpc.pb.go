@@ -0,0 +1,164 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.25.3
+// source: fincore/core/v1/core.proto
+
+// Servicio gRPC de FinCore Core. Expone los mismos cálculos que los
+// endpoints internos HTTP /api/v1/internal/calculate y
+// /api/v1/internal/validate-transfer, con la misma autenticación por token
+// de servicio (metadata x-service-token o authorization: Bearer).
+//
+// Regenerar desde services/core-go con:
+//   protoc -I proto --go_out=. --go_opt=module=github.com/fincore/core-go \
+//     --go-grpc_out=. --go-grpc_opt=module=github.com/fincore/core-go \
+//     fincore/core/v1/core.proto
+
+package corev1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	CoreService_CalculateMetrics_FullMethodName = "/fincore.core.v1.CoreService/CalculateMetrics"
+	CoreService_ValidateTransfer_FullMethodName = "/fincore.core.v1.CoreService/ValidateTransfer"
+)
+
+// CoreServiceClient is the client API for CoreService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CoreServiceClient interface {
+	// CalculateMetrics calcula VAN, ROI, TIR, MIRR, payback, índice de
+	// rentabilidad y EAA. Requiere el permiso calculate:metrics.
+	CalculateMetrics(ctx context.Context, in *CalculateMetricsRequest, opts ...grpc.CallOption) (*CalculateMetricsResponse, error)
+	// ValidateTransfer valida una transferencia antes de ejecutarla. Requiere
+	// el permiso validate:transfers.
+	ValidateTransfer(ctx context.Context, in *ValidateTransferRequest, opts ...grpc.CallOption) (*ValidateTransferResponse, error)
+}
+
+type coreServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCoreServiceClient(cc grpc.ClientConnInterface) CoreServiceClient {
+	return &coreServiceClient{cc}
+}
+
+func (c *coreServiceClient) CalculateMetrics(ctx context.Context, in *CalculateMetricsRequest, opts ...grpc.CallOption) (*CalculateMetricsResponse, error) {
+	out := new(CalculateMetricsResponse)
+	err := c.cc.Invoke(ctx, CoreService_CalculateMetrics_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreServiceClient) ValidateTransfer(ctx context.Context, in *ValidateTransferRequest, opts ...grpc.CallOption) (*ValidateTransferResponse, error) {
+	out := new(ValidateTransferResponse)
+	err := c.cc.Invoke(ctx, CoreService_ValidateTransfer_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CoreServiceServer is the server API for CoreService service.
+// All implementations must embed UnimplementedCoreServiceServer
+// for forward compatibility
+type CoreServiceServer interface {
+	// CalculateMetrics calcula VAN, ROI, TIR, MIRR, payback, índice de
+	// rentabilidad y EAA. Requiere el permiso calculate:metrics.
+	CalculateMetrics(context.Context, *CalculateMetricsRequest) (*CalculateMetricsResponse, error)
+	// ValidateTransfer valida una transferencia antes de ejecutarla. Requiere
+	// el permiso validate:transfers.
+	ValidateTransfer(context.Context, *ValidateTransferRequest) (*ValidateTransferResponse, error)
+	mustEmbedUnimplementedCoreServiceServer()
+}
+
+// UnimplementedCoreServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedCoreServiceServer struct {
+}
+
+func (UnimplementedCoreServiceServer) CalculateMetrics(context.Context, *CalculateMetricsRequest) (*CalculateMetricsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CalculateMetrics not implemented")
+}
+func (UnimplementedCoreServiceServer) ValidateTransfer(context.Context, *ValidateTransferRequest) (*ValidateTransferResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidateTransfer not implemented")
+}
+func (UnimplementedCoreServiceServer) mustEmbedUnimplementedCoreServiceServer() {}
+
+// UnsafeCoreServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CoreServiceServer will
+// result in compilation errors.
+type UnsafeCoreServiceServer interface {
+	mustEmbedUnimplementedCoreServiceServer()
+}
+
+func RegisterCoreServiceServer(s grpc.ServiceRegistrar, srv CoreServiceServer) {
+	s.RegisterService(&CoreService_ServiceDesc, srv)
+}
+
+func _CoreService_CalculateMetrics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CalculateMetricsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).CalculateMetrics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_CalculateMetrics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).CalculateMetrics(ctx, req.(*CalculateMetricsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreService_ValidateTransfer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateTransferRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreServiceServer).ValidateTransfer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoreService_ValidateTransfer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreServiceServer).ValidateTransfer(ctx, req.(*ValidateTransferRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CoreService_ServiceDesc is the grpc.ServiceDesc for CoreService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CoreService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "fincore.core.v1.CoreService",
+	HandlerType: (*CoreServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CalculateMetrics",
+			Handler:    _CoreService_CalculateMetrics_Handler,
+		},
+		{
+			MethodName: "ValidateTransfer",
+			Handler:    _CoreService_ValidateTransfer_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "fincore/core/v1/core.proto",
+}
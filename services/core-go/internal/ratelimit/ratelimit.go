@@ -0,0 +1,122 @@
+/*
+Rate limiting por cliente con token bucket
+
+Implementa:
+- Un token bucket (golang.org/x/time/rate) por clave (usuario o servicio)
+- Store acotado con expulsión LRU
+- Middleware de gin que responde 429 con Retry-After
+*/
+package ratelimit
+
+import (
+	"container/list"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fincore/core-go/internal/apierror"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+)
+
+// DefaultMaxKeys limita cuántos clientes se rastrean a la vez
+const DefaultMaxKeys = 10000
+
+// Store mantiene un limitador por clave. Cuando se llena expulsa la clave
+// usada hace más tiempo, cuyo bucket vuelve a empezar lleno si reaparece.
+type Store struct {
+	mu      sync.Mutex
+	limit   rate.Limit
+	burst   int
+	maxKeys int
+	entries map[string]*list.Element
+	order   *list.List
+	now     func() time.Time
+}
+
+type storeEntry struct {
+	key     string
+	limiter *rate.Limiter
+}
+
+// NewStore crea un store con perSecond solicitudes por segundo, ráfagas de
+// hasta burst y como máximo maxKeys clientes (DefaultMaxKeys si es <= 0)
+func NewStore(perSecond float64, burst, maxKeys int) *Store {
+	if maxKeys <= 0 {
+		maxKeys = DefaultMaxKeys
+	}
+	return &Store{
+		limit:   rate.Limit(perSecond),
+		burst:   burst,
+		maxKeys: maxKeys,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		now:     time.Now,
+	}
+}
+
+// Allow consume un token de la clave. Si no hay tokens retorna false y cuánto
+// esperar antes de reintentar.
+func (s *Store) Allow(key string) (bool, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	reservation := s.limiterFor(key).ReserveN(now, 1)
+	if !reservation.OK() {
+		return false, time.Second
+	}
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// Len retorna cuántas claves se están rastreando
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+func (s *Store) limiterFor(key string) *rate.Limiter {
+	if element, ok := s.entries[key]; ok {
+		s.order.MoveToFront(element)
+		return element.Value.(*storeEntry).limiter
+	}
+
+	if s.order.Len() >= s.maxKeys {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*storeEntry).key)
+	}
+
+	entry := &storeEntry{key: key, limiter: rate.NewLimiter(s.limit, s.burst)}
+	s.entries[key] = s.order.PushFront(entry)
+	return entry.limiter
+}
+
+// KeyFunc extrae la clave de rate limiting del request
+type KeyFunc func(c *gin.Context) string
+
+// Middleware rechaza con 429 los requests que exceden el límite de su clave
+func Middleware(store *Store, keyFunc KeyFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		allowed, retryAfter := store.Allow(keyFunc(c))
+		if !allowed {
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			if seconds < 1 {
+				seconds = 1
+			}
+			c.Header("Retry-After", strconv.Itoa(seconds))
+			apierror.RespondWith(c, http.StatusTooManyRequests, apierror.RateLimited, "Rate limit exceeded", gin.H{
+				"retry_after": seconds,
+			})
+			return
+		}
+		c.Next()
+	}
+}
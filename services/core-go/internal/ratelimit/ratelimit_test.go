@@ -0,0 +1,85 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTestStore(perSecond float64, burst, maxKeys int) (*Store, *time.Time) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewStore(perSecond, burst, maxKeys)
+	store.now = func() time.Time { return now }
+	return store, &now
+}
+
+func TestStoreRejectsAfterBurst(t *testing.T) {
+	store, now := newTestStore(1, 3, 0)
+
+	for i := 1; i <= 3; i++ {
+		if ok, _ := store.Allow("user:1"); !ok {
+			t.Fatalf("request %d within burst must be allowed", i)
+		}
+	}
+	ok, retryAfter := store.Allow("user:1")
+	if ok {
+		t.Fatal("4th request within the window must be rejected")
+	}
+	if retryAfter <= 0 || retryAfter > time.Second {
+		t.Errorf("retryAfter = %v, want (0, 1s]", retryAfter)
+	}
+
+	// Otras claves tienen su propio bucket
+	if ok, _ := store.Allow("user:2"); !ok {
+		t.Error("a different key must not be limited")
+	}
+
+	// Tras esperar se repone un token
+	*now = now.Add(time.Second)
+	if ok, _ := store.Allow("user:1"); !ok {
+		t.Error("request after refill must be allowed")
+	}
+}
+
+func TestStoreIsBounded(t *testing.T) {
+	store, _ := newTestStore(1, 1, 2)
+	for _, key := range []string{"a", "b", "c"} {
+		store.Allow(key)
+	}
+	if n := store.Len(); n != 2 {
+		t.Errorf("Len = %d, want 2", n)
+	}
+
+	// "a" fue expulsada por ser la menos reciente y empieza con bucket lleno
+	if ok, _ := store.Allow("a"); !ok {
+		t.Error("evicted key must start with a full bucket")
+	}
+	if ok, _ := store.Allow("c"); ok {
+		t.Error("tracked key must keep its exhausted bucket")
+	}
+}
+
+func TestMiddlewareReturns429WithRetryAfter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store, _ := newTestStore(0.5, 2, 0)
+
+	router := gin.New()
+	router.Use(Middleware(store, func(c *gin.Context) string { return "service:backend" }))
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	var w *httptest.ResponseRecorder
+	for i := 0; i < 3; i++ {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("3rd status = %d, want 429", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %q, want 2", got)
+	}
+}
@@ -0,0 +1,78 @@
+package security
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// ErrContextMismatch indica que el ciphertext no corresponde al contexto dado
+// (o fue alterado)
+var ErrContextMismatch = errors.New("decryption failed: context mismatch or tampered ciphertext")
+
+// EncryptWithContext cifra con XChaCha20-Poly1305 ligando el ciphertext a
+// context (por ejemplo "user:123" o el tipo de registro), de modo que no pueda
+// moverse a otro registro: DecryptWithContext falla si el contexto difiere.
+// El contexto no se guarda en el ciphertext; quien descifra debe conocerlo.
+func (sm *SecurityManager) EncryptWithContext(plaintext, context []byte) (string, error) {
+	activeHeader, key := sm.keyring.activeKey()
+	aead, err := chacha20poly1305.NewX(key[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to initialize cipher: %w", err)
+	}
+
+	header := activeHeader.encode(ciphertextFormatAEADKDF)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	encrypted := append(header, nonce...)
+	encrypted = aead.Seal(encrypted, nonce, plaintext, associatedData(header, context))
+	return base64.StdEncoding.EncodeToString(encrypted), nil
+}
+
+// DecryptWithContext descifra un ciphertext de EncryptWithContext verificando
+// que se cifró con el mismo contexto
+func (sm *SecurityManager) DecryptWithContext(ciphertext string, context []byte) ([]byte, error) {
+	encrypted, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode ciphertext: %w", err)
+	}
+
+	parsed, rest, ok := parseKeyHeader(encrypted, ciphertextFormatAEAD, ciphertextFormatAEADKDF)
+	if !ok {
+		return nil, errors.New("invalid ciphertext format")
+	}
+	key, found := sm.keyring.key(parsed)
+	if !found {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKeyID, parsed.id)
+	}
+
+	aead, err := chacha20poly1305.NewX(key[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cipher: %w", err)
+	}
+	if len(rest) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	header := encrypted[:len(encrypted)-len(rest)]
+	nonce, sealed := rest[:aead.NonceSize()], rest[aead.NonceSize():]
+	decrypted, err := aead.Open(nil, nonce, sealed, associatedData(header, context))
+	if err != nil {
+		return nil, ErrContextMismatch
+	}
+	return decrypted, nil
+}
+
+// associatedData autentica la cabecera junto al contexto para que tampoco se
+// pueda cambiar el id de clave
+func associatedData(header, context []byte) []byte {
+	data := make([]byte, 0, len(header)+len(context))
+	data = append(data, header...)
+	return append(data, context...)
+}
@@ -0,0 +1,183 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fincore/core-go/internal/canonical"
+)
+
+// Tipos de evento de auditoría de seguridad
+const (
+	AuditTokenVerificationFailed = "token_verification_failed"
+	AuditTokenRevoked            = "token_revoked"
+	AuditDecryptionFailed        = "decryption_failed"
+)
+
+// DefaultMaxAuditRecords es la cantidad de registros que AuditLogger conserva
+// en memoria; los más antiguos se descartan pero siguen en los logs
+const DefaultMaxAuditRecords = 10000
+
+// AuditGenesisHash es el PreviousHash del primer registro de auditoría
+var AuditGenesisHash = strings.Repeat("0", 64)
+
+// ErrAuditChainBroken indica que la cadena de auditoría fue alterada
+var ErrAuditChainBroken = errors.New("audit chain broken")
+
+// AuditRecord es un evento de seguridad encadenado con el registro anterior
+type AuditRecord struct {
+	Sequence     int64             `json:"sequence"`
+	Event        string            `json:"event"`
+	Details      map[string]string `json:"details,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+	PreviousHash string            `json:"previous_hash"`
+	Hash         string            `json:"hash"`
+}
+
+// AuditLogger agrega registros de auditoría encadenados por HMAC, igual que
+// el ledger, de modo que modificar, insertar o reordenar un registro rompe la
+// cadena. Cada registro también se escribe en el log estructurado para que
+// sobreviva a un reinicio.
+type AuditLogger struct {
+	mu         sync.Mutex
+	key        []byte
+	records    []AuditRecord
+	maxRecords int
+	lastHash   string
+	sequence   int64
+	now        func() time.Time
+}
+
+// NewAuditLogger crea un logger cuyos hashes se firman con key. maxRecords
+// <= 0 usa DefaultMaxAuditRecords.
+func NewAuditLogger(key []byte, maxRecords int) *AuditLogger {
+	if maxRecords <= 0 {
+		maxRecords = DefaultMaxAuditRecords
+	}
+	return &AuditLogger{
+		key:        key,
+		maxRecords: maxRecords,
+		lastHash:   AuditGenesisHash,
+		now:        time.Now,
+	}
+}
+
+// Record agrega un evento al final de la cadena y retorna el registro creado
+func (a *AuditLogger) Record(event string, details map[string]string) AuditRecord {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.sequence++
+	record := AuditRecord{
+		Sequence:     a.sequence,
+		Event:        event,
+		Details:      copyDetails(details),
+		CreatedAt:    a.now().UTC(),
+		PreviousHash: a.lastHash,
+	}
+	record.Hash = a.hashRecord(record)
+	a.lastHash = record.Hash
+
+	a.records = append(a.records, record)
+	if len(a.records) > a.maxRecords {
+		a.records = a.records[len(a.records)-a.maxRecords:]
+	}
+
+	slog.Warn("security audit event",
+		"sequence", record.Sequence,
+		"event", record.Event,
+		"details", record.Details,
+		"hash", record.Hash,
+	)
+	return record
+}
+
+// Records retorna una copia de los registros conservados en memoria
+func (a *AuditLogger) Records() []AuditRecord {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	records := make([]AuditRecord, len(a.records))
+	for i, record := range a.records {
+		record.Details = copyDetails(record.Details)
+		records[i] = record
+	}
+	return records
+}
+
+// VerifyAuditChain recalcula el hash de cada registro y comprueba que apunte
+// al anterior sin saltos de secuencia. Una cadena que empieza en la
+// secuencia 1 debe partir de AuditGenesisHash; si los registros más antiguos
+// ya se descartaron, la verificación se ancla en el primero recibido.
+func (a *AuditLogger) VerifyAuditChain(records []AuditRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	previousHash := records[0].PreviousHash
+	if records[0].Sequence == 1 {
+		previousHash = AuditGenesisHash
+	}
+	previousSequence := records[0].Sequence - 1
+
+	for _, record := range records {
+		if expected := previousSequence + 1; record.Sequence != expected {
+			return fmt.Errorf("%w: record %d is missing", ErrAuditChainBroken, expected)
+		}
+		if record.PreviousHash != previousHash {
+			return fmt.Errorf("%w: record %d: previous hash does not match prior record", ErrAuditChainBroken, record.Sequence)
+		}
+		if !hmac.Equal([]byte(a.hashRecord(record)), []byte(record.Hash)) {
+			return fmt.Errorf("%w: record %d: hash mismatch", ErrAuditChainBroken, record.Sequence)
+		}
+		previousHash = record.Hash
+		previousSequence = record.Sequence
+	}
+	return nil
+}
+
+// auditKey deriva la clave de la cadena de auditoría a partir de SECRET_KEY,
+// separada de la que firma tokens
+func auditKey(secretKey []byte) []byte {
+	mac := hmac.New(sha256.New, secretKey)
+	mac.Write([]byte("fincore-security-audit"))
+	return mac.Sum(nil)
+}
+
+// hashRecord calcula el HMAC-SHA256 de los campos canónicos del registro
+func (a *AuditLogger) hashRecord(record AuditRecord) string {
+	details := make(map[string]interface{}, len(record.Details))
+	for k, v := range record.Details {
+		details[k] = v
+	}
+	data, _ := canonical.Marshal(map[string]interface{}{
+		"sequence":      record.Sequence,
+		"event":         record.Event,
+		"details":       details,
+		"created_at":    record.CreatedAt.UTC().Format(time.RFC3339Nano),
+		"previous_hash": record.PreviousHash,
+	})
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// copyDetails copia los detalles; un mapa vacío se guarda como nil para que el
+// hash no cambie al serializar el registro con omitempty
+func copyDetails(details map[string]string) map[string]string {
+	if len(details) == 0 {
+		return nil
+	}
+	copied := make(map[string]string, len(details))
+	for k, v := range details {
+		copied[k] = v
+	}
+	return copied
+}
@@ -0,0 +1,100 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Límites por defecto de MemoryDeviceStore
+const (
+	DefaultMaxDeviceUsers    = 100000
+	DefaultMaxDevicesPerUser = 5
+)
+
+// ErrDeviceStoreFull indica que no hay lugar para un usuario nuevo. No se
+// descarta a otro usuario: al volver confiaría en el primer dispositivo que
+// se presente, que podría ser el de quien forzó el descarte.
+var ErrDeviceStoreFull = errors.New("device store full")
+
+// DeviceStore guarda los fingerprints de dispositivo conocidos por usuario.
+// Bind debe ser atómico para que dos requests concurrentes del primer
+// dispositivo no registren dos fingerprints distintos.
+type DeviceStore interface {
+	// Bind registra fingerprint si el usuario aún no tiene dispositivos y
+	// retorna si el fingerprint es conocido (o se acaba de registrar)
+	Bind(ctx context.Context, userID, fingerprint string) (bool, error)
+	// Remember agrega fingerprint a los dispositivos conocidos del usuario
+	Remember(ctx context.Context, userID, fingerprint string) error
+}
+
+// MemoryDeviceStore implementa DeviceStore en memoria con capacidad acotada:
+// un máximo de usuarios y de dispositivos por usuario. Al registrar un
+// dispositivo más se olvida el más antiguo del usuario.
+type MemoryDeviceStore struct {
+	mu                sync.Mutex
+	maxUsers          int
+	maxDevicesPerUser int
+	// devices guarda los fingerprints de cada usuario en orden de registro
+	devices map[string][]string
+}
+
+// NewMemoryDeviceStore crea un store de dispositivos vacío; los límites no
+// positivos usan DefaultMaxDeviceUsers y DefaultMaxDevicesPerUser
+func NewMemoryDeviceStore(maxUsers, maxDevicesPerUser int) *MemoryDeviceStore {
+	if maxUsers <= 0 {
+		maxUsers = DefaultMaxDeviceUsers
+	}
+	if maxDevicesPerUser <= 0 {
+		maxDevicesPerUser = DefaultMaxDevicesPerUser
+	}
+	return &MemoryDeviceStore{
+		maxUsers:          maxUsers,
+		maxDevicesPerUser: maxDevicesPerUser,
+		devices:           make(map[string][]string),
+	}
+}
+
+// Bind confía en el primer dispositivo de cada usuario; los siguientes solo
+// son conocidos si se registraron con Remember
+func (s *MemoryDeviceStore) Bind(_ context.Context, userID, fingerprint string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	known, ok := s.devices[userID]
+	if !ok {
+		if len(s.devices) >= s.maxUsers {
+			return false, ErrDeviceStoreFull
+		}
+		s.devices[userID] = []string{fingerprint}
+		return true, nil
+	}
+	for _, device := range known {
+		if device == fingerprint {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Remember registra fingerprint como dispositivo conocido del usuario
+func (s *MemoryDeviceStore) Remember(_ context.Context, userID, fingerprint string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	known, ok := s.devices[userID]
+	if !ok && len(s.devices) >= s.maxUsers {
+		return ErrDeviceStoreFull
+	}
+	for _, device := range known {
+		if device == fingerprint {
+			return nil
+		}
+	}
+	known = append(known, fingerprint)
+	if len(known) > s.maxDevicesPerUser {
+		known = known[len(known)-s.maxDevicesPerUser:]
+	}
+	s.devices[userID] = known
+	return nil
+}
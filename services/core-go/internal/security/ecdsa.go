@@ -0,0 +1,131 @@
+package security
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// ErrECDSAKeyNotConfigured indica que se pidió firmar o verificar un token
+// ES256 sin la clave correspondiente
+var ErrECDSAKeyNotConfigured = errors.New("ECDSA key not configured")
+
+// SetECDSAKeys configura las claves P-256 de los tokens ES256. El emisor pasa
+// la privada (la pública se deriva si public es nil); un servicio que sólo
+// verifica pasa private nil y la pública. Debe llamarse antes de emitir o
+// verificar tokens.
+func (sm *SecurityManager) SetECDSAKeys(private *ecdsa.PrivateKey, public *ecdsa.PublicKey) error {
+	if public == nil && private != nil {
+		public = &private.PublicKey
+	}
+	if public == nil {
+		return errors.New("an ECDSA public or private key is required")
+	}
+	if public.Curve != elliptic.P256() {
+		return errors.New("ECDSA keys must use curve P-256")
+	}
+	if private != nil && !private.PublicKey.Equal(public) {
+		return errors.New("ECDSA public key does not match private key")
+	}
+
+	sm.ecPrivateKey = private
+	sm.ecPublicKey = public
+	return nil
+}
+
+// ParseECDSAPrivateKeyPEM lee una clave privada EC en PEM (SEC 1 o PKCS#8)
+func ParseECDSAPrivateKeyPEM(data []byte) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid PEM data")
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ECDSA private key: %w", err)
+	}
+	key, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not ECDSA")
+	}
+	return key, nil
+}
+
+// ParseECDSAPublicKeyPEM lee una clave pública EC en PEM (PKIX)
+func ParseECDSAPublicKeyPEM(data []byte) (*ecdsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid PEM data")
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ECDSA public key: %w", err)
+	}
+	key, ok := parsed.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.New("public key is not ECDSA")
+	}
+	return key, nil
+}
+
+// GenerateServiceTokenEC genera un token de servicio firmado con la clave
+// privada ECDSA, verificable con sólo la clave pública y reutilizable hasta
+// su expiración
+func (sm *SecurityManager) GenerateServiceTokenEC(source, target string, permissions []string, ttlSeconds int) (string, error) {
+	return sm.issueServiceToken(source, target, permissions, ttlSeconds, TokenAlgES256, false)
+}
+
+// VerifyServiceTokenEC verifica un token ES256 con la clave pública y consume
+// su nonce como VerifyServiceToken. Rechaza los tokens HS256 aunque su HMAC
+// sea válido, para usarse donde no se debe confiar en SECRET_KEY.
+func (sm *SecurityManager) VerifyServiceTokenEC(token string) (*ServiceTokenClaims, error) {
+	claims, alg, err := sm.parseServiceTokenEnvelope(token)
+	if err == nil && alg != TokenAlgES256 {
+		err = fmt.Errorf("token algorithm %q is not %s", alg, TokenAlgES256)
+	}
+	if err == nil {
+		err = sm.consumeNonce(claims)
+	}
+	if err != nil {
+		sm.auditTokenFailure(claims, err)
+		return nil, err
+	}
+	return claims, nil
+}
+
+// signES256 firma el SHA-256 de los claims; la firma va en ASN.1 DER codificada en hex
+func (sm *SecurityManager) signES256(claimsJSON []byte) (string, error) {
+	if sm.ecPrivateKey == nil {
+		return "", ErrECDSAKeyNotConfigured
+	}
+	digest := sha256.Sum256(claimsJSON)
+	signature, err := ecdsa.SignASN1(rand.Reader, sm.ecPrivateKey, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return hex.EncodeToString(signature), nil
+}
+
+// verifyES256 verifica una firma de signES256 con la clave pública
+func (sm *SecurityManager) verifyES256(claimsJSON []byte, signature string) error {
+	if sm.ecPublicKey == nil {
+		return ErrECDSAKeyNotConfigured
+	}
+	raw, err := hex.DecodeString(signature)
+	if err != nil {
+		return errors.New("invalid signature")
+	}
+	digest := sha256.Sum256(claimsJSON)
+	if !ecdsa.VerifyASN1(sm.ecPublicKey, digest[:], raw) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
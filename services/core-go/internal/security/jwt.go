@@ -0,0 +1,173 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// jwtHeader es el header fijo de los JWT emitidos; sólo se acepta HS256 para
+// que un token no pueda elegir "none" u otro algoritmo
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// jwtClaims son los claims estándar más permissions
+type jwtClaims struct {
+	Issuer      string      `json:"iss"`
+	Audience    jwtAudience `json:"aud"`
+	ExpiresAt   int64       `json:"exp"`
+	IssuedAt    int64       `json:"iat"`
+	TokenID     string      `json:"jti,omitempty"`
+	Permissions []string    `json:"permissions"`
+}
+
+// jwtAudience acepta aud como string o como lista (RFC 7519 §4.1.3)
+type jwtAudience []string
+
+func (a *jwtAudience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = jwtAudience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return errors.New("aud must be a string or a list of strings")
+	}
+	*a = list
+	return nil
+}
+
+func (a jwtAudience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+// IsJWT indica si el token tiene la forma header.payload.signature de un JWT;
+// los tokens de envelope propio son base64 estándar, que no incluye puntos
+func IsJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// GenerateJWT emite un JWT HS256 firmado con SECRET_KEY para clientes que
+// hablan JWT estándar. source y target van en iss y aud.
+func (sm *SecurityManager) GenerateJWT(source, target string, permissions []string, ttlSeconds int) (string, error) {
+	now := sm.now()
+	claims := jwtClaims{
+		Issuer:      source,
+		Audience:    jwtAudience{target},
+		ExpiresAt:   now.Add(time.Duration(ttlSeconds) * time.Second).Unix(),
+		IssuedAt:    now.Unix(),
+		TokenID:     uuid.New().String(),
+		Permissions: permissions,
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal claims: %w", err)
+	}
+
+	signingInput := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signingInput + "." + sm.signJWT(signingInput), nil
+}
+
+// VerifyJWT verifica firma, vigencia (con la tolerancia de reloj), revocación
+// y que audience esté en aud. Los claims se retornan como ServiceTokenClaims
+// para autorizar igual que los tokens propios. A diferencia de ellos un JWT
+// no lleva nonce y puede usarse hasta su expiración. Cada fallo queda en el
+// log de auditoría.
+func (sm *SecurityManager) VerifyJWT(token, audience string) (*ServiceTokenClaims, error) {
+	claims, err := sm.parseJWT(token, audience)
+	if err != nil {
+		sm.auditTokenFailure(claims, err)
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (sm *SecurityManager) parseJWT(token, audience string) (*ServiceTokenClaims, error) {
+	if audience == "" {
+		return nil, errors.New("expected audience is required")
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("invalid JWT format")
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT header encoding: %w", err)
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, fmt.Errorf("invalid JWT header: %w", err)
+	}
+	if header.Alg != TokenAlgHS256 {
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", header.Alg)
+	}
+
+	signingInput := parts[0] + "." + parts[1]
+	if !sm.matchesSigningKey(parts[2], func(key []byte) string { return signJWTWith(key, signingInput) }) {
+		return nil, errors.New("invalid signature")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT payload encoding: %w", err)
+	}
+	var parsed jwtClaims
+	if err := json.Unmarshal(payload, &parsed); err != nil {
+		return nil, fmt.Errorf("invalid claims format: %w", err)
+	}
+
+	expiresAt := time.Unix(parsed.ExpiresAt, 0)
+	issuedAt := time.Unix(parsed.IssuedAt, 0)
+	claims := &ServiceTokenClaims{
+		Source:      parsed.Issuer,
+		Permissions: parsed.Permissions,
+		IssuedAt:    issuedAt.UTC().Format(time.RFC3339),
+		ExpiresAt:   expiresAt.UTC().Format(time.RFC3339),
+		TokenID:     parsed.TokenID,
+	}
+
+	now := sm.now()
+	if parsed.ExpiresAt == 0 || now.After(expiresAt.Add(sm.leeway)) {
+		return claims, ErrTokenExpired
+	}
+	if issuedAt.After(now.Add(sm.leeway)) {
+		return claims, errors.New("token issued in the future")
+	}
+	if parsed.TokenID != "" && sm.IsTokenRevoked(parsed.TokenID) {
+		return claims, ErrTokenRevoked
+	}
+
+	for _, aud := range parsed.Audience {
+		if SecureCompare(aud, audience) {
+			claims.Target = aud
+			return claims, nil
+		}
+	}
+	return claims, fmt.Errorf("token audience %q does not include %q", []string(parsed.Audience), audience)
+}
+
+// signJWT calcula la firma HS256 en base64url sin padding con la clave primaria
+func (sm *SecurityManager) signJWT(signingInput string) string {
+	return signJWTWith(sm.signingKeys.primaryKey(), signingInput)
+}
+
+// signJWTWith calcula la firma HS256 de signingInput con la clave dada
+func signJWTWith(key []byte, signingInput string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(signingInput))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
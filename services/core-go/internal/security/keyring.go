@@ -0,0 +1,252 @@
+package security
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// DefaultEncryptionKeyID identifica la clave derivada de ENCRYPTION_KEY
+// cuando ENCRYPTION_KEY_ID no está configurada
+const DefaultEncryptionKeyID = "v1"
+
+// Formatos de ciphertext. Los formatos 0x01 y 0x02 llevan cabecera
+// [formato][len(id)][id] y usan la clave sin Argon2 (SHA-256 o cruda); los
+// formatos 0x03 y 0x04 agregan la versión de derivación:
+// [formato][kdf][len(id)][id]
+const (
+	// ciphertextFormatKeyring: cabecera + [nonce][secretbox]
+	ciphertextFormatKeyring byte = 0x01
+	// ciphertextFormatAEAD: cabecera + [nonce][XChaCha20-Poly1305], con la
+	// cabecera y el contexto como datos asociados
+	ciphertextFormatAEAD byte = 0x02
+	// ciphertextFormatKeyringKDF: como 0x01 con versión de derivación
+	ciphertextFormatKeyringKDF byte = 0x03
+	// ciphertextFormatAEADKDF: como 0x02 con versión de derivación
+	ciphertextFormatAEADKDF byte = 0x04
+)
+
+// Versiones de derivación de clave guardadas en el ciphertext
+const (
+	// kdfRaw: clave de 32 bytes registrada con AddKey, sin derivar
+	kdfRaw byte = 0x00
+	// kdfSHA256: SHA-256 de ENCRYPTION_KEY (datos anteriores a Argon2)
+	kdfSHA256 byte = 0x01
+	// kdfArgon2id: Argon2id de ENCRYPTION_KEY con ENCRYPTION_KEY_SALT
+	kdfArgon2id byte = 0x02
+)
+
+// Parámetros de Argon2id (RFC 9106, segunda opción recomendada)
+const (
+	argon2Time    = 3
+	argon2Memory  = 64 * 1024 // KiB
+	argon2Threads = 4
+	// defaultKDFSalt se usa si ENCRYPTION_KEY_SALT no está configurada
+	defaultKDFSalt = "fincore-core-go-encryption-kdf-v2"
+	minKDFSaltLen  = 16
+)
+
+// ErrUnknownKeyID indica que el ciphertext fue cifrado con una clave que no
+// está en el keyring
+var ErrUnknownKeyID = errors.New("unknown encryption key id")
+
+// keyEntry guarda una clave en cada versión de derivación disponible
+type keyEntry struct {
+	versions map[byte][32]byte
+	// current es la versión con la que se cifran datos nuevos
+	current byte
+}
+
+// legacyVersion es la versión implícita en ciphertexts sin byte de derivación
+func (e keyEntry) legacyVersion() byte {
+	if _, ok := e.versions[kdfSHA256]; ok {
+		return kdfSHA256
+	}
+	return kdfRaw
+}
+
+// keyring guarda las claves de cifrado actuales e históricas. Cifrar siempre
+// usa la clave activa; descifrar elige la clave por el id de la cabecera.
+type keyring struct {
+	mu       sync.RWMutex
+	keys     map[string]keyEntry
+	activeID string
+	// legacyID es la clave inicial, usada para ciphertexts sin cabecera
+	legacyID string
+}
+
+func newKeyring(id string, entry keyEntry) *keyring {
+	return &keyring{
+		keys:     map[string]keyEntry{id: entry},
+		activeID: id,
+		legacyID: id,
+	}
+}
+
+// derivePassphraseKey deriva la clave inicial de ENCRYPTION_KEY en ambas
+// versiones: SHA-256 para descifrar datos antiguos y Argon2id para datos nuevos
+func derivePassphraseKey(passphrase, salt string) keyEntry {
+	legacy := sha256.Sum256([]byte(passphrase))
+
+	var derived [32]byte
+	copy(derived[:], argon2.IDKey([]byte(passphrase), []byte(salt), argon2Time, argon2Memory, argon2Threads, 32))
+
+	return keyEntry{
+		versions: map[byte][32]byte{kdfSHA256: legacy, kdfArgon2id: derived},
+		current:  kdfArgon2id,
+	}
+}
+
+// encryptionKeyID retorna el id de la clave inicial desde el entorno
+func encryptionKeyID() string {
+	if id := os.Getenv("ENCRYPTION_KEY_ID"); id != "" {
+		return id
+	}
+	return DefaultEncryptionKeyID
+}
+
+// encryptionKeySalt retorna la sal de Argon2id desde el entorno
+func encryptionKeySalt() (string, error) {
+	salt := os.Getenv("ENCRYPTION_KEY_SALT")
+	if salt == "" {
+		return defaultKDFSalt, nil
+	}
+	if len(salt) < minKDFSaltLen {
+		return "", fmt.Errorf("ENCRYPTION_KEY_SALT must be at least %d characters", minKDFSaltLen)
+	}
+	return salt, nil
+}
+
+func validateKeyID(id string) error {
+	if id == "" || len(id) > 255 {
+		return fmt.Errorf("key id must be between 1 and 255 bytes, got %d", len(id))
+	}
+	return nil
+}
+
+// AddKey registra una clave en el keyring sin activarla. Reemplazar una clave
+// existente deja ilegibles los datos cifrados con ella, por eso se rechaza.
+func (sm *SecurityManager) AddKey(id string, key [32]byte) error {
+	if err := validateKeyID(id); err != nil {
+		return err
+	}
+
+	sm.keyring.mu.Lock()
+	defer sm.keyring.mu.Unlock()
+	if _, exists := sm.keyring.keys[id]; exists {
+		return fmt.Errorf("key id %q already registered", id)
+	}
+	sm.keyring.keys[id] = keyEntry{versions: map[byte][32]byte{kdfRaw: key}, current: kdfRaw}
+	return nil
+}
+
+// SetActiveKey selecciona la clave con la que se cifran los datos nuevos
+func (sm *SecurityManager) SetActiveKey(id string) error {
+	sm.keyring.mu.Lock()
+	defer sm.keyring.mu.Unlock()
+	if _, ok := sm.keyring.keys[id]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownKeyID, id)
+	}
+	sm.keyring.activeID = id
+	return nil
+}
+
+// ActiveKeyID retorna el id de la clave activa
+func (sm *SecurityManager) ActiveKeyID() string {
+	sm.keyring.mu.RLock()
+	defer sm.keyring.mu.RUnlock()
+	return sm.keyring.activeID
+}
+
+// activeKey retorna la clave activa en su versión de derivación actual
+func (k *keyring) activeKey() (keyHeader, [32]byte) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	entry := k.keys[k.activeID]
+	return keyHeader{id: k.activeID, kdf: entry.current}, entry.versions[entry.current]
+}
+
+// key busca la clave indicada por la cabecera
+func (k *keyring) key(header keyHeader) ([32]byte, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	entry, ok := k.keys[header.id]
+	if !ok {
+		return [32]byte{}, false
+	}
+
+	kdf := header.kdf
+	if !header.hasKDF() {
+		kdf = entry.legacyVersion()
+	}
+	key, ok := entry.versions[kdf]
+	return key, ok
+}
+
+// legacyKey retorna la clave inicial para ciphertexts sin cabecera
+func (k *keyring) legacyKey() [32]byte {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	entry := k.keys[k.legacyID]
+	return entry.versions[entry.legacyVersion()]
+}
+
+// keyHeader identifica la clave con la que se cifró un ciphertext
+type keyHeader struct {
+	format byte
+	kdf    byte
+	id     string
+}
+
+// hasKDF indica si el formato incluye la versión de derivación
+func (h keyHeader) hasKDF() bool {
+	return h.format == ciphertextFormatKeyringKDF || h.format == ciphertextFormatAEADKDF
+}
+
+// parseKeyHeader separa la cabecera del resto del ciphertext si el formato
+// es uno de los aceptados
+func parseKeyHeader(data []byte, formats ...byte) (header keyHeader, rest []byte, ok bool) {
+	if len(data) < 2 {
+		return keyHeader{}, nil, false
+	}
+	header.format = data[0]
+	accepted := false
+	for _, format := range formats {
+		accepted = accepted || header.format == format
+	}
+	if !accepted {
+		return keyHeader{}, nil, false
+	}
+
+	offset := 1
+	if header.hasKDF() {
+		header.kdf = data[offset]
+		offset++
+	}
+	if len(data) <= offset {
+		return keyHeader{}, nil, false
+	}
+	idLen := int(data[offset])
+	offset++
+	if idLen == 0 || len(data) < offset+idLen {
+		return keyHeader{}, nil, false
+	}
+	header.id = string(data[offset : offset+idLen])
+	return header, data[offset+idLen:], true
+}
+
+// encode serializa la cabecera con el formato dado
+func (h keyHeader) encode(format byte) []byte {
+	h.format = format
+	encoded := make([]byte, 0, 3+len(h.id))
+	encoded = append(encoded, format)
+	if h.hasKDF() {
+		encoded = append(encoded, h.kdf)
+	}
+	encoded = append(encoded, byte(len(h.id)))
+	return append(encoded, h.id...)
+}
@@ -0,0 +1,74 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/fincore/core-go/internal/canonical"
+)
+
+// ErrInvalidReceipt indica que la firma no corresponde al payload del comprobante
+var ErrInvalidReceipt = errors.New("invalid receipt signature")
+
+// Receipt es el comprobante firmado de una operación. Payload es el JSON
+// canónico (canonical.Marshal) de lo procesado y se firma tal cual, por lo que
+// debe verificarse sin volver a serializarlo.
+type Receipt struct {
+	Payload   string `json:"payload"`
+	Algorithm string `json:"alg"`
+	Signature string `json:"signature"`
+}
+
+// GenerateReceipt firma la forma canónica de data. Con clave privada ECDSA la
+// firma es ES256 y un tercero puede verificarla con sólo la clave pública; si
+// no, es un HMAC-SHA256 con una clave derivada de la de integridad, que sólo
+// este servicio puede verificar.
+func (sm *SecurityManager) GenerateReceipt(data interface{}) (Receipt, error) {
+	payload, err := canonical.Marshal(data)
+	if err != nil {
+		return Receipt{}, fmt.Errorf("failed to serialize receipt: %w", err)
+	}
+
+	if sm.ecPrivateKey != nil {
+		signature, err := sm.signES256(payload)
+		if err != nil {
+			return Receipt{}, err
+		}
+		return Receipt{Payload: string(payload), Algorithm: TokenAlgES256, Signature: signature}, nil
+	}
+	return Receipt{Payload: string(payload), Algorithm: TokenAlgHS256, Signature: sm.signReceiptHMAC(payload)}, nil
+}
+
+// VerifyReceipt verifica la firma del comprobante y retorna ErrInvalidReceipt
+// si el payload o la firma fueron alterados. Los comprobantes ES256 se
+// verifican con la clave pública configurada en SetECDSAKeys.
+func (sm *SecurityManager) VerifyReceipt(receipt Receipt) error {
+	payload := []byte(receipt.Payload)
+	switch receipt.Algorithm {
+	case TokenAlgHS256:
+		if !hmac.Equal([]byte(sm.signReceiptHMAC(payload)), []byte(receipt.Signature)) {
+			return ErrInvalidReceipt
+		}
+		return nil
+	case TokenAlgES256:
+		if err := sm.verifyES256(payload, receipt.Signature); err != nil {
+			if errors.Is(err, ErrECDSAKeyNotConfigured) {
+				return err
+			}
+			return ErrInvalidReceipt
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported receipt algorithm %q", receipt.Algorithm)
+	}
+}
+
+// signReceiptHMAC calcula la firma HS256 de un comprobante
+func (sm *SecurityManager) signReceiptHMAC(payload []byte) string {
+	mac := hmac.New(sha256.New, deriveSubkey(sm.integrityKey, "fincore-receipt"))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,109 @@
+package security
+
+import (
+	"container/heap"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNonceCacheFull indica que no se puede registrar el nonce sin perder
+// protección contra replay; se rechaza el request en lugar de aceptarlo a ciegas
+var ErrNonceCacheFull = errors.New("nonce cache full")
+
+// NonceCache recuerda los nonces ya vistos hasta su expiración con capacidad
+// acotada. Al llenarse descarta todos los vencidos; si aun así no hay lugar,
+// Add falla con ErrNonceCacheFull en lugar de olvidar un nonce vigente, de
+// modo que la capacidad debe cubrir los requests esperados dentro de la
+// ventana de aceptación. Es seguro para uso concurrente.
+type NonceCache struct {
+	mu       sync.Mutex
+	capacity int
+	index    map[string]*nonceEntry
+	// expiries tiene en la raíz el nonce que vence primero
+	expiries nonceHeap
+}
+
+type nonceEntry struct {
+	nonce     string
+	expiresAt time.Time
+	position  int
+}
+
+// NewNonceCache crea un cache vacío; una capacidad no positiva usa
+// MaxTrackedNonces
+func NewNonceCache(capacity int) *NonceCache {
+	if capacity <= 0 {
+		capacity = MaxTrackedNonces
+	}
+	return &NonceCache{capacity: capacity, index: make(map[string]*nonceEntry)}
+}
+
+// Add registra el nonce hasta expiresAt; retorna false si ya estaba vigente,
+// es decir, si el request es un replay, y ErrNonceCacheFull si el cache está
+// lleno de nonces vigentes
+func (c *NonceCache) Add(nonce string, expiresAt, now time.Time) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.index[nonce]; ok {
+		if now.Before(entry.expiresAt) {
+			return false, nil
+		}
+		entry.expiresAt = expiresAt
+		heap.Fix(&c.expiries, entry.position)
+		return true, nil
+	}
+
+	if len(c.index) >= c.capacity {
+		c.evictExpired(now)
+		if len(c.index) >= c.capacity {
+			return false, ErrNonceCacheFull
+		}
+	}
+	entry := &nonceEntry{nonce: nonce, expiresAt: expiresAt}
+	heap.Push(&c.expiries, entry)
+	c.index[nonce] = entry
+	return true, nil
+}
+
+// Len retorna cuántos nonces hay en el cache, vencidos o no
+func (c *NonceCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.index)
+}
+
+// evictExpired descarta todos los nonces vencidos
+func (c *NonceCache) evictExpired(now time.Time) {
+	for len(c.expiries) > 0 && !now.Before(c.expiries[0].expiresAt) {
+		entry := heap.Pop(&c.expiries).(*nonceEntry)
+		delete(c.index, entry.nonce)
+	}
+}
+
+// nonceHeap implementa heap.Interface ordenado por expiración
+type nonceHeap []*nonceEntry
+
+func (h nonceHeap) Len() int           { return len(h) }
+func (h nonceHeap) Less(i, j int) bool { return h[i].expiresAt.Before(h[j].expiresAt) }
+
+func (h nonceHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].position = i
+	h[j].position = j
+}
+
+func (h *nonceHeap) Push(x interface{}) {
+	entry := x.(*nonceEntry)
+	entry.position = len(*h)
+	*h = append(*h, entry)
+}
+
+func (h *nonceHeap) Pop() interface{} {
+	old := *h
+	entry := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return entry
+}
@@ -2,34 +2,84 @@
 Módulo de Seguridad de Grado Militar para Go
 
 Implementa:
-- Cifrado con libsodium (NaCl)
-- Verificación de tokens Zero Trust
+- Cifrado con libsodium (NaCl) y rotación de claves
+- Cifrado AEAD ligado a contexto (XChaCha20-Poly1305)
+- Verificación de tokens Zero Trust (HMAC o ECDSA)
 - Device Fingerprinting
 - Integridad de datos con HMAC
 */
 package security
 
 import (
+	"crypto/ecdsa"
 	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash"
+	"log/slog"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/fincore/core-go/internal/canonical"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/nacl/secretbox"
 )
 
 // SecurityManager maneja todas las operaciones de seguridad
 type SecurityManager struct {
-	secretKey    []byte
-	encryptKey   [32]byte
+	// signingKeys contiene SECRET_KEY, que firma los tokens de servicio, y
+	// las claves reemplazadas que aún verifican
+	signingKeys *signingKeys
+	// integrityKey firma los HMAC de integridad de transacciones y ledger;
+	// separada de secretKey para rotarlas de forma independiente
+	integrityKey []byte
+	keyring      *keyring
 	vaultEnabled bool
+
+	// revokedTokens contiene los TokenID revocados hasta su expiración
+	revokedTokens *expiringSet
+	// seenNonces contiene los nonces ya presentados hasta la expiración de su token
+	seenNonces *NonceCache
+	now        func() time.Time
+
+	// leeway es la tolerancia de desfase de reloj entre servicios al validar
+	// la expiración y la emisión de los tokens
+	leeway time.Duration
+
+	// tokenAlg es el algoritmo HMAC con el que GenerateServiceToken firma
+	tokenAlg string
+
+	// audit registra los eventos de seguridad en una cadena verificable
+	audit *AuditLogger
+
+	// Claves ECDSA P-256 de los tokens ES256; un servicio que sólo verifica
+	// configura únicamente la pública
+	ecPrivateKey *ecdsa.PrivateKey
+	ecPublicKey  *ecdsa.PublicKey
+}
+
+// DefaultTokenLeeway es la tolerancia de desfase de reloj por defecto
+const DefaultTokenLeeway = 30 * time.Second
+
+// MaxServiceTokenTTL es la vida máxima en segundos de un token emitido por
+// MintServiceToken o renovado con RefreshServiceToken
+const MaxServiceTokenTTL = 3600
+
+// SetTokenLeeway configura la tolerancia de desfase de reloj; los valores
+// negativos se tratan como cero. Debe llamarse antes de verificar tokens.
+func (sm *SecurityManager) SetTokenLeeway(leeway time.Duration) {
+	if leeway < 0 {
+		leeway = 0
+	}
+	sm.leeway = leeway
 }
 
 // ServiceTokenClaims contiene los claims de un token de servicio
@@ -40,6 +90,27 @@ type ServiceTokenClaims struct {
 	IssuedAt    string   `json:"iat"`
 	ExpiresAt   string   `json:"exp"`
 	TokenID     string   `json:"token_id"`
+	// Nonce es opcional (GenerateSingleUseServiceToken); si está presente el
+	// token sólo puede usarse una vez
+	Nonce string `json:"nonce,omitempty"`
+}
+
+// PermissionWildcard otorga todos los permisos. Un permiso "accion:*" otorga
+// todos los recursos de esa acción.
+const PermissionWildcard = "*"
+
+// HasPermission indica si los claims incluyen el permiso, directamente o vía comodín
+func (c *ServiceTokenClaims) HasPermission(permission string) bool {
+	for _, granted := range c.Permissions {
+		if granted == PermissionWildcard || granted == permission {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(granted, ":"+PermissionWildcard); ok &&
+			strings.HasPrefix(permission, prefix+":") {
+			return true
+		}
+	}
+	return false
 }
 
 // ErrMissingSecretKey indica que SECRET_KEY no está configurada
@@ -48,19 +119,84 @@ var ErrMissingSecretKey = errors.New("SECRET_KEY environment variable is require
 // ErrMissingEncryptionKey indica que ENCRYPTION_KEY no está configurada
 var ErrMissingEncryptionKey = errors.New("ENCRYPTION_KEY environment variable is required")
 
+// ErrMissingIntegrityKey indica que INTEGRITY_KEY no está configurada
+var ErrMissingIntegrityKey = errors.New("INTEGRITY_KEY environment variable is required")
+
+// ErrDevKeyInProduction indica que se configuró una clave de desarrollo en producción
+var ErrDevKeyInProduction = errors.New("development keys are not allowed in production")
+
+// Claves de desarrollo usadas sólo cuando FINCORE_ENV=development o test y
+// las variables de entorno no están configuradas. Nunca se aceptan en producción.
+const (
+	devSecretKey     = "fincore-default-key-change-in-production"
+	devEncryptionKey = "fincore-dev-encryption-key-32byt"
+)
+
+// isProductionEnv indica si el proceso corre en producción
+func isProductionEnv() bool {
+	return os.Getenv("FINCORE_ENV") == "production" || os.Getenv("GIN_MODE") == "release"
+}
+
+// allowsDevKeys indica si se permiten las claves de desarrollo. Requiere un
+// entorno de desarrollo explícito para que un despliegue mal configurado no
+// arranque con claves conocidas.
+func allowsDevKeys() bool {
+	if isProductionEnv() {
+		return false
+	}
+	env := os.Getenv("FINCORE_ENV")
+	return env == "development" || env == "test"
+}
+
 // NewSecurityManager crea una nueva instancia del manager de seguridad
-// IMPORTANTE: Requiere SECRET_KEY y ENCRYPTION_KEY en variables de entorno
+// IMPORTANTE: Requiere SECRET_KEY, ENCRYPTION_KEY e INTEGRITY_KEY en variables
+// de entorno. INTEGRITY_KEY también puede leerse de INTEGRITY_KEY_FILE (por
+// ejemplo, un secreto de Vault renderizado por Vault Agent).
+// Sólo con FINCORE_ENV=development o test se usan claves de desarrollo si faltan
+// y la clave de integridad cae a SECRET_KEY; en producción (FINCORE_ENV=production
+// o GIN_MODE=release) las claves de desarrollo se rechazan aunque se configuren
+// explícitamente.
+//
+// Los hashes de integridad existentes se calcularon con SECRET_KEY: para que
+// sigan verificando, configurar primero INTEGRITY_KEY con el valor actual de
+// SECRET_KEY y rotar después SECRET_KEY por separado.
 func NewSecurityManager() (*SecurityManager, error) {
 	secretKey := os.Getenv("SECRET_KEY")
+	encryptKey := os.Getenv("ENCRYPTION_KEY")
+	integrityKey, err := integrityKeyFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	if allowsDevKeys() {
+		if secretKey == "" {
+			slog.Warn("SECRET_KEY not set, using development key")
+			secretKey = devSecretKey
+		}
+		if encryptKey == "" {
+			slog.Warn("ENCRYPTION_KEY not set, using development key")
+			encryptKey = devEncryptionKey
+		}
+		if integrityKey == "" {
+			slog.Warn("INTEGRITY_KEY not set, using SECRET_KEY for integrity hashes")
+			integrityKey = secretKey
+		}
+	}
+
 	if secretKey == "" {
 		return nil, ErrMissingSecretKey
 	}
-
-	encryptKey := os.Getenv("ENCRYPTION_KEY")
 	if encryptKey == "" {
 		return nil, ErrMissingEncryptionKey
 	}
 
+	if isProductionEnv() && (secretKey == devSecretKey || encryptKey == devEncryptionKey || integrityKey == devSecretKey) {
+		return nil, ErrDevKeyInProduction
+	}
+	if integrityKey == "" {
+		return nil, ErrMissingIntegrityKey
+	}
+
 	// Validar longitud mínima de las claves (32 caracteres)
 	if len(secretKey) < 32 {
 		return nil, errors.New("SECRET_KEY must be at least 32 characters")
@@ -68,19 +204,49 @@ func NewSecurityManager() (*SecurityManager, error) {
 	if len(encryptKey) < 32 {
 		return nil, errors.New("ENCRYPTION_KEY must be at least 32 characters")
 	}
+	if len(integrityKey) < 32 {
+		return nil, errors.New("INTEGRITY_KEY must be at least 32 characters")
+	}
 
-	// Derivar clave de 32 bytes
-	var key [32]byte
-	h := sha256.Sum256([]byte(encryptKey))
-	copy(key[:], h[:])
+	// Derivar clave de 32 bytes con Argon2id (y SHA-256 para datos antiguos)
+	salt, err := encryptionKeySalt()
+	if err != nil {
+		return nil, err
+	}
+	key := derivePassphraseKey(encryptKey, salt)
 
 	return &SecurityManager{
-		secretKey:    []byte(secretKey),
-		encryptKey:   key,
+		signingKeys:  newSigningKeys([]byte(secretKey)),
+		integrityKey: []byte(integrityKey),
+		keyring:      newKeyring(encryptionKeyID(), key),
 		vaultEnabled: os.Getenv("VAULT_ADDR") != "",
+
+		revokedTokens: newExpiringSet(),
+		seenNonces:    NewNonceCache(MaxTrackedNonces),
+		now:           time.Now,
+		leeway:        DefaultTokenLeeway,
+		tokenAlg:      TokenAlgHS256,
+		audit:         NewAuditLogger(auditKey([]byte(secretKey)), DefaultMaxAuditRecords),
 	}, nil
 }
 
+// integrityKeyFromEnv lee INTEGRITY_KEY o, si no está, el archivo indicado
+// por INTEGRITY_KEY_FILE
+func integrityKeyFromEnv() (string, error) {
+	if key := os.Getenv("INTEGRITY_KEY"); key != "" {
+		return key, nil
+	}
+	path := os.Getenv("INTEGRITY_KEY_FILE")
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read INTEGRITY_KEY_FILE: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
 // MustNewSecurityManager crea un SecurityManager o hace panic si falla
 // Usar solo en inicialización de la aplicación
 func MustNewSecurityManager() *SecurityManager {
@@ -91,12 +257,19 @@ func MustNewSecurityManager() *SecurityManager {
 	return sm
 }
 
+// Audit retorna el logger de auditoría de los eventos de seguridad
+func (sm *SecurityManager) Audit() *AuditLogger {
+	return sm.audit
+}
+
 // GenerateRequestID genera un ID único para cada request
 func (sm *SecurityManager) GenerateRequestID() string {
 	return uuid.New().String()
 }
 
-// Encrypt cifra datos usando NaCl secretbox (XSalsa20-Poly1305)
+// Encrypt cifra datos usando NaCl secretbox (XSalsa20-Poly1305) con la clave
+// activa del keyring. El ciphertext lleva el id de la clave para poder
+// descifrarlo después de rotarla.
 func (sm *SecurityManager) Encrypt(plaintext []byte) (string, error) {
 	// Generar nonce aleatorio de 24 bytes
 	var nonce [24]byte
@@ -105,20 +278,57 @@ func (sm *SecurityManager) Encrypt(plaintext []byte) (string, error) {
 	}
 
 	// Cifrar
-	encrypted := secretbox.Seal(nonce[:], plaintext, &nonce, &sm.encryptKey)
+	header, key := sm.keyring.activeKey()
+	encrypted := append(header.encode(ciphertextFormatKeyringKDF), nonce[:]...)
+	encrypted = secretbox.Seal(encrypted, plaintext, &nonce, &key)
 
 	// Retornar como base64
 	return base64.StdEncoding.EncodeToString(encrypted), nil
 }
 
-// Decrypt descifra datos
+// Decrypt descifra datos con la clave indicada en la cabecera. Los ciphertexts
+// sin cabecera (anteriores al keyring) se descifran con la clave inicial.
+// Cada fallo queda en el log de auditoría.
 func (sm *SecurityManager) Decrypt(ciphertext string) ([]byte, error) {
+	decrypted, err := sm.decrypt(ciphertext)
+	if err != nil {
+		sm.audit.Record(AuditDecryptionFailed, map[string]string{"reason": err.Error()})
+	}
+	return decrypted, err
+}
+
+func (sm *SecurityManager) decrypt(ciphertext string) ([]byte, error) {
 	// Decodificar base64
 	encrypted, err := base64.StdEncoding.DecodeString(ciphertext)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode ciphertext: %w", err)
 	}
 
+	if header, rest, ok := parseKeyHeader(encrypted, ciphertextFormatKeyring, ciphertextFormatKeyringKDF); ok {
+		key, found := sm.keyring.key(header)
+		if found {
+			if decrypted, err := openSecretbox(rest, &key); err == nil {
+				return decrypted, nil
+			}
+		}
+		// Un nonce legado puede empezar por el byte de formato; sólo en ese
+		// caso se intenta el formato sin cabecera antes de fallar
+		legacy := sm.keyring.legacyKey()
+		if decrypted, err := openSecretbox(encrypted, &legacy); err == nil {
+			return decrypted, nil
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownKeyID, header.id)
+		}
+		return nil, errors.New("decryption failed")
+	}
+
+	legacy := sm.keyring.legacyKey()
+	return openSecretbox(encrypted, &legacy)
+}
+
+// openSecretbox descifra [nonce][secretbox] con la clave dada
+func openSecretbox(encrypted []byte, key *[32]byte) ([]byte, error) {
 	// Extraer nonce (primeros 24 bytes)
 	if len(encrypted) < 24 {
 		return nil, errors.New("ciphertext too short")
@@ -128,7 +338,7 @@ func (sm *SecurityManager) Decrypt(ciphertext string) ([]byte, error) {
 	copy(nonce[:], encrypted[:24])
 
 	// Descifrar
-	decrypted, ok := secretbox.Open(nil, encrypted[24:], &nonce, &sm.encryptKey)
+	decrypted, ok := secretbox.Open(nil, encrypted[24:], &nonce, key)
 	if !ok {
 		return nil, errors.New("decryption failed")
 	}
@@ -136,9 +346,52 @@ func (sm *SecurityManager) Decrypt(ciphertext string) ([]byte, error) {
 	return decrypted, nil
 }
 
-// GenerateServiceToken genera un token temporal para comunicación entre servicios
+// Algoritmos de firma de los tokens de servicio, indicados en el campo "alg"
+// del envelope. Un envelope sin "alg" es HS256 (formato original).
+const (
+	TokenAlgHS256 = "HS256"
+	TokenAlgHS512 = "HS512"
+	TokenAlgES256 = "ES256"
+)
+
+// ErrUnsupportedTokenAlgorithm indica un algoritmo fuera de la lista permitida
+var ErrUnsupportedTokenAlgorithm = errors.New("unsupported token algorithm")
+
+// tokenHMACHashes es la lista de algoritmos HMAC permitidos. El algoritmo lo
+// elige el envelope, así que cualquier otro se rechaza en lugar de adivinar
+// cómo verificarlo.
+var tokenHMACHashes = map[string]func() hash.Hash{
+	TokenAlgHS256: sha256.New,
+	TokenAlgHS512: sha512.New,
+}
+
+// SetServiceTokenAlgorithm configura el algoritmo HMAC con el que
+// GenerateServiceToken firma (HS256 o HS512). La verificación acepta ambos,
+// de modo que el cambio no invalida los tokens vigentes.
+func (sm *SecurityManager) SetServiceTokenAlgorithm(alg string) error {
+	if _, ok := tokenHMACHashes[alg]; !ok {
+		return fmt.Errorf("%w %q", ErrUnsupportedTokenAlgorithm, alg)
+	}
+	sm.tokenAlg = alg
+	return nil
+}
+
+// GenerateServiceToken genera un token temporal para comunicación entre
+// servicios, reutilizable hasta su expiración
 func (sm *SecurityManager) GenerateServiceToken(source, target string, permissions []string, ttlSeconds int) (string, error) {
-	now := time.Now()
+	return sm.issueServiceToken(source, target, permissions, ttlSeconds, sm.tokenAlg, false)
+}
+
+// GenerateSingleUseServiceToken genera un token con Nonce: la primera
+// verificación exitosa lo consume y las siguientes fallan con ErrTokenReplay
+func (sm *SecurityManager) GenerateSingleUseServiceToken(source, target string, permissions []string, ttlSeconds int) (string, error) {
+	return sm.issueServiceToken(source, target, permissions, ttlSeconds, sm.tokenAlg, true)
+}
+
+// issueServiceToken arma los claims y los firma con el algoritmo dado; con
+// singleUse agrega un Nonce aleatorio
+func (sm *SecurityManager) issueServiceToken(source, target string, permissions []string, ttlSeconds int, alg string, singleUse bool) (string, error) {
+	now := sm.now()
 	expiresAt := now.Add(time.Duration(ttlSeconds) * time.Second)
 
 	claims := ServiceTokenClaims{
@@ -150,21 +403,39 @@ func (sm *SecurityManager) GenerateServiceToken(source, target string, permissio
 		TokenID:     uuid.New().String(),
 	}
 
+	if singleUse {
+		nonce := make([]byte, 16)
+		if _, err := rand.Read(nonce); err != nil {
+			return "", fmt.Errorf("failed to generate nonce: %w", err)
+		}
+		claims.Nonce = hex.EncodeToString(nonce)
+	}
+
 	// Serializar claims
 	claimsJSON, err := json.Marshal(claims)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal claims: %w", err)
 	}
 
-	// Calcular HMAC
-	mac := hmac.New(sha256.New, sm.secretKey)
-	mac.Write(claimsJSON)
-	signature := hex.EncodeToString(mac.Sum(nil))
-
 	// Combinar claims y signature
 	tokenData := map[string]string{
-		"claims":    base64.StdEncoding.EncodeToString(claimsJSON),
-		"signature": signature,
+		"claims": base64.StdEncoding.EncodeToString(claimsJSON),
+	}
+	switch alg {
+	case TokenAlgHS256:
+		tokenData["signature"] = sm.signHMAC(sha256.New, claimsJSON)
+	case TokenAlgHS512:
+		tokenData["alg"] = alg
+		tokenData["signature"] = sm.signHMAC(sha512.New, claimsJSON)
+	case TokenAlgES256:
+		signature, err := sm.signES256(claimsJSON)
+		if err != nil {
+			return "", err
+		}
+		tokenData["alg"] = alg
+		tokenData["signature"] = signature
+	default:
+		return "", fmt.Errorf("%w %q", ErrUnsupportedTokenAlgorithm, alg)
 	}
 
 	tokenJSON, err := json.Marshal(tokenData)
@@ -175,87 +446,334 @@ func (sm *SecurityManager) GenerateServiceToken(source, target string, permissio
 	return base64.StdEncoding.EncodeToString(tokenJSON), nil
 }
 
-// VerifyServiceToken verifica un token de servicio
+// signHMAC calcula el HMAC de los claims con la clave primaria y la función de hash dada
+func (sm *SecurityManager) signHMAC(newHash func() hash.Hash, claimsJSON []byte) string {
+	return hmacHex(sm.signingKeys.primaryKey(), newHash, claimsJSON)
+}
+
+// hmacHex calcula el HMAC en hex de data con la clave y la función de hash dadas
+func hmacHex(key []byte, newHash func() hash.Hash, data []byte) string {
+	mac := hmac.New(newHash, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ErrTokenReplay indica que el nonce del token ya fue presentado
+var ErrTokenReplay = errors.New("replay detected")
+
+var (
+	// ErrTokenExpired indica un token vencido, incluida la tolerancia de reloj
+	ErrTokenExpired = errors.New("token expired")
+	// ErrTokenRevoked indica un token revocado con RevokeToken
+	ErrTokenRevoked = errors.New("token revoked")
+)
+
+// VerifyServiceToken verifica un token de servicio. Si el token trae Nonce,
+// la verificación exitosa lo consume y una segunda presentación falla con
+// ErrTokenReplay. Cada fallo queda en el log de auditoría.
 func (sm *SecurityManager) VerifyServiceToken(token string) (*ServiceTokenClaims, error) {
+	claims, err := sm.parseServiceToken(token)
+	if err == nil {
+		err = sm.consumeNonce(claims)
+	}
+	if err != nil {
+		sm.auditTokenFailure(claims, err)
+		return nil, err
+	}
+	return claims, nil
+}
+
+// auditTokenFailure registra una verificación fallida; claims es nil si el
+// token no llegó a decodificarse
+func (sm *SecurityManager) auditTokenFailure(claims *ServiceTokenClaims, err error) {
+	details := map[string]string{"reason": err.Error()}
+	if claims != nil {
+		details["token_id"] = claims.TokenID
+		details["source"] = claims.Source
+		details["target"] = claims.Target
+	}
+	sm.audit.Record(AuditTokenVerificationFailed, details)
+}
+
+// consumeNonce registra el nonce mientras el token pueda ser aceptado, es
+// decir, hasta su expiración más la tolerancia de reloj
+func (sm *SecurityManager) consumeNonce(claims *ServiceTokenClaims) error {
+	if claims.Nonce == "" {
+		return nil
+	}
+	expiresAt, err := time.Parse(time.RFC3339, claims.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("invalid expiration time: %w", err)
+	}
+
+	added, err := sm.seenNonces.Add(claims.Nonce, expiresAt.Add(sm.leeway), sm.now())
+	if err != nil {
+		return err
+	}
+	if !added {
+		return ErrTokenReplay
+	}
+	return nil
+}
+
+// parseServiceToken valida firma, vigencia (con la tolerancia de reloj) y
+// revocación sin consumir el nonce
+func (sm *SecurityManager) parseServiceToken(token string) (*ServiceTokenClaims, error) {
+	claims, _, err := sm.parseServiceTokenEnvelope(token)
+	return claims, err
+}
+
+// parseServiceTokenEnvelope es parseServiceToken retornando además el
+// algoritmo con el que se firmó el token
+func (sm *SecurityManager) parseServiceTokenEnvelope(token string) (*ServiceTokenClaims, string, error) {
 	// Decodificar token
 	tokenJSON, err := base64.StdEncoding.DecodeString(token)
 	if err != nil {
-		return nil, fmt.Errorf("invalid token encoding: %w", err)
+		return nil, "", fmt.Errorf("invalid token encoding: %w", err)
 	}
 
 	var tokenData map[string]string
 	if err := json.Unmarshal(tokenJSON, &tokenData); err != nil {
-		return nil, fmt.Errorf("invalid token format: %w", err)
+		return nil, "", fmt.Errorf("invalid token format: %w", err)
 	}
 
 	// Obtener claims y signature
 	claimsB64, ok := tokenData["claims"]
 	if !ok {
-		return nil, errors.New("missing claims in token")
+		return nil, "", errors.New("missing claims in token")
 	}
 	signature, ok := tokenData["signature"]
 	if !ok {
-		return nil, errors.New("missing signature in token")
+		return nil, "", errors.New("missing signature in token")
 	}
 
 	// Decodificar claims
 	claimsJSON, err := base64.StdEncoding.DecodeString(claimsB64)
 	if err != nil {
-		return nil, fmt.Errorf("invalid claims encoding: %w", err)
+		return nil, "", fmt.Errorf("invalid claims encoding: %w", err)
 	}
 
-	// Verificar HMAC
-	mac := hmac.New(sha256.New, sm.secretKey)
-	mac.Write(claimsJSON)
-	expectedSignature := hex.EncodeToString(mac.Sum(nil))
-
-	if !hmac.Equal([]byte(signature), []byte(expectedSignature)) {
-		return nil, errors.New("invalid signature")
+	// Verificar la firma según el algoritmo del envelope
+	alg := tokenData["alg"]
+	if alg == "" {
+		alg = TokenAlgHS256
+	}
+	switch newHash, isHMAC := tokenHMACHashes[alg]; {
+	case isHMAC:
+		// Durante una rotación también se aceptan las claves secundarias
+		valid := sm.matchesSigningKey(signature, func(key []byte) string {
+			return hmacHex(key, newHash, claimsJSON)
+		})
+		if !valid {
+			return nil, "", errors.New("invalid signature")
+		}
+	case alg == TokenAlgES256:
+		if err := sm.verifyES256(claimsJSON, signature); err != nil {
+			return nil, "", err
+		}
+	default:
+		return nil, "", fmt.Errorf("%w %q", ErrUnsupportedTokenAlgorithm, alg)
 	}
 
 	// Parsear claims
 	var claims ServiceTokenClaims
 	if err := json.Unmarshal(claimsJSON, &claims); err != nil {
-		return nil, fmt.Errorf("invalid claims format: %w", err)
+		return nil, "", fmt.Errorf("invalid claims format: %w", err)
 	}
 
 	// Verificar expiración
 	expiresAt, err := time.Parse(time.RFC3339, claims.ExpiresAt)
 	if err != nil {
-		return nil, fmt.Errorf("invalid expiration time: %w", err)
+		return nil, "", fmt.Errorf("invalid expiration time: %w", err)
+	}
+
+	// Tolerar el desfase de reloj entre el emisor y este servicio
+	now := sm.now()
+	if now.After(expiresAt.Add(sm.leeway)) {
+		return nil, "", ErrTokenExpired
+	}
+
+	issuedAt, err := time.Parse(time.RFC3339, claims.IssuedAt)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid issued-at time: %w", err)
+	}
+	if issuedAt.After(now.Add(sm.leeway)) {
+		return nil, "", errors.New("token issued in the future")
+	}
+
+	if sm.IsTokenRevoked(claims.TokenID) {
+		return nil, "", ErrTokenRevoked
+	}
+
+	return &claims, alg, nil
+}
+
+// VerifyServiceTokenFor verifica el token y además que haya sido emitido para
+// expectedTarget, de modo que un token destinado a otro servicio no sea
+// aceptado aquí. Un expectedTarget vacío se rechaza por ser configuración inválida.
+func (sm *SecurityManager) VerifyServiceTokenFor(token, expectedTarget string) (*ServiceTokenClaims, error) {
+	if expectedTarget == "" {
+		return nil, errors.New("expected target is required")
+	}
+
+	claims, err := sm.parseServiceToken(token)
+	if err == nil {
+		err = sm.checkTargetAndConsume(claims, expectedTarget)
+	}
+	if err != nil {
+		sm.auditTokenFailure(claims, err)
+		return nil, err
+	}
+	return claims, nil
+}
+
+// checkTargetAndConsume comprueba el target antes de consumir el nonce para
+// que un token presentado al servicio equivocado no quede inutilizado
+func (sm *SecurityManager) checkTargetAndConsume(claims *ServiceTokenClaims, expectedTarget string) error {
+	if !SecureCompare(claims.Target, expectedTarget) {
+		return fmt.Errorf("token target %q does not match %q", claims.Target, expectedTarget)
 	}
+	return sm.consumeNonce(claims)
+}
 
-	if time.Now().After(expiresAt) {
-		return nil, errors.New("token expired")
+// RefreshServiceToken emite un token nuevo con el mismo source, target y
+// permisos que oldToken, con TokenID propio y expiración en additionalTTL
+// segundos (como máximo MaxServiceTokenTTL). oldToken debe seguir vigente y
+// sin revocar; al refrescarlo se revoca, de modo que no puede volver a usarse.
+// Un token de un solo uso cuyo nonce ya se consumió no se refresca: canjearlo
+// por uno nuevo anularía la protección contra replay.
+func (sm *SecurityManager) RefreshServiceToken(oldToken string, additionalTTL int) (string, error) {
+	if additionalTTL <= 0 || additionalTTL > MaxServiceTokenTTL {
+		return "", fmt.Errorf("additional TTL must be between 1 and %d seconds", MaxServiceTokenTTL)
 	}
 
-	return &claims, nil
+	claims, alg, err := sm.parseServiceTokenEnvelope(oldToken)
+	if err != nil {
+		return "", fmt.Errorf("cannot refresh token: %w", err)
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, claims.ExpiresAt)
+	if err != nil {
+		return "", fmt.Errorf("invalid expiration time: %w", err)
+	}
+	// Refrescar consume el nonce: de un token de un solo uso sólo se obtiene
+	// un uso o un refresco
+	if err := sm.consumeNonce(claims); err != nil {
+		return "", fmt.Errorf("cannot refresh token: %w", err)
+	}
+	// La revocación es atómica: de dos refrescos concurrentes sólo uno emite
+	if !sm.revokeOnce(claims.TokenID, expiresAt) {
+		return "", fmt.Errorf("cannot refresh token: %w", ErrTokenRevoked)
+	}
+
+	// El token nuevo se firma con el mismo algoritmo y es de un solo uso si
+	// el anterior lo era
+	return sm.issueServiceToken(claims.Source, claims.Target, claims.Permissions, additionalTTL, alg, claims.Nonce != "")
 }
 
-// CalculateIntegrityHash calcula hash de integridad para un registro
-func (sm *SecurityManager) CalculateIntegrityHash(data map[string]interface{}) string {
+// CalculateChecksum calcula un checksum SHA-256 sin clave de un registro.
+// Sirve para detectar corrupción accidental, no manipulación: cualquiera que
+// controle los datos puede recalcularlo. Para integridad usar
+// CalculateIntegrityHMAC.
+//
+// Usa la serialización canónica de canonical.Marshal para que el hash no dependa
+// del orden de inserción ni de la representación de los números. Retorna ""
+// si el registro contiene valores no serializables (NaN, Inf, canales...).
+func (sm *SecurityManager) CalculateChecksum(data map[string]interface{}) string {
 	// Serializar de forma determinística
-	jsonData, _ := json.Marshal(data)
+	jsonData, err := canonical.Marshal(data)
+	if err != nil {
+		return ""
+	}
 
 	// Calcular SHA-256
 	hash := sha256.Sum256(jsonData)
 	return hex.EncodeToString(hash[:])
 }
 
-// VerifyIntegrityHash verifica el hash de integridad
-func (sm *SecurityManager) VerifyIntegrityHash(data map[string]interface{}, expectedHash string) bool {
-	calculatedHash := sm.CalculateIntegrityHash(data)
-	return hmac.Equal([]byte(calculatedHash), []byte(expectedHash))
+// VerifyChecksum verifica el checksum sin clave
+func (sm *SecurityManager) VerifyChecksum(data map[string]interface{}, expectedChecksum string) bool {
+	calculated := sm.CalculateChecksum(data)
+	if calculated == "" {
+		return false
+	}
+	return hmac.Equal([]byte(calculated), []byte(expectedChecksum))
+}
+
+// CalculateIntegrityHMAC calcula el HMAC-SHA256 de la forma canónica del
+// registro con la clave de integridad, de modo que el hash no pueda
+// falsificarse sin conocerla. Retorna "" si el registro no es serializable.
+func (sm *SecurityManager) CalculateIntegrityHMAC(data map[string]interface{}) string {
+	jsonData, err := canonical.Marshal(data)
+	if err != nil {
+		return ""
+	}
+
+	mac := hmac.New(sha256.New, sm.integrityKey)
+	mac.Write(jsonData)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyIntegrityHMAC verifica en tiempo constante el HMAC de integridad
+func (sm *SecurityManager) VerifyIntegrityHMAC(data map[string]interface{}, expectedHMAC string) bool {
+	calculated := sm.CalculateIntegrityHMAC(data)
+	if calculated == "" {
+		return false
+	}
+	return hmac.Equal([]byte(calculated), []byte(expectedHMAC))
 }
 
-// GenerateDeviceFingerprint genera fingerprint del dispositivo
-func (sm *SecurityManager) GenerateDeviceFingerprint(userAgent, acceptLanguage, acceptEncoding string) string {
+// BlindIndex calcula un HMAC-SHA256 determinístico de un valor que se guarda
+// cifrado, para poder buscarlo por igualdad sin descifrar. Usa una clave
+// derivada de la de integridad, de modo que los índices no coinciden con
+// ningún otro HMAC del servicio.
+func (sm *SecurityManager) BlindIndex(value string) string {
+	mac := hmac.New(sha256.New, deriveSubkey(sm.integrityKey, "fincore-blind-index"))
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// deriveSubkey deriva de key una clave independiente para el uso label
+func deriveSubkey(key []byte, label string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(label))
+	return mac.Sum(nil)
+}
+
+// GenerateDeviceFingerprint genera fingerprint del dispositivo. extra agrega
+// señales estables provistas por el cliente (hash de canvas/WebGL, zona
+// horaria, métricas de pantalla) en el orden recibido, por lo que cada
+// llamador debe pasarlas siempre en el mismo orden. Sin extra el resultado es
+// el mismo que con solo los tres headers.
+func (sm *SecurityManager) GenerateDeviceFingerprint(userAgent, acceptLanguage, acceptEncoding string, extra ...string) string {
 	components := userAgent + "|" + acceptLanguage + "|" + acceptEncoding
+	for _, component := range extra {
+		components += "|" + component
+	}
 	hash := sha256.Sum256([]byte(components))
 	return hex.EncodeToString(hash[:16]) // Primeros 16 bytes (32 caracteres hex)
 }
 
-// SecureCompare compara dos strings de forma segura (timing-safe)
+// SecureCompare compara dos strings en tiempo constante sin revelar sus
+// longitudes: ambas se reducen primero a un SHA-256 de 32 bytes y se comparan
+// los digests. Usarla para secretos cuya longitud no es pública (contraseñas,
+// API keys, targets esperados).
+//
+// Para valores de longitud fija y conocida, como HMAC hex, basta
+// SecureCompareFixedLength, que evita el costo del hash.
 func SecureCompare(a, b string) bool {
+	digestA, digestB := compareDigest(a), compareDigest(b)
+	return subtle.ConstantTimeCompare(digestA[:], digestB[:]) == 1
+}
+
+// SecureCompareFixedLength compara en tiempo constante dos strings de la
+// misma longitud. Si las longitudes difieren retorna false de inmediato, por
+// lo que sólo debe usarse cuando la longitud no es secreta.
+func SecureCompareFixedLength(a, b string) bool {
 	return hmac.Equal([]byte(a), []byte(b))
 }
+
+// compareDigest reduce la entrada a un tamaño fijo para SecureCompare
+func compareDigest(s string) [sha256.Size]byte {
+	return sha256.Sum256([]byte(s))
+}
@@ -0,0 +1,1335 @@
+package security
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+// newTestManager crea un SecurityManager con claves fijas de prueba
+func newTestManager(t *testing.T) *SecurityManager {
+	t.Helper()
+	t.Setenv("SECRET_KEY", "test-secret-key-0123456789-abcdefghij")
+	t.Setenv("ENCRYPTION_KEY", "test-encryption-key-0123456789-abcdef")
+	t.Setenv("INTEGRITY_KEY", "test-integrity-key-0123456789-abcdefgh")
+
+	sm, err := NewSecurityManager()
+	if err != nil {
+		t.Fatalf("NewSecurityManager: %v", err)
+	}
+	return sm
+}
+
+func TestCalculateChecksumDeterministic(t *testing.T) {
+	sm := newTestManager(t)
+
+	a := map[string]interface{}{
+		"amount": 100,
+		"user":   "alice",
+		"meta": map[string]interface{}{
+			"z": 1.0,
+			"a": []interface{}{int64(2), "x"},
+		},
+	}
+
+	// Mismo contenido tras un round-trip JSON: orden distinto y números como float64/json.Number
+	b := map[string]interface{}{}
+	b["meta"] = map[string]interface{}{
+		"a": []interface{}{2.0, "x"},
+		"z": json.Number("1"),
+	}
+	b["user"] = "alice"
+	b["amount"] = 100.0
+
+	if ha, hb := sm.CalculateChecksum(a), sm.CalculateChecksum(b); ha != hb {
+		t.Errorf("hashes differ for semantically equal inputs: %s vs %s", ha, hb)
+	}
+
+	b["amount"] = 100.5
+	if sm.CalculateChecksum(a) == sm.CalculateChecksum(b) {
+		t.Error("different amounts must produce different hashes")
+	}
+}
+
+func TestIntegrityHMACRequiresKey(t *testing.T) {
+	sm := newTestManager(t)
+	data := map[string]interface{}{"id": "tx-1", "amount": "100.00"}
+
+	mac := sm.CalculateIntegrityHMAC(data)
+	if !sm.VerifyIntegrityHMAC(data, mac) {
+		t.Fatal("HMAC computed with the key must verify")
+	}
+
+	// Un atacante sin la clave sólo puede calcular el checksum sin clave
+	if forged := sm.CalculateChecksum(data); sm.VerifyIntegrityHMAC(data, forged) {
+		t.Error("unkeyed checksum must not verify as HMAC")
+	}
+
+	t.Setenv("INTEGRITY_KEY", "another-integrity-key-0123456789-abcdef")
+	other, err := NewSecurityManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sm.VerifyIntegrityHMAC(data, other.CalculateIntegrityHMAC(data)) {
+		t.Error("HMAC computed with a different key must not verify")
+	}
+}
+
+func TestIntegrityKeyIndependentOfTokenKey(t *testing.T) {
+	sm := newTestManager(t)
+	data := map[string]interface{}{"id": "tx-1", "amount": "100.00"}
+	mac := sm.CalculateIntegrityHMAC(data)
+
+	// Rotar SECRET_KEY invalida los tokens pero no los hashes de integridad
+	token, err := sm.GenerateServiceToken("python-backend", "fincore-core-go", nil, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SECRET_KEY", "rotated-secret-key-0123456789-abcdefghij")
+	rotated, err := NewSecurityManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rotated.VerifyIntegrityHMAC(data, mac) {
+		t.Error("integrity HMAC must verify after rotating SECRET_KEY")
+	}
+	if _, err := rotated.VerifyServiceToken(token); err == nil {
+		t.Error("token signed with the old SECRET_KEY must not verify")
+	}
+
+	// INTEGRITY_KEY_FILE se usa si INTEGRITY_KEY no está definida
+	path := filepath.Join(t.TempDir(), "integrity-key")
+	if err := os.WriteFile(path, []byte("test-integrity-key-0123456789-abcdefgh\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("INTEGRITY_KEY", "")
+	t.Setenv("INTEGRITY_KEY_FILE", path)
+	fromFile, err := NewSecurityManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fromFile.VerifyIntegrityHMAC(data, mac) {
+		t.Error("integrity key from INTEGRITY_KEY_FILE must match INTEGRITY_KEY")
+	}
+}
+
+func TestBlindIndex(t *testing.T) {
+	sm := newTestManager(t)
+
+	index := sm.BlindIndex("user-1")
+	if index != sm.BlindIndex("user-1") || len(index) != 64 {
+		t.Fatalf("BlindIndex = %q, want a stable 64-char hex HMAC", index)
+	}
+	if index == sm.BlindIndex("user-2") {
+		t.Error("different values must produce different indexes")
+	}
+	// No debe coincidir con un HMAC directo con la clave de integridad
+	mac := hmac.New(sha256.New, sm.integrityKey)
+	mac.Write([]byte("user-1"))
+	if index == hex.EncodeToString(mac.Sum(nil)) {
+		t.Error("blind index must use a derived key")
+	}
+}
+
+func TestRevokeToken(t *testing.T) {
+	sm := newTestManager(t)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	sm.now = func() time.Time { return now }
+
+	token, err := sm.GenerateServiceToken("core-go", "ml-service", []string{"read"}, 300)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := sm.VerifyServiceToken(token)
+	if err != nil {
+		t.Fatalf("fresh token must verify: %v", err)
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, claims.ExpiresAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sm.RevokeToken(claims.TokenID, expiresAt)
+
+	if _, err := sm.VerifyServiceToken(token); err == nil || err.Error() != "token revoked" {
+		t.Errorf("err = %v, want token revoked", err)
+	}
+
+	// Otros tokens no se ven afectados
+	other, _ := sm.GenerateServiceToken("core-go", "ml-service", []string{"read"}, 300)
+	if _, err := sm.VerifyServiceToken(other); err != nil {
+		t.Errorf("unrevoked token must verify: %v", err)
+	}
+
+	// Dentro de la tolerancia de reloj el token sigue revocado
+	now = expiresAt.Add(sm.leeway / 2)
+	if _, err := sm.VerifyServiceToken(token); err == nil || err.Error() != "token revoked" {
+		t.Errorf("within leeway: err = %v, want token revoked", err)
+	}
+
+	// Tras la expiración del token (más la tolerancia) la entrada se limpia sola
+	now = expiresAt.Add(sm.leeway + time.Second)
+	if n := sm.revokedTokens.len(now); n != 0 {
+		t.Errorf("revocation entries after expiry = %d, want 0", n)
+	}
+	if sm.IsTokenRevoked(claims.TokenID) {
+		t.Error("expired revocation must not be reported")
+	}
+}
+
+func TestVerifyServiceTokenFor(t *testing.T) {
+	sm := newTestManager(t)
+
+	token, err := sm.GenerateServiceToken("python-backend", "fincore-core-go", []string{"calculate"}, 300)
+	if err != nil {
+		t.Fatal(err)
+	}
+	untargeted, err := sm.GenerateServiceToken("python-backend", "", []string{"calculate"}, 300)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		target  string
+		wantErr bool
+	}{
+		{"target correcto", token, "fincore-core-go", false},
+		{"target incorrecto", token, "ml-service", true},
+		{"target esperado vacío", token, "", true},
+		{"token sin target", untargeted, "fincore-core-go", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := sm.VerifyServiceTokenFor(tt.token, tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && claims.Target != tt.target {
+				t.Errorf("claims.Target = %q, want %q", claims.Target, tt.target)
+			}
+		})
+	}
+}
+
+func TestServiceTokenReplay(t *testing.T) {
+	sm := newTestManager(t)
+
+	token, err := sm.GenerateSingleUseServiceToken("python-backend", "fincore-core-go", []string{"calculate:metrics"}, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sm.VerifyServiceToken(token); err != nil {
+		t.Fatalf("first use must succeed: %v", err)
+	}
+	if _, err := sm.VerifyServiceToken(token); err == nil || err.Error() != "replay detected" {
+		t.Errorf("second use err = %v, want replay detected", err)
+	}
+}
+
+func TestServiceTokenReusableWithoutNonce(t *testing.T) {
+	sm := newTestManager(t)
+
+	token, err := sm.GenerateServiceToken("python-backend", "fincore-core-go", []string{"calculate:metrics"}, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		claims, err := sm.VerifyServiceToken(token)
+		if err != nil {
+			t.Fatalf("use %d: %v", i+1, err)
+		}
+		if claims.Nonce != "" {
+			t.Fatalf("nonce = %q, want none unless requested", claims.Nonce)
+		}
+	}
+}
+
+func TestServiceTokenReplayWrongTargetDoesNotConsume(t *testing.T) {
+	sm := newTestManager(t)
+
+	token, err := sm.GenerateSingleUseServiceToken("python-backend", "fincore-core-go", nil, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sm.VerifyServiceTokenFor(token, "ml-service"); err == nil {
+		t.Fatal("wrong target must fail")
+	}
+	if _, err := sm.VerifyServiceTokenFor(token, "fincore-core-go"); err != nil {
+		t.Errorf("token must still be usable at its target: %v", err)
+	}
+}
+
+func TestNonceCacheBounded(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewNonceCache(2)
+
+	for _, nonce := range []string{"a", "b"} {
+		if added, err := cache.Add(nonce, now.Add(time.Minute), now); !added || err != nil {
+			t.Fatalf("Add(%q) = %v, %v", nonce, added, err)
+		}
+	}
+	if added, err := cache.Add("a", now.Add(time.Minute), now); added || err != nil {
+		t.Errorf("repeated nonce = %v, %v; want a replay", added, err)
+	}
+	// Lleno de nonces vigentes falla en lugar de olvidar uno
+	if _, err := cache.Add("c", now.Add(time.Minute), now); !errors.Is(err, ErrNonceCacheFull) {
+		t.Errorf("full cache err = %v, want ErrNonceCacheFull", err)
+	}
+	if added, _ := cache.Add("a", now.Add(time.Minute), now); added {
+		t.Error("a live nonce must not be evicted by a full cache")
+	}
+
+	// Cuando los nonces vencen se libera espacio
+	later := now.Add(2 * time.Minute)
+	if added, err := cache.Add("c", later.Add(time.Minute), later); !added || err != nil {
+		t.Errorf("Add after expiry = %v, %v", added, err)
+	}
+}
+
+func TestNonceCacheEvictsEveryExpiredNonce(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewNonceCache(4)
+
+	// Los vencidos quedan intercalados con los vigentes
+	nonces := []struct {
+		nonce string
+		ttl   time.Duration
+	}{
+		{"live-1", time.Hour},
+		{"short-1", time.Second},
+		{"live-2", time.Hour},
+		{"short-2", time.Second},
+	}
+	for _, n := range nonces {
+		if added, err := cache.Add(n.nonce, now.Add(n.ttl), now); !added || err != nil {
+			t.Fatalf("Add(%q) = %v, %v", n.nonce, added, err)
+		}
+	}
+
+	later := now.Add(time.Minute)
+	for _, nonce := range []string{"fresh-1", "fresh-2"} {
+		if added, err := cache.Add(nonce, later.Add(time.Hour), later); !added || err != nil {
+			t.Fatalf("Add(%q) = %v, %v", nonce, added, err)
+		}
+	}
+	if cache.Len() != 4 {
+		t.Errorf("len = %d, want 4 after evicting both expired nonces", cache.Len())
+	}
+	for _, nonce := range []string{"live-1", "live-2"} {
+		if added, _ := cache.Add(nonce, later.Add(time.Hour), later); added {
+			t.Errorf("%s was evicted while live", nonce)
+		}
+	}
+}
+func TestEncryptionKeyRotation(t *testing.T) {
+	sm := newTestManager(t)
+
+	v1Ciphertext, err := sm.Encrypt([]byte("datos v1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var v2 [32]byte
+	copy(v2[:], "clave-de-rotacion-v2-32-bytes!!!")
+	if err := sm.AddKey("v2", v2); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.SetActiveKey("v2"); err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext, err := sm.Decrypt(v1Ciphertext)
+	if err != nil || string(plaintext) != "datos v1" {
+		t.Fatalf("Decrypt(v1) = %q, %v", plaintext, err)
+	}
+
+	v2Ciphertext, err := sm.Encrypt([]byte("datos v2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := base64.StdEncoding.DecodeString(v2Ciphertext)
+	if header, _, ok := parseKeyHeader(raw, ciphertextFormatKeyringKDF); !ok || header.id != "v2" {
+		t.Errorf("new ciphertext key id = %q, want v2", header.id)
+	}
+	if plaintext, err := sm.Decrypt(v2Ciphertext); err != nil || string(plaintext) != "datos v2" {
+		t.Errorf("Decrypt(v2) = %q, %v", plaintext, err)
+	}
+
+	if err := sm.SetActiveKey("v3"); !errors.Is(err, ErrUnknownKeyID) {
+		t.Errorf("SetActiveKey(unknown) err = %v, want ErrUnknownKeyID", err)
+	}
+	if err := sm.AddKey("v2", v2); err == nil {
+		t.Error("re-registering a key id must fail")
+	}
+}
+
+func TestDecryptLegacyCiphertext(t *testing.T) {
+	sm := newTestManager(t)
+
+	// Formato previo al keyring: [nonce][secretbox] con la clave inicial
+	var nonce [24]byte
+	key := sm.keyring.legacyKey()
+	legacy := secretbox.Seal(nonce[:], []byte("legado"), &nonce, &key)
+
+	plaintext, err := sm.Decrypt(base64.StdEncoding.EncodeToString(legacy))
+	if err != nil || string(plaintext) != "legado" {
+		t.Errorf("Decrypt(legacy) = %q, %v", plaintext, err)
+	}
+}
+
+func TestEncryptWithContext(t *testing.T) {
+	sm := newTestManager(t)
+
+	ciphertext, err := sm.EncryptWithContext([]byte("CLABE 012345678901234567"), []byte("user:123"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext, err := sm.DecryptWithContext(ciphertext, []byte("user:123"))
+	if err != nil || string(plaintext) != "CLABE 012345678901234567" {
+		t.Fatalf("DecryptWithContext = %q, %v", plaintext, err)
+	}
+
+	if _, err := sm.DecryptWithContext(ciphertext, []byte("user:456")); !errors.Is(err, ErrContextMismatch) {
+		t.Errorf("wrong context err = %v, want ErrContextMismatch", err)
+	}
+	if _, err := sm.DecryptWithContext(ciphertext, nil); err == nil {
+		t.Error("missing context must fail")
+	}
+}
+
+func TestNewSecurityManagerEnvironment(t *testing.T) {
+	tests := []struct {
+		name      string
+		env       map[string]string
+		wantErr   error
+		wantValid bool
+	}{
+		{"producción sin claves", map[string]string{"FINCORE_ENV": "production"}, ErrMissingSecretKey, false},
+		{"GIN release sin claves", map[string]string{"GIN_MODE": "release"}, ErrMissingSecretKey, false},
+		{"release aunque FINCORE_ENV sea dev", map[string]string{"GIN_MODE": "release", "FINCORE_ENV": "development"}, ErrMissingSecretKey, false},
+		{"producción con claves de desarrollo", map[string]string{
+			"FINCORE_ENV":    "production",
+			"SECRET_KEY":     devSecretKey,
+			"ENCRYPTION_KEY": devEncryptionKey,
+		}, ErrDevKeyInProduction, false},
+		{"entorno no declarado sin claves", map[string]string{}, ErrMissingSecretKey, false},
+		{"producción sin clave de integridad", map[string]string{
+			"FINCORE_ENV":    "production",
+			"SECRET_KEY":     "prod-secret-key-0123456789-abcdefghijkl",
+			"ENCRYPTION_KEY": "prod-encryption-key-0123456789-abcdefgh",
+		}, ErrMissingIntegrityKey, false},
+		{"desarrollo sin clave de integridad usa SECRET_KEY", map[string]string{
+			"FINCORE_ENV":    "development",
+			"SECRET_KEY":     "dev-secret-key-0123456789-abcdefghijklm",
+			"ENCRYPTION_KEY": "dev-encryption-key-0123456789-abcdefghi",
+		}, nil, true},
+		{"desarrollo sin claves", map[string]string{"FINCORE_ENV": "development"}, nil, true},
+		{"test sin claves", map[string]string{"FINCORE_ENV": "test"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"FINCORE_ENV", "GIN_MODE", "SECRET_KEY", "ENCRYPTION_KEY", "INTEGRITY_KEY", "INTEGRITY_KEY_FILE"} {
+				t.Setenv(key, tt.env[key])
+			}
+
+			sm, err := NewSecurityManager()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if (sm != nil) != tt.wantValid {
+				t.Errorf("manager = %v, want valid %v", sm, tt.wantValid)
+			}
+		})
+	}
+}
+
+func TestKeyDerivationVersions(t *testing.T) {
+	sm := newTestManager(t)
+	passphrase := "test-encryption-key-0123456789-abcdef"
+
+	// Versión 1: formato 0x01 sin byte de derivación, clave SHA-256
+	legacyKey := sha256.Sum256([]byte(passphrase))
+	var nonce [24]byte
+	v1 := append([]byte{ciphertextFormatKeyring, byte(len(DefaultEncryptionKeyID))}, DefaultEncryptionKeyID...)
+	v1 = append(v1, nonce[:]...)
+	v1 = secretbox.Seal(v1, []byte("sha256"), &nonce, &legacyKey)
+
+	plaintext, err := sm.Decrypt(base64.StdEncoding.EncodeToString(v1))
+	if err != nil || string(plaintext) != "sha256" {
+		t.Fatalf("Decrypt(kdf v1) = %q, %v", plaintext, err)
+	}
+
+	// Versión 2: los datos nuevos se cifran con Argon2id y lo declaran en la cabecera
+	ciphertext, err := sm.Encrypt([]byte("argon2id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := base64.StdEncoding.DecodeString(ciphertext)
+	header, rest, ok := parseKeyHeader(raw, ciphertextFormatKeyringKDF)
+	if !ok || header.kdf != kdfArgon2id {
+		t.Fatalf("header = %+v, want kdf %d", header, kdfArgon2id)
+	}
+	if _, err := openSecretbox(rest, &legacyKey); err == nil {
+		t.Error("v2 ciphertext must not open with the SHA-256 key")
+	}
+	if plaintext, err := sm.Decrypt(ciphertext); err != nil || string(plaintext) != "argon2id" {
+		t.Errorf("Decrypt(kdf v2) = %q, %v", plaintext, err)
+	}
+
+	// La sal forma parte de la derivación v2 pero no de la v1
+	t.Setenv("ENCRYPTION_KEY_SALT", "otra-sal-de-al-menos-16-bytes")
+	salted, err := NewSecurityManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := salted.Decrypt(ciphertext); err == nil {
+		t.Error("v2 ciphertext must not decrypt with a different salt")
+	}
+	if _, err := salted.Decrypt(base64.StdEncoding.EncodeToString(v1)); err != nil {
+		t.Errorf("v1 ciphertext must decrypt regardless of salt: %v", err)
+	}
+
+	t.Setenv("ENCRYPTION_KEY_SALT", "corta")
+	if _, err := NewSecurityManager(); err == nil {
+		t.Error("short salt must be rejected")
+	}
+}
+
+func TestRefreshServiceToken(t *testing.T) {
+	sm := newTestManager(t)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	sm.now = func() time.Time { return now }
+
+	oldToken, err := sm.GenerateServiceToken("python-backend", "fincore-core-go", []string{"calculate:metrics"}, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Cerca de expirar se refresca por otros 300 segundos
+	now = now.Add(50 * time.Second)
+	newToken, err := sm.RefreshServiceToken(oldToken, 300)
+	if err != nil {
+		t.Fatalf("RefreshServiceToken: %v", err)
+	}
+
+	now = now.Add(60 * time.Second)
+	claims, err := sm.VerifyServiceTokenFor(newToken, "fincore-core-go")
+	if err != nil {
+		t.Fatalf("refreshed token must verify after the old expiry: %v", err)
+	}
+	if claims.Source != "python-backend" || len(claims.Permissions) != 1 || claims.Permissions[0] != "calculate:metrics" {
+		t.Errorf("refreshed claims = %+v, want same source and permissions", claims)
+	}
+
+	if _, err := sm.RefreshServiceToken(oldToken, 300); err == nil {
+		t.Error("an already refreshed token must not be refreshed again")
+	}
+}
+
+func TestRefreshServiceTokenAfterUse(t *testing.T) {
+	sm := newTestManager(t)
+
+	oldToken, err := sm.GenerateServiceToken("python-backend", "fincore-core-go", []string{"calculate:metrics"}, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// La llamada larga presentó el token reutilizable al empezar
+	if _, err := sm.VerifyServiceToken(oldToken); err != nil {
+		t.Fatalf("VerifyServiceToken: %v", err)
+	}
+
+	newToken, err := sm.RefreshServiceToken(oldToken, 300)
+	if err != nil {
+		t.Fatalf("refresh after use: %v", err)
+	}
+	if _, err := sm.VerifyServiceToken(newToken); err != nil {
+		t.Errorf("refreshed token: %v", err)
+	}
+	if _, err := sm.VerifyServiceToken(oldToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("old token after refresh: err = %v, want ErrTokenRevoked", err)
+	}
+}
+
+func TestRefreshServiceTokenSingleUse(t *testing.T) {
+	sm := newTestManager(t)
+
+	// Un token de un solo uso ya presentado no se canjea por uno nuevo
+	used, err := sm.GenerateSingleUseServiceToken("python-backend", "fincore-core-go", []string{"calculate:metrics"}, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sm.VerifyServiceToken(used); err != nil {
+		t.Fatalf("VerifyServiceToken: %v", err)
+	}
+	if _, err := sm.RefreshServiceToken(used, 300); !errors.Is(err, ErrTokenReplay) {
+		t.Errorf("refresh of a used single-use token: err = %v, want ErrTokenReplay", err)
+	}
+
+	// Sin usar se refresca una vez y el refresco consume su nonce
+	unused, err := sm.GenerateSingleUseServiceToken("python-backend", "fincore-core-go", []string{"calculate:metrics"}, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newToken, err := sm.RefreshServiceToken(unused, 300)
+	if err != nil {
+		t.Fatalf("refresh of an unused single-use token: %v", err)
+	}
+	if _, err := sm.VerifyServiceToken(unused); err == nil {
+		t.Error("refreshed single-use token must not verify")
+	}
+	claims, err := sm.VerifyServiceToken(newToken)
+	if err != nil {
+		t.Fatalf("refreshed token: %v", err)
+	}
+	if claims.Nonce == "" {
+		t.Error("refresh of a single-use token must issue a single-use token")
+	}
+	if _, err := sm.VerifyServiceToken(newToken); !errors.Is(err, ErrTokenReplay) {
+		t.Errorf("second use of the refreshed token: err = %v, want ErrTokenReplay", err)
+	}
+}
+
+func TestRefreshServiceTokenTTLBounds(t *testing.T) {
+	sm := newTestManager(t)
+
+	token, err := sm.GenerateServiceToken("python-backend", "fincore-core-go", nil, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ttl := range []int{0, -1, MaxServiceTokenTTL + 1, 100 * 365 * 24 * 3600} {
+		if _, err := sm.RefreshServiceToken(token, ttl); err == nil {
+			t.Errorf("additionalTTL %d accepted", ttl)
+		}
+	}
+	// Los rechazos no revocan el token
+	if _, err := sm.RefreshServiceToken(token, MaxServiceTokenTTL); err != nil {
+		t.Errorf("additionalTTL %d: %v", MaxServiceTokenTTL, err)
+	}
+}
+
+func TestRefreshServiceTokenExpired(t *testing.T) {
+	sm := newTestManager(t)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	sm.now = func() time.Time { return now }
+
+	token, err := sm.GenerateServiceToken("python-backend", "fincore-core-go", nil, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := sm.RefreshServiceToken(token, 300); err == nil {
+		t.Error("expired token must not be refreshed")
+	}
+}
+
+func TestVerifyServiceTokenLeeway(t *testing.T) {
+	sm := newTestManager(t)
+	sm.SetTokenLeeway(30 * time.Second)
+	issued := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tokenAt := func(at time.Time) string {
+		t.Helper()
+		sm.now = func() time.Time { return at }
+		token, err := sm.GenerateServiceToken("python-backend", "fincore-core-go", []string{"read"}, 60)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	verifyAt := func(token string, at time.Time) error {
+		sm.now = func() time.Time { return at }
+		_, err := sm.VerifyServiceToken(token)
+		return err
+	}
+
+	expiresAt := issued.Add(60 * time.Second)
+	if err := verifyAt(tokenAt(issued), expiresAt.Add(20*time.Second)); err != nil {
+		t.Errorf("expired within leeway: %v, want accepted", err)
+	}
+	if err := verifyAt(tokenAt(issued), expiresAt.Add(31*time.Second)); err == nil || err.Error() != "token expired" {
+		t.Errorf("expired beyond leeway: err = %v, want token expired", err)
+	}
+
+	// El reloj del emisor va adelantado respecto al de este servicio
+	if err := verifyAt(tokenAt(issued), issued.Add(-20*time.Second)); err != nil {
+		t.Errorf("issued within leeway in the future: %v, want accepted", err)
+	}
+	if err := verifyAt(tokenAt(issued), issued.Add(-31*time.Second)); err == nil || err.Error() != "token issued in the future" {
+		t.Errorf("issued beyond leeway in the future: err = %v, want token issued in the future", err)
+	}
+
+	// Sin tolerancia el token se rechaza en cuanto expira
+	sm.SetTokenLeeway(0)
+	if err := verifyAt(tokenAt(issued), expiresAt.Add(time.Second)); err == nil {
+		t.Error("zero leeway: expired token must be rejected")
+	}
+}
+
+func TestNonceRetainedThroughLeeway(t *testing.T) {
+	sm := newTestManager(t)
+	sm.SetTokenLeeway(30 * time.Second)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	sm.now = func() time.Time { return now }
+
+	token, err := sm.GenerateSingleUseServiceToken("python-backend", "fincore-core-go", []string{"read"}, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sm.VerifyServiceToken(token); err != nil {
+		t.Fatal(err)
+	}
+
+	// Pasada la expiración pero dentro de la tolerancia, el nonce sigue registrado
+	now = now.Add(75 * time.Second)
+	if _, err := sm.VerifyServiceToken(token); !errors.Is(err, ErrTokenReplay) {
+		t.Errorf("replay within leeway: err = %v, want ErrTokenReplay", err)
+	}
+}
+
+func TestSecureCompare(t *testing.T) {
+	secret := "s3cr3t-api-key-0123456789"
+	cases := []struct {
+		a, b string
+		want bool
+	}{
+		{secret, secret, true},
+		{"", "", true},
+		{secret, secret + "x", false},
+		{secret, secret[:len(secret)-1], false},
+		{secret, "", false},
+		{secret, strings.ToUpper(secret), false},
+	}
+	for _, tc := range cases {
+		if got := SecureCompare(tc.a, tc.b); got != tc.want {
+			t.Errorf("SecureCompare(%q, %q) = %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+		if got := SecureCompareFixedLength(tc.a, tc.b); got != tc.want {
+			t.Errorf("SecureCompareFixedLength(%q, %q) = %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestSecureCompareDigestIsFixedLength(t *testing.T) {
+	// Sin importar la longitud de la entrada, lo que se compara mide lo mismo
+	for _, input := range []string{"", "a", strings.Repeat("x", 10000)} {
+		if n := len(compareDigest(input)); n != 32 {
+			t.Errorf("digest of %d-byte input has %d bytes, want 32", len(input), n)
+		}
+	}
+}
+
+func BenchmarkSecureCompareShortGuess(b *testing.B) {
+	secret := strings.Repeat("k", 64)
+	for i := 0; i < b.N; i++ {
+		SecureCompare(secret, "k")
+	}
+}
+
+func BenchmarkSecureCompareFullLengthGuess(b *testing.B) {
+	secret := strings.Repeat("k", 64)
+	guess := strings.Repeat("j", 64)
+	for i := 0; i < b.N; i++ {
+		SecureCompare(secret, guess)
+	}
+}
+
+func TestGenerateDeviceFingerprintExtraSignals(t *testing.T) {
+	sm := newTestManager(t)
+	const ua, lang, enc = "Mozilla/5.0 (X11; Linux)", "es-MX", "gzip"
+
+	base := sm.GenerateDeviceFingerprint(ua, lang, enc)
+	if len(base) != 32 {
+		t.Fatalf("len(fingerprint) = %d, want 32", len(base))
+	}
+
+	// Una señal que distingue al dispositivo cambia el fingerprint
+	withCanvas := sm.GenerateDeviceFingerprint(ua, lang, enc, "canvas:9f2c", "America/Mexico_City")
+	if withCanvas == base {
+		t.Error("extra signals did not change the fingerprint")
+	}
+	if len(withCanvas) != 32 {
+		t.Errorf("len(fingerprint with extras) = %d, want 32", len(withCanvas))
+	}
+	if other := sm.GenerateDeviceFingerprint(ua, lang, enc, "canvas:0000", "America/Mexico_City"); other == withCanvas {
+		t.Error("different canvas hash produced the same fingerprint")
+	}
+
+	// Mismas señales en el mismo orden dan el mismo fingerprint; el orden importa
+	if again := sm.GenerateDeviceFingerprint(ua, lang, enc, "canvas:9f2c", "America/Mexico_City"); again != withCanvas {
+		t.Error("fingerprint is not stable for the same signals")
+	}
+	if swapped := sm.GenerateDeviceFingerprint(ua, lang, enc, "America/Mexico_City", "canvas:9f2c"); swapped == withCanvas {
+		t.Error("swapping signal order produced the same fingerprint")
+	}
+}
+
+func TestMemoryDeviceStoreBounded(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryDeviceStore(2, 2)
+
+	if known, err := store.Bind(ctx, "user-1", "laptop"); !known || err != nil {
+		t.Fatalf("first device: known = %v, err = %v", known, err)
+	}
+	if known, _ := store.Bind(ctx, "user-1", "phone"); known {
+		t.Error("second device trusted without enrolment")
+	}
+
+	// Al superar el máximo por usuario se olvida el dispositivo más antiguo
+	for _, device := range []string{"phone", "tablet"} {
+		if err := store.Remember(ctx, "user-1", device); err != nil {
+			t.Fatalf("Remember(%s): %v", device, err)
+		}
+	}
+	for device, want := range map[string]bool{"laptop": false, "phone": true, "tablet": true} {
+		if known, _ := store.Bind(ctx, "user-1", device); known != want {
+			t.Errorf("%s known = %v, want %v", device, known, want)
+		}
+	}
+
+	// Lleno de usuarios no descarta a nadie: el usuario nuevo queda sin confianza
+	if _, err := store.Bind(ctx, "user-2", "laptop"); err != nil {
+		t.Fatal(err)
+	}
+	if known, err := store.Bind(ctx, "user-3", "laptop"); known || !errors.Is(err, ErrDeviceStoreFull) {
+		t.Errorf("new user on a full store: known = %v, err = %v, want ErrDeviceStoreFull", known, err)
+	}
+	if err := store.Remember(ctx, "user-3", "laptop"); !errors.Is(err, ErrDeviceStoreFull) {
+		t.Errorf("Remember on a full store: err = %v, want ErrDeviceStoreFull", err)
+	}
+	if known, _ := store.Bind(ctx, "user-1", "phone"); !known {
+		t.Error("existing user lost a device when the store filled up")
+	}
+}
+
+func TestAuditChainDetectsTampering(t *testing.T) {
+	audit := NewAuditLogger([]byte("audit-test-key"), 0)
+	audit.Record(AuditTokenVerificationFailed, map[string]string{"reason": "invalid signature"})
+	audit.Record(AuditTokenRevoked, map[string]string{"token_id": "tok-1"})
+	audit.Record(AuditDecryptionFailed, nil)
+
+	records := audit.Records()
+	if len(records) != 3 {
+		t.Fatalf("len(records) = %d, want 3", len(records))
+	}
+	if err := audit.VerifyAuditChain(records); err != nil {
+		t.Fatalf("untouched chain: %v", err)
+	}
+
+	tampered := audit.Records()
+	tampered[1].Details["token_id"] = "tok-2"
+	if err := audit.VerifyAuditChain(tampered); !errors.Is(err, ErrAuditChainBroken) {
+		t.Errorf("altered details: err = %v, want ErrAuditChainBroken", err)
+	}
+
+	removed := append(audit.Records()[:1], audit.Records()[2:]...)
+	if err := audit.VerifyAuditChain(removed); !errors.Is(err, ErrAuditChainBroken) {
+		t.Errorf("removed record: err = %v, want ErrAuditChainBroken", err)
+	}
+
+	// Con otra clave no se puede rehacer la cadena
+	if err := NewAuditLogger([]byte("other-key"), 0).VerifyAuditChain(records); !errors.Is(err, ErrAuditChainBroken) {
+		t.Errorf("different key: err = %v, want ErrAuditChainBroken", err)
+	}
+}
+
+func TestAuditChainBoundedRetention(t *testing.T) {
+	audit := NewAuditLogger([]byte("audit-test-key"), 2)
+	for i := 0; i < 5; i++ {
+		audit.Record(AuditDecryptionFailed, nil)
+	}
+
+	records := audit.Records()
+	if len(records) != 2 || records[0].Sequence != 4 {
+		t.Fatalf("retained %d records starting at %d, want 2 starting at 4", len(records), records[0].Sequence)
+	}
+	if err := audit.VerifyAuditChain(records); err != nil {
+		t.Errorf("retained segment: %v", err)
+	}
+}
+
+func TestSecurityFailuresAreAudited(t *testing.T) {
+	sm := newTestManager(t)
+
+	token, err := sm.GenerateServiceToken("python-backend", "fincore-core-go", nil, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sm.VerifyServiceTokenFor(token, "other-service"); err == nil {
+		t.Fatal("token for another target must be rejected")
+	}
+	if _, err := sm.VerifyServiceToken("not-a-token"); err == nil {
+		t.Fatal("malformed token must be rejected")
+	}
+	if _, err := sm.Decrypt("bm90LWEtY2lwaGVydGV4dA=="); err == nil {
+		t.Fatal("garbage ciphertext must not decrypt")
+	}
+
+	records := sm.Audit().Records()
+	wantEvents := []string{AuditTokenVerificationFailed, AuditTokenVerificationFailed, AuditDecryptionFailed}
+	if len(records) != len(wantEvents) {
+		t.Fatalf("len(records) = %d, want %d", len(records), len(wantEvents))
+	}
+	for i, want := range wantEvents {
+		if records[i].Event != want {
+			t.Errorf("records[%d].Event = %s, want %s", i, records[i].Event, want)
+		}
+	}
+	if records[0].Details["source"] != "python-backend" {
+		t.Errorf("records[0] source = %q, want python-backend", records[0].Details["source"])
+	}
+	if err := sm.Audit().VerifyAuditChain(records); err != nil {
+		t.Errorf("audit chain: %v", err)
+	}
+}
+
+// newECTestManager configura un par de claves P-256 en un manager de prueba
+func newECTestManager(t *testing.T) (*SecurityManager, *ecdsa.PrivateKey) {
+	t.Helper()
+	sm := newTestManager(t)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.SetECDSAKeys(key, nil); err != nil {
+		t.Fatal(err)
+	}
+	return sm, key
+}
+
+func TestServiceTokenECRoundTrip(t *testing.T) {
+	issuer, key := newECTestManager(t)
+
+	token, err := issuer.GenerateServiceTokenEC("python-backend", "fincore-core-go", []string{"calculate:metrics"}, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// El verificador sólo tiene la clave pública y otro SECRET_KEY
+	t.Setenv("SECRET_KEY", "another-secret-key-0123456789-abcdefghij")
+	verifier, err := NewSecurityManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifier.SetECDSAKeys(nil, &key.PublicKey); err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := verifier.VerifyServiceTokenEC(token)
+	if err != nil {
+		t.Fatalf("VerifyServiceTokenEC: %v", err)
+	}
+	if claims.Source != "python-backend" || !claims.HasPermission("calculate:metrics") {
+		t.Errorf("claims = %+v", claims)
+	}
+	// Sin nonce el token se reutiliza hasta su expiración
+	if _, err := verifier.VerifyServiceTokenEC(token); err != nil {
+		t.Errorf("second use: err = %v, want a reusable token", err)
+	}
+
+	// VerifyServiceTokenFor elige el algoritmo por el envelope
+	token, _ = issuer.GenerateServiceTokenEC("python-backend", "fincore-core-go", nil, 60)
+	if _, err := verifier.VerifyServiceTokenFor(token, "fincore-core-go"); err != nil {
+		t.Errorf("VerifyServiceTokenFor with ES256 token: %v", err)
+	}
+}
+
+func TestServiceTokenECRejectsTampering(t *testing.T) {
+	sm, _ := newECTestManager(t)
+
+	token, err := sm.GenerateServiceTokenEC("python-backend", "fincore-core-go", []string{"validate:transfers"}, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Ampliar los permisos después de firmar invalida la firma
+	envelopeJSON, _ := base64.StdEncoding.DecodeString(token)
+	var envelope map[string]string
+	if err := json.Unmarshal(envelopeJSON, &envelope); err != nil {
+		t.Fatal(err)
+	}
+	claimsJSON, _ := base64.StdEncoding.DecodeString(envelope["claims"])
+	var claims map[string]interface{}
+	if err := json.Unmarshal(claimsJSON, &claims); err != nil {
+		t.Fatal(err)
+	}
+	claims["permissions"] = []string{PermissionWildcard}
+	claimsJSON, _ = json.Marshal(claims)
+	envelope["claims"] = base64.StdEncoding.EncodeToString(claimsJSON)
+	envelopeJSON, _ = json.Marshal(envelope)
+
+	if _, err := sm.VerifyServiceTokenEC(base64.StdEncoding.EncodeToString(envelopeJSON)); err == nil {
+		t.Error("tampered token accepted")
+	}
+
+	// Un token HMAC válido no pasa por VerifyServiceTokenEC
+	hmacToken, _ := sm.GenerateServiceToken("python-backend", "fincore-core-go", nil, 60)
+	if _, err := sm.VerifyServiceTokenEC(hmacToken); err == nil {
+		t.Error("HS256 token accepted by VerifyServiceTokenEC")
+	}
+
+	// Sin clave privada no se puede emitir
+	if _, err := newTestManager(t).GenerateServiceTokenEC("a", "b", nil, 60); !errors.Is(err, ErrECDSAKeyNotConfigured) {
+		t.Errorf("issue without key: err = %v, want ErrECDSAKeyNotConfigured", err)
+	}
+}
+
+// decodeTokenEnvelope retorna el envelope JSON de un token de servicio
+func decodeTokenEnvelope(t *testing.T, token string) map[string]string {
+	t.Helper()
+	envelopeJSON, err := base64.StdEncoding.DecodeString(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var envelope map[string]string
+	if err := json.Unmarshal(envelopeJSON, &envelope); err != nil {
+		t.Fatal(err)
+	}
+	return envelope
+}
+
+func encodeTokenEnvelope(envelope map[string]string) string {
+	envelopeJSON, _ := json.Marshal(envelope)
+	return base64.StdEncoding.EncodeToString(envelopeJSON)
+}
+
+func TestServiceTokenHMACAlgorithms(t *testing.T) {
+	tests := []struct {
+		alg          string
+		envelopeAlg  string
+		signatureLen int
+	}{
+		// HS256 conserva el envelope original, sin "alg"
+		{TokenAlgHS256, "", 2 * sha256.Size},
+		{TokenAlgHS512, TokenAlgHS512, 2 * 64},
+	}
+	for _, tt := range tests {
+		t.Run(tt.alg, func(t *testing.T) {
+			sm := newTestManager(t)
+			if err := sm.SetServiceTokenAlgorithm(tt.alg); err != nil {
+				t.Fatal(err)
+			}
+			token, err := sm.GenerateServiceToken("python-backend", "fincore-core-go", []string{"calculate:metrics"}, 60)
+			if err != nil {
+				t.Fatal(err)
+			}
+			envelope := decodeTokenEnvelope(t, token)
+			if envelope["alg"] != tt.envelopeAlg || len(envelope["signature"]) != tt.signatureLen {
+				t.Errorf("envelope alg/signature length = %q/%d, want %q/%d", envelope["alg"], len(envelope["signature"]), tt.envelopeAlg, tt.signatureLen)
+			}
+
+			// El verificador elige el hash por el envelope, no por su configuración
+			verifier := newTestManager(t)
+			claims, err := verifier.VerifyServiceTokenFor(token, "fincore-core-go")
+			if err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+			if claims.Source != "python-backend" {
+				t.Errorf("claims = %+v", claims)
+			}
+		})
+	}
+}
+
+func TestServiceTokenRejectsUnsupportedAlgorithm(t *testing.T) {
+	sm := newTestManager(t)
+
+	for _, alg := range []string{"none", "HS384", "hs512", "RS256"} {
+		if err := sm.SetServiceTokenAlgorithm(alg); !errors.Is(err, ErrUnsupportedTokenAlgorithm) {
+			t.Errorf("SetServiceTokenAlgorithm(%q): err = %v, want ErrUnsupportedTokenAlgorithm", alg, err)
+		}
+	}
+
+	token, err := sm.GenerateServiceToken("python-backend", "fincore-core-go", nil, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope := decodeTokenEnvelope(t, token)
+
+	// Un envelope que pide un algoritmo fuera de la lista se rechaza aunque
+	// la firma sea un HMAC válido
+	for _, alg := range []string{"none", "HS1", "sha256"} {
+		envelope["alg"] = alg
+		if _, err := sm.VerifyServiceToken(encodeTokenEnvelope(envelope)); !errors.Is(err, ErrUnsupportedTokenAlgorithm) {
+			t.Errorf("alg %q: err = %v, want ErrUnsupportedTokenAlgorithm", alg, err)
+		}
+	}
+
+	// Cambiar el algoritmo declarado invalida la firma
+	envelope["alg"] = TokenAlgHS512
+	if _, err := sm.VerifyServiceToken(encodeTokenEnvelope(envelope)); err == nil || errors.Is(err, ErrUnsupportedTokenAlgorithm) {
+		t.Errorf("HS256 signature declared as HS512: err = %v, want invalid signature", err)
+	}
+}
+
+func TestVerifyJWT(t *testing.T) {
+	sm := newTestManager(t)
+
+	token, err := sm.GenerateJWT("node-gateway", "fincore-core-go", []string{"calculate:metrics"}, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsJWT(token) {
+		t.Fatalf("token %q does not look like a JWT", token)
+	}
+
+	claims, err := sm.VerifyJWT(token, "fincore-core-go")
+	if err != nil {
+		t.Fatalf("valid JWT: %v", err)
+	}
+	if claims.Source != "node-gateway" || claims.Target != "fincore-core-go" || !claims.HasPermission("calculate:metrics") {
+		t.Errorf("claims = %+v", claims)
+	}
+	// Sin nonce el mismo JWT sigue siendo válido hasta expirar
+	if _, err := sm.VerifyJWT(token, "fincore-core-go"); err != nil {
+		t.Errorf("second use: %v", err)
+	}
+	if _, err := sm.VerifyJWT(token, "other-service"); err == nil {
+		t.Error("JWT for another audience accepted")
+	}
+}
+
+func TestVerifyJWTExpired(t *testing.T) {
+	sm := newTestManager(t)
+	sm.SetTokenLeeway(0)
+
+	token, err := sm.GenerateJWT("node-gateway", "fincore-core-go", nil, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issued := time.Now()
+	sm.now = func() time.Time { return issued.Add(2 * time.Minute) }
+	if _, err := sm.VerifyJWT(token, "fincore-core-go"); err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Errorf("err = %v, want token expired", err)
+	}
+}
+
+func TestVerifyJWTBadSignature(t *testing.T) {
+	sm := newTestManager(t)
+
+	token, err := sm.GenerateJWT("node-gateway", "fincore-core-go", []string{"validate:transfers"}, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(token, ".")
+
+	// Payload con permisos ampliados y la firma original
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"iss":"node-gateway","aud":"fincore-core-go","exp":9999999999,"iat":0,"permissions":["*"]}`))
+	if _, err := sm.VerifyJWT(parts[0]+"."+payload+"."+parts[2], "fincore-core-go"); err == nil {
+		t.Error("JWT with altered payload accepted")
+	}
+
+	// Firmado con otra clave
+	t.Setenv("SECRET_KEY", "another-secret-key-0123456789-abcdefghij")
+	other, err := NewSecurityManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.VerifyJWT(token, "fincore-core-go"); err == nil {
+		t.Error("JWT signed with another key accepted")
+	}
+
+	// alg "none" nunca se acepta
+	none := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+	if _, err := sm.VerifyJWT(none+"."+parts[1]+".", "fincore-core-go"); err == nil {
+		t.Error("unsigned JWT accepted")
+	}
+}
+
+func TestVerifyRequestSignature(t *testing.T) {
+	secret := []byte("client-secret")
+	body := []byte(`{"amount":"10"}`)
+	signature := SignRequest(secret, "1700000000", body)
+
+	if !VerifyRequestSignature(secret, "1700000000", body, signature) {
+		t.Fatal("valid signature rejected")
+	}
+	if VerifyRequestSignature(secret, "1700000001", body, signature) {
+		t.Error("signature accepted with a different timestamp")
+	}
+	if VerifyRequestSignature(secret, "1700000000", []byte(`{"amount":"99"}`), signature) {
+		t.Error("signature accepted with a different body")
+	}
+	if VerifyRequestSignature([]byte("other-secret"), "1700000000", body, signature) {
+		t.Error("signature accepted with a different secret")
+	}
+
+	secrets := StaticClientSecrets{"app": secret}
+	if _, err := secrets.ClientSecret(context.Background(), "missing"); !errors.Is(err, ErrUnknownClient) {
+		t.Errorf("unknown client err = %v, want ErrUnknownClient", err)
+	}
+}
+
+func TestReceipt(t *testing.T) {
+	sm := newTestManager(t)
+	data := map[string]interface{}{"id": "tx-1", "amount": "1500.00", "status": "completed"}
+
+	receipt, err := sm.GenerateReceipt(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Algorithm != TokenAlgHS256 || receipt.Payload != `{"amount":"1500.00","id":"tx-1","status":"completed"}` {
+		t.Errorf("receipt = %+v, want HS256 over the canonical JSON", receipt)
+	}
+	if err := sm.VerifyReceipt(receipt); err != nil {
+		t.Fatalf("valid receipt rejected: %v", err)
+	}
+
+	tampered := receipt
+	tampered.Payload = strings.Replace(receipt.Payload, "1500.00", "9500.00", 1)
+	if err := sm.VerifyReceipt(tampered); !errors.Is(err, ErrInvalidReceipt) {
+		t.Errorf("tampered payload: err = %v, want ErrInvalidReceipt", err)
+	}
+	tampered = receipt
+	tampered.Signature = sm.CalculateIntegrityHMAC(data)
+	if err := sm.VerifyReceipt(tampered); !errors.Is(err, ErrInvalidReceipt) {
+		t.Errorf("integrity HMAC as signature: err = %v, want ErrInvalidReceipt", err)
+	}
+	tampered = receipt
+	tampered.Algorithm = "none"
+	if err := sm.VerifyReceipt(tampered); err == nil {
+		t.Error("receipt accepted with an unsupported algorithm")
+	}
+}
+
+func TestReceiptES256(t *testing.T) {
+	issuer, key := newECTestManager(t)
+	receipt, err := issuer.GenerateReceipt(map[string]interface{}{"id": "tx-1", "amount": "10"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Algorithm != TokenAlgES256 {
+		t.Fatalf("alg = %q, want ES256 with an ECDSA key", receipt.Algorithm)
+	}
+
+	// Un tercero verifica con sólo la clave pública
+	t.Setenv("SECRET_KEY", "another-secret-key-0123456789-abcdefghij")
+	verifier, err := NewSecurityManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifier.VerifyReceipt(receipt); !errors.Is(err, ErrECDSAKeyNotConfigured) {
+		t.Errorf("without public key: err = %v, want ErrECDSAKeyNotConfigured", err)
+	}
+	if err := verifier.SetECDSAKeys(nil, &key.PublicKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifier.VerifyReceipt(receipt); err != nil {
+		t.Fatalf("valid receipt rejected: %v", err)
+	}
+
+	receipt.Payload = strings.Replace(receipt.Payload, `"10"`, `"1000"`, 1)
+	if err := verifier.VerifyReceipt(receipt); !errors.Is(err, ErrInvalidReceipt) {
+		t.Errorf("tampered payload: err = %v, want ErrInvalidReceipt", err)
+	}
+}
+
+func TestRotateSigningKeyKeepsOldTokensDuringWindow(t *testing.T) {
+	sm := newTestManager(t)
+	now := time.Now()
+	sm.now = func() time.Time { return now }
+	sm.SetSigningKeyRotationWindow(10 * time.Minute)
+
+	// Los tokens tienen nonce y sólo verifican una vez: se emite uno para
+	// verificar durante la ventana y otro para después
+	issue := func() string {
+		t.Helper()
+		token, err := sm.GenerateServiceToken("python-backend", "fincore-core-go", []string{"calculate:metrics"}, 3600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	duringWindow, afterWindow := issue(), issue()
+	oldJWT, err := sm.GenerateJWT("python-backend", "fincore-core-go", []string{"calculate:metrics"}, 3600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sm.RotateSigningKey([]byte("rotated-secret-key-0123456789-abcdefgh")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sm.VerifyServiceTokenFor(duringWindow, "fincore-core-go"); err != nil {
+		t.Errorf("token signed before rotation during the window: %v", err)
+	}
+	if _, err := sm.VerifyJWT(oldJWT, "fincore-core-go"); err != nil {
+		t.Errorf("JWT signed before rotation during the window: %v", err)
+	}
+	// Los tokens nuevos se firman sólo con la clave nueva
+	newToken := issue()
+	if _, err := newTestManager(t).VerifyServiceTokenFor(newToken, "fincore-core-go"); err == nil {
+		t.Error("token signed after rotation must not verify with the old key")
+	}
+	if _, err := sm.VerifyServiceTokenFor(newToken, "fincore-core-go"); err != nil {
+		t.Errorf("token signed after rotation: %v", err)
+	}
+
+	// Pasada la ventana la clave anterior ya no verifica, aunque los tokens sigan vigentes
+	now = now.Add(11 * time.Minute)
+	if _, err := sm.VerifyServiceTokenFor(afterWindow, "fincore-core-go"); err == nil {
+		t.Error("token signed with a retired key must fail after the window")
+	}
+	if _, err := sm.VerifyJWT(oldJWT, "fincore-core-go"); err == nil {
+		t.Error("JWT signed with a retired key must fail after the window")
+	}
+	if _, err := sm.VerifyServiceTokenFor(issue(), "fincore-core-go"); err != nil {
+		t.Errorf("token signed with the primary key after the window: %v", err)
+	}
+}
+
+func TestDropSecondarySigningKeys(t *testing.T) {
+	sm := newTestManager(t)
+	oldToken, err := sm.GenerateServiceToken("python-backend", "fincore-core-go", []string{"calculate:metrics"}, 3600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.RotateSigningKey([]byte("rotated-secret-key-0123456789-abcdefgh")); err != nil {
+		t.Fatal(err)
+	}
+
+	sm.DropSecondarySigningKeys()
+	if _, err := sm.VerifyServiceTokenFor(oldToken, "fincore-core-go"); err == nil {
+		t.Error("token signed with a dropped key must fail")
+	}
+}
+
+func TestRotateSigningKeyValidation(t *testing.T) {
+	sm := newTestManager(t)
+	if err := sm.RotateSigningKey([]byte("short")); !errors.Is(err, ErrSigningKeyTooShort) {
+		t.Errorf("short key err = %v, want ErrSigningKeyTooShort", err)
+	}
+	if err := sm.RotateSigningKey([]byte("test-secret-key-0123456789-abcdefghij")); !errors.Is(err, ErrSigningKeyUnchanged) {
+		t.Errorf("same key err = %v, want ErrSigningKeyUnchanged", err)
+	}
+
+	records := sm.Audit().Records()
+	if err := sm.RotateSigningKey([]byte("rotated-secret-key-0123456789-abcdefgh")); err != nil {
+		t.Fatal(err)
+	}
+	if after := sm.Audit().Records(); len(after) != len(records)+1 || after[len(after)-1].Event != AuditSigningKeyRotated {
+		t.Errorf("audit records = %+v, want a rotation event", after)
+	}
+}
@@ -0,0 +1,55 @@
+package security
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+)
+
+// ErrUnknownClient indica que no hay secreto de firma para el cliente
+var ErrUnknownClient = errors.New("unknown signing client")
+
+// ClientSecretStore resuelve el secreto con el que cada cliente firma sus
+// requests. Permite mover los secretos a Vault o a una base de datos sin
+// cambiar el middleware.
+type ClientSecretStore interface {
+	// ClientSecret retorna ErrUnknownClient si el cliente no existe
+	ClientSecret(ctx context.Context, clientID string) ([]byte, error)
+}
+
+// StaticClientSecrets implementa ClientSecretStore con un mapa fijo
+type StaticClientSecrets map[string][]byte
+
+// ClientSecret busca el secreto del cliente en el mapa
+func (s StaticClientSecrets) ClientSecret(_ context.Context, clientID string) ([]byte, error) {
+	secret, ok := s[clientID]
+	if !ok || len(secret) == 0 {
+		return nil, ErrUnknownClient
+	}
+	return secret, nil
+}
+
+// SignRequest calcula la firma HMAC-SHA256 en hex de "timestamp.body". El
+// timestamp forma parte del mensaje para que no pueda cambiarse sin invalidar
+// la firma.
+func SignRequest(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyRequestSignature compara la firma en tiempo constante
+func VerifyRequestSignature(secret []byte, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(SignRequest(secret, timestamp, body)), []byte(signature))
+}
+
+// SignWebhook firma un webhook saliente con la clave primaria usando el mismo
+// esquema que SignRequest, de modo que el receptor lo verifique con
+// VerifyRequestSignature
+func (sm *SecurityManager) SignWebhook(timestamp string, payload []byte) string {
+	return SignRequest(sm.signingKeys.primaryKey(), timestamp, payload)
+}
@@ -0,0 +1,139 @@
+package security
+
+import (
+	"crypto/hmac"
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultSigningKeyRotationWindow es cuánto sigue verificando una clave de
+// firma reemplazada; cubre el TTL máximo de los tokens que emite el servicio
+const DefaultSigningKeyRotationWindow = 2 * time.Hour
+
+var (
+	// ErrSigningKeyTooShort indica una clave de firma de menos de 32 bytes
+	ErrSigningKeyTooShort = errors.New("signing key must be at least 32 bytes")
+	// ErrSigningKeyUnchanged indica que la clave nueva es la primaria actual
+	ErrSigningKeyUnchanged = errors.New("signing key is already the primary key")
+)
+
+// AuditSigningKeyRotated es el evento de auditoría de una rotación de SECRET_KEY
+const AuditSigningKeyRotated = "signing_key_rotated"
+
+// signingKeys contiene la clave primaria, la única que firma, y las claves
+// secundarias que se retiran: siguen verificando hasta retiresAt para que los
+// tokens emitidos antes de una rotación no se invaliden de golpe
+type signingKeys struct {
+	mu        sync.RWMutex
+	primary   []byte
+	secondary []retiringKey
+	window    time.Duration
+}
+
+type retiringKey struct {
+	key       []byte
+	retiresAt time.Time
+}
+
+func newSigningKeys(primary []byte) *signingKeys {
+	return &signingKeys{primary: primary, window: DefaultSigningKeyRotationWindow}
+}
+
+// primaryKey retorna la clave con la que se firma
+func (k *signingKeys) primaryKey() []byte {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.primary
+}
+
+// verificationKeys retorna la primaria seguida de las secundarias vigentes
+func (k *signingKeys) verificationKeys(now time.Time) [][]byte {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	keys := [][]byte{k.primary}
+	for _, secondary := range k.secondary {
+		if now.Before(secondary.retiresAt) {
+			keys = append(keys, secondary.key)
+		}
+	}
+	return keys
+}
+
+// rotate hace primaria a newKey y secundaria a la anterior hasta now+window,
+// descartando las secundarias ya retiradas
+func (k *signingKeys) rotate(newKey []byte, now time.Time) (time.Time, error) {
+	if len(newKey) < 32 {
+		return time.Time{}, ErrSigningKeyTooShort
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if hmac.Equal(newKey, k.primary) {
+		return time.Time{}, ErrSigningKeyUnchanged
+	}
+
+	active := k.secondary[:0]
+	for _, secondary := range k.secondary {
+		if now.Before(secondary.retiresAt) {
+			active = append(active, secondary)
+		}
+	}
+	retiresAt := now.Add(k.window)
+	k.secondary = append(active, retiringKey{key: k.primary, retiresAt: retiresAt})
+	k.primary = append([]byte(nil), newKey...)
+	return retiresAt, nil
+}
+
+// RotateSigningKey reemplaza la clave que firma los tokens de servicio, los
+// JWT y los webhooks. La clave anterior pasa a secundaria y los tokens que
+// firmó siguen verificando durante la ventana de rotación. La rotación sólo
+// afecta a este proceso: cada réplica y cada servicio que comparte
+// SECRET_KEY debe rotarla también.
+func (sm *SecurityManager) RotateSigningKey(newKey []byte) error {
+	retiresAt, err := sm.signingKeys.rotate(newKey, sm.now())
+	if err != nil {
+		return err
+	}
+	sm.audit.Record(AuditSigningKeyRotated, map[string]string{
+		"previous_key_retires_at": retiresAt.UTC().Format(time.RFC3339),
+	})
+	return nil
+}
+
+// DropSecondarySigningKeys deja de aceptar las claves reemplazadas antes de
+// que termine su ventana, por ejemplo si una se vio comprometida
+func (sm *SecurityManager) DropSecondarySigningKeys() {
+	sm.signingKeys.mu.Lock()
+	defer sm.signingKeys.mu.Unlock()
+	sm.signingKeys.secondary = nil
+}
+
+// SetSigningKeyRotationWindow configura cuánto verifica una clave reemplazada
+// por RotateSigningKey; un valor no positivo restaura
+// DefaultSigningKeyRotationWindow. Aplica a las rotaciones siguientes.
+func (sm *SecurityManager) SetSigningKeyRotationWindow(window time.Duration) {
+	if window <= 0 {
+		window = DefaultSigningKeyRotationWindow
+	}
+	sm.signingKeys.mu.Lock()
+	defer sm.signingKeys.mu.Unlock()
+	sm.signingKeys.window = window
+}
+
+// SigningKeyRotationWindow retorna la ventana de rotación configurada
+func (sm *SecurityManager) SigningKeyRotationWindow() time.Duration {
+	sm.signingKeys.mu.RLock()
+	defer sm.signingKeys.mu.RUnlock()
+	return sm.signingKeys.window
+}
+
+// matchesSigningKey indica si sign, calculada con alguna de las claves de
+// verificación vigentes, coincide con signature
+func (sm *SecurityManager) matchesSigningKey(signature string, sign func(key []byte) string) bool {
+	for _, key := range sm.signingKeys.verificationKeys(sm.now()) {
+		if hmac.Equal([]byte(signature), []byte(sign(key))) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package security
+
+import (
+	"sync"
+	"time"
+)
+
+// MaxTrackedNonces limita cuántos nonces vigentes se recuerdan a la vez
+const MaxTrackedNonces = 100000
+
+// expiringSet es un conjunto en memoria cuyos elementos caducan solos.
+// Las entradas vencidas se purgan de forma perezosa al agregar elementos.
+type expiringSet struct {
+	mu      sync.Mutex
+	entries map[string]time.Time
+}
+
+func newExpiringSet() *expiringSet {
+	return &expiringSet{entries: make(map[string]time.Time)}
+}
+
+// add registra la clave hasta expiresAt; si ya existía conserva la expiración más lejana
+func (s *expiringSet) add(key string, expiresAt, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purge(now)
+	if current, ok := s.entries[key]; !ok || expiresAt.After(current) {
+		s.entries[key] = expiresAt
+	}
+}
+
+// addIfAbsent registra la clave sólo si no está vigente; retorna false si ya estaba
+func (s *expiringSet) addIfAbsent(key string, expiresAt, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if current, ok := s.entries[key]; ok && now.Before(current) {
+		return false
+	}
+	s.purge(now)
+	s.entries[key] = expiresAt
+	return true
+}
+
+// contains indica si la clave está registrada y aún no expiró
+func (s *expiringSet) contains(key string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expiresAt, ok := s.entries[key]
+	return ok && now.Before(expiresAt)
+}
+
+// len retorna cuántas entradas no vencidas quedan tras purgar
+func (s *expiringSet) len(now time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purge(now)
+	return len(s.entries)
+}
+
+func (s *expiringSet) purge(now time.Time) {
+	for key, expiresAt := range s.entries {
+		if !now.Before(expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// RevokeToken invalida un token de servicio antes de su expiración.
+// expiresAt debe ser el ExpiresAt del propio token: pasado ese momento (más
+// la tolerancia de reloj) el token ya es rechazado por expiración y la
+// entrada se elimina sola.
+func (sm *SecurityManager) RevokeToken(tokenID string, expiresAt time.Time) {
+	sm.revokedTokens.add(tokenID, expiresAt.Add(sm.leeway), sm.now())
+	sm.audit.Record(AuditTokenRevoked, map[string]string{"token_id": tokenID})
+}
+
+// revokeOnce revoca el TokenID como RevokeToken; retorna false sin registrar
+// nada si ya estaba revocado
+func (sm *SecurityManager) revokeOnce(tokenID string, expiresAt time.Time) bool {
+	if !sm.revokedTokens.addIfAbsent(tokenID, expiresAt.Add(sm.leeway), sm.now()) {
+		return false
+	}
+	sm.audit.Record(AuditTokenRevoked, map[string]string{"token_id": tokenID})
+	return true
+}
+
+// IsTokenRevoked indica si el TokenID fue revocado
+func (sm *SecurityManager) IsTokenRevoked(tokenID string) bool {
+	return sm.revokedTokens.contains(tokenID, sm.now())
+}
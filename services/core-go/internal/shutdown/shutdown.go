@@ -0,0 +1,143 @@
+/*
+Apagado ordenado de componentes
+
+Implementa:
+- Registro de los componentes que deben detenerse al apagar el servicio
+- Señal de parada simultánea con un plazo compartido
+- Reporte de los componentes que fallaron o no terminaron a tiempo
+- Logs de progreso con backoff exponencial y jitter mientras se espera
+*/
+package shutdown
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// initialProgressInterval es la espera antes del primer log de progreso
+	initialProgressInterval = time.Second
+	// maxProgressInterval acota el backoff entre logs de progreso
+	maxProgressInterval = 8 * time.Second
+)
+
+// StopFunc detiene un componente y espera a que termine; debe retornar, con
+// ctx.Err() si corresponde, cuando ctx vence
+type StopFunc func(ctx context.Context) error
+
+type component struct {
+	name string
+	stop StopFunc
+}
+
+// Manager detiene en paralelo los componentes registrados. Los componentes
+// que dependen de otros (por ejemplo los webhooks, que encolan los requests
+// en curso) se registran en un Manager que se apaga después.
+type Manager struct {
+	mu         sync.Mutex
+	components []component
+	logger     *slog.Logger
+
+	// progressInterval es la primera espera entre logs de progreso
+	progressInterval time.Duration
+}
+
+// NewManager crea un Manager sin componentes que registra el apagado en logger
+func NewManager(logger *slog.Logger) *Manager {
+	return &Manager{logger: logger, progressInterval: initialProgressInterval}
+}
+
+// Register agrega un componente que Shutdown detendrá
+func (m *Manager) Register(name string, stop StopFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.components = append(m.components, component{name: name, stop: stop})
+}
+
+// Report es el resultado de Shutdown; cada lista está ordenada por nombre
+type Report struct {
+	Completed []string
+	// Failed son los componentes que terminaron con error
+	Failed map[string]error
+	// Unfinished son los componentes que seguían deteniéndose al vencer el plazo
+	Unfinished []string
+}
+
+// Clean indica si todos los componentes terminaron sin error
+func (r Report) Clean() bool {
+	return len(r.Failed) == 0 && len(r.Unfinished) == 0
+}
+
+type stopResult struct {
+	name string
+	err  error
+}
+
+// Shutdown señala a todos los componentes que se detengan y los espera hasta
+// que terminen o venza ctx. Los que sigan corriendo al vencer el plazo se
+// informan en Unfinished y no se esperan más.
+func (m *Manager) Shutdown(ctx context.Context) Report {
+	m.mu.Lock()
+	components := append([]component(nil), m.components...)
+	m.mu.Unlock()
+
+	report := Report{Failed: make(map[string]error)}
+	pending := make(map[string]bool, len(components))
+	// Con buffer para que un componente tardío no quede bloqueado al terminar
+	results := make(chan stopResult, len(components))
+	for _, c := range components {
+		pending[c.name] = true
+		go func(c component) {
+			results <- stopResult{name: c.name, err: c.stop(ctx)}
+		}(c)
+	}
+
+	interval := m.progressInterval
+	progress := time.NewTimer(withJitter(interval))
+	defer progress.Stop()
+
+	for len(pending) > 0 {
+		select {
+		case r := <-results:
+			delete(pending, r.name)
+			if r.err != nil {
+				report.Failed[r.name] = r.err
+				m.logger.Warn("Component failed to stop", "component", r.name, "error", r.err)
+			} else {
+				report.Completed = append(report.Completed, r.name)
+			}
+		case <-progress.C:
+			m.logger.Info("Waiting for components to stop", "components", names(pending))
+			interval = min(2*interval, maxProgressInterval)
+			progress.Reset(withJitter(interval))
+		case <-ctx.Done():
+			report.Unfinished = names(pending)
+			sort.Strings(report.Completed)
+			m.logger.Warn("Components did not stop before the deadline", "components", report.Unfinished)
+			return report
+		}
+	}
+
+	sort.Strings(report.Completed)
+	return report
+}
+
+// withJitter varía d en ±20% para no alinear los logs de varias réplicas
+func withJitter(d time.Duration) time.Duration {
+	jitter := int64(d) / 5
+	return d + time.Duration(rand.Int64N(2*jitter+1)-jitter)
+}
+
+// names retorna las claves del set ordenadas
+func names(set map[string]bool) []string {
+	list := make([]string, 0, len(set))
+	for name := range set {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,106 @@
+package shutdown
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestManager(logs *bytes.Buffer) *Manager {
+	m := NewManager(slog.New(slog.NewTextHandler(logs, nil)))
+	m.progressInterval = 5 * time.Millisecond
+	return m
+}
+
+func TestShutdownSignalsRegisteredComponents(t *testing.T) {
+	var logs bytes.Buffer
+	m := newTestManager(&logs)
+
+	var signaled atomic.Int32
+	for _, name := range []string{"webhooks", "workers"} {
+		m.Register(name, func(ctx context.Context) error {
+			signaled.Add(1)
+			return nil
+		})
+	}
+	errFlush := errors.New("flush failed")
+	m.Register("audit", func(ctx context.Context) error { return errFlush })
+
+	report := m.Shutdown(context.Background())
+	if signaled.Load() != 2 {
+		t.Errorf("signaled = %d, want 2", signaled.Load())
+	}
+	if len(report.Completed) != 2 || report.Completed[0] != "webhooks" || report.Completed[1] != "workers" {
+		t.Errorf("completed = %v, want [webhooks workers]", report.Completed)
+	}
+	if !errors.Is(report.Failed["audit"], errFlush) || len(report.Unfinished) != 0 || report.Clean() {
+		t.Errorf("report = %+v, want audit failed and nothing unfinished", report)
+	}
+}
+
+func TestShutdownStopsComponentsConcurrently(t *testing.T) {
+	var logs bytes.Buffer
+	m := newTestManager(&logs)
+
+	// Cada componente espera a que el otro haya recibido la señal
+	first, second := make(chan struct{}), make(chan struct{})
+	m.Register("first", func(ctx context.Context) error {
+		close(first)
+		<-second
+		return nil
+	})
+	m.Register("second", func(ctx context.Context) error {
+		close(second)
+		<-first
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if report := m.Shutdown(ctx); !report.Clean() || len(report.Completed) != 2 {
+		t.Errorf("report = %+v, want both completed", report)
+	}
+}
+
+func TestShutdownReportsUnfinishedComponents(t *testing.T) {
+	var logs bytes.Buffer
+	m := newTestManager(&logs)
+
+	m.Register("fast", func(ctx context.Context) error { return nil })
+	// Ignora el contexto, como un componente que no sabe cancelarse
+	release := make(chan struct{})
+	defer close(release)
+	m.Register("stuck", func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	report := m.Shutdown(ctx)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Shutdown took %v, want it bounded by the deadline", elapsed)
+	}
+
+	if len(report.Unfinished) != 1 || report.Unfinished[0] != "stuck" || len(report.Completed) != 1 || report.Clean() {
+		t.Errorf("report = %+v, want stuck unfinished and fast completed", report)
+	}
+	output := logs.String()
+	if !strings.Contains(output, "Waiting for components to stop") || !strings.Contains(output, "did not stop before the deadline") {
+		t.Errorf("logs = %q, want progress and deadline messages", output)
+	}
+}
+
+func TestWithJitter(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		if d := withJitter(time.Second); d < 800*time.Millisecond || d > 1200*time.Millisecond {
+			t.Fatalf("withJitter(1s) = %v, want within ±20%%", d)
+		}
+	}
+}
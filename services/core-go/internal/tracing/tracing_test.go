@@ -0,0 +1,105 @@
+package tracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// useInMemoryExporter registra un TracerProvider que guarda los spans en memoria
+func useInMemoryExporter(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return exporter
+}
+
+func newTracedRouter() *gin.Engine {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("request_id", c.GetHeader("X-Test-Request-ID"))
+		c.Next()
+	})
+	router.Use(Middleware())
+	router.GET("/items/:id", func(c *gin.Context) {
+		_, span := Start(c.Request.Context(), "store.get")
+		span.End()
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func attributeValue(attrs []attribute.KeyValue, key string) string {
+	for _, attr := range attrs {
+		if string(attr.Key) == key {
+			return attr.Value.Emit()
+		}
+	}
+	return ""
+}
+
+func TestMiddlewareCreatesSpanPerRequest(t *testing.T) {
+	exporter := useInMemoryExporter(t)
+	router := newTracedRouter()
+
+	for _, requestID := range []string{"req-1", "req-2"} {
+		req := httptest.NewRequest(http.MethodGet, "/items/42", nil)
+		req.Header.Set("X-Test-Request-ID", requestID)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	var roots []sdktrace.ReadOnlySpan
+	spans := exporter.GetSpans().Snapshots()
+	for _, span := range spans {
+		if span.Name() == "GET /items/:id" {
+			roots = append(roots, span)
+		}
+	}
+	if len(roots) != 2 {
+		t.Fatalf("got %d request spans, want 2 (all spans: %d)", len(roots), len(spans))
+	}
+	for i, want := range []string{"req-1", "req-2"} {
+		if got := attributeValue(roots[i].Attributes(), "request_id"); got != want {
+			t.Errorf("span %d request_id = %q, want %q", i, got, want)
+		}
+	}
+
+	// El span del store es hijo del span del request
+	for _, span := range spans {
+		if span.Name() == "store.get" && !span.Parent().IsValid() {
+			t.Error("store span has no parent")
+		}
+	}
+}
+
+func TestMiddlewareContinuesUpstreamTrace(t *testing.T) {
+	exporter := useInMemoryExporter(t)
+	router := newTracedRouter()
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/items/42", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans().Snapshots()
+	if len(spans) == 0 {
+		t.Fatal("no spans recorded")
+	}
+	for _, span := range spans {
+		if got := span.SpanContext().TraceID().String(); got != traceID {
+			t.Errorf("span %s trace id = %s, want %s", span.Name(), got, traceID)
+		}
+	}
+}